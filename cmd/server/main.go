@@ -97,6 +97,17 @@ func main() {
 			} else {
 				logger.Info().Msg("Temporal worker started successfully.")
 				defer temporalWorker.Stop()
+
+				// Reconcile runs left unfinished by crashed workers or timed-out workflows
+				reconcileSchedule, ok := os.LookupEnv("RUN_RECONCILE_SCHEDULE")
+				if !ok {
+					reconcileSchedule = "*/5 * * * *"
+				}
+				if err := temporalClient.ScheduleRunReconciler(context.Background(), reconcileSchedule); err != nil {
+					logger.Warn().Err(err).Msg("Failed to schedule run reconciler")
+				} else {
+					logger.Info().Str("schedule", reconcileSchedule).Msg("Run reconciler scheduled.")
+				}
 			}
 		}
 	}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/backfill"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/service"
+)
+
+// StartBackfillHandler handles POST requests to /replication-tasks/{id}/backfill. The body holds
+// the range, step and max_parallel; the backfill runs in the background and the new backfill
+// is returned with 202 Accepted.
+func (h *APIHandler) StartBackfillHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := compareTaskID(w, r, "backfill")
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	defer r.Body.Close()
+
+	record, err := h.svc.StartBackfill(r.Context(), taskID, strings.TrimSpace(string(body)))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		case errors.Is(err, backfill.ErrInvalidBackfill):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error starting backfill")
+			respondWithError(w, http.StatusInternalServerError, "Failed to start backfill")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusAccepted, record)
+}
+
+// ListBackfillsHandler handles GET requests to /replication-tasks/{id}/backfills.
+func (h *APIHandler) ListBackfillsHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := compareTaskID(w, r, "backfills")
+	if !ok {
+		return
+	}
+
+	backfills, err := h.svc.ListBackfills(r.Context(), taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		} else {
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error listing backfills")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve backfills")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, backfills)
+}
+
+// GetBackfillHandler handles GET requests to /backfills/{id}.
+func (h *APIHandler) GetBackfillHandler(w http.ResponseWriter, r *http.Request) {
+	backfillID, ok := backfillPathID(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := h.svc.GetBackfill(r.Context(), backfillID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Backfill not found")
+		} else {
+			h.logger.Error().Err(err).Int64("backfill_id", backfillID).Msg("Error getting backfill")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve backfill")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, record)
+}
+
+// ListBackfillSlicesHandler handles GET requests to /backfills/{id}/slices.
+func (h *APIHandler) ListBackfillSlicesHandler(w http.ResponseWriter, r *http.Request) {
+	backfillID, ok := backfillPathID(w, r)
+	if !ok {
+		return
+	}
+
+	slices, err := h.svc.ListBackfillSlices(r.Context(), backfillID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Backfill not found")
+		} else {
+			h.logger.Error().Err(err).Int64("backfill_id", backfillID).Msg("Error listing backfill slices")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve backfill slices")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, slices)
+}
+
+// ControlBackfillHandler handles POST requests to /backfills/{id}/pause, /resume and /cancel.
+// The request is passed to the backfill's workflow and the backfill is returned with 202
+// Accepted; its status changes once the workflow has acted on it.
+func (h *APIHandler) ControlBackfillHandler(w http.ResponseWriter, r *http.Request) {
+	backfillID, ok := backfillPathID(w, r)
+	if !ok {
+		return
+	}
+
+	var record *data.Backfill
+	var err error
+	action := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[2]
+	switch action {
+	case backfill.ActionPause:
+		record, err = h.svc.PauseBackfill(r.Context(), backfillID)
+	case backfill.ActionResume:
+		record, err = h.svc.ResumeBackfill(r.Context(), backfillID)
+	case backfill.ActionCancel:
+		record, err = h.svc.CancelBackfill(r.Context(), backfillID)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Backfill not found")
+		case errors.Is(err, service.ErrBackfillNotActive):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("backfill_id", backfillID).Str("action", action).Msg("Error controlling backfill")
+			respondWithError(w, http.StatusInternalServerError, "Failed to "+action+" backfill")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusAccepted, record)
+}
+
+// backfillPathID parses the backfill ID of a /backfills/{id}[/...] path.
+func backfillPathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) < 2 || len(pathParts) > 3 || pathParts[0] != "backfills" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return 0, false
+	}
+	backfillID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid backfill ID")
+		return 0, false
+	}
+	return backfillID, true
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+)
+
+// StartCompareHandler handles POST requests to /replication-tasks/{id}/compare. The optional
+// body holds the compare options; the compare runs in the background and the new report is
+// returned with 202 Accepted.
+func (h *APIHandler) StartCompareHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := compareTaskID(w, r, "compare")
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	defer r.Body.Close()
+
+	report, err := h.svc.StartCompare(r.Context(), taskID, strings.TrimSpace(string(body)))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		case errors.Is(err, compare.ErrInvalidOptions), errors.Is(err, compare.ErrUnsupported):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error starting compare")
+			respondWithError(w, http.StatusInternalServerError, "Failed to start compare")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusAccepted, report)
+}
+
+// ListCompareReportsHandler handles GET requests to /replication-tasks/{id}/compare-reports.
+func (h *APIHandler) ListCompareReportsHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := compareTaskID(w, r, "compare-reports")
+	if !ok {
+		return
+	}
+
+	reports, err := h.svc.ListCompareReports(r.Context(), taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		} else {
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error listing compare reports")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve compare reports")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, reports)
+}
+
+// GetCompareReportHandler handles GET requests to /compare-reports/{id}.
+func (h *APIHandler) GetCompareReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	reportID, err := strconv.ParseInt(strings.Trim(r.URL.Path[len("/compare-reports/"):], "/"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid compare report ID")
+		return
+	}
+
+	report, err := h.svc.GetCompareReport(r.Context(), reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Compare report not found")
+		} else {
+			h.logger.Error().Err(err).Int64("report_id", reportID).Msg("Error getting compare report")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve compare report")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+func compareTaskID(w http.ResponseWriter, r *http.Request, resource string) (int64, bool) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-tasks" || pathParts[2] != resource {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return 0, false
+	}
+	taskID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication task ID")
+		return 0, false
+	}
+	return taskID, true
+}
+
+// StartRefreshHandler handles POST requests to /compare-reports/{id}/refresh. The optional body
+// holds the refresh options; the refresh runs in the background and the new refresh is
+// returned with 202 Accepted.
+func (h *APIHandler) StartRefreshHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "compare-reports" || pathParts[2] != "refresh" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	reportID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid compare report ID")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	defer r.Body.Close()
+
+	refresh, err := h.svc.StartRefresh(r.Context(), reportID, strings.TrimSpace(string(body)))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Compare report not found")
+		case errors.Is(err, compare.ErrInvalidOptions):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, compare.ErrNothingToRefresh):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("report_id", reportID).Msg("Error starting refresh")
+			respondWithError(w, http.StatusInternalServerError, "Failed to start refresh")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusAccepted, refresh)
+}
+
+// GetRefreshHandler handles GET requests to /refreshes/{id}.
+func (h *APIHandler) GetRefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	refreshID, err := strconv.ParseInt(strings.Trim(r.URL.Path[len("/refreshes/"):], "/"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid refresh ID")
+		return
+	}
+
+	refresh, err := h.svc.GetRefresh(r.Context(), refreshID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Refresh not found")
+		} else {
+			h.logger.Error().Err(err).Int64("refresh_id", refreshID).Msg("Error getting refresh")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve refresh")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, refresh)
+}
+
+// ListRefreshesHandler handles GET requests to /replication-tasks/{id}/refreshes.
+func (h *APIHandler) ListRefreshesHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := compareTaskID(w, r, "refreshes")
+	if !ok {
+		return
+	}
+
+	refreshes, err := h.svc.ListRefreshes(r.Context(), taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		} else {
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error listing refreshes")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve refreshes")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, refreshes)
+}
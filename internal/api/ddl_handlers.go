@@ -0,0 +1,46 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// PreviewTargetDDLHandler handles GET requests to /replication-tasks/{id}/ddl.
+func (h *APIHandler) PreviewTargetDDLHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-tasks" || pathParts[2] != "ddl" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	taskID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication task ID")
+		return
+	}
+
+	tables, err := h.svc.PreviewTargetDDL(r.Context(), taskID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		case errors.Is(err, schema.ErrTableNotFound):
+			respondWithError(w, http.StatusNotFound, "Table not found")
+		case errors.Is(err, ddl.ErrUnsupportedTarget), errors.Is(err, schema.ErrUnsupportedType), errors.Is(err, ddl.ErrNoTypeMapping),
+			errors.Is(err, criteria.ErrInvalidTemplate):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error previewing target DDL")
+			// The source is unreachable or rejected the query, or the task's rules are invalid
+			respondWithError(w, http.StatusBadGateway, "Failed to preview target DDL")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, tables)
+}
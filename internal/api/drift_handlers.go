@@ -0,0 +1,104 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// ListSchemaSnapshotsHandler handles GET requests to /replication-tasks/{id}/schema-snapshots.
+func (h *APIHandler) ListSchemaSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := schemaSnapshotsTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	snapshots, err := h.svc.ListSchemaSnapshots(r.Context(), taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		} else {
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error listing schema snapshots")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve schema snapshots")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, snapshots)
+}
+
+// ResetSchemaSnapshotsHandler handles DELETE requests to /replication-tasks/{id}/schema-snapshots.
+// Removing the snapshots accepts the current source schema as the new drift baseline.
+func (h *APIHandler) ResetSchemaSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := schemaSnapshotsTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.ResetSchemaSnapshots(r.Context(), taskID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		} else {
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error resetting schema snapshots")
+			respondWithError(w, http.StatusInternalServerError, "Failed to reset schema snapshots")
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func schemaSnapshotsTaskID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-tasks" || pathParts[2] != "schema-snapshots" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return 0, false
+	}
+	taskID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication task ID")
+		return 0, false
+	}
+	return taskID, true
+}
+
+// ListEventsHandler handles GET requests to /events. The optional task_id, run_id, type and
+// limit query parameters narrow the feed.
+func (h *APIHandler) ListEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := data.EventFilter{Type: query.Get("type")}
+	for name, target := range map[string]*int64{"task_id": &filter.TaskID, "run_id": &filter.RunID} {
+		if value := query.Get(name); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				respondWithError(w, http.StatusBadRequest, "Invalid "+name)
+				return
+			}
+			*target = id
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > 1000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit: must be between 1 and 1000")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.svc.ListEvents(r.Context(), filter)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing events")
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve events")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, events)
+}
@@ -5,13 +5,20 @@ import (
 	"encoding/json"
 	"errors" // Import necessary for errors.Is
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
 	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/partition"
+	"github.com/eleon00/hsoetlnlm/internal/policy"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
 	"github.com/eleon00/hsoetlnlm/internal/service"
+	"github.com/eleon00/hsoetlnlm/internal/stream"
 	"github.com/go-playground/validator/v10" // Import validator
 	"github.com/rs/zerolog"                  // Import zerolog
 )
@@ -262,6 +269,27 @@ func (h *APIHandler) DeleteConnectionHandler(w http.ResponseWriter, r *http.Requ
 	respondWithJSON(w, http.StatusNoContent, nil) // 204 No Content on successful deletion
 }
 
+// ListConnectionLeasesHandler handles GET requests to /connections/{id}/leases, listing the
+// runs holding a slot of the connection's max_concurrent_runs.
+func (h *APIHandler) ListConnectionLeasesHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseConnectionPathID(w, r)
+	if !ok {
+		return
+	}
+
+	leases, err := h.svc.ListConnectionLeases(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Connection not found")
+		} else {
+			h.logger.Error().Err(err).Int64("connection_id", id).Msg("Error listing connection leases")
+			respondWithError(w, http.StatusInternalServerError, "Failed to list connection leases")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, leases)
+}
+
 // --- Replication Task Handlers ---
 
 // ListReplicationTasksHandler handles GET requests to /replication-tasks.
@@ -316,6 +344,38 @@ func (h *APIHandler) CreateReplicationTaskHandler(w http.ResponseWriter, r *http
 		}
 		return
 	}
+	if _, err := schema.ParseTableRules(input.TableRules); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := ddl.ParseColumnTypes(input.ColumnTypes); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := schema.ParseDriftPolicy(input.DriftPolicy); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := partition.Parse(input.Partitioning); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := criteria.Validate(input.DataSelectionCriteria); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := policy.Parse(input.ExecutionPolicy); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := stream.Parse(input.StreamConfig); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if stream.Enabled(input.StreamConfig) && input.TableRules != "" {
+		respondWithError(w, http.StatusBadRequest, "multi-table tasks cannot stream")
+		return
+	}
 	// --- End Input Validation ---
 
 	newID, err := h.svc.CreateReplicationTask(r.Context(), &input)
@@ -405,6 +465,38 @@ func (h *APIHandler) UpdateReplicationTaskHandler(w http.ResponseWriter, r *http
 		}
 		return
 	}
+	if _, err := schema.ParseTableRules(input.TableRules); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := ddl.ParseColumnTypes(input.ColumnTypes); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := schema.ParseDriftPolicy(input.DriftPolicy); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := partition.Parse(input.Partitioning); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := criteria.Validate(input.DataSelectionCriteria); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := policy.Parse(input.ExecutionPolicy); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := stream.Parse(input.StreamConfig); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if stream.Enabled(input.StreamConfig) && input.TableRules != "" {
+		respondWithError(w, http.StatusBadRequest, "multi-table tasks cannot stream")
+		return
+	}
 	// --- End Input Validation ---
 
 	err = h.svc.UpdateReplicationTask(r.Context(), &input)
@@ -706,3 +798,274 @@ func (h *APIHandler) GetReplicationRunHandler(w http.ResponseWriter, r *http.Req
 
 	respondWithJSON(w, http.StatusOK, runDetails)
 }
+
+// ListReplicationRunTablesHandler handles GET requests to /replication-runs/{run_id}/tables
+func (h *APIHandler) ListReplicationRunTablesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-runs" || pathParts[2] != "tables" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	runID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication run ID")
+		return
+	}
+
+	if _, err := h.svc.GetReplicationRunDetails(r.Context(), runID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication run not found")
+		} else {
+			h.logger.Error().Err(err).Int64("run_id", runID).Msg("Error getting replication run details")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve replication run details")
+		}
+		return
+	}
+
+	tableRuns, err := h.svc.ListReplicationRunTables(r.Context(), runID)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("run_id", runID).Msg("Error listing replication run tables")
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve replication run tables")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tableRuns)
+}
+
+// ListReplicationRunChunksHandler handles GET requests to /replication-runs/{run_id}/chunks
+func (h *APIHandler) ListReplicationRunChunksHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-runs" || pathParts[2] != "chunks" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	runID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication run ID")
+		return
+	}
+
+	if _, err := h.svc.GetReplicationRunDetails(r.Context(), runID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication run not found")
+		} else {
+			h.logger.Error().Err(err).Int64("run_id", runID).Msg("Error getting replication run details")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve replication run details")
+		}
+		return
+	}
+
+	chunks, err := h.svc.ListRunChunks(r.Context(), runID)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("run_id", runID).Msg("Error listing replication run chunks")
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve replication run chunks")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chunks)
+}
+
+// RunReplicationTaskHandler handles POST requests to /replication-tasks/{task_id}/run. The
+// optional body holds the run's template parameters and logical time; the run starts in the
+// background and its workflow ID is returned with 202 Accepted.
+func (h *APIHandler) RunReplicationTaskHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-tasks" || pathParts[2] != "run" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	taskID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication task ID")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	defer r.Body.Close()
+	trigger, err := criteria.ParseTrigger(string(body))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	workflowID, err := h.svc.StartReplicationTask(r.Context(), taskID, trigger)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		case errors.Is(err, criteria.ErrInvalidTemplate):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrTaskPaused):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error starting replication task")
+			respondWithError(w, http.StatusInternalServerError, "Failed to start replication task")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{"workflow_id": workflowID})
+}
+
+// ControlReplicationTaskHandler handles POST requests to /replication-tasks/{task_id}/pause,
+// /resume and /skip-next. Pause and resume are signalled to the task's run in progress, if
+// any; the task is returned with 202 Accepted.
+func (h *APIHandler) ControlReplicationTaskHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-tasks" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	taskID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication task ID")
+		return
+	}
+
+	var task *data.ReplicationTask
+	action := pathParts[2]
+	switch action {
+	case service.TaskActionPause:
+		task, err = h.svc.PauseReplicationTask(r.Context(), taskID)
+	case service.TaskActionResume:
+		task, err = h.svc.ResumeReplicationTask(r.Context(), taskID)
+	case service.TaskActionSkipNext:
+		task, err = h.svc.SkipNextReplicationRun(r.Context(), taskID)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		case errors.Is(err, service.ErrTaskPaused), errors.Is(err, service.ErrTaskNotPaused):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("task_id", taskID).Str("action", action).Msg("Error controlling replication task")
+			respondWithError(w, http.StatusInternalServerError, "Failed to "+action+" replication task")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, task)
+}
+
+// ResumeReplicationRunHandler handles POST requests to /replication-runs/{run_id}/resume
+func (h *APIHandler) ResumeReplicationRunHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-runs" || pathParts[2] != "resume" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	runID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication run ID")
+		return
+	}
+
+	run, err := h.svc.ResumeReplicationRun(r.Context(), runID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication run not found")
+		case errors.Is(err, service.ErrRunNotResumable), errors.Is(err, service.ErrTaskPaused), errors.Is(err, service.ErrTaskWorkflowRunning):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("run_id", runID).Msg("Error resuming replication run")
+			respondWithError(w, http.StatusInternalServerError, "Failed to resume replication run")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, run)
+}
+
+// CancelReplicationRunHandler handles POST requests to /replication-runs/{run_id}/cancel
+func (h *APIHandler) CancelReplicationRunHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-runs" || pathParts[2] != "cancel" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	runID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication run ID")
+		return
+	}
+
+	run, err := h.svc.CancelReplicationRun(r.Context(), runID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication run not found")
+		case errors.Is(err, service.ErrRunNotCancellable):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("run_id", runID).Msg("Error cancelling replication run")
+			respondWithError(w, http.StatusInternalServerError, "Failed to cancel replication run")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, run)
+}
+
+// RerunReplicationRunHandler handles POST requests to /replication-runs/{run_id}/rerun. The new
+// run is returned with 202 Accepted.
+func (h *APIHandler) RerunReplicationRunHandler(w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 3 || pathParts[0] != "replication-runs" || pathParts[2] != "rerun" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+	runID, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid replication run ID")
+		return
+	}
+
+	run, err := h.svc.RerunReplicationRun(r.Context(), runID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Replication run not found")
+		case errors.Is(err, service.ErrRunNotRerunnable), errors.Is(err, service.ErrTaskPaused), errors.Is(err, service.ErrTaskWorkflowRunning):
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("run_id", runID).Msg("Error rerunning replication run")
+			respondWithError(w, http.StatusInternalServerError, "Failed to rerun replication run")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, run)
+}
+
+// ReconcileReplicationRunsHandler handles POST requests to /replication-runs/reconcile. It runs
+// the scheduled run reconciliation at once and returns its report.
+func (h *APIHandler) ReconcileReplicationRunsHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := h.svc.ReconcileReplicationRuns(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error reconciling replication runs")
+		respondWithError(w, http.StatusInternalServerError, "Failed to reconcile replication runs")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/lag"
+)
+
+// GetReplicationLagHandler handles GET requests to /replication-tasks/{id}/lag. The optional
+// since (RFC 3339, default an hour ago) and limit (default 1000, at most 10000) query
+// parameters select the samples of the time series.
+func (h *APIHandler) GetReplicationLagHandler(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := compareTaskID(w, r, "lag")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	since := time.Now().Add(-time.Hour)
+	if value := query.Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since: must be an RFC 3339 time")
+			return
+		}
+		since = parsed
+	}
+	limit := 1000
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > 10000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit: must be between 1 and 10000")
+			return
+		}
+		limit = parsed
+	}
+
+	result, err := h.svc.GetReplicationLag(r.Context(), taskID, since, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Replication task not found")
+		} else {
+			h.logger.Error().Err(err).Int64("task_id", taskID).Msg("Error retrieving replication lag")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve replication lag")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// MetricsHandler handles GET requests to /metrics, serving the replication lag gauges of
+// streaming tasks in the Prometheus text format.
+func (h *APIHandler) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	tasks, err := h.svc.ListStreamingTaskLag(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing replication lag for metrics")
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve metrics")
+		return
+	}
+	var body bytes.Buffer
+	if err := lag.WritePrometheus(&body, tasks); err != nil {
+		h.logger.Error().Err(err).Msg("Error writing metrics")
+		respondWithError(w, http.StatusInternalServerError, "Failed to write metrics")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
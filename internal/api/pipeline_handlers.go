@@ -0,0 +1,256 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/dag"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/go-playground/validator/v10"
+)
+
+// CreatePipelineHandler handles POST requests to /pipelines.
+func (h *APIHandler) CreatePipelineHandler(w http.ResponseWriter, r *http.Request) {
+	var input data.Pipeline
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.logger.Error().Err(err).Msg("Error decoding create pipeline request")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	if !h.validatePipeline(w, &input) {
+		return
+	}
+
+	newID, err := h.svc.CreatePipeline(r.Context(), &input)
+	if err != nil {
+		if errors.Is(err, dag.ErrInvalidPipeline) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		} else {
+			h.logger.Error().Err(err).Msg("Error creating pipeline")
+			respondWithError(w, http.StatusInternalServerError, "Failed to create pipeline")
+		}
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/pipelines/%d", newID))
+	input.ID = newID
+	respondWithJSON(w, http.StatusCreated, input)
+}
+
+// ListPipelinesHandler handles GET requests to /pipelines.
+func (h *APIHandler) ListPipelinesHandler(w http.ResponseWriter, r *http.Request) {
+	pipelines, err := h.svc.ListPipelines(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Error listing pipelines")
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve pipelines")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, pipelines)
+}
+
+// GetPipelineHandler handles GET requests to /pipelines/{id}.
+func (h *APIHandler) GetPipelineHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pipelineID(w, r, "")
+	if !ok {
+		return
+	}
+
+	pipeline, err := h.svc.GetPipeline(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Pipeline not found")
+		} else {
+			h.logger.Error().Err(err).Int64("pipeline_id", id).Msg("Error getting pipeline")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve pipeline")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, pipeline)
+}
+
+// UpdatePipelineHandler handles PUT requests to /pipelines/{id}. The tasks in the body replace
+// the pipeline's tasks.
+func (h *APIHandler) UpdatePipelineHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pipelineID(w, r, "")
+	if !ok {
+		return
+	}
+
+	var input data.Pipeline
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.logger.Error().Err(err).Msg("Error decoding update pipeline request")
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	input.ID = id // Set ID from URL
+	if !h.validatePipeline(w, &input) {
+		return
+	}
+
+	if err := h.svc.UpdatePipeline(r.Context(), &input); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusNotFound, "Pipeline not found")
+		case errors.Is(err, dag.ErrInvalidPipeline):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error().Err(err).Int64("pipeline_id", id).Msg("Error updating pipeline")
+			respondWithError(w, http.StatusInternalServerError, "Failed to update pipeline")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, input)
+}
+
+// DeletePipelineHandler handles DELETE requests to /pipelines/{id}.
+func (h *APIHandler) DeletePipelineHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pipelineID(w, r, "")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeletePipeline(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Pipeline not found")
+		} else {
+			h.logger.Error().Err(err).Int64("pipeline_id", id).Msg("Error deleting pipeline")
+			respondWithError(w, http.StatusInternalServerError, "Failed to delete pipeline")
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunPipelineHandler handles POST requests to /pipelines/{id}/run. The run goes on in the
+// background; its workflow ID is returned with 202 Accepted.
+func (h *APIHandler) RunPipelineHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pipelineID(w, r, "run")
+	if !ok {
+		return
+	}
+
+	workflowID, err := h.svc.RunPipeline(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Pipeline not found")
+		} else {
+			h.logger.Error().Err(err).Int64("pipeline_id", id).Msg("Error running pipeline")
+			respondWithError(w, http.StatusInternalServerError, "Failed to run pipeline")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusAccepted, map[string]string{"workflow_id": workflowID})
+}
+
+// ListPipelineRunsHandler handles GET requests to /pipelines/{id}/runs.
+func (h *APIHandler) ListPipelineRunsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pipelineID(w, r, "runs")
+	if !ok {
+		return
+	}
+
+	runs, err := h.svc.ListPipelineRuns(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Pipeline not found")
+		} else {
+			h.logger.Error().Err(err).Int64("pipeline_id", id).Msg("Error listing pipeline runs")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve pipeline runs")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, runs)
+}
+
+// GetPipelineDAGHandler handles GET requests to /pipelines/{id}/dag, returning the pipeline's
+// tasks as nodes and dependencies as edges, with the task statuses of its latest run.
+func (h *APIHandler) GetPipelineDAGHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pipelineID(w, r, "dag")
+	if !ok {
+		return
+	}
+
+	graph, err := h.svc.GetPipelineDAG(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Pipeline not found")
+		} else {
+			h.logger.Error().Err(err).Int64("pipeline_id", id).Msg("Error getting pipeline DAG")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve pipeline DAG")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, graph)
+}
+
+// GetPipelineRunHandler handles GET requests to /pipeline-runs/{id}.
+func (h *APIHandler) GetPipelineRunHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	runID, err := strconv.ParseInt(strings.Trim(r.URL.Path[len("/pipeline-runs/"):], "/"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pipeline run ID")
+		return
+	}
+
+	run, err := h.svc.GetPipelineRun(r.Context(), runID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Pipeline run not found")
+		} else {
+			h.logger.Error().Err(err).Int64("pipeline_run_id", runID).Msg("Error getting pipeline run")
+			respondWithError(w, http.StatusInternalServerError, "Failed to retrieve pipeline run")
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, run)
+}
+
+// validatePipeline checks a pipeline's required fields, responding with 400 when one is missing.
+func (h *APIHandler) validatePipeline(w http.ResponseWriter, input *data.Pipeline) bool {
+	err := h.validator.Struct(input)
+	if err == nil {
+		return true
+	}
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		h.logger.Warn().Err(err).Interface("validation_errors", validationErrors).Msg("Validation errors for pipeline")
+		respondWithError(w, http.StatusBadRequest, "Invalid input: validation failed")
+	} else {
+		h.logger.Error().Err(err).Msg("Unexpected error during validation")
+		respondWithError(w, http.StatusInternalServerError, "Validation error")
+	}
+	return false
+}
+
+// pipelineID parses the ID from /pipelines/{id}, or from /pipelines/{id}/{resource} when
+// resource is set.
+func pipelineID(w http.ResponseWriter, r *http.Request, resource string) (int64, bool) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	valid := len(pathParts) == 2 && resource == ""
+	if resource != "" {
+		valid = len(pathParts) == 3 && pathParts[2] == resource
+	}
+	if !valid || pathParts[0] != "pipelines" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return 0, false
+	}
+	id, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pipeline ID")
+		return 0, false
+	}
+	return id, true
+}
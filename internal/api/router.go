@@ -35,6 +35,29 @@ func NewRouter(handler *APIHandler) *http.ServeMux {
 			http.NotFound(w, r)
 			return
 		}
+		// Schema discovery: /connections/{id}/schemas, /tables and /tables/{name}/columns;
+		// concurrency leases: /connections/{id}/leases
+		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(pathParts) > 2 {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+				return
+			}
+			switch {
+			case len(pathParts) == 3 && pathParts[2] == "schemas":
+				handler.ListSourceSchemasHandler(w, r)
+			case len(pathParts) == 3 && pathParts[2] == "tables":
+				handler.ListSourceTablesHandler(w, r)
+			case len(pathParts) == 3 && pathParts[2] == "leases":
+				handler.ListConnectionLeasesHandler(w, r)
+			case len(pathParts) == 5 && pathParts[2] == "tables" && pathParts[4] == "columns":
+				handler.ListSourceColumnsHandler(w, r)
+			default:
+				http.NotFound(w, r)
+			}
+			return
+		}
 		// Note: This simple routing doesn't validate the {id} part is numeric here.
 		// It relies on the handler to parse and validate the ID.
 		switch r.Method {
@@ -81,6 +104,90 @@ func NewRouter(handler *APIHandler) *http.ServeMux {
 				w.Header().Set("Allow", "GET, PUT, DELETE")
 				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
 			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "ddl" {
+			// /replication-tasks/{task_id}/ddl previews the target table DDL
+			if r.Method == http.MethodGet {
+				handler.PreviewTargetDDLHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "schema-snapshots" {
+			// /replication-tasks/{task_id}/schema-snapshots lists (GET) or resets (DELETE) the drift baseline
+			switch r.Method {
+			case http.MethodGet:
+				handler.ListSchemaSnapshotsHandler(w, r)
+			case http.MethodDelete:
+				handler.ResetSchemaSnapshotsHandler(w, r)
+			default:
+				w.Header().Set("Allow", "GET, DELETE")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "compare" {
+			// /replication-tasks/{task_id}/compare starts a source-target compare
+			if r.Method == http.MethodPost {
+				handler.StartCompareHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "compare-reports" {
+			// /replication-tasks/{task_id}/compare-reports lists the task's compare history
+			if r.Method == http.MethodGet {
+				handler.ListCompareReportsHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "refreshes" {
+			// /replication-tasks/{task_id}/refreshes lists the task's refreshes
+			if r.Method == http.MethodGet {
+				handler.ListRefreshesHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "backfill" {
+			// /replication-tasks/{task_id}/backfill starts a backfill over a date or ID range
+			if r.Method == http.MethodPost {
+				handler.StartBackfillHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "backfills" {
+			// /replication-tasks/{task_id}/backfills lists the task's backfills
+			if r.Method == http.MethodGet {
+				handler.ListBackfillsHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "run" {
+			// /replication-tasks/{task_id}/run starts an ad-hoc run, optionally with template parameters
+			if r.Method == http.MethodPost {
+				handler.RunReplicationTaskHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" &&
+			(pathParts[2] == "pause" || pathParts[2] == "resume" || pathParts[2] == "skip-next") {
+			// /replication-tasks/{task_id}/pause, /resume and /skip-next control the task's runs
+			if r.Method == http.MethodPost {
+				handler.ControlReplicationTaskHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "lag" {
+			// /replication-tasks/{task_id}/lag returns the replication lag time series of a streaming task
+			if r.Method == http.MethodGet {
+				handler.GetReplicationLagHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
 		} else if len(pathParts) == 3 && pathParts[0] == "replication-tasks" && pathParts[2] == "runs" {
 			// Assumed /replication-tasks/{task_id}/runs
 			if r.Method == http.MethodGet {
@@ -127,8 +234,36 @@ func NewRouter(handler *APIHandler) *http.ServeMux {
 	})
 
 	// Replication Runs endpoints
+	// POST /replication-runs/reconcile ends the runs whose workflows stopped without recording
+	// their outcome; the exact path takes precedence over /replication-runs/{run_id}
+	router.HandleFunc("/replication-runs/reconcile", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			handler.ReconcileReplicationRunsHandler(w, r)
+		} else {
+			w.Header().Set("Allow", "POST")
+			respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+	})
+
 	router.HandleFunc("/replication-runs/", func(w http.ResponseWriter, r *http.Request) {
-		// Route for GET /replication-runs/{run_id}
+		// Route for GET /replication-runs/{run_id}, /replication-runs/{run_id}/tables and /chunks,
+		// and POST /replication-runs/{run_id}/resume, /cancel and /rerun
+		for suffix, action := range map[string]http.HandlerFunc{
+			"/resume": handler.ResumeReplicationRunHandler,
+			"/cancel": handler.CancelReplicationRunHandler,
+			"/rerun":  handler.RerunReplicationRunHandler,
+		} {
+			if !strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), suffix) {
+				continue
+			}
+			if r.Method == http.MethodPost {
+				action(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+			return
+		}
 		if r.Method == http.MethodGet {
 			// Basic check for path structure
 			pathPrefix := "/replication-runs/"
@@ -136,6 +271,14 @@ func NewRouter(handler *APIHandler) *http.ServeMux {
 				http.NotFound(w, r)
 				return
 			}
+			if strings.HasSuffix(r.URL.Path, "/tables") {
+				handler.ListReplicationRunTablesHandler(w, r)
+				return
+			}
+			if strings.HasSuffix(r.URL.Path, "/chunks") {
+				handler.ListReplicationRunChunksHandler(w, r)
+				return
+			}
 			handler.GetReplicationRunHandler(w, r)
 		} else {
 			w.Header().Set("Allow", "GET")
@@ -143,6 +286,126 @@ func NewRouter(handler *APIHandler) *http.ServeMux {
 		}
 	})
 
+	// Compare Reports endpoints
+	router.HandleFunc("/compare-reports/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/compare-reports/" {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/refresh") {
+			// /compare-reports/{id}/refresh resynchronizes the report's mismatched ranges
+			if r.Method == http.MethodPost {
+				handler.StartRefreshHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+			return
+		}
+		handler.GetCompareReportHandler(w, r)
+	})
+
+	// Refreshes endpoints
+	router.HandleFunc("/refreshes/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/refreshes/" {
+			http.NotFound(w, r)
+			return
+		}
+		handler.GetRefreshHandler(w, r)
+	})
+
+	// Backfills endpoints
+	router.HandleFunc("/backfills/", func(w http.ResponseWriter, r *http.Request) {
+		// Handle GET /backfills/{id} and /backfills/{id}/slices, and POST /backfills/{id}/pause,
+		// /resume and /cancel
+		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(pathParts) == 2 || len(pathParts) == 3 && pathParts[2] == "slices":
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			} else if len(pathParts) == 2 {
+				handler.GetBackfillHandler(w, r)
+			} else {
+				handler.ListBackfillSlicesHandler(w, r)
+			}
+		case len(pathParts) == 3 && (pathParts[2] == "pause" || pathParts[2] == "resume" || pathParts[2] == "cancel"):
+			if r.Method == http.MethodPost {
+				handler.ControlBackfillHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	// Pipelines endpoints
+	router.HandleFunc("/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handler.ListPipelinesHandler(w, r)
+		case http.MethodPost:
+			handler.CreatePipelineHandler(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		}
+	})
+
+	router.HandleFunc("/pipelines/", func(w http.ResponseWriter, r *http.Request) {
+		// Handle /pipelines/{id}, POST /pipelines/{id}/run, and GET /pipelines/{id}/runs and /dag
+		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(pathParts) == 2:
+			switch r.Method {
+			case http.MethodGet:
+				handler.GetPipelineHandler(w, r)
+			case http.MethodPut:
+				handler.UpdatePipelineHandler(w, r)
+			case http.MethodDelete:
+				handler.DeletePipelineHandler(w, r)
+			default:
+				w.Header().Set("Allow", "GET, PUT, DELETE")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		case len(pathParts) == 3 && pathParts[2] == "run":
+			if r.Method == http.MethodPost {
+				handler.RunPipelineHandler(w, r)
+			} else {
+				w.Header().Set("Allow", "POST")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+		case len(pathParts) == 3 && (pathParts[2] == "runs" || pathParts[2] == "dag"):
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", "GET")
+				respondWithError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			} else if pathParts[2] == "runs" {
+				handler.ListPipelineRunsHandler(w, r)
+			} else {
+				handler.GetPipelineDAGHandler(w, r)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	// Pipeline Runs endpoints
+	router.HandleFunc("/pipeline-runs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pipeline-runs/" {
+			http.NotFound(w, r)
+			return
+		}
+		handler.GetPipelineRunHandler(w, r)
+	})
+
+	// Events feed endpoint
+	router.HandleFunc("/events", handler.ListEventsHandler)
+
+	// Prometheus metrics endpoint (replication lag gauges of streaming tasks)
+	router.HandleFunc("/metrics", handler.MetricsHandler)
+
 	// Placeholder for other resource routes
 
 	return router
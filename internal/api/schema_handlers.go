@@ -0,0 +1,94 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// --- Source Schema Discovery Handlers ---
+
+// ListSourceSchemasHandler handles GET requests to /connections/{id}/schemas.
+func (h *APIHandler) ListSourceSchemasHandler(w http.ResponseWriter, r *http.Request) {
+	connID, ok := parseConnectionPathID(w, r)
+	if !ok {
+		return
+	}
+
+	schemas, err := h.svc.ListSourceSchemas(r.Context(), connID)
+	if err != nil {
+		h.respondWithDiscoveryError(w, err, connID, "Failed to list schemas")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, schemas)
+}
+
+// ListSourceTablesHandler handles GET requests to /connections/{id}/tables?schema={schema}.
+func (h *APIHandler) ListSourceTablesHandler(w http.ResponseWriter, r *http.Request) {
+	connID, ok := parseConnectionPathID(w, r)
+	if !ok {
+		return
+	}
+
+	tables, err := h.svc.ListSourceTables(r.Context(), connID, r.URL.Query().Get("schema"))
+	if err != nil {
+		h.respondWithDiscoveryError(w, err, connID, "Failed to list tables")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, tables)
+}
+
+// ListSourceColumnsHandler handles GET requests to /connections/{id}/tables/{name}/columns?schema={schema}.
+func (h *APIHandler) ListSourceColumnsHandler(w http.ResponseWriter, r *http.Request) {
+	connID, ok := parseConnectionPathID(w, r)
+	if !ok {
+		return
+	}
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) != 5 || pathParts[3] == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return
+	}
+
+	columns, err := h.svc.ListSourceColumns(r.Context(), connID, r.URL.Query().Get("schema"), pathParts[3])
+	if err != nil {
+		h.respondWithDiscoveryError(w, err, connID, "Failed to list columns")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, columns)
+}
+
+// parseConnectionPathID reads the {id} of /connections/{id}/... paths.
+func parseConnectionPathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) < 2 || pathParts[0] != "connections" {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL path format")
+		return 0, false
+	}
+	id, err := strconv.ParseInt(pathParts[1], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid connection ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// respondWithDiscoveryError maps schema discovery errors to HTTP responses.
+func (h *APIHandler) respondWithDiscoveryError(w http.ResponseWriter, err error, connID int64, message string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondWithError(w, http.StatusNotFound, "Connection not found")
+	case errors.Is(err, schema.ErrTableNotFound):
+		respondWithError(w, http.StatusNotFound, "Table not found")
+	case errors.Is(err, schema.ErrUnsupportedType), errors.Is(err, schema.ErrSchemaRequired):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error().Err(err).Int64("connection_id", connID).Msg(message)
+		// The source itself is unreachable or rejected the query
+		respondWithError(w, http.StatusBadGateway, message)
+	}
+}
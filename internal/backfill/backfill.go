@@ -0,0 +1,195 @@
+// Package backfill splits a date or ID range into slices, each replicated by its own run of
+// a task whose source query takes the slice's bounds as arguments.
+package backfill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxParallel = 1
+	maxMaxParallel     = 32
+	maxSlices          = 10000
+)
+
+// ControlSignal is the Temporal signal that pauses or resumes a backfill's workflow; its
+// payload is ActionPause or ActionResume.
+const ControlSignal = "backfill-control"
+
+// Actions on a running backfill. Cancelling goes through workflow cancellation, not the signal.
+const (
+	ActionPause  = "pause"
+	ActionResume = "resume"
+	ActionCancel = "cancel"
+)
+
+// ErrInvalidBackfill is returned for malformed backfill options.
+var ErrInvalidBackfill = errors.New("invalid backfill")
+
+// Options is the range a backfill covers and how it is sliced.
+type Options struct {
+	Start       string `json:"start"`                  // First value, included
+	End         string `json:"end"`                    // Last value, excluded
+	Step        string `json:"step"`                   // Slice width: an integer for IDs, or e.g. 1d, 12h, 1w, 1mo for dates
+	MaxParallel int    `json:"max_parallel,omitempty"` // Slices replicated at the same time
+}
+
+// Slice is a half-open range [Start, End) of a backfill.
+type Slice struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Timestamp layouts accepted for date ranges, most specific last. Slice bounds are written in
+// the layout of the backfill's start.
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+var stepPattern = regexp.MustCompile(`^(\d+)(h|d|w|mo|y)$`)
+
+// Parse parses backfill options JSON and fills in defaults.
+func Parse(raw string) (*Options, error) {
+	opts := &Options{}
+	if err := json.Unmarshal([]byte(raw), opts); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackfill, err)
+	}
+	opts.Start, opts.End, opts.Step = strings.TrimSpace(opts.Start), strings.TrimSpace(opts.End), strings.TrimSpace(opts.Step)
+	if opts.Start == "" || opts.End == "" || opts.Step == "" {
+		return nil, fmt.Errorf("%w: start, end and step are required", ErrInvalidBackfill)
+	}
+	if opts.MaxParallel < 0 || opts.MaxParallel > maxMaxParallel {
+		return nil, fmt.Errorf("%w: max_parallel must be between 1 and %d", ErrInvalidBackfill, maxMaxParallel)
+	}
+	if opts.MaxParallel == 0 {
+		opts.MaxParallel = defaultMaxParallel
+	}
+	return opts, nil
+}
+
+// Supported reports whether a task with this source type can be backfilled. Range arguments
+// are passed to SQL source queries only.
+func Supported(sourceType string) error {
+	switch sourceType {
+	case "sqlserver", "oracle", "postgres", "mysql":
+		return nil
+	}
+	return fmt.Errorf("%w: %s sources take no query arguments", ErrInvalidBackfill, sourceType)
+}
+
+// Plan splits the options' range into slices of one step each; the last slice ends at End.
+func Plan(opts *Options) ([]Slice, error) {
+	if start, err := strconv.ParseInt(opts.Start, 10, 64); err == nil {
+		end, err := strconv.ParseInt(opts.End, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: end %q must be an integer like start", ErrInvalidBackfill, opts.End)
+		}
+		step, err := strconv.ParseInt(opts.Step, 10, 64)
+		if err != nil || step < 1 {
+			return nil, fmt.Errorf("%w: step of an ID range must be a positive integer", ErrInvalidBackfill)
+		}
+		return planIDs(start, end, step)
+	}
+	return planDates(opts)
+}
+
+func planIDs(start, end, step int64) ([]Slice, error) {
+	if start >= end {
+		return nil, fmt.Errorf("%w: start must be before end", ErrInvalidBackfill)
+	}
+	if (end-start-1)/step+1 > maxSlices {
+		return nil, fmt.Errorf("%w: more than %d slices", ErrInvalidBackfill, maxSlices)
+	}
+	slices := make([]Slice, 0)
+	for low := start; low < end; low += step {
+		high := end
+		if end-low > step {
+			high = low + step
+		}
+		slices = append(slices, Slice{Start: strconv.FormatInt(low, 10), End: strconv.FormatInt(high, 10)})
+	}
+	return slices, nil
+}
+
+func planDates(opts *Options) ([]Slice, error) {
+	layout, start, err := parseDate(opts.Start)
+	if err != nil {
+		return nil, err
+	}
+	_, end, err := parseDate(opts.End)
+	if err != nil {
+		return nil, err
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("%w: start must be before end", ErrInvalidBackfill)
+	}
+	match := stepPattern.FindStringSubmatch(opts.Step)
+	if match == nil {
+		return nil, fmt.Errorf("%w: step %q must be a count and a unit (h, d, w, mo or y), e.g. 1d", ErrInvalidBackfill, opts.Step)
+	}
+	count, err := strconv.Atoi(match[1])
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("%w: step %q must be positive", ErrInvalidBackfill, opts.Step)
+	}
+	if match[2] == "h" && layout == dateLayouts[0] {
+		return nil, fmt.Errorf("%w: an hourly step needs start and end with a time of day", ErrInvalidBackfill)
+	}
+
+	slices := make([]Slice, 0)
+	for low := start; low.Before(end); {
+		high := advance(low, count, match[2])
+		if high.After(end) {
+			high = end
+		}
+		slices = append(slices, Slice{Start: low.Format(layout), End: high.Format(layout)})
+		if len(slices) > maxSlices {
+			return nil, fmt.Errorf("%w: more than %d slices", ErrInvalidBackfill, maxSlices)
+		}
+		low = high
+	}
+	return slices, nil
+}
+
+func parseDate(value string) (string, time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return layout, t, nil
+		}
+	}
+	return "", time.Time{}, fmt.Errorf("%w: %q is neither an integer nor a date like 2024-01-01, 2024-01-01 00:00:00 or RFC 3339", ErrInvalidBackfill, value)
+}
+
+// advance moves t forward by count units. Months and years keep the day of the month where
+// the calendar allows, as time.AddDate does.
+func advance(t time.Time, count int, unit string) time.Time {
+	switch unit {
+	case "h":
+		return t.Add(time.Duration(count) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, count)
+	case "w":
+		return t.AddDate(0, 0, 7*count)
+	case "mo":
+		return t.AddDate(0, count, 0)
+	default:
+		return t.AddDate(count, 0, 0)
+	}
+}
+
+// ArgsMapping renders a slice's bounds as the Bloblang args_mapping of a sql_select input, so
+// the task's query receives them as its first and second placeholders. Integer bounds are
+// passed as numbers and dates as strings.
+func ArgsMapping(slice Slice) string {
+	return fmt.Sprintf("root = [ %s, %s ]", argLiteral(slice.Start), argLiteral(slice.End))
+}
+
+func argLiteral(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return value
+	}
+	return strconv.Quote(value)
+}
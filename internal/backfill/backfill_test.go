@@ -0,0 +1,64 @@
+package backfill
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	opts, err := Parse(`{"start": " 2024-01-01 ", "end": "2024-02-01", "step": "1d"}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Options{Start: "2024-01-01", End: "2024-02-01", Step: "1d", MaxParallel: 1}, opts)
+
+	for _, raw := range []string{
+		`{"start": "2024-01-01", "end": "2024-02-01"}`,
+		`{"start": "1", "end": "10", "step": "5", "max_parallel": 100}`,
+		`{"start": "1", "end": "10", "step": "5", "max_parallel": -1}`,
+		`[]`,
+	} {
+		_, err := Parse(raw)
+		assert.ErrorIs(t, err, ErrInvalidBackfill, raw)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []Slice
+	}{
+		{"ids", Options{Start: "1", End: "250", Step: "100"}, []Slice{{"1", "101"}, {"101", "201"}, {"201", "250"}}},
+		{"days", Options{Start: "2024-02-27", End: "2024-03-01", Step: "1d"}, []Slice{{"2024-02-27", "2024-02-28"}, {"2024-02-28", "2024-02-29"}, {"2024-02-29", "2024-03-01"}}},
+		{"months clipped to end", Options{Start: "2024-01-01", End: "2024-03-15", Step: "1mo"}, []Slice{{"2024-01-01", "2024-02-01"}, {"2024-02-01", "2024-03-01"}, {"2024-03-01", "2024-03-15"}}},
+		{"hours", Options{Start: "2024-01-01 00:00:00", End: "2024-01-01 12:00:00", Step: "6h"}, []Slice{{"2024-01-01 00:00:00", "2024-01-01 06:00:00"}, {"2024-01-01 06:00:00", "2024-01-01 12:00:00"}}},
+		{"rfc3339 weeks", Options{Start: "2024-01-01T00:00:00Z", End: "2024-01-10T00:00:00Z", Step: "1w"}, []Slice{{"2024-01-01T00:00:00Z", "2024-01-08T00:00:00Z"}, {"2024-01-08T00:00:00Z", "2024-01-10T00:00:00Z"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slices, err := Plan(&tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, slices)
+		})
+	}
+
+	for _, opts := range []Options{
+		{Start: "10", End: "1", Step: "1"},
+		{Start: "1", End: "2024-01-01", Step: "1"},
+		{Start: "1", End: "10", Step: "0"},
+		{Start: "1", End: "100000", Step: "1"},
+		{Start: "2024-01-01", End: "2024-01-02", Step: "1x"},
+		{Start: "2024-01-01", End: "2024-01-02", Step: "6h"},
+		{Start: "yesterday", End: "today", Step: "1d"},
+	} {
+		_, err := Plan(&opts)
+		assert.ErrorIs(t, err, ErrInvalidBackfill, opts)
+	}
+}
+
+func TestArgsMapping(t *testing.T) {
+	assert.Equal(t, `root = [ 1, 101 ]`, ArgsMapping(Slice{"1", "101"}))
+	assert.Equal(t, `root = [ "2024-01-01", "2024-01-02" ]`, ArgsMapping(Slice{"2024-01-01", "2024-01-02"}))
+}
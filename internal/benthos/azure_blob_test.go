@@ -0,0 +1,82 @@
+package benthos
+
+import (
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfig_AzureBlobAzuriteToAccountKey(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{ID: 30, Type: "azure_blob", ConnectionString: "connection_string=env:AZURITE_CONNECTION_STRING;container=landing"}
+	targetConn := data.Connection{ID: 31, Type: "azure_blob", ConnectionString: "account=lakeacct;access_key=env:LAKE_KEY;container=curated;path=orders/${! now().ts_format(\"2006/01/02\") }/${!count(\"files\")}.json"}
+	task := data.ReplicationTask{ID: 401, DataSelectionCriteria: "orders/"}
+
+	// Act
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+
+	// Assert
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+
+	blobIn := configData["input"].(map[string]interface{})["azure_blob_storage"].(map[string]interface{})
+	assert.Equal(t, "${AZURITE_CONNECTION_STRING}", blobIn["storage_connection_string"])
+	assert.Equal(t, "landing", blobIn["container"])
+	assert.Equal(t, "orders/", blobIn["prefix"])
+	assert.NotContains(t, blobIn, "storage_account")
+
+	blobOut := configData["output"].(map[string]interface{})["azure_blob_storage"].(map[string]interface{})
+	assert.Equal(t, "lakeacct", blobOut["storage_account"])
+	assert.Equal(t, "${LAKE_KEY}", blobOut["storage_access_key"])
+	assert.Equal(t, "curated", blobOut["container"])
+	assert.Equal(t, `orders/${! now().ts_format("2006/01/02") }/${!count("files")}.json`, blobOut["path"])
+	assert.Equal(t, "BLOCK", blobOut["blob_type"])
+}
+
+func TestGenerateBenthosConfig_AzureBlobSASAndDefaultPath(t *testing.T) {
+	sourceConn := data.Connection{Type: "azure_blob", ConnectionString: "account=src;sas_token=env:SRC_SAS;container=in"}
+	targetConn := data.Connection{Type: "azure_blob", ConnectionString: "account=dst;container=out;path_prefix=exports/"}
+	task := data.ReplicationTask{ID: 402}
+
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+	require.NoError(t, err)
+
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+	blobIn := configData["input"].(map[string]interface{})["azure_blob_storage"].(map[string]interface{})
+	assert.Equal(t, "${SRC_SAS}", blobIn["storage_sas_token"])
+
+	blobOut := configData["output"].(map[string]interface{})["azure_blob_storage"].(map[string]interface{})
+	assert.Equal(t, "dst", blobOut["storage_account"])
+	assert.NotContains(t, blobOut, "storage_access_key", "Account-only auth should fall back to Azure AD credentials")
+	assert.Equal(t, "exports/"+defaultObjectName(FormatJSON), blobOut["path"])
+}
+
+func TestGenerateBenthosConfig_AzureBlobErrors(t *testing.T) {
+	target := data.Connection{Type: "azure_blob", ConnectionString: "account=dst;container=out"}
+	task := data.ReplicationTask{}
+
+	tests := []struct {
+		name    string
+		connStr string
+		wantErr string
+	}{
+		{"missing container", "account=src", "'container' not found"},
+		{"missing auth", "container=in", "either 'account' or 'connection_string'"},
+		{"literal access key", "account=src;container=in;access_key=abc", "must be a secret reference"},
+		{"literal connection string", "connection_string=DefaultEndpointsProtocol=https;container=in", "'connection_string' must be a secret reference"},
+		{"key and sas", "account=src;container=in;access_key=env:K;sas_token=env:S", "mutually exclusive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBenthosConfig(task, data.Connection{Type: "azure_blob", ConnectionString: tt.connStr}, target)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package benthos
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3" // We'll use YAML for Benthos configs
@@ -9,8 +10,8 @@ import (
 	"github.com/eleon00/hsoetlnlm/internal/data" // Assuming models are in internal/data
 )
 
-// parseConnectionString splits a key-value string (e.g., "key1=val1;key2=val2") into a map.
-func parseConnectionString(connStr string) map[string]string {
+// ParseConnectionString splits a key-value string (e.g., "key1=val1;key2=val2") into a map.
+func ParseConnectionString(connStr string) map[string]string {
 	params := make(map[string]string)
 	pairs := strings.Split(connStr, ";")
 	for _, pair := range pairs {
@@ -26,9 +27,35 @@ func parseConnectionString(connStr string) map[string]string {
 	return params
 }
 
+// SplitList splits a comma-separated value into trimmed, non-empty items.
+func SplitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseBool reads a boolean connection string value, falling back to def when unset or invalid.
+func parseBool(value string, def bool) bool {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
 // GenerateBenthosConfig dynamically creates a Benthos configuration YAML string
 // based on the replication task and connection details.
 func GenerateBenthosConfig(task data.ReplicationTask, sourceConn data.Connection, targetConn data.Connection) (string, error) {
+	return GenerateBenthosConfigForRun(task, sourceConn, targetConn, RunContext{})
+}
+
+// GenerateBenthosConfigForRun is GenerateBenthosConfig for a specific replication run, whose ID
+// and start time are used by run-scoped output paths (e.g. localfile targets).
+func GenerateBenthosConfigForRun(task data.ReplicationTask, sourceConn data.Connection, targetConn data.Connection, run RunContext) (string, error) {
 	// Basic Benthos config structure
 	config := map[string]interface{}{
 		"http": map[string]interface{}{
@@ -52,15 +79,45 @@ func GenerateBenthosConfig(task data.ReplicationTask, sourceConn data.Connection
 		},
 	}
 
+	if run.HTTPAddress != "" {
+		config["http"].(map[string]interface{})["address"] = run.HTTPAddress
+	}
+
 	// --- Input Configuration ---
-	inputConfig, err := generateInputConfig(sourceConn, task)
+	generateInput := generateInputConfig
+	if run.Streaming {
+		// localfile targets publish their files when a run ends, which a stream never does
+		if targetConn.Type == "localfile" {
+			return "", fmt.Errorf("localfile targets cannot be used by streaming tasks")
+		}
+		generateInput = generateStreamingInput
+	}
+	inputConfig, err := generateInput(sourceConn, task)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate input config: %w", err)
 	}
+	if run.ArgsMapping != "" {
+		sqlSelect, ok := inputConfig["sql_select"].(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("query arguments are not supported for %s sources", sourceConn.Type)
+		}
+		sqlSelect["args_mapping"] = run.ArgsMapping
+	}
 	config["input"] = inputConfig
 
+	// API sources need paging state and rate limit resources alongside the input
+	if sourceConn.Type == "http_api" {
+		resources, err := httpAPIResources(ParseConnectionString(sourceConn.ConnectionString), task)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate input resources: %w", err)
+		}
+		for key, value := range resources {
+			config[key] = value
+		}
+	}
+
 	// --- Output Configuration ---
-	outputConfig, err := generateOutputConfig(targetConn, task)
+	outputConfig, err := generateOutputConfig(targetConn, task, run)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate output config: %w", err)
 	}
@@ -76,6 +133,10 @@ func GenerateBenthosConfig(task data.ReplicationTask, sourceConn data.Connection
 		})
 		pipeline["processors"] = processors
 	}
+	if run.Streaming {
+		pipeline := config["pipeline"].(map[string]interface{})
+		pipeline["processors"] = append(pipeline["processors"].([]interface{}), lagProcessors(sourceConn.Type)...)
+	}
 
 	// Marshal the map into a YAML string
 	yamlBytes, err := yaml.Marshal(config)
@@ -89,11 +150,11 @@ func GenerateBenthosConfig(task data.ReplicationTask, sourceConn data.Connection
 // generateInputConfig creates the Benthos input section based on the source connection.
 func generateInputConfig(conn data.Connection, task data.ReplicationTask) (map[string]interface{}, error) {
 	inputConf := map[string]interface{}{}
-	params := parseConnectionString(conn.ConnectionString)
+	params := ParseConnectionString(conn.ConnectionString)
 
 	// Example: Add logic based on conn.Type
 	switch conn.Type {
-	case "sqlserver", "oracle":
+	case "sqlserver", "oracle", "postgres", "mysql":
 		driver := conn.Type
 		if driver == "sqlserver" {
 			driver = "mssql" // Benthos uses 'mssql'
@@ -110,47 +171,21 @@ func generateInputConfig(conn data.Connection, task data.ReplicationTask) (map[s
 
 		inputConf["sql_select"] = map[string]interface{}{ // Use sql_select for pulling data
 			"driver":       driver,
-			"dsn":          dsn,
+			"dsn":          resolveSecretRef(dsn),
 			"query":        query,
 			"args_mapping": "", // No args for now
 		}
 	case "s3":
-		bucket, ok := params["bucket"]
-		if !ok {
-			return nil, fmt.Errorf("'bucket' not found in connection string for S3")
-		}
-		prefix := task.DataSelectionCriteria // Use criteria as the prefix
-		region, ok := params["region"]
-		if !ok {
-			// Attempt to infer region or use a default; Benthos might handle this too
-			region = "" // Let Benthos SDK try to determine
-		}
-
-		s3Input := map[string]interface{}{ // Use aws_s3 input
-			"bucket": bucket,
-			"prefix": prefix,
-		}
-		if region != "" {
-			s3Input["region"] = region
-		}
-		// Credentials should ideally be handled via environment vars or AWS SDK defaults
-		// Avoid putting explicit keys in the config if possible.
-		// Example: s3Input["credentials"] = map[string]string{"profile": params["profile"]} if profile is specified
-		inputConf["aws_s3"] = s3Input
+		return generateS3Input(params, task)
 
 	case "localfile":
 		paths := strings.Split(task.DataSelectionCriteria, ",") // Allow comma-separated paths
 		if len(paths) == 0 || paths[0] == "" {
 			return nil, fmt.Errorf("DataSelectionCriteria (file paths) cannot be empty for localfile input")
 		}
-		codec, ok := params["codec"]
-		if !ok {
-			codec = "lines" // Default codec
-		}
-		inputConf["file"] = map[string]interface{}{ // Use file input
+		return applyInputFormat("file", map[string]interface{}{ // Use file input
 			"paths": paths,
-			"codec": codec,
-		}
+		}, params, "lines")
 
 	case "bigquery":
 		project, ok := params["project"]
@@ -167,6 +202,19 @@ func generateInputConfig(conn data.Connection, task data.ReplicationTask) (map[s
 			"query":   query,
 			// Credentials should ideally be handled via environment vars or GCP SDK defaults
 		}
+
+	case "kafka":
+		return generateKafkaInput(params, task)
+
+	case "azure_blob":
+		return generateAzureBlobInput(params, task)
+
+	case "sftp":
+		return generateSFTPInput(params, task)
+
+	case "http_api":
+		return generateHTTPAPIInput(params, task)
+
 	default:
 		return nil, fmt.Errorf("unsupported source connection type: %s", conn.Type)
 	}
@@ -175,9 +223,15 @@ func generateInputConfig(conn data.Connection, task data.ReplicationTask) (map[s
 }
 
 // generateOutputConfig creates the Benthos output section based on the target connection.
-func generateOutputConfig(conn data.Connection, task data.ReplicationTask) (map[string]interface{}, error) {
+func generateOutputConfig(conn data.Connection, task data.ReplicationTask, run RunContext) (map[string]interface{}, error) {
 	outputConf := map[string]interface{}{}
-	params := parseConnectionString(conn.ConnectionString)
+	params := ParseConnectionString(conn.ConnectionString)
+	if run.TargetTable != "" {
+		applyTargetTable(conn.Type, params, run.TargetTable)
+	}
+	if _, ok := params["parquet_schema"]; !ok && run.ParquetSchema != "" {
+		params["parquet_schema"] = run.ParquetSchema
+	}
 
 	switch conn.Type {
 	case "snowflake":
@@ -220,35 +274,44 @@ func generateOutputConfig(conn data.Connection, task data.ReplicationTask) (map[
 			// Consider adding a warning log here about using password auth
 		}
 
+		// Staged files use the connection's format; json keeps the original gzipped JSON files
+		format, err := fileFormat(params)
+		if err != nil {
+			return nil, err
+		}
+		if format != FormatJSON {
+			batching, err := fileBatching(params)
+			if err != nil {
+				return nil, err
+			}
+			snowflakeOutput["batching"] = batching
+			snowflakeOutput["file_name_format"] = `${!count("files")}-${!timestamp_unix_nano()}.` + format + ".gz"
+			if format == FormatParquet {
+				// Parquet compresses internally
+				snowflakeOutput["file_name_format"] = `${!count("files")}-${!timestamp_unix_nano()}.parquet`
+				snowflakeOutput["compression"] = "NONE"
+			}
+		}
+
 		outputConf["snowflake_put"] = snowflakeOutput
 
 	case "s3":
-		bucket, ok := params["bucket"]
-		if !ok {
-			return nil, fmt.Errorf("'bucket' not found in connection string for S3 output")
-		}
-		pathPrefix, ok := params["path_prefix"]
-		if !ok {
-			pathPrefix = "output/" // Default prefix
-		}
-		region, ok := params["region"]
-		if !ok {
-			region = "" // Let Benthos SDK try to determine
-		}
+		return generateS3Output(params)
 
-		s3Output := map[string]interface{}{ // Use aws_s3 output
-			"bucket": bucket,
-			"path":   fmt.Sprintf(`%s${!count("files")}-${!timestamp_unix_nano()}.json`, pathPrefix),
-			"batching": map[string]interface{}{ // Enable batching for S3 efficiency
-				"count":  100,
-				"period": "1s",
-			},
-		}
-		if region != "" {
-			s3Output["region"] = region
-		}
-		// Handle credentials like in input
-		outputConf["aws_s3"] = s3Output
+	case "kafka":
+		return generateKafkaOutput(params)
+
+	case "azure_blob":
+		return generateAzureBlobOutput(params)
+
+	case "sftp":
+		return generateSFTPOutput(params)
+
+	case "localfile":
+		return generateLocalFileOutput(params, task, run)
+
+	case "postgres":
+		return generatePostgresOutput(params, run)
 
 	default:
 		return nil, fmt.Errorf("unsupported target connection type: %s", conn.Type)
@@ -0,0 +1,317 @@
+package benthos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// File formats for file-based sources and targets, selected with the `format` connection string key.
+const (
+	FormatJSON    = "json"
+	FormatNDJSON  = "ndjson"
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+	FormatAvro    = "avro"
+)
+
+// Default batching of file outputs: a file holds up to defaultBatchSize records, or whatever
+// arrived within defaultBatchPeriod.
+const (
+	defaultBatchSize   = 10000
+	defaultBatchPeriod = "10s"
+)
+
+// parquetTypes are the column types accepted in parquet_schema.
+var parquetTypes = map[string]bool{
+	"BOOLEAN": true, "INT32": true, "INT64": true, "FLOAT": true, "DOUBLE": true, "BYTE_ARRAY": true, "UTF8": true,
+}
+
+// fileFormat returns the validated format of a connection. Without a `format` key the
+// original JSON behaviour is kept.
+func fileFormat(params map[string]string) (string, error) {
+	format, ok := params["format"]
+	if !ok {
+		return FormatJSON, nil
+	}
+	switch format {
+	case FormatJSON, FormatNDJSON, FormatCSV, FormatParquet, FormatAvro:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported file format: %s", format)
+	}
+}
+
+// defaultObjectName is the interpolated file name used by object store outputs
+// when no explicit path template is configured.
+func defaultObjectName(format string) string {
+	return `${!count("files")}-${!timestamp_unix_nano()}.` + format
+}
+
+// inputFormat returns the codec and decoding processors for a file-based input.
+// For json the connection's `codec` (or defaultCodec) is used unchanged.
+func inputFormat(params map[string]string, defaultCodec string) (string, []interface{}, error) {
+	format, err := fileFormat(params)
+	if err != nil {
+		return "", nil, err
+	}
+	switch format {
+	case FormatNDJSON:
+		return "lines", nil, nil
+	case FormatCSV:
+		return csvDecoder(params)
+	case FormatParquet:
+		// Parquet needs the whole file; the decoder emits one message per row
+		return "all-bytes", []interface{}{map[string]interface{}{"parquet_decode": map[string]interface{}{}}}, nil
+	case FormatAvro:
+		// Object container files embed their schema
+		return "avro-ocf:marshaler=json", nil, nil
+	}
+	if codec, ok := params["codec"]; ok {
+		return codec, nil, nil
+	}
+	return defaultCodec, nil, nil
+}
+
+// applyInputFormat sets the codec on a file-based input config and wraps it with any
+// decoding processors.
+func applyInputFormat(component string, conf map[string]interface{}, params map[string]string, defaultCodec string) (map[string]interface{}, error) {
+	codec, processors, err := inputFormat(params, defaultCodec)
+	if err != nil {
+		return nil, err
+	}
+	if codec != "" {
+		conf["codec"] = codec
+	}
+	input := map[string]interface{}{component: conf}
+	if len(processors) > 0 {
+		input["processors"] = processors
+	}
+	return input, nil
+}
+
+// outputBatchProcessors returns the batch processors that encode a batch of records into
+// one file of the connection's format. json returns none so existing outputs are unchanged.
+func outputBatchProcessors(params map[string]string) ([]interface{}, error) {
+	format, err := fileFormat(params)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatNDJSON:
+		return []interface{}{archiveLines()}, nil
+	case FormatCSV:
+		return csvEncoder(params)
+	case FormatParquet:
+		return parquetEncoder(params)
+	case FormatAvro:
+		return avroFileEncoder(params)
+	}
+	return nil, nil
+}
+
+// batchPolicy returns the batching policy of file outputs from batch_size (records) and
+// batch_period (a duration), with the given batch processors.
+func batchPolicy(params map[string]string, processors []interface{}) (map[string]interface{}, error) {
+	count := defaultBatchSize
+	if size, ok := params["batch_size"]; ok {
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("'batch_size' must be a positive number of records")
+		}
+		count = n
+	}
+	period := defaultBatchPeriod
+	if value, ok := params["batch_period"]; ok {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("'batch_period' must be a positive duration")
+		}
+		period = d.String()
+	}
+
+	batching := map[string]interface{}{"count": count, "period": period}
+	if len(processors) > 0 {
+		batching["processors"] = processors
+	}
+	return batching, nil
+}
+
+// fileBatching returns the batching policy for file outputs, or nil when the format
+// writes one file per message.
+func fileBatching(params map[string]string) (map[string]interface{}, error) {
+	processors, err := outputBatchProcessors(params)
+	if err != nil || len(processors) == 0 {
+		return nil, err
+	}
+	return batchPolicy(params, processors)
+}
+
+func archiveLines() map[string]interface{} {
+	return map[string]interface{}{"archive": map[string]interface{}{"format": "lines"}}
+}
+
+// parquetEncoder builds a parquet_encode processor from parquet_schema, a comma-separated
+// list of name:TYPE columns. All columns are nullable.
+func parquetEncoder(params map[string]string) ([]interface{}, error) {
+	columns := SplitList(params["parquet_schema"])
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("'parquet_schema' is required for parquet output")
+	}
+	schema := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		name, typ, ok := strings.Cut(column, ":")
+		typ = strings.ToUpper(strings.TrimSpace(typ))
+		if !ok || strings.TrimSpace(name) == "" || !parquetTypes[typ] {
+			return nil, fmt.Errorf("invalid parquet_schema column: %s", column)
+		}
+		schema = append(schema, map[string]interface{}{"name": strings.TrimSpace(name), "type": typ, "optional": true})
+	}
+
+	encoder := map[string]interface{}{"schema": schema}
+	if compression, ok := params["parquet_compression"]; ok {
+		switch compression {
+		case "uncompressed", "snappy", "gzip", "brotli", "zstd", "lz4raw":
+			encoder["default_compression"] = compression
+		default:
+			return nil, fmt.Errorf("unsupported parquet compression: %s", compression)
+		}
+	}
+	return []interface{}{map[string]interface{}{"parquet_encode": encoder}}, nil
+}
+
+// avroSyncMarker is the 16-byte sync marker (hex) written after the header and each block of
+// Avro object container files. Readers take it from the header, so a fixed marker is valid.
+const avroSyncMarker = "9c2e6f0b5a4d41e7b3f8c2d6a1e05f77"
+
+// avroFileEncoder writes each batch as one Avro object container file. Benthos only encodes
+// single Avro datums, so records are encoded with the avro processor (avro_schema or
+// avro_schema_file), concatenated into a single block and framed with the container header.
+func avroFileEncoder(params map[string]string) ([]interface{}, error) {
+	avro := map[string]interface{}{"operator": "from_json", "encoding": "binary"}
+	var schema string
+	if inline, ok := params["avro_schema"]; ok {
+		avro["schema"] = inline
+		encoded, _ := json.Marshal(inline) // A JSON string is a valid Bloblang literal
+		schema = string(encoded)
+	} else if schemaFile, ok := params["avro_schema_file"]; ok {
+		avro["schema_path"] = "file://" + schemaFile
+		encoded, _ := json.Marshal(schemaFile)
+		schema = fmt.Sprintf("file(%s).string()", encoded)
+	} else {
+		return nil, fmt.Errorf("avro file output requires 'avro_schema' or 'avro_schema_file'")
+	}
+
+	// Avro longs are zigzag varints; avro_long encodes a non-negative number as hex. The header
+	// is the magic bytes, a two-entry metadata map ("04") and the sync marker.
+	framing := strings.Join([]string{
+		"map avro_long {",
+		"  let v = this * 2",
+		"  root = [1, 128, 16384, 2097152, 268435456, 34359738368].filter(d -> d == 1 || $v >= d).map_each(d -> \"%02x\".format((($v / d).floor().int64() % 128) + (if $v >= d * 128 { 128 } else { 0 }))).join(\"\")",
+		"}",
+		"map avro_bytes {",
+		"  root = this.bytes().length().apply(\"avro_long\") + this.bytes().encode(\"hex\")",
+		"}",
+		"let sync = \"" + avroSyncMarker + "\"",
+		"let header = \"4f626a01\" + \"04\" + \"avro.schema\".apply(\"avro_bytes\") + " + schema + ".apply(\"avro_bytes\") + \"avro.codec\".apply(\"avro_bytes\") + \"null\".apply(\"avro_bytes\") + \"00\" + $sync",
+		"let block = @avro_count.number().int64().apply(\"avro_long\") + content().length().apply(\"avro_long\") + content().encode(\"hex\") + $sync",
+		"root = ($header + $block).decode(\"hex\")",
+	}, "\n")
+
+	return []interface{}{
+		// The archived message keeps the first record's metadata
+		map[string]interface{}{"mutation": "meta avro_count = batch_size()"},
+		map[string]interface{}{"avro": avro},
+		map[string]interface{}{"archive": map[string]interface{}{"format": "concatenate"}},
+		map[string]interface{}{"mapping": framing},
+	}, nil
+}
+
+// csvDelimiter returns the single-character csv_delimiter (default ",").
+func csvDelimiter(params map[string]string) (string, error) {
+	delimiter, ok := params["csv_delimiter"]
+	if !ok {
+		return ",", nil
+	}
+	if delimiter == `\t` || delimiter == "tab" {
+		return "\t", nil
+	}
+	if utf8.RuneCountInString(delimiter) != 1 || delimiter == `"` {
+		return "", fmt.Errorf("'csv_delimiter' must be a single character")
+	}
+	return delimiter, nil
+}
+
+// csvDecoder reads CSV files. With a header row each record becomes an object keyed by the
+// header; otherwise csv_columns names the fields (or rows are emitted as arrays).
+func csvDecoder(params map[string]string) (string, []interface{}, error) {
+	delimiter, err := csvDelimiter(params)
+	if err != nil {
+		return "", nil, err
+	}
+	if parseBool(params["csv_header"], true) {
+		if delimiter == "," {
+			return "csv", nil, nil
+		}
+		return "csv:" + delimiter, nil, nil
+	}
+
+	row := fmt.Sprintf("content().string().parse_csv(false, %q).index(0)", delimiter)
+	mapping := "root = " + row
+	if columns := SplitList(params["csv_columns"]); len(columns) > 0 {
+		fields := make([]string, 0, len(columns))
+		for i, column := range columns {
+			fields = append(fields, fmt.Sprintf("%q: $row.index(%d)", column, i))
+		}
+		mapping = "let row = " + row + "\nroot = {" + strings.Join(fields, ", ") + "}"
+	}
+	return "lines", []interface{}{map[string]interface{}{"mapping": mapping}}, nil
+}
+
+// csvEncoder turns each record into a CSV row, joins the batch into lines and prepends a
+// header row. Columns come from csv_columns, or from the record's (sorted) keys.
+func csvEncoder(params map[string]string) ([]interface{}, error) {
+	delimiter, err := csvDelimiter(params)
+	if err != nil {
+		return nil, err
+	}
+	quote, ok := params["csv_quote"]
+	if !ok {
+		quote = "minimal"
+	}
+	if quote != "minimal" && quote != "all" {
+		return nil, fmt.Errorf("unsupported csv_quote mode: %s", quote)
+	}
+
+	columns := "this.keys()"
+	if names := SplitList(params["csv_columns"]); len(names) > 0 {
+		encoded, _ := json.Marshal(names) // A JSON array of strings is a valid Bloblang literal
+		columns = string(encoded)
+	}
+	cell := func(value string) string {
+		quoted := `"\"" + s.replace_all("\"", "\"\"") + "\""`
+		if quote == "all" {
+			return fmt.Sprintf("%s.(s -> %s)", value, quoted)
+		}
+		return fmt.Sprintf(`%s.(s -> if s.contains(%q) || s.contains("\"") || s.contains("\n") { %s } else { s })`, value, delimiter, quoted)
+	}
+
+	processors := []interface{}{
+		map[string]interface{}{"mapping": strings.Join([]string{
+			"let columns = " + columns,
+			fmt.Sprintf("meta csv_header = $columns.map_each(c -> %s).join(%q)", cell("c"), delimiter),
+			fmt.Sprintf(`root = $columns.map_each(c -> %s).join(%q)`, cell(`this.get(c).or("").string()`), delimiter),
+		}, "\n")},
+		archiveLines(),
+	}
+	if parseBool(params["csv_header"], true) {
+		// The archived message keeps the first record's metadata
+		processors = append(processors, map[string]interface{}{
+			"mapping": `root = @csv_header + "\n" + content().string()`,
+		})
+	}
+	return processors, nil
+}
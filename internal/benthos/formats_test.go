@@ -0,0 +1,201 @@
+package benthos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func generateConfigMap(t *testing.T, task data.ReplicationTask, source, target data.Connection) map[string]interface{} {
+	t.Helper()
+	configYAML, err := GenerateBenthosConfig(task, source, target)
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+	return configData
+}
+
+func TestGenerateBenthosConfig_CSVToParquetOnS3(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{Type: "localfile", ConnectionString: "format=csv;csv_delimiter=|;csv_header=false;csv_columns=id,name"}
+	targetConn := data.Connection{Type: "s3", ConnectionString: "bucket=lake;path_prefix=orders/;format=parquet;parquet_schema=id:INT64,name:utf8;parquet_compression=zstd"}
+	task := data.ReplicationTask{ID: 501, DataSelectionCriteria: "/data/in/*.csv"}
+
+	// Act
+	configData := generateConfigMap(t, task, sourceConn, targetConn)
+
+	// Assert
+	inputMap := configData["input"].(map[string]interface{})
+	assert.Equal(t, "lines", inputMap["file"].(map[string]interface{})["codec"])
+	decoders := inputMap["processors"].([]interface{})
+	require.Len(t, decoders, 1)
+	mapping := decoders[0].(map[string]interface{})["mapping"].(string)
+	assert.Contains(t, mapping, `parse_csv(false, "|")`)
+	assert.Contains(t, mapping, `"name": $row.index(1)`)
+
+	s3Out := configData["output"].(map[string]interface{})["aws_s3"].(map[string]interface{})
+	assert.True(t, strings.HasPrefix(s3Out["path"].(string), "orders/"))
+	assert.True(t, strings.HasSuffix(s3Out["path"].(string), ".parquet"))
+	batching := s3Out["batching"].(map[string]interface{})
+	encoder := batching["processors"].([]interface{})[0].(map[string]interface{})["parquet_encode"].(map[string]interface{})
+	assert.Equal(t, "zstd", encoder["default_compression"])
+	schema := encoder["schema"].([]interface{})
+	require.Len(t, schema, 2)
+	assert.Equal(t, map[string]interface{}{"name": "name", "type": "UTF8", "optional": true}, schema[1])
+}
+
+func TestGenerateBenthosConfig_CSVOutputOnSFTPAndAzure(t *testing.T) {
+	sourceConn := data.Connection{Type: "s3", ConnectionString: "bucket=src;format=parquet"}
+	task := data.ReplicationTask{ID: 502, DataSelectionCriteria: "in/"}
+
+	for _, target := range []data.Connection{
+		{Type: "sftp", ConnectionString: "address=sftp:22;username=u;password=env:P;format=csv;csv_columns=id,note;csv_quote=all"},
+		{Type: "azure_blob", ConnectionString: "account=acct;container=c;format=csv;csv_columns=id,note;csv_quote=all"},
+	} {
+		t.Run(target.Type, func(t *testing.T) {
+			configData := generateConfigMap(t, task, sourceConn, target)
+
+			inputMap := configData["input"].(map[string]interface{})
+			assert.Equal(t, "all-bytes", inputMap["aws_s3"].(map[string]interface{})["codec"])
+			assert.Contains(t, inputMap["processors"].([]interface{})[0], "parquet_decode")
+
+			var outConf map[string]interface{}
+			for _, conf := range configData["output"].(map[string]interface{}) {
+				outConf = conf.(map[string]interface{})
+			}
+			assert.True(t, strings.HasSuffix(outConf["path"].(string), ".csv"))
+			processors := outConf["batching"].(map[string]interface{})["processors"].([]interface{})
+			require.Len(t, processors, 3, "Rows are encoded, archived as lines and prefixed with a header")
+			rowMapping := processors[0].(map[string]interface{})["mapping"].(string)
+			assert.Contains(t, rowMapping, `let columns = ["id","note"]`)
+			assert.NotContains(t, rowMapping, "s.contains", "csv_quote=all quotes every cell")
+			assert.Equal(t, "lines", processors[1].(map[string]interface{})["archive"].(map[string]interface{})["format"])
+		})
+	}
+}
+
+func TestGenerateBenthosConfig_SnowflakeStageFormats(t *testing.T) {
+	sourceConn := data.Connection{Type: "localfile", ConnectionString: "format=ndjson"}
+	task := data.ReplicationTask{ID: 503, DataSelectionCriteria: "/data/in.ndjson"}
+
+	configData := generateConfigMap(t, task, sourceConn,
+		data.Connection{Type: "snowflake", ConnectionString: "account=a;user=u;table=t;format=parquet;parquet_schema=id:INT64"})
+	snowflake := configData["output"].(map[string]interface{})["snowflake_put"].(map[string]interface{})
+	assert.True(t, strings.HasSuffix(snowflake["file_name_format"].(string), ".parquet"))
+	assert.Equal(t, "NONE", snowflake["compression"])
+	assert.Contains(t, snowflake, "batching")
+
+	configData = generateConfigMap(t, task, sourceConn,
+		data.Connection{Type: "snowflake", ConnectionString: "account=a;user=u;table=t;format=csv"})
+	snowflake = configData["output"].(map[string]interface{})["snowflake_put"].(map[string]interface{})
+	assert.True(t, strings.HasSuffix(snowflake["file_name_format"].(string), ".csv.gz"))
+	assert.NotContains(t, snowflake, "compression")
+}
+
+func TestGenerateBenthosConfig_KafkaAvro(t *testing.T) {
+	sourceConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;format=avro;schema_registry_url=http://registry:8081"}
+	targetConn := data.Connection{Type: "kafka", ConnectionString: `brokers=localhost:19092;topic=out;format=avro;avro_schema={"type":"record","name":"r","fields":[{"name":"id","type":"long"}]}`}
+	task := data.ReplicationTask{ID: 504, DataSelectionCriteria: "in"}
+
+	configData := generateConfigMap(t, task, sourceConn, targetConn)
+
+	decoder := configData["input"].(map[string]interface{})["processors"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "http://registry:8081", decoder["schema_registry_decode"].(map[string]interface{})["url"])
+	encoder := configData["output"].(map[string]interface{})["processors"].([]interface{})[0].(map[string]interface{})["avro"].(map[string]interface{})
+	assert.Equal(t, "from_json", encoder["operator"])
+	assert.Contains(t, encoder["schema"], `"name":"id"`)
+}
+
+func TestGenerateBenthosConfig_AvroFileOutput(t *testing.T) {
+	sourceConn := data.Connection{Type: "localfile", ConnectionString: "format=ndjson"}
+	task := data.ReplicationTask{ID: 505, DataSelectionCriteria: "/data/in.ndjson"}
+
+	for _, target := range []data.Connection{
+		{Type: "s3", ConnectionString: `bucket=lake;format=avro;avro_schema={"type":"record","name":"r","fields":[{"name":"id","type":"long"}]}`},
+		{Type: "localfile", ConnectionString: "directory=/data/out;format=avro;avro_schema_file=/schemas/r.avsc"},
+		{Type: "sftp", ConnectionString: "address=sftp:22;username=u;password=env:P;format=avro;avro_schema_file=/schemas/r.avsc"},
+	} {
+		t.Run(target.Type, func(t *testing.T) {
+			configData := generateConfigMap(t, task, sourceConn, target)
+
+			var outConf map[string]interface{}
+			for _, conf := range configData["output"].(map[string]interface{}) {
+				outConf = conf.(map[string]interface{})
+			}
+			assert.True(t, strings.HasSuffix(outConf["path"].(string), ".avro"))
+			processors := outConf["batching"].(map[string]interface{})["processors"].([]interface{})
+			require.Len(t, processors, 4, "Records are counted, encoded, concatenated and framed as a container file")
+			assert.Equal(t, "from_json", processors[1].(map[string]interface{})["avro"].(map[string]interface{})["operator"])
+			assert.Equal(t, "concatenate", processors[2].(map[string]interface{})["archive"].(map[string]interface{})["format"])
+			framing := processors[3].(map[string]interface{})["mapping"].(string)
+			assert.Contains(t, framing, `"4f626a01"`, "Files start with the Avro magic bytes")
+			assert.Contains(t, framing, avroSyncMarker)
+		})
+	}
+}
+
+func TestGenerateBenthosConfig_FileBatchSize(t *testing.T) {
+	sourceConn := data.Connection{Type: "localfile", ConnectionString: "format=ndjson"}
+	task := data.ReplicationTask{ID: 506, DataSelectionCriteria: "/data/in.ndjson"}
+
+	configData := generateConfigMap(t, task, sourceConn, data.Connection{Type: "s3", ConnectionString: "bucket=lake;format=csv"})
+	batching := configData["output"].(map[string]interface{})["aws_s3"].(map[string]interface{})["batching"].(map[string]interface{})
+	assert.Equal(t, defaultBatchSize, batching["count"])
+	assert.Equal(t, defaultBatchPeriod, batching["period"])
+
+	configData = generateConfigMap(t, task, sourceConn, data.Connection{Type: "azure_blob", ConnectionString: "account=a;container=c;format=ndjson;batch_size=250000;batch_period=2m"})
+	batching = configData["output"].(map[string]interface{})["azure_blob_storage"].(map[string]interface{})["batching"].(map[string]interface{})
+	assert.Equal(t, 250000, batching["count"])
+	assert.Equal(t, "2m0s", batching["period"])
+}
+
+func TestGenerateBenthosConfigForRun_DerivedParquetSchema(t *testing.T) {
+	sourceConn := data.Connection{Type: "postgres", ConnectionString: "dsn=env:APP_DSN"}
+	task := data.ReplicationTask{ID: 507, DataSelectionCriteria: "SELECT id, name FROM orders"}
+	run := RunContext{ParquetSchema: "id:INT64,name:UTF8"}
+
+	configYAML, err := GenerateBenthosConfigForRun(task, sourceConn, data.Connection{Type: "s3", ConnectionString: "bucket=lake;format=parquet"}, run)
+	require.NoError(t, err)
+	assert.Contains(t, configYAML, "dsn: ${APP_DSN}")
+	assert.Contains(t, configYAML, "parquet_encode")
+	assert.Contains(t, configYAML, "name: name")
+
+	// An explicit parquet_schema wins over the derived one
+	configYAML, err = GenerateBenthosConfigForRun(task, sourceConn, data.Connection{Type: "s3", ConnectionString: "bucket=lake;format=parquet;parquet_schema=id:INT32"}, run)
+	require.NoError(t, err)
+	assert.Contains(t, configYAML, "type: INT32")
+	assert.NotContains(t, configYAML, "name: name")
+}
+
+func TestGenerateBenthosConfig_FormatErrors(t *testing.T) {
+	source := data.Connection{Type: "localfile"}
+	task := data.ReplicationTask{DataSelectionCriteria: "/data/in.json"}
+
+	tests := []struct {
+		name    string
+		source  data.Connection
+		target  data.Connection
+		wantErr string
+	}{
+		{"unknown format", data.Connection{Type: "localfile", ConnectionString: "format=xml"}, data.Connection{Type: "s3", ConnectionString: "bucket=b"}, "unsupported file format: xml"},
+		{"bad delimiter", data.Connection{Type: "localfile", ConnectionString: "format=csv;csv_delimiter=||"}, data.Connection{Type: "s3", ConnectionString: "bucket=b"}, "single character"},
+		{"parquet without schema", source, data.Connection{Type: "s3", ConnectionString: "bucket=b;format=parquet"}, "'parquet_schema' is required"},
+		{"bad parquet type", source, data.Connection{Type: "s3", ConnectionString: "bucket=b;format=parquet;parquet_schema=id:BIGINT"}, "invalid parquet_schema column"},
+		{"avro file without schema", source, data.Connection{Type: "sftp", ConnectionString: "address=a;username=u;password=env:P;format=avro"}, "avro file output requires"},
+		{"bad batch size", source, data.Connection{Type: "s3", ConnectionString: "bucket=b;batch_size=0"}, "'batch_size' must be"},
+		{"bad batch period", source, data.Connection{Type: "localfile", ConnectionString: "directory=/d;batch_period=soon"}, "'batch_period' must be"},
+		{"kafka avro without schema", source, data.Connection{Type: "kafka", ConnectionString: "brokers=b;topic=t;format=avro"}, "avro format requires"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBenthosConfig(task, tt.source, tt.target)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package benthos
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// HTTP API pagination strategies.
+const (
+	HTTPPaginationNone   = "none"
+	HTTPPaginationCursor = "cursor"
+	HTTPPaginationPage   = "page"
+	HTTPPaginationLink   = "link_header"
+)
+
+const (
+	httpAPIStateCache = "http_api_state"
+	httpAPIRateLimit  = "http_api_rate"
+	defaultMaxPages   = 1000
+)
+
+// bloblangPath restricts JSON paths to plain dotted field names before embedding them in mappings.
+var bloblangPath = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// HTTPAPISelection describes an API pull. It is stored as JSON in DataSelectionCriteria.
+type HTTPAPISelection struct {
+	Endpoint    string            `json:"endpoint"`               // Path appended to the connection's base_url
+	Method      string            `json:"method,omitempty"`       // Defaults to GET
+	Query       map[string]string `json:"query,omitempty"`        // Static query parameters
+	RecordsPath string            `json:"records_path,omitempty"` // Dotted path to the records array; empty if the body is the array
+	Pagination  HTTPAPIPagination `json:"pagination"`
+}
+
+// HTTPAPIPagination configures how the next page is requested.
+type HTTPAPIPagination struct {
+	Type          string `json:"type"`                      // none, cursor, page or link_header
+	CursorParam   string `json:"cursor_param,omitempty"`    // cursor: query parameter carrying the cursor
+	CursorPath    string `json:"cursor_path,omitempty"`     // cursor: dotted path to the next cursor in the response
+	PageParam     string `json:"page_param,omitempty"`      // page: query parameter carrying the page number
+	StartPage     int    `json:"start_page,omitempty"`      // page: first page number (default 1)
+	PageSizeParam string `json:"page_size_param,omitempty"` // Optional page size query parameter
+	PageSize      int    `json:"page_size,omitempty"`
+	MaxPages      int    `json:"max_pages,omitempty"` // Safety cap per run (default 1000)
+}
+
+// ParseHTTPAPISelection decodes and validates a task's API selection criteria.
+func ParseHTTPAPISelection(criteria string) (*HTTPAPISelection, error) {
+	if strings.TrimSpace(criteria) == "" {
+		return nil, fmt.Errorf("DataSelectionCriteria (endpoint JSON) cannot be empty for http_api input")
+	}
+	var sel HTTPAPISelection
+	if err := json.Unmarshal([]byte(criteria), &sel); err != nil {
+		return nil, fmt.Errorf("invalid http_api DataSelectionCriteria JSON: %w", err)
+	}
+	if sel.Endpoint == "" {
+		return nil, fmt.Errorf("'endpoint' is required in http_api DataSelectionCriteria")
+	}
+	if sel.Method == "" {
+		sel.Method = "GET"
+	}
+	if sel.RecordsPath != "" && !bloblangPath.MatchString(sel.RecordsPath) {
+		return nil, fmt.Errorf("invalid records_path: %s", sel.RecordsPath)
+	}
+
+	p := &sel.Pagination
+	if p.Type == "" {
+		p.Type = HTTPPaginationNone
+	}
+	if p.MaxPages <= 0 {
+		p.MaxPages = defaultMaxPages
+	}
+	switch p.Type {
+	case HTTPPaginationNone, HTTPPaginationLink:
+	case HTTPPaginationCursor:
+		if p.CursorParam == "" || p.CursorPath == "" {
+			return nil, fmt.Errorf("cursor pagination requires 'cursor_param' and 'cursor_path'")
+		}
+		if !bloblangPath.MatchString(p.CursorPath) {
+			return nil, fmt.Errorf("invalid cursor_path: %s", p.CursorPath)
+		}
+	case HTTPPaginationPage:
+		if p.PageParam == "" {
+			return nil, fmt.Errorf("page pagination requires 'page_param'")
+		}
+		if p.StartPage == 0 {
+			p.StartPage = 1
+		}
+	default:
+		return nil, fmt.Errorf("unsupported http_api pagination type: %s", p.Type)
+	}
+	return &sel, nil
+}
+
+// generateHTTPAPIInput builds a paging loop: a generate input emits one tick per page and
+// input-level processors fetch the next page, save the paging state and split the records.
+// Paging state lives in a file cache (see HTTPAPIStateDir) so the last cursor can be persisted.
+func generateHTTPAPIInput(params map[string]string, task data.ReplicationTask) (map[string]interface{}, error) {
+	baseURL, ok := params["base_url"]
+	if !ok {
+		return nil, fmt.Errorf("'base_url' not found in connection string for http_api")
+	}
+	sel, err := ParseHTTPAPISelection(task.DataSelectionCriteria)
+	if err != nil {
+		return nil, err
+	}
+	httpProc, err := httpAPIProcessor(params, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	for k, v := range sel.Query {
+		query.Set(k, v)
+	}
+	if sel.Pagination.PageSizeParam != "" && sel.Pagination.PageSize > 0 {
+		query.Set(sel.Pagination.PageSizeParam, fmt.Sprint(sel.Pagination.PageSize))
+	}
+	requestURL := strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(sel.Endpoint, "/")
+	separator := "?"
+	if encoded := query.Encode(); encoded != "" {
+		requestURL += "?" + encoded
+		separator = "&"
+	}
+
+	records := "this"
+	if sel.RecordsPath != "" {
+		records = "this." + sel.RecordsPath
+	}
+
+	var processors []interface{}
+	pageCount := 1
+	if sel.Pagination.Type == HTTPPaginationNone {
+		processors = []interface{}{
+			map[string]interface{}{"mapping": fmt.Sprintf("meta request_url = %q", requestURL)},
+			httpProc,
+		}
+	} else {
+		pageCount = sel.Pagination.MaxPages
+		processors = httpAPIPagingProcessors(sel, requestURL, separator, records, httpProc)
+	}
+	processors = append(processors,
+		map[string]interface{}{"mapping": fmt.Sprintf("root = %s.or([])", records)},
+		map[string]interface{}{"unarchive": map[string]interface{}{"format": "json_array"}},
+	)
+
+	return map[string]interface{}{
+		"generate": map[string]interface{}{
+			"count":    pageCount,
+			"interval": "",
+			"mapping":  "root = {}",
+		},
+		"processors": processors,
+	}, nil
+}
+
+// httpAPIPagingProcessors loads the paging state, requests the current page, computes the next
+// position and saves it. Once the last page is reached the remaining ticks are dropped.
+func httpAPIPagingProcessors(sel *HTTPAPISelection, requestURL, separator, records string, httpProc map[string]interface{}) []interface{} {
+	p := sel.Pagination
+
+	var urlExpr, responseNext, nextExpr string
+	switch p.Type {
+	case HTTPPaginationCursor:
+		urlExpr = fmt.Sprintf(`%q + if @next != "" { %q + @next.escape_url_query() } else { "" }`, requestURL, separator+p.CursorParam+"=")
+		responseNext = fmt.Sprintf(`this.%s.or("").string()`, p.CursorPath)
+		nextExpr = "@response_next"
+	case HTTPPaginationPage:
+		urlExpr = fmt.Sprintf(`%q + %q + @next`, requestURL, separator+p.PageParam+"=")
+		responseNext = `""`
+		nextExpr = fmt.Sprintf(`if %s.or([]).length() > 0 { (@next.number() + 1).string() } else { "" }`, records)
+	case HTTPPaginationLink:
+		urlExpr = fmt.Sprintf(`if @next != "" { @next } else { %q }`, requestURL)
+		responseNext = `(@link | @Link | "").re_find_all_submatch("<([^>]+)>\\s*;\\s*rel=\"next\"").index(0).index(1).catch("")`
+		nextExpr = "@response_next"
+	}
+
+	return []interface{}{
+		// Load the paging state written by the previous page (or seeded before the run)
+		map[string]interface{}{"branch": map[string]interface{}{
+			"processors": []interface{}{
+				map[string]interface{}{"cache": map[string]interface{}{"resource": httpAPIStateCache, "operator": "get", "key": "state"}},
+			},
+			"result_map": "meta state = content().string()",
+		}},
+		map[string]interface{}{"mapping": strings.Join([]string{
+			"let state = @state.parse_json()",
+			`meta next = $state.next.or("").string()`,
+			"root = if $state.done.or(false) { deleted() } else { this }",
+		}, "\n")},
+		// Fetch the current page
+		map[string]interface{}{"branch": map[string]interface{}{
+			"request_map": "root = \"\"\nmeta request_url = " + urlExpr,
+			"processors":  []interface{}{httpProc},
+			"result_map":  "root = this\nmeta response_next = " + responseNext,
+		}},
+		// Work out the next position; "last" is where an incremental pull resumes
+		map[string]interface{}{"mapping": strings.Join([]string{
+			"let next = " + nextExpr,
+			`meta done = if $next == "" { "true" } else { "false" }`,
+			`meta last = if $next != "" { $next } else { @next }`,
+			"meta next = $next",
+		}, "\n")},
+		map[string]interface{}{"branch": map[string]interface{}{
+			"request_map": `root = {"next": @next, "done": @done == "true", "last": @last}`,
+			"processors": []interface{}{
+				map[string]interface{}{"cache": map[string]interface{}{
+					"resource": httpAPIStateCache, "operator": "set", "key": "state", "value": "${! content() }",
+				}},
+			},
+		}},
+	}
+}
+
+// httpAPIProcessor builds the http processor with auth, rate limiting and header extraction.
+func httpAPIProcessor(params map[string]string, sel *HTTPAPISelection) (map[string]interface{}, error) {
+	httpConf := map[string]interface{}{
+		"url":     "${! @request_url }",
+		"verb":    sel.Method,
+		"headers": map[string]interface{}{"Accept": "application/json"},
+		"extract_headers": map[string]interface{}{
+			"include_patterns": []interface{}{"(?i)^link$"},
+		},
+	}
+
+	if authHeader, ok := params["auth_header"]; ok {
+		value, err := requireSecretRef("auth_header", authHeader)
+		if err != nil {
+			return nil, err
+		}
+		headerName, ok := params["auth_header_name"]
+		if !ok {
+			headerName = "Authorization"
+		}
+		httpConf["headers"].(map[string]interface{})[headerName] = value
+	}
+
+	if clientID, ok := params["oauth2_client_id"]; ok {
+		tokenURL, ok := params["oauth2_token_url"]
+		if !ok {
+			return nil, fmt.Errorf("'oauth2_token_url' is required for OAuth2 client credentials")
+		}
+		secret, err := requireSecretRef("oauth2_client_secret", params["oauth2_client_secret"])
+		if err != nil {
+			return nil, err
+		}
+		oauth2 := map[string]interface{}{
+			"enabled":       true,
+			"client_key":    clientID,
+			"client_secret": secret,
+			"token_url":     tokenURL,
+		}
+		if scopes := SplitList(params["oauth2_scopes"]); len(scopes) > 0 {
+			oauth2["scopes"] = scopes
+		}
+		httpConf["oauth2"] = oauth2
+	}
+
+	if _, ok := params["rate_limit"]; ok {
+		httpConf["rate_limit"] = httpAPIRateLimit
+	}
+
+	return map[string]interface{}{"http": httpConf}, nil
+}
+
+// httpAPIResources returns the top-level cache and rate limit resources used by the input.
+func httpAPIResources(params map[string]string, task data.ReplicationTask) (map[string]interface{}, error) {
+	resources := map[string]interface{}{
+		"cache_resources": []interface{}{
+			map[string]interface{}{
+				"label": httpAPIStateCache,
+				"file":  map[string]interface{}{"directory": HTTPAPIStateDir(task.ID)},
+			},
+		},
+	}
+
+	if rateLimit, ok := params["rate_limit"]; ok {
+		var count int
+		if _, err := fmt.Sscan(rateLimit, &count); err != nil || count <= 0 {
+			return nil, fmt.Errorf("'rate_limit' must be a positive number of requests")
+		}
+		interval, ok := params["rate_limit_interval"]
+		if !ok {
+			interval = "1s"
+		}
+		resources["rate_limit_resources"] = []interface{}{
+			map[string]interface{}{
+				"label": httpAPIRateLimit,
+				"local": map[string]interface{}{"count": count, "interval": interval},
+			},
+		}
+	}
+	return resources, nil
+}
@@ -0,0 +1,131 @@
+package benthos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfig_HTTPAPICursorWithOAuth2AndRateLimit(t *testing.T) {
+	// Arrange
+	t.Setenv("HSOETLNLM_STATE_DIR", t.TempDir())
+	sourceConn := data.Connection{
+		ID:               50,
+		Type:             "http_api",
+		ConnectionString: "base_url=https://api.example.com/;oauth2_client_id=etl;oauth2_client_secret=env:API_CLIENT_SECRET;oauth2_token_url=https://auth.example.com/token;oauth2_scopes=orders.read;rate_limit=5;rate_limit_interval=1s",
+	}
+	targetConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;topic=orders"}
+	task := data.ReplicationTask{
+		ID: 601,
+		DataSelectionCriteria: `{"endpoint": "/v1/orders", "query": {"status": "open"}, "records_path": "data",
+			"pagination": {"type": "cursor", "cursor_param": "after", "cursor_path": "meta.next_cursor", "page_size_param": "limit", "page_size": 100, "max_pages": 50}}`,
+	}
+
+	// Act
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+
+	// Assert
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+
+	inputMap := configData["input"].(map[string]interface{})
+	generate := inputMap["generate"].(map[string]interface{})
+	assert.Equal(t, 50, generate["count"], "One tick per page, capped by max_pages")
+
+	processors := inputMap["processors"].([]interface{})
+	require.Len(t, processors, 7)
+	fetch := processors[2].(map[string]interface{})["branch"].(map[string]interface{})
+	assert.Contains(t, fetch["request_map"], `"https://api.example.com/v1/orders?limit=100&status=open"`)
+	assert.Contains(t, fetch["request_map"], `"&after="`)
+	assert.Contains(t, fetch["result_map"], "this.meta.next_cursor")
+
+	httpConf := fetch["processors"].([]interface{})[0].(map[string]interface{})["http"].(map[string]interface{})
+	assert.Equal(t, "GET", httpConf["verb"])
+	assert.Equal(t, httpAPIRateLimit, httpConf["rate_limit"])
+	oauth2 := httpConf["oauth2"].(map[string]interface{})
+	assert.Equal(t, "etl", oauth2["client_key"])
+	assert.Equal(t, "${API_CLIENT_SECRET}", oauth2["client_secret"])
+	assert.Equal(t, []interface{}{"orders.read"}, oauth2["scopes"])
+
+	assert.Equal(t, "root = this.data.or([])", processors[5].(map[string]interface{})["mapping"])
+
+	caches := configData["cache_resources"].([]interface{})
+	cacheDir := caches[0].(map[string]interface{})["file"].(map[string]interface{})["directory"]
+	assert.Equal(t, HTTPAPIStateDir(task.ID), cacheDir)
+	rateLimits := configData["rate_limit_resources"].([]interface{})
+	assert.Equal(t, 5, rateLimits[0].(map[string]interface{})["local"].(map[string]interface{})["count"])
+}
+
+func TestGenerateBenthosConfig_HTTPAPIPageAndLinkHeader(t *testing.T) {
+	sourceConn := data.Connection{Type: "http_api", ConnectionString: "base_url=https://api.example.com;auth_header=env:API_TOKEN;auth_header_name=X-Api-Key"}
+	targetConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;topic=t"}
+
+	pageTask := data.ReplicationTask{ID: 602, DataSelectionCriteria: `{"endpoint": "items", "pagination": {"type": "page", "page_param": "page"}}`}
+	configYAML, err := GenerateBenthosConfig(pageTask, sourceConn, targetConn)
+	require.NoError(t, err)
+	assert.Contains(t, configYAML, `"https://api.example.com/items" + "?page=" + @next`)
+	assert.Contains(t, configYAML, "X-Api-Key: ${API_TOKEN}")
+	assert.NotContains(t, configYAML, "rate_limit_resources")
+
+	linkTask := data.ReplicationTask{ID: 603, DataSelectionCriteria: `{"endpoint": "items", "pagination": {"type": "link_header"}}`}
+	configYAML, err = GenerateBenthosConfig(linkTask, sourceConn, targetConn)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(configYAML, `rel=\"next\"`), "Link header pagination should parse rel=next")
+
+	singleTask := data.ReplicationTask{ID: 604, DataSelectionCriteria: `{"endpoint": "status"}`}
+	configYAML, err = GenerateBenthosConfig(singleTask, sourceConn, targetConn)
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+	generate := configData["input"].(map[string]interface{})["generate"].(map[string]interface{})
+	assert.Equal(t, 1, generate["count"], "Unpaginated endpoints are fetched once")
+}
+
+func TestParseHTTPAPISelection_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria string
+		wantErr  string
+	}{
+		{"empty", "", "cannot be empty"},
+		{"not json", "/v1/orders", "invalid http_api DataSelectionCriteria JSON"},
+		{"missing endpoint", `{"query": {}}`, "'endpoint' is required"},
+		{"cursor without path", `{"endpoint": "x", "pagination": {"type": "cursor", "cursor_param": "c"}}`, "requires 'cursor_param' and 'cursor_path'"},
+		{"unsafe records path", `{"endpoint": "x", "records_path": "data\") + env(\"X"}`, "invalid records_path"},
+		{"unknown type", `{"endpoint": "x", "pagination": {"type": "offset"}}`, "unsupported http_api pagination type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseHTTPAPISelection(tt.criteria)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestHTTPAPIState_SeedAndReadWatermark(t *testing.T) {
+	t.Setenv("HSOETLNLM_STATE_DIR", t.TempDir())
+
+	sel, err := ParseHTTPAPISelection(`{"endpoint": "x", "pagination": {"type": "page", "page_param": "p", "start_page": 0}}`)
+	require.NoError(t, err)
+
+	watermark, err := ReadHTTPAPIWatermark(700)
+	require.NoError(t, err)
+	assert.Empty(t, watermark, "No state means no watermark")
+
+	require.NoError(t, SeedHTTPAPIState(700, sel, ""))
+	watermark, err = ReadHTTPAPIWatermark(700)
+	require.NoError(t, err)
+	assert.Empty(t, watermark, "A fresh page pull has not reached a position yet")
+
+	require.NoError(t, SeedHTTPAPIState(700, sel, "42"))
+	watermark, err = ReadHTTPAPIWatermark(700)
+	require.NoError(t, err)
+	assert.Equal(t, "42", watermark)
+}
@@ -0,0 +1,175 @@
+package benthos
+
+import (
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// generateKafkaInput builds a kafka_franz consumer-group input. Topics come from
+// the task's DataSelectionCriteria so one Kafka connection can serve many tasks.
+func generateKafkaInput(params map[string]string, task data.ReplicationTask) (map[string]interface{}, error) {
+	brokers := SplitList(params["brokers"])
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("'brokers' not found in connection string for Kafka")
+	}
+	topics := SplitList(task.DataSelectionCriteria)
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("DataSelectionCriteria (topic list) cannot be empty for Kafka input")
+	}
+
+	consumerGroup, ok := params["consumer_group"]
+	if !ok {
+		// A stable per-task group keeps committed offsets across runs
+		consumerGroup = fmt.Sprintf("hsoetlnlm-task-%d", task.ID)
+	}
+
+	kafkaInput := map[string]interface{}{
+		"seed_brokers":      brokers,
+		"topics":            topics,
+		"consumer_group":    consumerGroup,
+		"start_from_oldest": parseBool(params["start_from_oldest"], true),
+	}
+	if err := applyKafkaSecurity(kafkaInput, params); err != nil {
+		return nil, err
+	}
+
+	input := map[string]interface{}{"kafka_franz": kafkaInput}
+	decoders, err := kafkaAvroProcessors(params, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoders) > 0 {
+		input["processors"] = decoders
+	}
+	return input, nil
+}
+
+// generateKafkaOutput builds a kafka_franz producer output with optional key
+// mapping and partitioner.
+func generateKafkaOutput(params map[string]string) (map[string]interface{}, error) {
+	brokers := SplitList(params["brokers"])
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("'brokers' not found in connection string for Kafka output")
+	}
+	topic, ok := params["topic"]
+	if !ok {
+		return nil, fmt.Errorf("'topic' not found in connection string for Kafka output")
+	}
+
+	kafkaOutput := map[string]interface{}{
+		"seed_brokers":  brokers,
+		"topic":         topic,
+		"max_in_flight": 10,
+	}
+	if key, ok := params["key"]; ok {
+		// Interpolated key, e.g. ${! json("id") }
+		kafkaOutput["key"] = key
+	}
+	if partitioner, ok := params["partitioner"]; ok {
+		switch partitioner {
+		case "murmur2_hash", "round_robin", "least_backup", "manual":
+			kafkaOutput["partitioner"] = partitioner
+		default:
+			return nil, fmt.Errorf("unsupported Kafka partitioner: %s", partitioner)
+		}
+		if partitioner == "manual" {
+			partition, ok := params["partition"]
+			if !ok {
+				return nil, fmt.Errorf("'partition' is required when partitioner is manual")
+			}
+			kafkaOutput["partition"] = partition
+		}
+	}
+	if err := applyKafkaSecurity(kafkaOutput, params); err != nil {
+		return nil, err
+	}
+
+	output := map[string]interface{}{"kafka_franz": kafkaOutput}
+	encoders, err := kafkaAvroProcessors(params, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(encoders) > 0 {
+		output["processors"] = encoders
+	}
+	return output, nil
+}
+
+// applyKafkaSecurity adds TLS and SASL sections shared by the Kafka input and output. The SASL
+// password must be a secret reference.
+func applyKafkaSecurity(conf map[string]interface{}, params map[string]string) error {
+	if parseBool(params["tls"], false) {
+		tls := map[string]interface{}{
+			"enabled":          true,
+			"skip_cert_verify": parseBool(params["tls_skip_verify"], false),
+		}
+		if rootCAs, ok := params["tls_root_cas_file"]; ok {
+			tls["root_cas_file"] = rootCAs
+		}
+		certFile, certOk := params["tls_cert_file"]
+		keyFile, keyOk := params["tls_key_file"]
+		if certOk && keyOk {
+			tls["client_certs"] = []interface{}{
+				map[string]interface{}{"cert_file": certFile, "key_file": resolveSecretRef(keyFile)},
+			}
+		}
+		conf["tls"] = tls
+	}
+
+	if mechanism, ok := params["sasl_mechanism"]; ok {
+		password, err := requireSecretRef("sasl_password", params["sasl_password"])
+		if err != nil {
+			return err
+		}
+		conf["sasl"] = []interface{}{
+			map[string]interface{}{
+				"mechanism": mechanism, // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+				"username":  params["sasl_username"],
+				"password":  password,
+			},
+		}
+	}
+	return nil
+}
+
+// kafkaAvroProcessors returns the per-message Avro decoders (input) or encoders (output) for a
+// Kafka connection with format=avro, using a schema registry or an inline schema.
+func kafkaAvroProcessors(params map[string]string, encode bool) ([]interface{}, error) {
+	format, err := fileFormat(params)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return nil, nil
+	case FormatAvro:
+	default:
+		return nil, fmt.Errorf("unsupported format for Kafka: %s", format)
+	}
+
+	if registryURL, ok := params["schema_registry_url"]; ok {
+		if !encode {
+			return []interface{}{map[string]interface{}{"schema_registry_decode": map[string]interface{}{"url": registryURL}}}, nil
+		}
+		subject, ok := params["avro_subject"]
+		if !ok {
+			return nil, fmt.Errorf("'avro_subject' is required to encode with a schema registry")
+		}
+		return []interface{}{map[string]interface{}{"schema_registry_encode": map[string]interface{}{"url": registryURL, "subject": subject}}}, nil
+	}
+
+	operator := "to_json"
+	if encode {
+		operator = "from_json"
+	}
+	avro := map[string]interface{}{"operator": operator, "encoding": "binary"}
+	if schema, ok := params["avro_schema"]; ok {
+		avro["schema"] = schema
+	} else if schemaFile, ok := params["avro_schema_file"]; ok {
+		avro["schema_path"] = "file://" + schemaFile
+	} else {
+		return nil, fmt.Errorf("avro format requires 'schema_registry_url', 'avro_schema' or 'avro_schema_file'")
+	}
+	return []interface{}{map[string]interface{}{"avro": avro}}, nil
+}
@@ -0,0 +1,137 @@
+package benthos
+
+import (
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfig_KafkaToKafkaWithSecurity(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{
+		ID:               10,
+		Name:             "Test Kafka Source",
+		Type:             "kafka",
+		ConnectionString: "brokers=broker1:9092, broker2:9092;consumer_group=replicator;tls=true;tls_skip_verify=true;sasl_mechanism=SCRAM-SHA-256;sasl_username=svc;sasl_password=env:KAFKA_PASSWORD",
+	}
+	targetConn := data.Connection{
+		ID:               11,
+		Name:             "Test Redpanda Target",
+		Type:             "kafka",
+		ConnectionString: "brokers=localhost:19092;topic=orders-replicated;key=${! json(\"id\") };partitioner=murmur2_hash",
+	}
+	task := data.ReplicationTask{
+		ID:                    201,
+		Name:                  "Kafka fan-out",
+		SourceConnectionID:    sourceConn.ID,
+		TargetConnectionID:    targetConn.ID,
+		DataSelectionCriteria: "orders, customers",
+	}
+
+	// Act
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+
+	// Assert
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+
+	// Check Input (consumer group)
+	inputMap := configData["input"].(map[string]interface{})
+	kafkaIn, ok := inputMap["kafka_franz"].(map[string]interface{})
+	require.True(t, ok, "Input section should contain kafka_franz config")
+	assert.Equal(t, []interface{}{"broker1:9092", "broker2:9092"}, kafkaIn["seed_brokers"])
+	assert.Equal(t, []interface{}{"orders", "customers"}, kafkaIn["topics"])
+	assert.Equal(t, "replicator", kafkaIn["consumer_group"])
+	tls := kafkaIn["tls"].(map[string]interface{})
+	assert.Equal(t, true, tls["enabled"])
+	assert.Equal(t, true, tls["skip_cert_verify"])
+	sasl := kafkaIn["sasl"].([]interface{})
+	require.Len(t, sasl, 1)
+	assert.Equal(t, "SCRAM-SHA-256", sasl[0].(map[string]interface{})["mechanism"])
+	assert.Equal(t, "svc", sasl[0].(map[string]interface{})["username"])
+	assert.Equal(t, "${KAFKA_PASSWORD}", sasl[0].(map[string]interface{})["password"])
+
+	// Check Output (producer)
+	outputMap := configData["output"].(map[string]interface{})
+	kafkaOut, ok := outputMap["kafka_franz"].(map[string]interface{})
+	require.True(t, ok, "Output section should contain kafka_franz config")
+	assert.Equal(t, []interface{}{"localhost:19092"}, kafkaOut["seed_brokers"])
+	assert.Equal(t, "orders-replicated", kafkaOut["topic"])
+	assert.Equal(t, `${! json("id") }`, kafkaOut["key"])
+	assert.Equal(t, "murmur2_hash", kafkaOut["partitioner"])
+	assert.NotContains(t, kafkaOut, "tls", "TLS should only be set when enabled")
+}
+
+func TestGenerateBenthosConfig_KafkaDefaultConsumerGroup(t *testing.T) {
+	sourceConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092"}
+	targetConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;topic=out"}
+	task := data.ReplicationTask{ID: 202, DataSelectionCriteria: "in"}
+
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+	require.NoError(t, err)
+
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+	kafkaIn := configData["input"].(map[string]interface{})["kafka_franz"].(map[string]interface{})
+	assert.Equal(t, "hsoetlnlm-task-202", kafkaIn["consumer_group"])
+}
+
+func TestGenerateBenthosConfig_KafkaErrors(t *testing.T) {
+	kafkaTarget := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;topic=out"}
+
+	tests := []struct {
+		name    string
+		source  data.Connection
+		target  data.Connection
+		task    data.ReplicationTask
+		wantErr string
+	}{
+		{
+			name:    "missing brokers",
+			source:  data.Connection{Type: "kafka", ConnectionString: "consumer_group=g"},
+			target:  kafkaTarget,
+			task:    data.ReplicationTask{DataSelectionCriteria: "orders"},
+			wantErr: "'brokers' not found",
+		},
+		{
+			name:    "empty topic list",
+			source:  data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092"},
+			target:  kafkaTarget,
+			task:    data.ReplicationTask{DataSelectionCriteria: " , "},
+			wantErr: "topic list",
+		},
+		{
+			name:    "missing target topic",
+			source:  data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092"},
+			target:  data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092"},
+			task:    data.ReplicationTask{DataSelectionCriteria: "orders"},
+			wantErr: "'topic' not found",
+		},
+		{
+			name:    "unknown partitioner",
+			source:  data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092"},
+			target:  data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;topic=out;partitioner=random"},
+			task:    data.ReplicationTask{DataSelectionCriteria: "orders"},
+			wantErr: "unsupported Kafka partitioner",
+		},
+		{
+			name:    "literal sasl password",
+			source:  data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;sasl_mechanism=PLAIN;sasl_username=svc;sasl_password=secret"},
+			target:  kafkaTarget,
+			task:    data.ReplicationTask{DataSelectionCriteria: "orders"},
+			wantErr: "'sasl_password' must be a secret reference",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBenthosConfig(tt.task, tt.source, tt.target)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
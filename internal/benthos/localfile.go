@@ -0,0 +1,219 @@
+package benthos
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+const (
+	defaultLocalFilePath = "{task}/{yyyy}/{mm}/{dd}/{run_id}-{seq}.{ext}"
+	localFileStagingDir  = ".staging"
+	localFileManifestDir = "_manifests"
+	hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+)
+
+// RunContext identifies the replication run a pipeline config is generated for.
+type RunContext struct {
+	RunID         int64
+	StartTime     time.Time
+	TargetTable   string          // Target table of a multi-table task's per-table run
+	Chunk         int             // Chunk index of a partitioned run; 0 otherwise
+	Columns       []ColumnMapping // Target columns of a created table, for column-wise targets
+	ParquetSchema string          // parquet_schema derived from the source columns, for Parquet targets without one
+	ArgsMapping   string          // Bloblang arguments for the source query's placeholders, e.g. a backfill slice's bounds
+	Streaming     bool            // Continuous pipeline of a streaming task; sources read changes instead of a query
+	HTTPAddress   string          // Address of the pipeline's HTTP server and metrics; Benthos's default port when empty
+}
+
+// LocalFileManifest lists the files a run published to a localfile target.
+type LocalFileManifest struct {
+	TaskID    int64               `json:"task_id"`
+	RunID     int64               `json:"run_id"`
+	CreatedAt time.Time           `json:"created_at"`
+	Files     []LocalManifestFile `json:"files"`
+}
+
+// LocalManifestFile is one published file, relative to the target directory.
+type LocalManifestFile struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// generateLocalFileOutput builds a file output that writes one file per batch into the run's
+// staging directory. PublishLocalFiles moves the files into place once the run succeeds.
+func generateLocalFileOutput(params map[string]string, task data.ReplicationTask, run RunContext) (map[string]interface{}, error) {
+	directory, ok := params["directory"]
+	if !ok {
+		return nil, fmt.Errorf("'directory' not found in connection string for localfile output")
+	}
+	format, err := fileFormat(params)
+	if err != nil {
+		return nil, err
+	}
+	template, ok := params["path"]
+	if !ok {
+		template = defaultLocalFilePath
+	}
+
+	batchProcessors, err := outputBatchProcessors(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(batchProcessors) == 0 {
+		// JSON records are written as newline-delimited JSON, one file per batch
+		batchProcessors = []interface{}{archiveLines()}
+	}
+
+	relPath := renderLocalFilePath(template, format, task, run)
+	if fields := SplitList(params["partition_by"]); len(fields) > 0 {
+		partitioning, err := hivePartitionProcessors(fields)
+		if err != nil {
+			return nil, err
+		}
+		// Split each batch by partition before encoding so every file holds a single partition
+		batchProcessors = append(partitioning, batchProcessors...)
+		dir, file := filepath.Split(relPath)
+		relPath = dir + "${! @hive_path }" + file
+	}
+	batching, err := batchPolicy(params, batchProcessors)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"file": map[string]interface{}{
+			"path":     filepath.Join(LocalFileStagingDir(directory, task.ID, run.RunID), relPath),
+			"codec":    "all-bytes",
+			"batching": batching,
+		},
+	}, nil
+}
+
+// renderLocalFilePath expands the {task}, {yyyy}, {mm}, {dd}, {hh}, {run_id}, {seq} and {ext}
+// placeholders. Dates come from the run start (UTC) so a run writes to a single date partition.
+func renderLocalFilePath(template, format string, task data.ReplicationTask, run RunContext) string {
+	start := run.StartTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+	start = start.UTC()
+	return strings.NewReplacer(
+		"{task}", strconv.FormatInt(task.ID, 10),
+		"{yyyy}", start.Format("2006"),
+		"{mm}", start.Format("01"),
+		"{dd}", start.Format("02"),
+		"{hh}", start.Format("15"),
+		"{run_id}", strconv.FormatInt(run.RunID, 10),
+		"{seq}", `${!count("files")}`,
+		"{ext}", format,
+	).Replace(template)
+}
+
+// hivePartitionProcessors tag each record with its field=value/ path and group the batch by it.
+func hivePartitionProcessors(fields []string) ([]interface{}, error) {
+	segments := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !bloblangPath.MatchString(field) {
+			return nil, fmt.Errorf("invalid partition_by field: %s", field)
+		}
+		segments = append(segments, fmt.Sprintf(`%q + this.%s.or(%q).string().replace_all("/", "_") + "/"`,
+			field+"=", field, hiveDefaultPartition))
+	}
+	return []interface{}{
+		map[string]interface{}{"mapping": "meta hive_path = " + strings.Join(segments, " + ")},
+		map[string]interface{}{"group_by_value": map[string]interface{}{"value": "${! @hive_path }"}},
+	}, nil
+}
+
+// LocalFileStagingDir is where a run's files are written before they are published.
+func LocalFileStagingDir(directory string, taskID, runID int64) string {
+	return filepath.Join(directory, localFileStagingDir, fmt.Sprintf("task-%d-run-%d", taskID, runID))
+}
+
+// ResetLocalFileStaging clears leftovers from an earlier attempt of the same run.
+func ResetLocalFileStaging(params map[string]string, taskID, runID int64) error {
+	directory, ok := params["directory"]
+	if !ok {
+		return fmt.Errorf("'directory' not found in connection string for localfile output")
+	}
+	if err := os.RemoveAll(LocalFileStagingDir(directory, taskID, runID)); err != nil {
+		return fmt.Errorf("error clearing localfile staging dir: %w", err)
+	}
+	return nil
+}
+
+// PublishLocalFiles renames a successful run's staged files into the target directory and,
+// unless manifest=false, writes a manifest listing them to _manifests/.
+func PublishLocalFiles(params map[string]string, taskID, runID int64) (*LocalFileManifest, error) {
+	directory, ok := params["directory"]
+	if !ok {
+		return nil, fmt.Errorf("'directory' not found in connection string for localfile output")
+	}
+	staging := LocalFileStagingDir(directory, taskID, runID)
+	manifest := &LocalFileManifest{TaskID: taskID, RunID: runID, CreatedAt: time.Now().UTC(), Files: []LocalManifestFile{}}
+
+	err := filepath.WalkDir(staging, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(directory, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		// Rename is atomic within a filesystem, so readers never see partial files
+		if err := os.Rename(path, dest); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, LocalManifestFile{Path: filepath.ToSlash(rel), SizeBytes: info.Size()})
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error publishing localfile output: %w", err)
+	}
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, fmt.Errorf("error removing localfile staging dir: %w", err)
+	}
+
+	if parseBool(params["manifest"], true) {
+		if err := writeLocalFileManifest(directory, manifest); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// writeLocalFileManifest writes the manifest via a temp file and rename.
+func writeLocalFileManifest(directory string, manifest *LocalFileManifest) error {
+	dir := filepath.Join(directory, localFileManifestDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating manifest dir: %w", err)
+	}
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("task-%d-run-%d.json", manifest.TaskID, manifest.RunID))
+	if err := os.WriteFile(path+".tmp", manifestBytes, 0o644); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+	return nil
+}
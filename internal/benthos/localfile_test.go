@@ -0,0 +1,106 @@
+package benthos
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfigForRun_LocalFileTargetWithHivePartitions(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{Type: "s3", ConnectionString: "bucket=src"}
+	targetConn := data.Connection{Type: "localfile", ConnectionString: "directory=/data/out;format=parquet;parquet_schema=id:INT64,region:UTF8;partition_by=region"}
+	task := data.ReplicationTask{ID: 601, DataSelectionCriteria: "in/"}
+	run := RunContext{RunID: 42, StartTime: time.Date(2026, 3, 7, 23, 30, 0, 0, time.UTC)}
+
+	// Act
+	configYAML, err := GenerateBenthosConfigForRun(task, sourceConn, targetConn, run)
+
+	// Assert
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+
+	fileOut := configData["output"].(map[string]interface{})["file"].(map[string]interface{})
+	assert.Equal(t, `/data/out/.staging/task-601-run-42/601/2026/03/07/${! @hive_path }42-${!count("files")}.parquet`, fileOut["path"])
+	assert.Equal(t, "all-bytes", fileOut["codec"])
+
+	processors := fileOut["batching"].(map[string]interface{})["processors"].([]interface{})
+	require.Len(t, processors, 3)
+	assert.Contains(t, processors[0].(map[string]interface{})["mapping"], `"region=" + this.region.or("__HIVE_DEFAULT_PARTITION__")`)
+	assert.Contains(t, processors[1], "group_by_value")
+	assert.Contains(t, processors[2], "parquet_encode")
+}
+
+func TestGenerateBenthosConfig_LocalFileTargetDefaults(t *testing.T) {
+	sourceConn := data.Connection{Type: "localfile"}
+	targetConn := data.Connection{Type: "localfile", ConnectionString: "directory=/data/out;path={task}/{run_id}/part-{seq}.{ext}"}
+	task := data.ReplicationTask{ID: 602, DataSelectionCriteria: "/data/in.json"}
+
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+
+	fileOut := configData["output"].(map[string]interface{})["file"].(map[string]interface{})
+	assert.Equal(t, `/data/out/.staging/task-602-run-0/602/0/part-${!count("files")}.json`, fileOut["path"])
+	processors := fileOut["batching"].(map[string]interface{})["processors"].([]interface{})
+	assert.Equal(t, []interface{}{archiveLines()}, processors, "JSON records are written as JSON lines")
+
+	_, err = GenerateBenthosConfig(task, sourceConn, data.Connection{Type: "localfile", ConnectionString: "path=x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'directory' not found")
+	_, err = GenerateBenthosConfig(task, sourceConn, data.Connection{Type: "localfile", ConnectionString: "directory=/d;partition_by=a-b"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid partition_by field")
+}
+
+func TestPublishLocalFiles(t *testing.T) {
+	// Arrange
+	directory := t.TempDir()
+	params := map[string]string{"directory": directory}
+	staging := LocalFileStagingDir(directory, 7, 3)
+	for _, rel := range []string{"7/region=EU/3-1.csv", "7/region=US/3-2.csv"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(staging, rel)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(staging, rel), []byte("id\n1\n"), 0o644))
+	}
+
+	// Act
+	manifest, err := PublishLocalFiles(params, 7, 3)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, LocalManifestFile{Path: "7/region=EU/3-1.csv", SizeBytes: 5}, manifest.Files[0])
+	assert.FileExists(t, filepath.Join(directory, "7/region=US/3-2.csv"))
+	assert.NoDirExists(t, staging)
+
+	manifestBytes, err := os.ReadFile(filepath.Join(directory, "_manifests", "task-7-run-3.json"))
+	require.NoError(t, err)
+	var written LocalFileManifest
+	require.NoError(t, json.Unmarshal(manifestBytes, &written))
+	assert.Equal(t, manifest.Files, written.Files)
+
+	// A run that produced nothing still gets an (empty) manifest
+	manifest, err = PublishLocalFiles(params, 7, 4)
+	require.NoError(t, err)
+	assert.Empty(t, manifest.Files)
+	assert.FileExists(t, filepath.Join(directory, "_manifests", "task-7-run-4.json"))
+}
+
+func TestResetLocalFileStaging(t *testing.T) {
+	directory := t.TempDir()
+	staging := LocalFileStagingDir(directory, 8, 1)
+	require.NoError(t, os.MkdirAll(staging, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(staging, "partial.json"), []byte("{"), 0o644))
+
+	require.NoError(t, ResetLocalFileStaging(map[string]string{"directory": directory}, 8, 1))
+	assert.NoDirExists(t, staging)
+}
@@ -0,0 +1,101 @@
+package benthos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// ColumnMapping maps a source record field to the target table column it is inserted into.
+type ColumnMapping struct {
+	Source string
+	Target string
+}
+
+// Run columns record the run and chunk that wrote each row of a postgres target with
+// run_columns=true, so a retried or resumed load can delete its earlier attempt's rows first.
+const (
+	RunIDColumn    = "_run_id"
+	RunChunkColumn = "_run_chunk"
+)
+
+// RunColumns reports whether a postgres target records the writing run on each row.
+func RunColumns(params map[string]string) bool {
+	return parseBool(params["run_columns"], false)
+}
+
+// PostgresRunCleanup renders the DELETE removing the rows an earlier attempt of the run's
+// chunk wrote, making the load idempotent by run ID. It returns "" for targets without run
+// columns.
+func PostgresRunCleanup(conn data.Connection, run RunContext) (string, error) {
+	params := ParseConnectionString(conn.ConnectionString)
+	if conn.Type != "postgres" || !RunColumns(params) {
+		return "", nil
+	}
+	if run.TargetTable != "" {
+		applyTargetTable(conn.Type, params, run.TargetTable)
+	}
+	table, err := postgresTable(params)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s = %d AND %s = %d", table, RunIDColumn, run.RunID, RunChunkColumn, run.Chunk), nil
+}
+
+func postgresTable(params map[string]string) (string, error) {
+	table, ok := params["table"]
+	if !ok {
+		return "", fmt.Errorf("'table' not found in connection string for postgres output")
+	}
+	return QualifiedName("postgres", params, table), nil
+}
+
+// generatePostgresOutput builds a sql_insert output into schema.table. Columns come from the
+// run (derived from the source, already identifiers) or the connection's `columns` key.
+func generatePostgresOutput(params map[string]string, run RunContext) (map[string]interface{}, error) {
+	dsn, ok := params["dsn"]
+	if !ok {
+		return nil, fmt.Errorf("'dsn' not found in connection string for postgres output")
+	}
+	table, err := postgresTable(params)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := run.Columns
+	if len(columns) == 0 {
+		for _, name := range SplitList(params["columns"]) {
+			columns = append(columns, ColumnMapping{Source: name, Target: Identifier("postgres", name)})
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("'columns' not found in connection string for postgres output")
+	}
+
+	targets := make([]string, 0, len(columns))
+	args := make([]string, 0, len(columns))
+	for _, column := range columns {
+		targets = append(targets, column.Target)
+		args = append(args, "this."+strconv.Quote(column.Source))
+	}
+	if RunColumns(params) {
+		targets = append(targets, RunIDColumn, RunChunkColumn)
+		args = append(args, strconv.FormatInt(run.RunID, 10), strconv.Itoa(run.Chunk))
+	}
+
+	return map[string]interface{}{
+		"sql_insert": map[string]interface{}{
+			"driver":       "postgres",
+			"dsn":          resolveSecretRef(dsn),
+			"table":        table,
+			"columns":      targets,
+			"args_mapping": "root = [ " + strings.Join(args, ", ") + " ]",
+			"batching": map[string]interface{}{
+				"count":  100,
+				"period": "1s",
+			},
+		},
+	}, nil
+}
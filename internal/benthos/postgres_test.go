@@ -0,0 +1,80 @@
+package benthos
+
+import (
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfigForRun_PostgresTarget(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{Type: "sqlserver", ConnectionString: "dsn=sqlserver://u:p@host/db"}
+	targetConn := data.Connection{Type: "postgres", ConnectionString: "dsn=env:WAREHOUSE_DSN;schema=staging;table=orders"}
+	task := data.ReplicationTask{ID: 801, DataSelectionCriteria: "SELECT * FROM [dbo].[Orders]"}
+	run := RunContext{RunID: 3, Columns: []ColumnMapping{{Source: "OrderID", Target: "orderid"}, {Source: "Ship To", Target: `"Ship To"`}}}
+
+	// Act
+	configYAML, err := GenerateBenthosConfigForRun(task, sourceConn, targetConn, run)
+
+	// Assert
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+	insert := configData["output"].(map[string]interface{})["sql_insert"].(map[string]interface{})
+	assert.Equal(t, "postgres", insert["driver"])
+	assert.Equal(t, "${WAREHOUSE_DSN}", insert["dsn"], "Secrets stay out of the generated config")
+	assert.Equal(t, "staging.orders", insert["table"])
+	assert.Equal(t, []interface{}{"orderid", `"Ship To"`}, insert["columns"])
+	assert.Equal(t, `root = [ this."OrderID", this."Ship To" ]`, insert["args_mapping"])
+}
+
+func TestGenerateBenthosConfig_PostgresTargetColumns(t *testing.T) {
+	sourceConn := data.Connection{Type: "localfile"}
+	task := data.ReplicationTask{DataSelectionCriteria: "/data/in.json"}
+
+	configData := generateConfigMap(t, task, sourceConn, data.Connection{Type: "postgres", ConnectionString: "dsn=postgres://h/db;table=events;columns=id,kind"})
+	insert := configData["output"].(map[string]interface{})["sql_insert"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"id", "kind"}, insert["columns"])
+	assert.Equal(t, `root = [ this."id", this."kind" ]`, insert["args_mapping"])
+
+	assert.Equal(t, "public.events", insert["table"], "Tables default to the public schema, as when they are created")
+
+	configData = generateConfigMap(t, task, sourceConn, data.Connection{Type: "postgres", ConnectionString: `dsn=postgres://h/db;schema=Sales Data;table=order"lines;columns=id,Ship To`})
+	insert = configData["output"].(map[string]interface{})["sql_insert"].(map[string]interface{})
+	assert.Equal(t, `"Sales Data"."order""lines"`, insert["table"])
+	assert.Equal(t, []interface{}{"id", `"Ship To"`}, insert["columns"])
+	assert.Equal(t, `root = [ this."id", this."Ship To" ]`, insert["args_mapping"])
+
+	_, err := GenerateBenthosConfig(task, sourceConn, data.Connection{Type: "postgres", ConnectionString: "dsn=postgres://h/db;table=events"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'columns' not found")
+}
+
+func TestGenerateBenthosConfigForRun_PostgresRunColumns(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{Type: "postgres", ConnectionString: "dsn=postgres://h/src"}
+	targetConn := data.Connection{Type: "postgres", ConnectionString: "dsn=postgres://h/db;schema=staging;table=orders;run_columns=true"}
+	task := data.ReplicationTask{DataSelectionCriteria: "SELECT * FROM orders"}
+	run := RunContext{RunID: 42, Chunk: 3, TargetTable: "orders_eu", Columns: []ColumnMapping{{Source: "id", Target: "id"}}}
+
+	// Act
+	configYAML, err := GenerateBenthosConfigForRun(task, sourceConn, targetConn, run)
+	require.NoError(t, err)
+	cleanup, err := PostgresRunCleanup(targetConn, run)
+	require.NoError(t, err)
+
+	// Assert
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+	insert := configData["output"].(map[string]interface{})["sql_insert"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"id", "_run_id", "_run_chunk"}, insert["columns"])
+	assert.Equal(t, `root = [ this."id", 42, 3 ]`, insert["args_mapping"])
+	assert.Equal(t, "DELETE FROM staging.orders_eu WHERE _run_id = 42 AND _run_chunk = 3", cleanup)
+
+	cleanup, err = PostgresRunCleanup(data.Connection{Type: "postgres", ConnectionString: "dsn=x;table=orders"}, run)
+	require.NoError(t, err)
+	assert.Empty(t, cleanup, "Targets without run columns are not cleaned up")
+}
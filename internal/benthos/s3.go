@@ -0,0 +1,141 @@
+package benthos
+
+import (
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// generateS3Input builds an aws_s3 input reading objects under the task's prefix.
+func generateS3Input(params map[string]string, task data.ReplicationTask) (map[string]interface{}, error) {
+	bucket, ok := params["bucket"]
+	if !ok {
+		return nil, fmt.Errorf("'bucket' not found in connection string for S3")
+	}
+
+	s3Input := map[string]interface{}{ // Use aws_s3 input
+		"bucket": bucket,
+		"prefix": task.DataSelectionCriteria, // Use criteria as the prefix
+	}
+	if err := applyS3Connection(s3Input, params); err != nil {
+		return nil, err
+	}
+	// Server-side encrypted objects (SSE-S3/SSE-KMS) are decrypted transparently on read,
+	// so the sse/kms_key_id keys only affect outputs.
+
+	return applyInputFormat("aws_s3", s3Input, params, "")
+}
+
+// generateS3Output builds an aws_s3 output writing batched objects under path_prefix.
+func generateS3Output(params map[string]string) (map[string]interface{}, error) {
+	bucket, ok := params["bucket"]
+	if !ok {
+		return nil, fmt.Errorf("'bucket' not found in connection string for S3 output")
+	}
+	pathPrefix, ok := params["path_prefix"]
+	if !ok {
+		pathPrefix = "output/" // Default prefix
+	}
+	format, err := fileFormat(params)
+	if err != nil {
+		return nil, err
+	}
+	batchProcessors, err := outputBatchProcessors(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Batching is enabled for S3 efficiency; formats with batch processors encode each batch into a single object
+	batching, err := batchPolicy(params, batchProcessors)
+	if err != nil {
+		return nil, err
+	}
+	s3Output := map[string]interface{}{ // Use aws_s3 output
+		"bucket":   bucket,
+		"path":     pathPrefix + defaultObjectName(format),
+		"batching": batching,
+	}
+	if err := applyS3Connection(s3Output, params); err != nil {
+		return nil, err
+	}
+
+	if sse, ok := params["sse"]; ok {
+		switch sse {
+		case "AES256", "aws:kms":
+			s3Output["server_side_encryption"] = sse
+		default:
+			return nil, fmt.Errorf("unsupported S3 server-side encryption: %s", sse)
+		}
+	}
+	if kmsKeyID, ok := params["kms_key_id"]; ok {
+		s3Output["kms_key_id"] = kmsKeyID
+		if _, ok := params["sse"]; !ok {
+			s3Output["server_side_encryption"] = "aws:kms"
+		}
+	}
+
+	return map[string]interface{}{"aws_s3": s3Output}, nil
+}
+
+// applyS3Connection maps region, endpoint and credential keys shared by the S3 input and output.
+func applyS3Connection(conf map[string]interface{}, params map[string]string) error {
+	if region, ok := params["region"]; ok && region != "" {
+		conf["region"] = region
+	}
+	// Custom endpoints target S3-compatible stores such as MinIO
+	if endpoint, ok := params["endpoint"]; ok {
+		conf["endpoint"] = endpoint
+	}
+	if forcePathStyle, ok := params["force_path_style"]; ok {
+		conf["force_path_style_urls"] = parseBool(forcePathStyle, false)
+	}
+
+	credentials, err := s3Credentials(params)
+	if err != nil {
+		return err
+	}
+	if len(credentials) > 0 {
+		conf["credentials"] = credentials
+	}
+	return nil
+}
+
+// s3Credentials builds the credentials section. Without any credential keys the AWS SDK
+// default chain (environment, shared config, instance role) is used.
+func s3Credentials(params map[string]string) (map[string]interface{}, error) {
+	credentials := map[string]interface{}{}
+
+	if profile, ok := params["profile"]; ok {
+		credentials["profile"] = profile
+	}
+
+	accessKeyID, idOk := params["access_key_id"]
+	secretKey, secretOk := params["secret_access_key"]
+	if idOk != secretOk {
+		return nil, fmt.Errorf("'access_key_id' and 'secret_access_key' must be provided together for S3")
+	}
+	if idOk {
+		secret, err := requireSecretRef("secret_access_key", secretKey)
+		if err != nil {
+			return nil, err
+		}
+		credentials["id"] = resolveSecretRef(accessKeyID)
+		credentials["secret"] = secret
+		if sessionToken, ok := params["session_token"]; ok {
+			token, err := requireSecretRef("session_token", sessionToken)
+			if err != nil {
+				return nil, err
+			}
+			credentials["token"] = token
+		}
+	}
+
+	if roleARN, ok := params["role_arn"]; ok {
+		credentials["role"] = roleARN
+		if externalID, ok := params["role_external_id"]; ok {
+			credentials["role_external_id"] = externalID
+		}
+	}
+
+	return credentials, nil
+}
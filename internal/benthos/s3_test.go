@@ -0,0 +1,94 @@
+package benthos
+
+import (
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfig_MinIOToMinIOWithStaticKeys(t *testing.T) {
+	// Arrange
+	minio := "bucket=landing;region=us-east-1;endpoint=http://localhost:9000;force_path_style=true;access_key_id=env:MINIO_ACCESS_KEY;secret_access_key=env:MINIO_SECRET_KEY"
+	sourceConn := data.Connection{ID: 20, Type: "s3", ConnectionString: minio}
+	targetConn := data.Connection{ID: 21, Type: "s3", ConnectionString: minio + ";path_prefix=curated/;sse=aws:kms;kms_key_id=alias/lake"}
+	task := data.ReplicationTask{ID: 301, DataSelectionCriteria: "raw/"}
+
+	// Act
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotContains(t, configYAML, "MINIO_SECRET_KEY=", "Secrets should only appear as env interpolations")
+
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+
+	s3In := configData["input"].(map[string]interface{})["aws_s3"].(map[string]interface{})
+	assert.Equal(t, "http://localhost:9000", s3In["endpoint"])
+	assert.Equal(t, true, s3In["force_path_style_urls"])
+	assert.Equal(t, "raw/", s3In["prefix"])
+	inCreds := s3In["credentials"].(map[string]interface{})
+	assert.Equal(t, "${MINIO_ACCESS_KEY}", inCreds["id"])
+	assert.Equal(t, "${MINIO_SECRET_KEY}", inCreds["secret"])
+	assert.NotContains(t, s3In, "server_side_encryption")
+
+	s3Out := configData["output"].(map[string]interface{})["aws_s3"].(map[string]interface{})
+	assert.Equal(t, "http://localhost:9000", s3Out["endpoint"])
+	assert.Equal(t, true, s3Out["force_path_style_urls"])
+	assert.Equal(t, inCreds, s3Out["credentials"], "Input and output credentials should be mapped identically")
+	assert.Equal(t, "aws:kms", s3Out["server_side_encryption"])
+	assert.Equal(t, "alias/lake", s3Out["kms_key_id"])
+}
+
+func TestGenerateBenthosConfig_S3ProfileAndRole(t *testing.T) {
+	sourceConn := data.Connection{Type: "s3", ConnectionString: "bucket=src;profile=analytics;role_arn=arn:aws:iam::123456789012:role/reader;role_external_id=ext-1"}
+	targetConn := data.Connection{Type: "s3", ConnectionString: "bucket=dst;kms_key_id=arn:aws:kms:us-east-1:123456789012:key/abc"}
+	task := data.ReplicationTask{ID: 302, DataSelectionCriteria: "in/"}
+
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+	require.NoError(t, err)
+
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+
+	s3In := configData["input"].(map[string]interface{})["aws_s3"].(map[string]interface{})
+	creds := s3In["credentials"].(map[string]interface{})
+	assert.Equal(t, "analytics", creds["profile"])
+	assert.Equal(t, "arn:aws:iam::123456789012:role/reader", creds["role"])
+	assert.Equal(t, "ext-1", creds["role_external_id"])
+
+	s3Out := configData["output"].(map[string]interface{})["aws_s3"].(map[string]interface{})
+	assert.NotContains(t, s3Out, "credentials", "Default credential chain should be used when no keys are set")
+	assert.Equal(t, "aws:kms", s3Out["server_side_encryption"], "A KMS key implies SSE-KMS")
+}
+
+func TestGenerateBenthosConfig_S3CredentialErrors(t *testing.T) {
+	target := data.Connection{Type: "s3", ConnectionString: "bucket=dst"}
+	task := data.ReplicationTask{DataSelectionCriteria: "in/"}
+
+	tests := []struct {
+		name    string
+		connStr string
+		wantErr string
+	}{
+		{"literal secret key", "bucket=src;access_key_id=AKIA;secret_access_key=plaintext", "must be a secret reference"},
+		{"missing secret key", "bucket=src;access_key_id=AKIA", "must be provided together"},
+		{"literal session token", "bucket=src;access_key_id=AKIA;secret_access_key=env:S;session_token=tok", "'session_token' must be a secret reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBenthosConfig(task, data.Connection{Type: "s3", ConnectionString: tt.connStr}, target)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := GenerateBenthosConfig(task, data.Connection{Type: "s3", ConnectionString: "bucket=src"},
+		data.Connection{Type: "s3", ConnectionString: "bucket=dst;sse=rot13"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported S3 server-side encryption")
+}
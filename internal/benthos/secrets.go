@@ -0,0 +1,48 @@
+package benthos
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// secretRefPrefix marks a connection string value as a reference to an environment
+// variable rather than the secret itself, e.g. "secret_access_key=env:MINIO_SECRET_KEY".
+const secretRefPrefix = "env:"
+
+// isSecretRef reports whether a connection string value is a secret reference.
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, secretRefPrefix) && len(value) > len(secretRefPrefix)
+}
+
+// resolveSecretRef turns a secret reference into a Benthos environment interpolation so the
+// secret is only resolved by the pipeline process and never written into generated config.
+// Plain values are returned unchanged.
+func resolveSecretRef(value string) string {
+	if !isSecretRef(value) {
+		return value
+	}
+	return fmt.Sprintf("${%s}", strings.TrimPrefix(value, secretRefPrefix))
+}
+
+// requireSecretRef resolves a value that must not be stored in clear text.
+func requireSecretRef(key, value string) (string, error) {
+	if !isSecretRef(value) {
+		return "", fmt.Errorf("'%s' must be a secret reference (env:VAR_NAME), not a literal value", key)
+	}
+	return resolveSecretRef(value), nil
+}
+
+// LookupSecretRef returns the actual value behind a connection string value for code that
+// talks to a system directly instead of through a pipeline. Plain values are returned unchanged.
+func LookupSecretRef(value string) (string, error) {
+	if !isSecretRef(value) {
+		return value, nil
+	}
+	name := strings.TrimPrefix(value, secretRefPrefix)
+	secret, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("secret reference %s is not set in the environment", value)
+	}
+	return secret, nil
+}
@@ -0,0 +1,156 @@
+package benthos
+
+import (
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/sftpclient"
+)
+
+// SFTP source post-run actions, applied only after the whole run succeeded.
+const (
+	SFTPOnSuccessNone    = "none"
+	SFTPOnSuccessArchive = "archive"
+	SFTPOnSuccessDelete  = "delete"
+)
+
+// generateSFTPInput builds an sftp input reading the glob paths in DataSelectionCriteria.
+func generateSFTPInput(params map[string]string, task data.ReplicationTask) (map[string]interface{}, error) {
+	address, ok := params["address"]
+	if !ok {
+		return nil, fmt.Errorf("'address' not found in connection string for SFTP")
+	}
+	paths := SplitList(task.DataSelectionCriteria)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("DataSelectionCriteria (file globs) cannot be empty for SFTP input")
+	}
+	if _, err := SFTPSourceAction(params); err != nil {
+		return nil, err
+	}
+	credentials, err := sftpCredentials(params)
+	if err != nil {
+		return nil, err
+	}
+
+	sftpInput := map[string]interface{}{
+		"address":     address,
+		"credentials": credentials,
+		"paths":       paths,
+		// Files are archived or deleted by the workflow once the run succeeds
+		"delete_on_finish": false,
+	}
+	return applyInputFormat("sftp", sftpInput, params, "lines")
+}
+
+// generateSFTPOutput builds an sftp output writing files to an interpolated path template.
+func generateSFTPOutput(params map[string]string) (map[string]interface{}, error) {
+	address, ok := params["address"]
+	if !ok {
+		return nil, fmt.Errorf("'address' not found in connection string for SFTP output")
+	}
+	credentials, err := sftpCredentials(params)
+	if err != nil {
+		return nil, err
+	}
+	format, err := fileFormat(params)
+	if err != nil {
+		return nil, err
+	}
+	path, ok := params["path"]
+	if !ok {
+		pathPrefix, ok := params["path_prefix"]
+		if !ok {
+			pathPrefix = "output/" // Default prefix
+		}
+		path = pathPrefix + defaultObjectName(format)
+	}
+	codec, ok := params["codec"]
+	if !ok {
+		codec = "all-bytes" // One file per message unless the path template repeats
+	}
+
+	sftpOutput := map[string]interface{}{
+		"address":       address,
+		"credentials":   credentials,
+		"path":          path,
+		"codec":         codec,
+		"max_in_flight": 16,
+	}
+	batching, err := fileBatching(params)
+	if err != nil {
+		return nil, err
+	}
+	if batching != nil {
+		sftpOutput["batching"] = batching
+	}
+	return map[string]interface{}{"sftp": sftpOutput}, nil
+}
+
+// sftpCredentials maps password or key-file auth for the sftp components.
+func sftpCredentials(params map[string]string) (map[string]interface{}, error) {
+	username, ok := params["username"]
+	if !ok {
+		return nil, fmt.Errorf("'username' not found in connection string for SFTP")
+	}
+	credentials := map[string]interface{}{"username": username}
+
+	if keyFile, ok := params["private_key_file"]; ok {
+		credentials["private_key_file"] = keyFile
+		if pass, ok := params["private_key_pass"]; ok {
+			resolved, err := requireSecretRef("private_key_pass", pass)
+			if err != nil {
+				return nil, err
+			}
+			credentials["private_key_pass"] = resolved
+		}
+		return credentials, nil
+	}
+	if password, ok := params["password"]; ok {
+		resolved, err := requireSecretRef("password", password)
+		if err != nil {
+			return nil, err
+		}
+		credentials["password"] = resolved
+		return credentials, nil
+	}
+	return nil, fmt.Errorf("SFTP requires 'password' or 'private_key_file'")
+}
+
+// SFTPSourceAction returns the validated on_success action for an SFTP source.
+func SFTPSourceAction(params map[string]string) (string, error) {
+	action, ok := params["on_success"]
+	if !ok {
+		return SFTPOnSuccessNone, nil
+	}
+	switch action {
+	case SFTPOnSuccessNone, SFTPOnSuccessDelete:
+		return action, nil
+	case SFTPOnSuccessArchive:
+		if params["archive_dir"] == "" {
+			return "", fmt.Errorf("'archive_dir' is required when on_success is archive")
+		}
+		return action, nil
+	default:
+		return "", fmt.Errorf("unsupported SFTP on_success action: %s", action)
+	}
+}
+
+// SFTPClientConfig resolves connection string values, including secret references,
+// into a config for talking to the server directly.
+func SFTPClientConfig(params map[string]string) (sftpclient.Config, error) {
+	cfg := sftpclient.Config{
+		Address:             params["address"],
+		Username:            params["username"],
+		PrivateKeyFile:      params["private_key_file"],
+		KnownHostsFile:      params["known_hosts_file"],
+		InsecureSkipHostKey: parseBool(params["insecure_skip_host_key"], false),
+	}
+	var err error
+	if cfg.Password, err = LookupSecretRef(params["password"]); err != nil {
+		return cfg, err
+	}
+	if cfg.PrivateKeyPass, err = LookupSecretRef(params["private_key_pass"]); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
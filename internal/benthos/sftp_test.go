@@ -0,0 +1,80 @@
+package benthos
+
+import (
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfig_SFTPToSFTP(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{ID: 40, Type: "sftp", ConnectionString: "address=partner.example.com:22;username=ingest;password=env:PARTNER_SFTP_PASSWORD;on_success=archive;archive_dir=/outbox/archive"}
+	targetConn := data.Connection{ID: 41, Type: "sftp", ConnectionString: "address=localhost:2222;username=etl;private_key_file=/keys/id_ed25519;private_key_pass=env:ETL_KEY_PASS;path=exports/orders-${!count(\"files\")}.csv"}
+	task := data.ReplicationTask{ID: 501, DataSelectionCriteria: "/outbox/*.csv, /outbox/late/*.csv"}
+
+	// Act
+	configYAML, err := GenerateBenthosConfig(task, sourceConn, targetConn)
+
+	// Assert
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+
+	sftpIn := configData["input"].(map[string]interface{})["sftp"].(map[string]interface{})
+	assert.Equal(t, "partner.example.com:22", sftpIn["address"])
+	assert.Equal(t, []interface{}{"/outbox/*.csv", "/outbox/late/*.csv"}, sftpIn["paths"])
+	assert.Equal(t, false, sftpIn["delete_on_finish"], "Post-run actions are handled by the workflow")
+	inCreds := sftpIn["credentials"].(map[string]interface{})
+	assert.Equal(t, "ingest", inCreds["username"])
+	assert.Equal(t, "${PARTNER_SFTP_PASSWORD}", inCreds["password"])
+
+	sftpOut := configData["output"].(map[string]interface{})["sftp"].(map[string]interface{})
+	assert.Equal(t, `exports/orders-${!count("files")}.csv`, sftpOut["path"])
+	outCreds := sftpOut["credentials"].(map[string]interface{})
+	assert.Equal(t, "/keys/id_ed25519", outCreds["private_key_file"])
+	assert.Equal(t, "${ETL_KEY_PASS}", outCreds["private_key_pass"])
+	assert.NotContains(t, outCreds, "password")
+}
+
+func TestGenerateBenthosConfig_SFTPErrors(t *testing.T) {
+	target := data.Connection{Type: "sftp", ConnectionString: "address=localhost:2222;username=etl;password=env:P"}
+	task := data.ReplicationTask{DataSelectionCriteria: "/in/*.csv"}
+
+	tests := []struct {
+		name    string
+		connStr string
+		task    data.ReplicationTask
+		wantErr string
+	}{
+		{"missing address", "username=u;password=env:P", task, "'address' not found"},
+		{"missing globs", "address=h:22;username=u;password=env:P", data.ReplicationTask{}, "file globs"},
+		{"no auth", "address=h:22;username=u", task, "'password' or 'private_key_file'"},
+		{"literal password", "address=h:22;username=u;password=hunter2", task, "must be a secret reference"},
+		{"archive without dir", "address=h:22;username=u;password=env:P;on_success=archive", task, "'archive_dir' is required"},
+		{"unknown action", "address=h:22;username=u;password=env:P;on_success=shred", task, "unsupported SFTP on_success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateBenthosConfig(tt.task, data.Connection{Type: "sftp", ConnectionString: tt.connStr}, target)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSFTPClientConfig_ResolvesSecretRefs(t *testing.T) {
+	t.Setenv("TEST_SFTP_PASSWORD", "s3cret")
+
+	cfg, err := SFTPClientConfig(ParseConnectionString("address=h:22;username=u;password=env:TEST_SFTP_PASSWORD;insecure_skip_host_key=true"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.Password)
+	assert.True(t, cfg.InsecureSkipHostKey)
+
+	_, err = SFTPClientConfig(ParseConnectionString("address=h:22;username=u;password=env:TEST_SFTP_UNSET_VAR"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not set in the environment")
+}
@@ -0,0 +1,69 @@
+package benthos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// StateRoot is the directory pipelines use for local state handed between runs and the worker.
+// It defaults to the OS temp dir and can be overridden per environment with HSOETLNLM_STATE_DIR.
+func StateRoot() string {
+	if dir := os.Getenv("HSOETLNLM_STATE_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "hsoetlnlm-state")
+}
+
+// HTTPAPIStateDir is the file cache directory holding a task's API paging state.
+func HTTPAPIStateDir(taskID int64) string {
+	return filepath.Join(StateRoot(), fmt.Sprintf("task-%d", taskID), "http_api")
+}
+
+// httpAPIState is the paging state shared by consecutive page requests.
+type httpAPIState struct {
+	Next string `json:"next"`
+	Done bool   `json:"done"`
+	Last string `json:"last"`
+}
+
+// SeedHTTPAPIState writes the starting paging state for a run, resuming from the persisted
+// watermark (the last cursor, page or next link) when there is one.
+func SeedHTTPAPIState(taskID int64, sel *HTTPAPISelection, watermark string) error {
+	state := httpAPIState{Next: watermark, Last: watermark}
+	if state.Next == "" && sel.Pagination.Type == HTTPPaginationPage {
+		state.Next = strconv.Itoa(sel.Pagination.StartPage)
+	}
+
+	dir := HTTPAPIStateDir(taskID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("error creating http_api state dir: %w", err)
+	}
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "state"), stateBytes, 0o600); err != nil {
+		return fmt.Errorf("error seeding http_api state: %w", err)
+	}
+	return nil
+}
+
+// ReadHTTPAPIWatermark returns the position the next incremental pull should resume from.
+func ReadHTTPAPIWatermark(taskID int64) (string, error) {
+	stateBytes, err := os.ReadFile(filepath.Join(HTTPAPIStateDir(taskID), "state"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error reading http_api state: %w", err)
+	}
+	var state httpAPIState
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return "", fmt.Errorf("error decoding http_api state: %w", err)
+	}
+	return state.Last, nil
+}
@@ -0,0 +1,350 @@
+package benthos
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// SupportsStreaming reports whether pipelines reading from a source type can run continuously
+// and continue where they stopped when restarted: Kafka keeps offsets in the consumer group,
+// Postgres keeps the position in a logical replication slot.
+func SupportsStreaming(connType string) bool {
+	switch connType {
+	case "kafka", "postgres":
+		return true
+	}
+	return false
+}
+
+// generateStreamingInput creates the input of a streaming task's continuous pipeline.
+func generateStreamingInput(conn data.Connection, task data.ReplicationTask) (map[string]interface{}, error) {
+	params := ParseConnectionString(conn.ConnectionString)
+	switch conn.Type {
+	case "kafka":
+		return generateKafkaInput(params, task)
+	case "postgres":
+		return generatePostgresCDCInput(params, task)
+	}
+	return nil, fmt.Errorf("streaming is not supported for %s sources", conn.Type)
+}
+
+// generatePostgresCDCInput builds a postgres_cdc input streaming the changes of the tables in
+// the task's DataSelectionCriteria from a logical replication slot. The slot is kept between
+// pipelines, so a restarted pipeline continues from the last acknowledged change.
+func generatePostgresCDCInput(params map[string]string, task data.ReplicationTask) (map[string]interface{}, error) {
+	dsn, ok := params["dsn"]
+	if !ok {
+		return nil, fmt.Errorf("'dsn' not found in connection string for postgres")
+	}
+	tables := SplitList(task.DataSelectionCriteria)
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("DataSelectionCriteria (table list) cannot be empty for postgres CDC input")
+	}
+
+	schemaName := params["cdc_schema"]
+	if schemaName == "" {
+		schemaName = "public"
+	}
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if tableSchema, name, qualified := strings.Cut(table, "."); qualified {
+			if tableSchema != schemaName {
+				return nil, fmt.Errorf("postgres CDC tables must be in schema %q, not %q", schemaName, tableSchema)
+			}
+			table = name
+		}
+		names = append(names, table)
+	}
+
+	return map[string]interface{}{
+		"postgres_cdc": map[string]interface{}{
+			"dsn":             resolveSecretRef(dsn),
+			"schema":          schemaName,
+			"tables":          names,
+			"slot_name":       cdcSlot(params, task),
+			"stream_snapshot": parseBool(params["cdc_snapshot"], false),
+		},
+	}, nil
+}
+
+// PostgresCDCSlot returns the replication slot a streaming Postgres source's pipeline reads from.
+func PostgresCDCSlot(conn data.Connection, task data.ReplicationTask) string {
+	return cdcSlot(ParseConnectionString(conn.ConnectionString), task)
+}
+
+// cdcSlot is the replication slot of a task's Postgres CDC input.
+func cdcSlot(params map[string]string, task data.ReplicationTask) string {
+	if slot := params["cdc_slot"]; slot != "" {
+		return slot
+	}
+	return fmt.Sprintf("hsoetlnlm_task_%d", task.ID)
+}
+
+// LocalHTTPAddress returns a free loopback address for a pipeline's HTTP server, so pipelines
+// running side by side on a worker do not compete for the default port.
+func LocalHTTPAddress() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to find a free port for the pipeline: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String(), nil
+}
+
+// Pipeline is a Benthos pipeline running in the background, such as the continuous pipeline
+// of a streaming task.
+type Pipeline struct {
+	cmd    *exec.Cmd
+	output *syncBuffer
+	done   chan struct{}
+	err    error
+}
+
+// StartBenthosPipeline starts 'rpk connect run' with the provided YAML configuration and
+// returns without waiting for it to exit.
+func StartBenthosPipeline(configYAML string) (*Pipeline, error) {
+	rpkPath, err := exec.LookPath("rpk")
+	if err != nil {
+		return nil, fmt.Errorf("rpk command not found in PATH: %w", err)
+	}
+
+	cmd := exec.Command(rpkPath, "connect", "run", "-c", "-")
+	cmd.Stdin = bytes.NewBufferString(configYAML)
+	p := &Pipeline{cmd: cmd, output: &syncBuffer{}, done: make(chan struct{})}
+	cmd.Stdout = p.output
+	cmd.Stderr = p.output
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start rpk connect run: %w", err)
+	}
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// Done is closed once the pipeline has exited.
+func (p *Pipeline) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns why the pipeline exited, once Done is closed. A pipeline exiting on its own with
+// no error still ends a stream that was meant to keep running.
+func (p *Pipeline) Err() error {
+	if p.err != nil {
+		return fmt.Errorf("rpk connect run exited: %w\nOutput:\n%s", p.err, truncateString(p.Output(), 4000))
+	}
+	return fmt.Errorf("rpk connect run exited\nOutput:\n%s", truncateString(p.Output(), 4000))
+}
+
+// Output returns what the pipeline has printed so far.
+func (p *Pipeline) Output() string {
+	return p.output.String()
+}
+
+// Stop interrupts the pipeline so it can finish the messages in flight, and waits for it to
+// exit. A pipeline still running after timeout is killed.
+func (p *Pipeline) Stop(timeout time.Duration) error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	_ = p.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(timeout):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+		return fmt.Errorf("pipeline did not stop within %v and was killed", timeout)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for a running process to write while it is read.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Names of the gauges streaming Kafka pipelines keep per topic partition, see lagProcessors.
+const (
+	latencyMetric = "replication_latency_ms"
+	backlogMetric = "replication_backlog"
+)
+
+// PipelineMetrics are the message counters of a running pipeline since it started, and the
+// lag gauges of the partitions it has consumed from.
+type PipelineMetrics struct {
+	Received   int64                    `json:"received"`   // Messages read by the input
+	Sent       int64                    `json:"sent"`       // Messages delivered by the output
+	Partitions map[string]*PartitionLag `json:"partitions"` // Keyed by topic/partition
+}
+
+// PartitionLag is the lag of the last message a pipeline consumed from a Kafka partition. A
+// gauge the pipeline has not set yet is nil.
+type PartitionLag struct {
+	LatencyMs *int64 `json:"latency_ms,omitempty"` // From the message's timestamp to its hand-off to the output
+	Backlog   *int64 `json:"backlog,omitempty"`    // Messages behind the partition's high watermark
+}
+
+// lagProcessors returns the processors that keep the lag gauges of a streaming pipeline
+// reading from a source type, if the source carries what they need. Kafka messages carry their
+// timestamp and their partition's lag. The processors run last, just before the output.
+func lagProcessors(connType string) []interface{} {
+	if connType != "kafka" {
+		return nil
+	}
+	labels := map[string]interface{}{
+		"topic":     `${! meta("kafka_topic") }`,
+		"partition": `${! meta("kafka_partition") }`,
+	}
+	return []interface{}{
+		map[string]interface{}{"metric": map[string]interface{}{
+			"type":   "gauge",
+			"name":   latencyMetric,
+			"labels": labels,
+			"value":  `${! timestamp_unix_milli() - meta("kafka_timestamp_ms").number() }`,
+		}},
+		map[string]interface{}{"metric": map[string]interface{}{
+			"type":   "gauge",
+			"name":   backlogMetric,
+			"labels": labels,
+			"value":  `${! meta("kafka_lag") }`,
+		}},
+	}
+}
+
+// ReadPipelineMetrics reads the input_received and output_sent counters and the lag gauges from
+// the Prometheus metrics of a pipeline's HTTP server at address.
+func ReadPipelineMetrics(ctx context.Context, address string) (*PipelineMetrics, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/metrics", address), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build metrics request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pipeline metrics returned status %d", resp.StatusCode)
+	}
+	return parsePipelineMetrics(resp.Body)
+}
+
+// parsePipelineMetrics sums the samples of the input_received and output_sent counters, and
+// reads the lag gauges by topic partition, in the Prometheus text format.
+func parsePipelineMetrics(r io.Reader) (*PipelineMetrics, error) {
+	metrics := &PipelineMetrics{Partitions: make(map[string]*PartitionLag)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 {
+			name = line[:i]
+		}
+		rest := line[len(name):]
+		name = strings.TrimSuffix(name, "_total")
+		switch name {
+		case "input_received", "output_sent", latencyMetric, backlogMetric:
+		default:
+			continue
+		}
+
+		labels := ""
+		if strings.HasPrefix(rest, "{") {
+			end := strings.LastIndex(rest, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("malformed metric line %q", line)
+			}
+			labels, rest = rest[1:end], rest[end+1:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("malformed metric line %q", line)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed value in metric line %q: %w", line, err)
+		}
+
+		switch name {
+		case "input_received":
+			metrics.Received += int64(value)
+		case "output_sent":
+			metrics.Sent += int64(value)
+		default:
+			parsed := parseLabels(labels)
+			key := parsed["topic"] + "/" + parsed["partition"]
+			partition, ok := metrics.Partitions[key]
+			if !ok {
+				partition = &PartitionLag{}
+				metrics.Partitions[key] = partition
+			}
+			gauge := int64(value)
+			if name == latencyMetric {
+				partition.LatencyMs = &gauge
+			} else {
+				partition.Backlog = &gauge
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pipeline metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+// parseLabels parses the label pairs of a Prometheus sample, e.g. topic="a",partition="0".
+func parseLabels(raw string) map[string]string {
+	labels := make(map[string]string)
+	for raw != "" {
+		name, rest, ok := strings.Cut(raw, "=")
+		if !ok || !strings.HasPrefix(rest, `"`) {
+			break
+		}
+		var value strings.Builder
+		i := 1
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+			}
+			value.WriteByte(rest[i])
+		}
+		labels[strings.TrimSpace(name)] = value.String()
+		if i >= len(rest) {
+			break
+		}
+		raw = strings.TrimPrefix(strings.TrimSpace(rest[i+1:]), ",")
+	}
+	return labels
+}
@@ -0,0 +1,144 @@
+package benthos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func generateStreamingConfigMap(t *testing.T, task data.ReplicationTask, source, target data.Connection) map[string]interface{} {
+	t.Helper()
+	configYAML, err := GenerateBenthosConfigForRun(task, source, target, RunContext{RunID: 7, Streaming: true, HTTPAddress: "127.0.0.1:40195"})
+	require.NoError(t, err)
+	var configData map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData), "Generated YAML should be valid")
+	return configData
+}
+
+func TestGenerateBenthosConfigForRun_PostgresCDC(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{Type: "postgres", ConnectionString: "dsn=env:ERP_DSN;cdc_schema=sales;cdc_snapshot=true"}
+	targetConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;topic=cdc.orders"}
+	task := data.ReplicationTask{ID: 31, DataSelectionCriteria: "sales.orders, order_lines"}
+
+	// Act
+	configData := generateStreamingConfigMap(t, task, sourceConn, targetConn)
+
+	// Assert
+	cdc := configData["input"].(map[string]interface{})["postgres_cdc"].(map[string]interface{})
+	assert.Equal(t, "${ERP_DSN}", cdc["dsn"], "Secrets stay out of the generated config")
+	assert.Equal(t, "sales", cdc["schema"])
+	assert.Equal(t, []interface{}{"orders", "order_lines"}, cdc["tables"])
+	assert.Equal(t, "hsoetlnlm_task_31", cdc["slot_name"])
+	assert.Equal(t, true, cdc["stream_snapshot"])
+	assert.Equal(t, "127.0.0.1:40195", configData["http"].(map[string]interface{})["address"])
+}
+
+func TestGenerateBenthosConfigForRun_StreamingKafka(t *testing.T) {
+	sourceConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092"}
+	targetConn := data.Connection{Type: "s3", ConnectionString: "bucket=lake"}
+	task := data.ReplicationTask{ID: 32, DataSelectionCriteria: "cdc.orders"}
+
+	configData := generateStreamingConfigMap(t, task, sourceConn, targetConn)
+	kafka := configData["input"].(map[string]interface{})["kafka_franz"].(map[string]interface{})
+	assert.Equal(t, "hsoetlnlm-task-32", kafka["consumer_group"])
+}
+
+func TestGenerateBenthosConfigForRun_StreamingErrors(t *testing.T) {
+	run := RunContext{Streaming: true}
+	kafka := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092;topic=out"}
+
+	_, err := GenerateBenthosConfigForRun(data.ReplicationTask{DataSelectionCriteria: "SELECT 1"}, data.Connection{Type: "mysql", ConnectionString: "dsn=u:p@tcp(h)/db"}, kafka, run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "streaming is not supported for mysql sources")
+
+	_, err = GenerateBenthosConfigForRun(data.ReplicationTask{DataSelectionCriteria: "other.orders"}, data.Connection{Type: "postgres", ConnectionString: "dsn=postgres://h/db"}, kafka, run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `must be in schema "public"`)
+
+	_, err = GenerateBenthosConfigForRun(data.ReplicationTask{DataSelectionCriteria: "topic"}, kafka, data.Connection{Type: "localfile", ConnectionString: "directory=/tmp/out"}, run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "localfile targets cannot be used by streaming tasks")
+
+	assert.True(t, SupportsStreaming("postgres"))
+	assert.False(t, SupportsStreaming("sqlserver"))
+}
+
+func TestGenerateBenthosConfigForRun_StreamingLagProcessors(t *testing.T) {
+	sourceConn := data.Connection{Type: "kafka", ConnectionString: "brokers=localhost:19092"}
+	targetConn := data.Connection{Type: "s3", ConnectionString: "bucket=lake"}
+	task := data.ReplicationTask{ID: 33, DataSelectionCriteria: "cdc.orders", TransformationRules: "root = this"}
+
+	configData := generateStreamingConfigMap(t, task, sourceConn, targetConn)
+	processors := configData["pipeline"].(map[string]interface{})["processors"].([]interface{})
+	require.Len(t, processors, 3)
+	assert.Contains(t, processors[0], "bloblang", "Lag gauges are set after the task's transformations")
+	for i, name := range []string{"replication_latency_ms", "replication_backlog"} {
+		metric := processors[i+1].(map[string]interface{})["metric"].(map[string]interface{})
+		assert.Equal(t, "gauge", metric["type"])
+		assert.Equal(t, name, metric["name"])
+	}
+
+	// Postgres lag is read from the replication slot instead
+	postgres := data.Connection{Type: "postgres", ConnectionString: "dsn=postgres://h/db"}
+	configData = generateStreamingConfigMap(t, data.ReplicationTask{ID: 34, DataSelectionCriteria: "orders"}, postgres, targetConn)
+	assert.Empty(t, configData["pipeline"].(map[string]interface{})["processors"])
+}
+
+func TestPostgresCDCSlot(t *testing.T) {
+	task := data.ReplicationTask{ID: 35}
+	assert.Equal(t, "hsoetlnlm_task_35", PostgresCDCSlot(data.Connection{Type: "postgres", ConnectionString: "dsn=env:ERP_DSN"}, task))
+	assert.Equal(t, "erp", PostgresCDCSlot(data.Connection{Type: "postgres", ConnectionString: "dsn=postgres://h/db;cdc_slot=erp"}, task))
+}
+
+func TestParsePipelineMetrics(t *testing.T) {
+	metrics := `# HELP input_received Benthos Counter metric
+# TYPE input_received counter
+input_received{label="",path="root.input"} 120
+input_received{label="orders",path="root.input.broker.inputs.0"} 3
+output_sent{label="",path="root.output"} 1.18e+02
+output_error{label="",path="root.output"} 2
+input_received_bytes 4096
+replication_latency_ms{label="",partition="0",path="root.pipeline.processors.1",topic="cdc.orders"} 850
+replication_backlog{label="",partition="0",path="root.pipeline.processors.2",topic="cdc.orders"} 12
+replication_backlog{label="",partition="1",path="root.pipeline.processors.2",topic="cdc.orders"} 0
+`
+	parsed, err := parsePipelineMetrics(strings.NewReader(metrics))
+	require.NoError(t, err)
+	assert.Equal(t, int64(123), parsed.Received)
+	assert.Equal(t, int64(118), parsed.Sent)
+	require.Len(t, parsed.Partitions, 2)
+	assert.Equal(t, int64(850), *parsed.Partitions["cdc.orders/0"].LatencyMs)
+	assert.Equal(t, int64(12), *parsed.Partitions["cdc.orders/0"].Backlog)
+	assert.Nil(t, parsed.Partitions["cdc.orders/1"].LatencyMs)
+	assert.Equal(t, int64(0), *parsed.Partitions["cdc.orders/1"].Backlog)
+
+	_, err = parsePipelineMetrics(strings.NewReader("output_sent{path=\"root.output\"} many\n"))
+	assert.Error(t, err)
+}
+
+func TestParseLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{"topic": `a"b`, "partition": "3"}, parseLabels(`topic="a\"b", partition="3"`))
+	assert.Empty(t, parseLabels(""))
+}
+
+func TestReadPipelineMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/metrics", r.URL.Path)
+		fmt.Fprintln(w, "input_received_total 5")
+		fmt.Fprintln(w, "output_sent_total 4")
+	}))
+	defer server.Close()
+
+	parsed, err := ReadPipelineMetrics(context.Background(), strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	assert.Equal(t, &PipelineMetrics{Received: 5, Sent: 4, Partitions: map[string]*PartitionLag{}}, parsed)
+}
@@ -0,0 +1,73 @@
+package benthos
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Unquoted identifiers are folded to the target's case; anything else is quoted.
+var simpleIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// applyTargetTable points a target at the table a multi-table run is loading. Snowflake and
+// Postgres write to the table itself; file targets write under a per-table prefix, or
+// substitute {table} in their path template; kafka substitutes {table} in the topic.
+func applyTargetTable(connType string, params map[string]string, table string) {
+	switch connType {
+	case "snowflake", "postgres":
+		params["table"] = table
+	case "s3", "azure_blob", "sftp":
+		// S3 object names always come from path_prefix; the others may use a path template
+		if path, ok := params["path"]; ok && connType != "s3" {
+			params["path"] = strings.ReplaceAll(path, "{table}", table)
+			return
+		}
+		pathPrefix, ok := params["path_prefix"]
+		if !ok {
+			pathPrefix = "output/" // Default prefix
+		}
+		params["path_prefix"] = pathPrefix + table + "/"
+	case "localfile":
+		path, ok := params["path"]
+		if !ok {
+			path = strings.Replace(defaultLocalFilePath, "{task}", "{task}/{table}", 1)
+		}
+		params["path"] = strings.ReplaceAll(path, "{table}", table)
+	case "kafka":
+		if topic, ok := params["topic"]; ok {
+			params["topic"] = strings.ReplaceAll(topic, "{table}", table)
+		}
+	}
+}
+
+// Identifier folds a name to the target's default case (upper for Snowflake, lower for
+// Postgres) so it can be used unquoted, and quotes names that are not plain identifiers.
+func Identifier(targetType, name string) string {
+	if !simpleIdentifier.MatchString(name) {
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+	if targetType == "snowflake" {
+		return strings.ToUpper(name)
+	}
+	return strings.ToLower(name)
+}
+
+// QualifiedName prefixes the table with the connection's database and schema (Snowflake) or
+// schema (Postgres, default public).
+func QualifiedName(targetType string, params map[string]string, table string) string {
+	parts := []string{}
+	switch targetType {
+	case "snowflake":
+		for _, key := range []string{"database", "schema"} {
+			if params[key] != "" {
+				parts = append(parts, Identifier(targetType, params[key]))
+			}
+		}
+	case "postgres":
+		schemaName := params["schema"]
+		if schemaName == "" {
+			schemaName = "public"
+		}
+		parts = append(parts, Identifier(targetType, schemaName))
+	}
+	return strings.Join(append(parts, Identifier(targetType, table)), ".")
+}
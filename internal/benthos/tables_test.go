@@ -0,0 +1,53 @@
+package benthos
+
+import (
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateBenthosConfigForRun_TargetTable(t *testing.T) {
+	// Arrange
+	sourceConn := data.Connection{Type: "sqlserver", ConnectionString: "dsn=sqlserver://u:p@host/db"}
+	task := data.ReplicationTask{ID: 701, DataSelectionCriteria: "SELECT * FROM [dbo].[Orders]"}
+	run := RunContext{RunID: 9, TargetTable: "ORDERS"}
+
+	tests := []struct {
+		name      string
+		target    data.Connection
+		component string
+		field     string
+		want      string
+	}{
+		{"snowflake table", data.Connection{Type: "snowflake", ConnectionString: "account=a;user=u;table=IGNORED"}, "snowflake_put", "table", "ORDERS"},
+		{"s3 prefix", data.Connection{Type: "s3", ConnectionString: "bucket=lake;path_prefix=raw/"}, "aws_s3", "path", `raw/ORDERS/${!count("files")}-${!timestamp_unix_nano()}.json`},
+		{"sftp template", data.Connection{Type: "sftp", ConnectionString: "address=a;username=u;password=env:P;path=/in/{table}/${!count(\"f\")}.json"}, "sftp", "path", `/in/ORDERS/${!count("f")}.json`},
+		{"localfile path template", data.Connection{Type: "localfile", ConnectionString: "directory=/data;path={task}/{table}.{ext}"}, "file", "path", "/data/.staging/task-701-run-9/701/ORDERS.json"},
+		{"kafka topic", data.Connection{Type: "kafka", ConnectionString: "brokers=b:9092;topic=cdc.{table}"}, "kafka_franz", "topic", "cdc.ORDERS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			configYAML, err := GenerateBenthosConfigForRun(task, sourceConn, tt.target, run)
+
+			// Assert
+			require.NoError(t, err)
+			var configData map[string]interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(configYAML), &configData))
+			output := configData["output"].(map[string]interface{})[tt.component].(map[string]interface{})
+			assert.Equal(t, tt.want, output[tt.field])
+		})
+	}
+}
+
+func TestApplyTargetTable_LocalFileDefaultPath(t *testing.T) {
+	params := map[string]string{"directory": "/data"}
+
+	applyTargetTable("localfile", params, "orders")
+
+	assert.Equal(t, "{task}/orders/{yyyy}/{mm}/{dd}/{run_id}-{seq}.{ext}", params["path"], "Tables get their own directory")
+}
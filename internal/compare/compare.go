@@ -0,0 +1,278 @@
+// Package compare reconciles a task's source and target by row counts and hash aggregates
+// over key ranges, optionally drilling down to row-level differences.
+package compare
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsupported is returned for tasks whose source or target cannot be compared.
+	ErrUnsupported = errors.New("compare is not supported")
+	// ErrInvalidOptions is returned for malformed compare options.
+	ErrInvalidOptions = errors.New("invalid compare options")
+)
+
+const (
+	defaultChunkSize   = 100000
+	defaultMaxRowDiffs = 100
+	defaultMaxParallel = 2
+	maxRanges          = 1000000
+)
+
+// Options control a compare run. They are stored on the compare report.
+type Options struct {
+	Tables      []string `json:"tables,omitempty"`        // schema.table names of a multi-table task; default all
+	KeyColumns  []string `json:"key_columns,omitempty"`   // Default: the source table's primary key
+	ChunkSize   int64    `json:"chunk_size,omitempty"`    // Key values per range for integer keys
+	RowDiffs    bool     `json:"row_diffs,omitempty"`     // Drill down into mismatched ranges
+	MaxRowDiffs int      `json:"max_row_diffs,omitempty"` // Row diffs kept per table
+	MaxParallel int      `json:"max_parallel,omitempty"`  // Tables compared at the same time
+}
+
+// ParseOptions parses compare options JSON (empty means all defaults) and fills in defaults.
+func ParseOptions(raw string) (*Options, error) {
+	opts := &Options{}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), opts); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+		}
+	}
+	if opts.ChunkSize < 0 || opts.MaxRowDiffs < 0 || opts.MaxParallel < 0 {
+		return nil, fmt.Errorf("%w: chunk_size, max_row_diffs and max_parallel must not be negative", ErrInvalidOptions)
+	}
+	for _, key := range opts.KeyColumns {
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%w: empty key column", ErrInvalidOptions)
+		}
+	}
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = defaultChunkSize
+	}
+	if opts.MaxRowDiffs == 0 {
+		opts.MaxRowDiffs = defaultMaxRowDiffs
+	}
+	if opts.MaxParallel == 0 {
+		opts.MaxParallel = defaultMaxParallel
+	}
+	return opts, nil
+}
+
+// Selects reports whether a table is compared: it is listed in Tables, or Tables is empty.
+func (o *Options) Selects(table string) bool {
+	return len(o.Tables) == 0 || containsFold(o.Tables, table)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Table is one source table (or a single-table task's query) and its target table.
+type Table struct {
+	Name   string `json:"name"` // schema.table, or "query" for query-based tasks
+	Schema string `json:"schema,omitempty"`
+	Table  string `json:"table,omitempty"`
+	Query  string `json:"query,omitempty"`
+	Target string `json:"target"` // Unqualified target table name
+}
+
+// KeyRange is the inclusive range [Low, High] of an integer key.
+type KeyRange struct {
+	Low  int64 `json:"low"`
+	High int64 `json:"high"`
+}
+
+// Ranges splits [min, max] into ranges of size key values. A range count above the limit
+// is an error, so a tiny chunk size cannot schedule millions of queries.
+func Ranges(min, max, size int64) ([]*KeyRange, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	span := new(big.Int).Sub(big.NewInt(max), big.NewInt(min))
+	count := new(big.Int).Add(new(big.Int).Quo(span, big.NewInt(size)), big.NewInt(1))
+	if count.Cmp(big.NewInt(maxRanges)) > 0 {
+		return nil, fmt.Errorf("key range %d..%d needs %s ranges of %d; use a larger chunk_size", min, max, count, size)
+	}
+	ranges := make([]*KeyRange, 0, count.Int64())
+	for low := min; ; low += size {
+		high := low + size - 1
+		if high < low || high >= max { // Overflow or the last range
+			return append(ranges, &KeyRange{Low: low, High: max}), nil
+		}
+		ranges = append(ranges, &KeyRange{Low: low, High: high})
+	}
+}
+
+// Row diff kinds.
+const (
+	RowMissing = "missing" // In the source but not the target
+	RowExtra   = "extra"   // In the target but not the source
+	RowChanged = "changed" // In both with different values
+)
+
+// RowDiff is a row that differs between source and target.
+type RowDiff struct {
+	Key  string `json:"key"` // Key values joined with "|"
+	Kind string `json:"kind"`
+}
+
+// RangeResult is the comparison of one key range (or the whole table when Range is nil).
+type RangeResult struct {
+	Range      *KeyRange `json:"range,omitempty"`
+	SourceRows int64     `json:"source_rows"`
+	TargetRows int64     `json:"target_rows"`
+	SourceHash string    `json:"source_hash"`
+	TargetHash string    `json:"target_hash"`
+	RowDiffs   []RowDiff `json:"row_diffs,omitempty"`
+}
+
+// Match reports whether both sides have the same rows.
+func (r *RangeResult) Match() bool {
+	return r.SourceRows == r.TargetRows && r.SourceHash == r.TargetHash
+}
+
+// TableResult is the comparison of one table, keeping only the mismatched ranges.
+type TableResult struct {
+	Table            string        `json:"table"`
+	Target           string        `json:"target"`
+	KeyColumns       []string      `json:"key_columns,omitempty"`
+	SourceRows       int64         `json:"source_rows"`
+	TargetRows       int64         `json:"target_rows"`
+	Ranges           int           `json:"ranges"`
+	MismatchedRanges []RangeResult `json:"mismatched_ranges,omitempty"`
+	RowDiffs         int           `json:"row_diffs,omitempty"` // Row diffs kept across the mismatched ranges
+	Error            string        `json:"error,omitempty"`
+}
+
+// Add accumulates a range's result, keeping at most maxRowDiffs row diffs for the table.
+func (t *TableResult) Add(result *RangeResult, maxRowDiffs int) {
+	t.Ranges++
+	t.SourceRows += result.SourceRows
+	t.TargetRows += result.TargetRows
+	if result.Match() {
+		return
+	}
+	kept := *result
+	if remaining := maxRowDiffs - t.RowDiffs; len(kept.RowDiffs) > remaining {
+		kept.RowDiffs = kept.RowDiffs[:max(remaining, 0)]
+	}
+	t.RowDiffs += len(kept.RowDiffs)
+	t.MismatchedRanges = append(t.MismatchedRanges, kept)
+}
+
+// InSync reports whether the table compared without errors or mismatches.
+func (t *TableResult) InSync() bool {
+	return t.Error == "" && len(t.MismatchedRanges) == 0
+}
+
+// chunk accumulates the row count and order-independent hash of a set of rows.
+type chunk struct {
+	rows int64
+	sum  uint64
+	keys map[string]uint64 // Row hashes by key; only kept for row diffs
+}
+
+func (c *chunk) add(key string, rowHash uint64) {
+	c.rows++
+	c.sum += rowHash // Wrapping addition keeps the aggregate independent of row order
+	if c.keys != nil {
+		c.keys[key] = rowHash
+	}
+}
+
+func (c *chunk) hash() string {
+	return fmt.Sprintf("%016x", c.sum)
+}
+
+// diffRows lists the keys whose rows differ between two chunks, sorted by key.
+func diffRows(source, target map[string]uint64) []RowDiff {
+	diffs := []RowDiff{}
+	for key, sourceHash := range source {
+		targetHash, ok := target[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, RowDiff{Key: key, Kind: RowMissing})
+		case targetHash != sourceHash:
+			diffs = append(diffs, RowDiff{Key: key, Kind: RowChanged})
+		}
+	}
+	for key := range target {
+		if _, ok := source[key]; !ok {
+			diffs = append(diffs, RowDiff{Key: key, Kind: RowExtra})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
+
+var (
+	numeric = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+	uuid    = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+)
+
+// normalize renders a scanned value the same way whichever database and driver it came
+// from: numbers in lowest terms, times in UTC, UUIDs in lower case, CHAR padding trimmed.
+func normalize(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "\x00"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case float64:
+		return normalizeString(strconv.FormatFloat(v, 'f', -1, 64))
+	case float32:
+		return normalizeString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return normalizeString(string(v))
+	case string:
+		return normalizeString(v)
+	default:
+		return normalizeString(fmt.Sprint(v))
+	}
+}
+
+func normalizeString(s string) string {
+	if numeric.MatchString(s) {
+		if rat, ok := new(big.Rat).SetString(s); ok {
+			return rat.RatString()
+		}
+	}
+	if uuid.MatchString(s) {
+		return strings.ToLower(s)
+	}
+	return strings.TrimRight(s, " ")
+}
+
+// rowHash hashes a row's normalized values.
+func rowHash(values []string) uint64 {
+	h := sha256.New()
+	for _, value := range values {
+		h.Write([]byte(value))
+		h.Write([]byte{0x1f})
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil)[:8])
+}
@@ -0,0 +1,111 @@
+package compare
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRanges(t *testing.T) {
+	ranges, err := Ranges(1, 250, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []*KeyRange{{1, 100}, {101, 200}, {201, 250}}, ranges)
+
+	ranges, err = Ranges(7, 7, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []*KeyRange{{7, 7}}, ranges)
+
+	ranges, err = Ranges(math.MaxInt64-5, math.MaxInt64, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []*KeyRange{{math.MaxInt64 - 5, math.MaxInt64 - 2}, {math.MaxInt64 - 1, math.MaxInt64}}, ranges)
+
+	_, err = Ranges(math.MinInt64, math.MaxInt64, 1000)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "use a larger chunk_size")
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := ParseOptions("")
+	require.NoError(t, err)
+	assert.Equal(t, &Options{ChunkSize: 100000, MaxRowDiffs: 100, MaxParallel: 2}, opts)
+
+	opts, err = ParseOptions(`{"tables": ["dbo.Orders"], "key_columns": ["OrderID"], "chunk_size": 5000, "row_diffs": true}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dbo.Orders"}, opts.Tables)
+	assert.Equal(t, int64(5000), opts.ChunkSize)
+	assert.True(t, opts.RowDiffs)
+
+	for _, raw := range []string{`{"chunk_size": -1}`, `{"key_columns": [" "]}`, `[1]`} {
+		_, err := ParseOptions(raw)
+		assert.ErrorIs(t, err, ErrInvalidOptions, raw)
+	}
+}
+
+func TestNormalize_MatchesAcrossDrivers(t *testing.T) {
+	tests := []struct {
+		name           string
+		source, target interface{}
+	}{
+		{"decimal vs float", []byte("12.50"), 12.5},
+		{"integer vs numeric", int64(42), "42.000"},
+		{"char padding", "AB  ", "AB"},
+		{"uuid case", "6F9619FF-8B86-D011-B42D-00C04FC964FF", []byte("6f9619ff-8b86-d011-b42d-00c04fc964ff")},
+		{"time zone", time.Date(2026, 1, 2, 12, 0, 0, 0, time.FixedZone("CET", 3600)), time.Date(2026, 1, 2, 11, 0, 0, 0, time.UTC)},
+		{"bool vs bit", true, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, normalize(tt.source), normalize(tt.target))
+		})
+	}
+
+	assert.NotEqual(t, normalize(nil), normalize(""))
+	assert.NotEqual(t, rowHash([]string{"a", "bc"}), rowHash([]string{"ab", "c"}))
+}
+
+func TestChunk_OrderIndependentHash(t *testing.T) {
+	first, second := &chunk{}, &chunk{}
+	rows := [][]string{{"1", "a"}, {"2", "b"}, {"3", "c"}}
+	for i := range rows {
+		first.add(rows[i][0], rowHash(rows[i]))
+		second.add(rows[len(rows)-1-i][0], rowHash(rows[len(rows)-1-i]))
+	}
+	assert.Equal(t, first.hash(), second.hash())
+	assert.Equal(t, int64(3), second.rows)
+}
+
+func TestDiffRows(t *testing.T) {
+	source := map[string]uint64{"1": 10, "2": 20, "3": 30}
+	target := map[string]uint64{"1": 10, "3": 31, "4": 40}
+
+	assert.Equal(t, []RowDiff{
+		{Key: "2", Kind: RowMissing},
+		{Key: "3", Kind: RowChanged},
+		{Key: "4", Kind: RowExtra},
+	}, diffRows(source, target))
+}
+
+func TestTableResult_Add(t *testing.T) {
+	// Arrange
+	result := &TableResult{Table: "dbo.Orders"}
+	diffs := []RowDiff{{"1", RowMissing}, {"2", RowMissing}, {"3", RowChanged}}
+
+	// Act
+	result.Add(&RangeResult{Range: &KeyRange{1, 10}, SourceRows: 10, TargetRows: 10, SourceHash: "a", TargetHash: "a"}, 4)
+	result.Add(&RangeResult{Range: &KeyRange{11, 20}, SourceRows: 10, TargetRows: 8, SourceHash: "b", TargetHash: "c", RowDiffs: diffs}, 4)
+	result.Add(&RangeResult{Range: &KeyRange{21, 30}, SourceRows: 10, TargetRows: 7, SourceHash: "d", TargetHash: "e", RowDiffs: diffs}, 4)
+
+	// Assert
+	assert.False(t, result.InSync())
+	assert.Equal(t, 3, result.Ranges)
+	assert.Equal(t, int64(30), result.SourceRows)
+	assert.Equal(t, int64(25), result.TargetRows)
+	require.Len(t, result.MismatchedRanges, 2)
+	assert.Len(t, result.MismatchedRanges[0].RowDiffs, 3)
+	assert.Len(t, result.MismatchedRanges[1].RowDiffs, 1)
+	assert.Equal(t, 4, result.RowDiffs)
+}
@@ -0,0 +1,233 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNothingToRefresh is returned for refreshes of compare reports without mismatched ranges.
+var ErrNothingToRefresh = errors.New("nothing to refresh")
+
+// Refresh modes.
+const (
+	RefreshDeleteInsert = "delete_insert" // Replace the target rows of each range with the source rows
+	RefreshUpsert       = "upsert"        // Insert or update by key; target-only rows are kept
+)
+
+const defaultRefreshBatchSize = 500
+
+// RefreshOptions control a refresh of a compare report's mismatched ranges. They are stored
+// on the refresh.
+type RefreshOptions struct {
+	Mode       string   `json:"mode,omitempty"`        // Default delete_insert
+	Tables     []string `json:"tables,omitempty"`      // Default: every table with mismatches
+	FullTables bool     `json:"full_tables,omitempty"` // Also refresh tables compared as one range
+	BatchSize  int      `json:"batch_size,omitempty"`  // Rows per INSERT or MERGE
+}
+
+// ParseRefreshOptions parses refresh options JSON (empty means all defaults) and fills in defaults.
+func ParseRefreshOptions(raw string) (*RefreshOptions, error) {
+	opts := &RefreshOptions{}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), opts); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+		}
+	}
+	switch opts.Mode {
+	case "":
+		opts.Mode = RefreshDeleteInsert
+	case RefreshDeleteInsert, RefreshUpsert:
+	default:
+		return nil, fmt.Errorf("%w: mode must be %s or %s", ErrInvalidOptions, RefreshDeleteInsert, RefreshUpsert)
+	}
+	if opts.BatchSize < 0 {
+		return nil, fmt.Errorf("%w: batch_size must not be negative", ErrInvalidOptions)
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultRefreshBatchSize
+	}
+	return opts, nil
+}
+
+// Selects reports whether a table is refreshed: it is listed in Tables, or Tables is empty.
+func (o *RefreshOptions) Selects(table string) bool {
+	return len(o.Tables) == 0 || containsFold(o.Tables, table)
+}
+
+// RefreshTable is a table's mismatched ranges to refresh, keyed the way the compare keyed them.
+type RefreshTable struct {
+	Table      Table       `json:"table"`
+	KeyColumns []string    `json:"key_columns"`
+	Ranges     []*KeyRange `json:"ranges"` // A nil range is the whole table
+}
+
+// PlanRefresh picks the ranges to refresh from a compare report's results. Tables compared
+// as one range are only refreshed with FullTables, and are listed in skipped otherwise.
+func PlanRefresh(results []TableResult, tables []Table, opts *RefreshOptions) (plan []RefreshTable, skipped []string) {
+	for _, result := range results {
+		if len(result.MismatchedRanges) == 0 || !opts.Selects(result.Table) {
+			continue
+		}
+		refresh := RefreshTable{KeyColumns: result.KeyColumns}
+		found := false
+		for _, table := range tables {
+			if strings.EqualFold(table.Name, result.Table) {
+				refresh.Table, found = table, true
+			}
+		}
+		if !found {
+			skipped = append(skipped, result.Table) // No longer part of the task
+			continue
+		}
+		for _, mismatch := range result.MismatchedRanges {
+			refresh.Ranges = append(refresh.Ranges, mismatch.Range)
+		}
+		if refresh.Ranges[0] == nil && !opts.FullTables {
+			skipped = append(skipped, result.Table)
+			continue
+		}
+		plan = append(plan, refresh)
+	}
+	return plan, skipped
+}
+
+// RefreshResult counts the target rows a refresh deleted and wrote.
+type RefreshResult struct {
+	RowsDeleted int64 `json:"rows_deleted"`
+	RowsWritten int64 `json:"rows_written"`
+}
+
+// RefreshRange re-reads a key range (nil for the whole table) from the source and writes it
+// to the target in one transaction, so a failed range leaves the target unchanged.
+func (s *Session) RefreshRange(ctx context.Context, keyRange *KeyRange, mode string, batchSize int) (*RefreshResult, error) {
+	if mode == RefreshUpsert && len(s.keyColumns) == len(s.target.columns) {
+		mode = RefreshDeleteInsert // Nothing to update; every column is part of the key
+	}
+	batchSize = min(batchSize, 65535/len(s.target.columns)) // Bind parameter limit per statement
+
+	tx, err := s.target.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &RefreshResult{}
+	if mode == RefreshDeleteInsert {
+		deleted, err := tx.ExecContext(ctx, "DELETE FROM "+s.target.from+s.target.where(keyRange))
+		if err != nil {
+			return nil, fmt.Errorf("error deleting target rows: %w", err)
+		}
+		if result.RowsDeleted, err = deleted.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.source.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(s.source.columns, ", "), s.source.from, s.source.where(keyRange)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading source rows: %w", err)
+	}
+	defer rows.Close()
+
+	batch := make([]interface{}, 0, batchSize*len(s.target.columns))
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		count := len(batch) / len(s.target.columns)
+		if _, err := tx.ExecContext(ctx, s.writeStatement(mode, count), batch...); err != nil {
+			return fmt.Errorf("error writing target rows: %w", err)
+		}
+		result.RowsWritten += int64(count)
+		batch = batch[:0]
+		return nil
+	}
+
+	values := make([]interface{}, len(s.source.columns))
+	pointers := make([]interface{}, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("error reading source rows: %w", err)
+		}
+		for i, value := range values {
+			if b, ok := value.([]byte); ok && !s.source.binary[i] {
+				value = string(b) // Drivers return decimals and text as bytes; bind them as text
+			}
+			batch = append(batch, value)
+		}
+		if len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading source rows: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, tx.Commit()
+}
+
+// where restricts a side to a key range.
+func (s *side) where(keyRange *KeyRange) string {
+	if keyRange == nil {
+		return ""
+	}
+	// Bounds are integers, so they are inlined rather than bound in each dialect's placeholder style
+	return fmt.Sprintf(" WHERE %s BETWEEN %d AND %d", s.key, keyRange.Low, keyRange.High)
+}
+
+// writeStatement renders the INSERT (delete_insert) or upsert of rowCount rows in the target's
+// dialect: INSERT ... ON CONFLICT on Postgres, MERGE on Snowflake.
+func (s *Session) writeStatement(mode string, rowCount int) string {
+	columns := s.target.columns
+	keys := columns[:len(s.keyColumns)]
+	tuples := make([]string, rowCount)
+	for row := range tuples {
+		placeholders := make([]string, len(columns))
+		for i := range placeholders {
+			if s.targetType == "postgres" {
+				placeholders[i] = fmt.Sprintf("$%d", row*len(columns)+i+1)
+			} else {
+				placeholders[i] = "?"
+			}
+		}
+		tuples[row] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	values := strings.Join(tuples, ", ")
+
+	if mode != RefreshUpsert {
+		return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", s.target.from, strings.Join(columns, ", "), values)
+	}
+	if s.targetType == "postgres" {
+		updates := make([]string, 0, len(columns)-len(keys))
+		for _, column := range columns[len(keys):] {
+			updates = append(updates, column+" = EXCLUDED."+column)
+		}
+		return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT (%s) DO UPDATE SET %s",
+			s.target.from, strings.Join(columns, ", "), values, strings.Join(keys, ", "), strings.Join(updates, ", "))
+	}
+
+	// Snowflake names VALUES columns COLUMN1, COLUMN2, ...
+	sourceColumns := make([]string, len(columns))
+	for i := range columns {
+		sourceColumns[i] = fmt.Sprintf("s.COLUMN%d", i+1)
+	}
+	matches := make([]string, len(keys))
+	for i, key := range keys {
+		matches[i] = fmt.Sprintf("t.%s = %s", key, sourceColumns[i])
+	}
+	updates := make([]string, 0, len(columns)-len(keys))
+	for i, column := range columns[len(keys):] {
+		updates = append(updates, column+" = "+sourceColumns[len(keys)+i])
+	}
+	return fmt.Sprintf("MERGE INTO %s t USING (SELECT * FROM (VALUES %s)) s ON %s WHEN MATCHED THEN UPDATE SET %s WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		s.target.from, values, strings.Join(matches, " AND "), strings.Join(updates, ", "), strings.Join(columns, ", "), strings.Join(sourceColumns, ", "))
+}
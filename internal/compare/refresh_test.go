@@ -0,0 +1,70 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRefreshOptions(t *testing.T) {
+	opts, err := ParseRefreshOptions("")
+	require.NoError(t, err)
+	assert.Equal(t, &RefreshOptions{Mode: RefreshDeleteInsert, BatchSize: 500}, opts)
+
+	opts, err = ParseRefreshOptions(`{"mode": "upsert", "tables": ["dbo.Orders"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, RefreshUpsert, opts.Mode)
+	assert.True(t, opts.Selects("DBO.ORDERS"))
+	assert.False(t, opts.Selects("dbo.Customers"))
+
+	for _, raw := range []string{`{"mode": "truncate"}`, `{"batch_size": -5}`, `"upsert"`} {
+		_, err := ParseRefreshOptions(raw)
+		assert.ErrorIs(t, err, ErrInvalidOptions, raw)
+	}
+}
+
+func TestPlanRefresh(t *testing.T) {
+	// Arrange
+	results := []TableResult{
+		{Table: "dbo.Orders", KeyColumns: []string{"OrderID"}, MismatchedRanges: []RangeResult{{Range: &KeyRange{1, 100}}, {Range: &KeyRange{301, 400}}}},
+		{Table: "dbo.Customers", KeyColumns: []string{"CustomerID"}},
+		{Table: "dbo.OrderLines", KeyColumns: []string{"OrderID", "Line"}, MismatchedRanges: []RangeResult{{}}},
+		{Table: "dbo.Dropped", KeyColumns: []string{"ID"}, MismatchedRanges: []RangeResult{{Range: &KeyRange{1, 1}}}},
+	}
+	tables := []Table{
+		{Name: "dbo.Orders", Schema: "dbo", Table: "Orders", Target: "orders"},
+		{Name: "dbo.Customers", Schema: "dbo", Table: "Customers", Target: "customers"},
+		{Name: "dbo.OrderLines", Schema: "dbo", Table: "OrderLines", Target: "order_lines"},
+	}
+
+	// Act
+	plan, skipped := PlanRefresh(results, tables, &RefreshOptions{})
+	fullPlan, fullSkipped := PlanRefresh(results, tables, &RefreshOptions{FullTables: true, Tables: []string{"dbo.OrderLines"}})
+
+	// Assert
+	require.Len(t, plan, 1)
+	assert.Equal(t, "orders", plan[0].Table.Target)
+	assert.Equal(t, []*KeyRange{{1, 100}, {301, 400}}, plan[0].Ranges)
+	assert.Equal(t, []string{"dbo.OrderLines", "dbo.Dropped"}, skipped)
+
+	require.Len(t, fullPlan, 1)
+	assert.Equal(t, []*KeyRange{nil}, fullPlan[0].Ranges)
+	assert.Equal(t, []string{"OrderID", "Line"}, fullPlan[0].KeyColumns)
+	assert.Empty(t, fullSkipped)
+}
+
+func TestWriteStatement(t *testing.T) {
+	postgres := &Session{targetType: "postgres", keyColumns: []string{"ID"}, target: side{from: "public.orders", columns: []string{"id", "amount"}}}
+	snowflake := &Session{targetType: "snowflake", keyColumns: []string{"ID"}, target: side{from: "DB.RAW.ORDERS", columns: []string{"ID", "AMOUNT"}}}
+
+	assert.Equal(t, "INSERT INTO public.orders (id, amount) VALUES ($1, $2), ($3, $4)",
+		postgres.writeStatement(RefreshDeleteInsert, 2))
+	assert.Equal(t, "INSERT INTO public.orders (id, amount) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET amount = EXCLUDED.amount",
+		postgres.writeStatement(RefreshUpsert, 1))
+	assert.Equal(t, "INSERT INTO DB.RAW.ORDERS (ID, AMOUNT) VALUES (?, ?)",
+		snowflake.writeStatement(RefreshDeleteInsert, 1))
+	assert.Equal(t, "MERGE INTO DB.RAW.ORDERS t USING (SELECT * FROM (VALUES (?, ?), (?, ?))) s ON t.ID = s.COLUMN1"+
+		" WHEN MATCHED THEN UPDATE SET AMOUNT = s.COLUMN2 WHEN NOT MATCHED THEN INSERT (ID, AMOUNT) VALUES (s.COLUMN1, s.COLUMN2)",
+		snowflake.writeStatement(RefreshUpsert, 2))
+}
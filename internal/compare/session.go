@@ -0,0 +1,296 @@
+package compare
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// Supported reports whether a task with these connection types can be compared: a
+// database/sql source and a Snowflake or Postgres target.
+func Supported(sourceType, targetType string) error {
+	switch sourceType {
+	case "sqlserver", "oracle", "postgres", "mysql":
+	default:
+		return fmt.Errorf("%w for source type %s", ErrUnsupported, sourceType)
+	}
+	if !ddl.Supported(targetType) {
+		return fmt.Errorf("%w for target type %s", ErrUnsupported, targetType)
+	}
+	return nil
+}
+
+// side is the SQL for reading one side of a table.
+type side struct {
+	db      *sql.DB
+	from    string   // Table name or derived table
+	columns []string // Select expressions, key columns first
+	key     string   // First key column, used for ranges
+	binary  []bool   // Columns holding raw bytes rather than text
+}
+
+// Session compares one table. It holds open connections to the source and target.
+type Session struct {
+	table      Table
+	opts       *Options
+	targetType string
+	keyColumns []string
+	source     side
+	target     side
+}
+
+// Open connects to both sides of a table and resolves the columns to compare: every source
+// column, matched to the target column of the same name folded the target's way.
+func Open(ctx context.Context, sourceConn, targetConn *data.Connection, table Table, opts *Options) (*Session, error) {
+	if err := Supported(sourceConn.Type, targetConn.Type); err != nil {
+		return nil, err
+	}
+	sourceDB, err := schema.OpenDB(ctx, sourceConn)
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{table: table, opts: opts, targetType: targetConn.Type, source: side{db: sourceDB}}
+	if err := s.resolve(ctx, sourceConn, targetConn); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if s.target.db, err = ddl.OpenTarget(targetConn); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Session) resolve(ctx context.Context, sourceConn, targetConn *data.Connection) error {
+	inspector, err := schema.Open(ctx, sourceConn)
+	if err != nil {
+		return err
+	}
+	defer inspector.Close()
+
+	var columns []schema.Column
+	if s.table.Query != "" {
+		describer, ok := inspector.(schema.QueryDescriber)
+		if !ok {
+			return fmt.Errorf("%w: cannot describe %s queries", ErrUnsupported, sourceConn.Type)
+		}
+		columns, err = describer.DescribeQuery(ctx, s.table.Query)
+		s.source.from = "(" + strings.TrimRight(strings.TrimSpace(s.table.Query), ";") + ") q"
+	} else {
+		columns, err = inspector.ListColumns(ctx, s.table.Schema, s.table.Table)
+		if err == nil {
+			s.source.from, err = schema.QuoteTableName(sourceConn.Type, s.table.Schema, s.table.Table)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	keys, err := keyColumns(columns, s.opts.KeyColumns)
+	if err != nil {
+		return fmt.Errorf("table %s: %w", s.table.Name, err)
+	}
+	s.keyColumns = keys
+	ordered := make([]schema.Column, 0, len(columns))
+	for _, key := range keys {
+		for _, column := range columns {
+			if column.Name == key {
+				ordered = append(ordered, column)
+			}
+		}
+	}
+	for _, column := range columns {
+		if !containsName(keys, column.Name) {
+			ordered = append(ordered, column)
+		}
+	}
+
+	params := benthos.ParseConnectionString(targetConn.ConnectionString)
+	s.target.from = ddl.QualifiedName(targetConn.Type, params, s.table.Target)
+	for _, column := range ordered {
+		quoted, err := schema.QuoteIdentifier(sourceConn.Type, column.Name)
+		if err != nil {
+			return err
+		}
+		if sourceConn.Type == "sqlserver" && strings.EqualFold(column.DataType, "uniqueidentifier") {
+			quoted = "CONVERT(varchar(36), " + quoted + ")" // Otherwise the driver returns raw GUID bytes
+		}
+		logical, err := ddl.SourceType(sourceConn.Type, column)
+		s.source.columns = append(s.source.columns, quoted)
+		s.source.binary = append(s.source.binary, err == nil && logical.Kind == ddl.KindBinary)
+		s.target.columns = append(s.target.columns, ddl.Identifier(targetConn.Type, column.Name))
+	}
+	s.source.key, s.target.key = s.source.columns[0], s.target.columns[0]
+	return nil
+}
+
+// keyColumns returns the requested key columns (matched case-insensitively) or the
+// primary key.
+func keyColumns(columns []schema.Column, requested []string) ([]string, error) {
+	var keys []string
+	if len(requested) > 0 {
+		for _, name := range requested {
+			found := false
+			for _, column := range columns {
+				if strings.EqualFold(column.Name, strings.TrimSpace(name)) {
+					keys, found = append(keys, column.Name), true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("key column %s not found", name)
+			}
+		}
+		return keys, nil
+	}
+	for _, column := range columns {
+		if column.PrimaryKey {
+			keys = append(keys, column.Name)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no primary key; set key_columns")
+	}
+	return keys, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyColumns are the source columns identifying rows.
+func (s *Session) KeyColumns() []string {
+	return s.keyColumns
+}
+
+// Ranges splits the table into key ranges of the chunk size. Tables keyed by anything but
+// a single integer column are compared as one range (nil).
+func (s *Session) Ranges(ctx context.Context) ([]*KeyRange, error) {
+	if len(s.keyColumns) != 1 {
+		return []*KeyRange{nil}, nil
+	}
+	sourceMin, sourceMax, sourceOK, err := s.source.integerBounds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading source key range: %w", err)
+	}
+	targetMin, targetMax, targetOK, err := s.target.integerBounds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading target key range: %w", err)
+	}
+	switch {
+	case sourceOK && targetOK:
+		return Ranges(min(sourceMin, targetMin), max(sourceMax, targetMax), s.opts.ChunkSize)
+	case sourceOK:
+		return Ranges(sourceMin, sourceMax, s.opts.ChunkSize)
+	case targetOK:
+		return Ranges(targetMin, targetMax, s.opts.ChunkSize)
+	default:
+		return []*KeyRange{nil}, nil // Empty, or not an integer key
+	}
+}
+
+// integerBounds reads the key's MIN and MAX. ok is false for empty tables and keys that
+// aren't integers.
+func (s *side) integerBounds(ctx context.Context) (int64, int64, bool, error) {
+	var low, high sql.NullString
+	query := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", s.key, s.key, s.from)
+	if err := s.db.QueryRowContext(ctx, query).Scan(&low, &high); err != nil {
+		return 0, 0, false, err
+	}
+	if !low.Valid || !high.Valid {
+		return 0, 0, false, nil
+	}
+	lowValue, lowErr := strconv.ParseInt(strings.TrimSpace(low.String), 10, 64)
+	highValue, highErr := strconv.ParseInt(strings.TrimSpace(high.String), 10, 64)
+	if lowErr != nil || highErr != nil {
+		return 0, 0, false, nil
+	}
+	return lowValue, highValue, true, nil
+}
+
+// CompareRange counts and hashes a key range (nil for the whole table) on both sides,
+// listing row diffs when the range mismatches and row diffs were requested.
+func (s *Session) CompareRange(ctx context.Context, keyRange *KeyRange) (*RangeResult, error) {
+	type scanned struct {
+		chunk *chunk
+		err   error
+	}
+	targetDone := make(chan scanned, 1)
+	go func() {
+		c, err := s.target.scan(ctx, keyRange, len(s.keyColumns), s.opts.RowDiffs)
+		targetDone <- scanned{c, err}
+	}()
+	source, err := s.source.scan(ctx, keyRange, len(s.keyColumns), s.opts.RowDiffs)
+	target := <-targetDone
+	if err != nil {
+		return nil, fmt.Errorf("error reading source rows: %w", err)
+	}
+	if target.err != nil {
+		return nil, fmt.Errorf("error reading target rows: %w", target.err)
+	}
+
+	result := &RangeResult{
+		Range:      keyRange,
+		SourceRows: source.rows,
+		TargetRows: target.chunk.rows,
+		SourceHash: source.hash(),
+		TargetHash: target.chunk.hash(),
+	}
+	if s.opts.RowDiffs && !result.Match() {
+		result.RowDiffs = diffRows(source.keys, target.chunk.keys)
+	}
+	return result, nil
+}
+
+// scan reads a range's rows and accumulates their count and hash.
+func (s *side) scan(ctx context.Context, keyRange *KeyRange, keyCount int, keepKeys bool) (*chunk, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(s.columns, ", "), s.from, s.where(keyRange))
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	c := &chunk{}
+	if keepKeys {
+		c.keys = map[string]uint64{}
+	}
+	values := make([]interface{}, len(s.columns))
+	pointers := make([]interface{}, len(s.columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	normalized := make([]string, len(s.columns))
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			normalized[i] = normalize(value)
+		}
+		c.add(strings.Join(normalized[:keyCount], "|"), rowHash(normalized))
+	}
+	return c, rows.Err()
+}
+
+// Close closes both connections.
+func (s *Session) Close() error {
+	for _, db := range []*sql.DB{s.source.db, s.target.db} {
+		if db != nil {
+			db.Close()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Package criteria renders the templates in a task's data selection criteria (query, object
+// prefix or file list) for a run, from the run's parameters, logical time and the task's
+// watermark.
+package criteria
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	tparse "text/template/parse"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// DateLayout is the layout of RunDate.
+const DateLayout = "2006-01-02"
+
+// ErrInvalidTemplate is returned for criteria templates that do not parse or do not render.
+var ErrInvalidTemplate = errors.New("invalid criteria template")
+
+// ErrInvalidTrigger is returned for malformed run trigger bodies.
+var ErrInvalidTrigger = errors.New("invalid run trigger")
+
+// Trigger is what a run is started with: parameters of an ad-hoc run, and the logical time of
+// a scheduled run or of an ad-hoc run that covers a past date.
+type Trigger struct {
+	Params      map[string]string `json:"params,omitempty"`
+	LogicalTime *time.Time        `json:"logical_time,omitempty"`
+}
+
+// Vars are the values a criteria template can use.
+type Vars struct {
+	TaskID        int64
+	RunID         int64
+	RunDate       string    // Logical date of the run, e.g. 2024-01-01
+	LogicalTime   time.Time // Scheduled or requested time of the run; its start time otherwise
+	LastWatermark string    // Task watermark left by the last successful run
+	Params        map[string]string
+}
+
+// Literal is a value quoted as a SQL string literal. The parameters of runs of SQL sources
+// render as literals, so a parameter can never add SQL of its own to a query.
+type Literal string
+
+// templateVars are the values a template is executed with: vars, with the parameters of SQL
+// sources quoted.
+type templateVars struct {
+	Vars
+	Params map[string]any
+}
+
+// funcs are the template functions for a source type.
+func funcs(sourceType string) template.FuncMap {
+	return template.FuncMap{
+		// sql quotes a value as a SQL string literal; parameters already quoted are kept
+		"sql": func(value any) (Literal, error) {
+			switch v := value.(type) {
+			case Literal:
+				return v, nil
+			case string:
+				return quote(sourceType, v), nil
+			default:
+				return "", fmt.Errorf("sql: cannot quote %T", value)
+			}
+		},
+		// addDays shifts a time by a number of days, e.g. {{ (addDays .LogicalTime -1).Format "2006-01-02" }}
+		"addDays": func(t time.Time, days int) time.Time {
+			return t.AddDate(0, 0, days)
+		},
+	}
+}
+
+// quote quotes value as a string literal in the dialect of a SQL source, and in standard SQL
+// for other source types.
+func quote(sourceType, value string) Literal {
+	if quoted, err := schema.QuoteString(sourceType, value); err == nil {
+		return Literal(quoted)
+	}
+	return Literal("'" + strings.ReplaceAll(value, "'", "''") + "'")
+}
+
+// ParseTrigger parses a run trigger body. An empty body is an empty trigger.
+func ParseTrigger(raw string) (*Trigger, error) {
+	trigger := &Trigger{}
+	if strings.TrimSpace(raw) == "" {
+		return trigger, nil
+	}
+	if err := json.Unmarshal([]byte(raw), trigger); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrigger, err)
+	}
+	for name := range trigger.Params {
+		if name == "" {
+			return nil, fmt.Errorf("%w: parameter names must not be empty", ErrInvalidTrigger)
+		}
+	}
+	return trigger, nil
+}
+
+// IsTemplate reports whether criteria contain template actions.
+func IsTemplate(criteria string) bool {
+	return strings.Contains(criteria, "{{")
+}
+
+// Validate checks that criteria parse as a template.
+func Validate(criteria string) error {
+	if !IsTemplate(criteria) {
+		return nil
+	}
+	if _, err := parse(criteria, ""); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// WatermarkSourceType is the only source type whose runs maintain the task's watermark.
+const WatermarkSourceType = "http_api"
+
+// Render renders criteria with vars for a source of sourceType. Criteria without template
+// actions are returned as they are. Referencing a parameter the run was not given is an error,
+// and so is referencing .LastWatermark for a source that does not maintain a watermark.
+// For SQL sources, parameters render as string literals quoted in the source's dialect.
+func Render(criteria, sourceType string, vars Vars) (string, error) {
+	if !IsTemplate(criteria) {
+		return criteria, nil
+	}
+	tmpl, err := parse(criteria, sourceType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if sourceType != WatermarkSourceType && usesField(tmpl.Tree.Root, "LastWatermark") {
+		return "", fmt.Errorf("%w: .LastWatermark is only maintained for %s sources, not %s", ErrInvalidTemplate, WatermarkSourceType, sourceType)
+	}
+	data := templateVars{Vars: vars, Params: map[string]any{}}
+	for name, value := range vars.Params {
+		data.Params[name] = value
+		if quoted, err := schema.QuoteString(sourceType, value); err == nil {
+			data.Params[name] = Literal(quoted)
+		}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return out.String(), nil
+}
+
+// NewVars builds the template values of a run. The logical time defaults to the run's start.
+func NewVars(taskID, runID int64, startTime time.Time, logicalTime *time.Time, watermark string, params map[string]string) Vars {
+	logical := startTime
+	if logicalTime != nil {
+		logical = *logicalTime
+	}
+	return Vars{
+		TaskID:        taskID,
+		RunID:         runID,
+		RunDate:       logical.Format(DateLayout),
+		LogicalTime:   logical,
+		LastWatermark: watermark,
+		Params:        params,
+	}
+}
+
+// usesField reports whether the template tree under node references the top-level field name,
+// e.g. .LastWatermark.
+func usesField(node tparse.Node, name string) bool {
+	switch n := node.(type) {
+	case *tparse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if usesField(child, name) {
+				return true
+			}
+		}
+	case *tparse.ActionNode:
+		return usesField(n.Pipe, name)
+	case *tparse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if usesField(cmd, name) {
+				return true
+			}
+		}
+	case *tparse.CommandNode:
+		for _, arg := range n.Args {
+			if usesField(arg, name) {
+				return true
+			}
+		}
+	case *tparse.FieldNode:
+		return len(n.Ident) > 0 && n.Ident[0] == name
+	case *tparse.ChainNode:
+		return usesField(n.Node, name)
+	case *tparse.IfNode:
+		return usesField(n.Pipe, name) || usesField(n.List, name) || usesField(n.ElseList, name)
+	case *tparse.RangeNode:
+		return usesField(n.Pipe, name) || usesField(n.List, name) || usesField(n.ElseList, name)
+	case *tparse.WithNode:
+		return usesField(n.Pipe, name) || usesField(n.List, name) || usesField(n.ElseList, name)
+	}
+	return false
+}
+
+func parse(criteria, sourceType string) (*template.Template, error) {
+	return template.New("criteria").Funcs(funcs(sourceType)).Option("missingkey=error").Parse(criteria)
+}
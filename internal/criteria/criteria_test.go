@@ -0,0 +1,110 @@
+package criteria
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	logical := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	vars := NewVars(3, 41, time.Date(2024, 3, 1, 2, 5, 0, 0, time.UTC), &logical, "1700", map[string]string{"region": "o'hare"})
+
+	tests := []struct {
+		name     string
+		criteria string
+		want     string
+	}{
+		{"plain", "SELECT * FROM dbo.Orders", "SELECT * FROM dbo.Orders"},
+		{"run date", "exports/{{ .RunDate }}/", "exports/2024-03-01/"},
+		{"quoted param", "SELECT * FROM t WHERE region = {{ sql .Params.region }}", "SELECT * FROM t WHERE region = 'o''hare'"},
+		{"param", "SELECT * FROM t WHERE region = {{ .Params.region }}", "SELECT * FROM t WHERE region = 'o''hare'"},
+		{"quoted value", "SELECT * FROM t WHERE day = {{ sql .RunDate }}", "SELECT * FROM t WHERE day = '2024-03-01'"},
+		{"logical time", `{{ .LogicalTime.Format "2006-01-02T15" }}`, "2024-03-01T02"},
+		{"previous day", `{{ (addDays .LogicalTime -1).Format "2006-01-02" }}`, "2024-02-29"},
+		{"ids", "{{ .TaskID }}-{{ .RunID }}", "3-41"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.criteria, "postgres", vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRender_Watermark(t *testing.T) {
+	vars := NewVars(3, 41, time.Now(), nil, "1700", nil)
+
+	got, err := Render(`{"params": {"since": "{{ .LastWatermark }}"}}`, "http_api", vars)
+	require.NoError(t, err)
+	assert.Equal(t, `{"params": {"since": "1700"}}`, got)
+
+	// Only HTTP API runs advance the watermark, so other sources cannot reference it
+	_, err = Render("SELECT * FROM t WHERE id > {{ .LastWatermark }}", "postgres", vars)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+	_, err = Render(`{{ if .LastWatermark }}WHERE id > {{ sql .LastWatermark }}{{ end }}`, "mysql", vars)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+}
+
+func TestRender_ParamsBySourceType(t *testing.T) {
+	vars := NewVars(3, 41, time.Now(), nil, "", map[string]string{"region": `x\' OR 1=1 --`})
+
+	got, err := Render("WHERE region = {{ .Params.region }}", "mysql", vars)
+	require.NoError(t, err)
+	assert.Equal(t, `WHERE region = 'x\\'' OR 1=1 --'`, got)
+
+	got, err = Render("WHERE region = {{ .Params.region }}", "sqlserver", vars)
+	require.NoError(t, err)
+	assert.Equal(t, `WHERE region = N'x\'' OR 1=1 --'`, got)
+
+	// File and object sources use parameters as they are
+	got, err = Render("exports/{{ .Params.region }}/", "s3", vars)
+	require.NoError(t, err)
+	assert.Equal(t, `exports/x\' OR 1=1 --/`, got)
+}
+
+func TestRender_Errors(t *testing.T) {
+	vars := NewVars(3, 41, time.Now(), nil, "", nil)
+
+	_, err := Render("SELECT {{ .Params.missing }}", "postgres", vars)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+
+	_, err = Render("SELECT {{ .Unknown }}", "postgres", vars)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+
+	_, err = Render("SELECT {{ .RunDate", "postgres", vars)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+}
+
+func TestNewVars_DefaultsToStartTime(t *testing.T) {
+	start := time.Date(2024, 5, 6, 23, 59, 0, 0, time.UTC)
+	vars := NewVars(1, 2, start, nil, "", nil)
+	assert.Equal(t, "2024-05-06", vars.RunDate)
+	assert.Equal(t, start, vars.LogicalTime)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("SELECT 1"))
+	assert.NoError(t, Validate("SELECT {{ .Params.anything }}"))
+	assert.ErrorIs(t, Validate("SELECT {{ if }}"), ErrInvalidTemplate)
+}
+
+func TestParseTrigger(t *testing.T) {
+	trigger, err := ParseTrigger("")
+	require.NoError(t, err)
+	assert.Equal(t, &Trigger{}, trigger)
+
+	trigger, err = ParseTrigger(`{"params": {"region": "eu"}, "logical_time": "2024-01-01T00:00:00Z"}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu"}, trigger.Params)
+	require.NotNil(t, trigger.LogicalTime)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *trigger.LogicalTime)
+
+	for _, raw := range []string{`{"params": {"region": 1}}`, `{"params": {"": "x"}}`, `[]`} {
+		_, err := ParseTrigger(raw)
+		assert.ErrorIs(t, err, ErrInvalidTrigger, raw)
+	}
+}
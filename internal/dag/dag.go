@@ -0,0 +1,174 @@
+// Package dag validates pipelines of dependent replication tasks and decides, from the
+// outcomes of a task's dependencies, whether it runs.
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// ErrInvalidPipeline is returned for pipelines with unknown or cyclic dependencies.
+var ErrInvalidPipeline = errors.New("invalid pipeline")
+
+// Trigger rules: when a task runs, given the outcomes of its dependencies.
+const (
+	TriggerAllSucceeded = "all_succeeded" // Every dependency succeeded (default)
+	TriggerAnyFailed    = "any_failed"    // Every dependency finished and at least one failed
+	TriggerAlways       = "always"        // Every dependency finished, whatever the outcome
+)
+
+// Task statuses within a pipeline run.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped" // The trigger rule can no longer be met
+)
+
+// Decision is what a pipeline run does next with a pending task.
+type Decision int
+
+const (
+	Wait Decision = iota // Dependencies are still pending or running
+	Run
+	Skip
+)
+
+// Normalize fills in default trigger rules and sorts dependencies, so stored pipelines compare
+// equal however they were submitted.
+func Normalize(tasks []data.PipelineTask) {
+	for i := range tasks {
+		if tasks[i].Trigger == "" {
+			tasks[i].Trigger = TriggerAllSucceeded
+		}
+		sort.Slice(tasks[i].DependsOn, func(a, b int) bool { return tasks[i].DependsOn[a] < tasks[i].DependsOn[b] })
+	}
+}
+
+// Validate checks that a pipeline lists each task once, depends only on its own tasks, uses
+// known trigger rules and has no cycles.
+func Validate(tasks []data.PipelineTask) error {
+	if len(tasks) == 0 {
+		return fmt.Errorf("%w: a pipeline needs at least one task", ErrInvalidPipeline)
+	}
+	byID := make(map[int64]data.PipelineTask, len(tasks))
+	for _, task := range tasks {
+		if _, ok := byID[task.TaskID]; ok {
+			return fmt.Errorf("%w: task %d is listed twice", ErrInvalidPipeline, task.TaskID)
+		}
+		byID[task.TaskID] = task
+	}
+	for _, task := range tasks {
+		switch task.Trigger {
+		case "", TriggerAllSucceeded, TriggerAlways:
+		case TriggerAnyFailed:
+			if len(task.DependsOn) == 0 {
+				return fmt.Errorf("%w: task %d uses %s but has no dependencies", ErrInvalidPipeline, task.TaskID, TriggerAnyFailed)
+			}
+		default:
+			return fmt.Errorf("%w: task %d has unknown trigger %q", ErrInvalidPipeline, task.TaskID, task.Trigger)
+		}
+		for _, dependency := range task.DependsOn {
+			if dependency == task.TaskID {
+				return fmt.Errorf("%w: task %d depends on itself", ErrInvalidPipeline, task.TaskID)
+			}
+			if _, ok := byID[dependency]; !ok {
+				return fmt.Errorf("%w: task %d depends on task %d, which is not in the pipeline", ErrInvalidPipeline, task.TaskID, dependency)
+			}
+		}
+	}
+	if cycle := findCycle(tasks, byID); cycle != nil {
+		steps := make([]string, len(cycle))
+		for i, id := range cycle {
+			steps[i] = fmt.Sprint(id)
+		}
+		return fmt.Errorf("%w: dependency cycle %s", ErrInvalidPipeline, strings.Join(steps, " -> "))
+	}
+	return nil
+}
+
+// findCycle returns the tasks of a dependency cycle, first task repeated at the end, or nil.
+func findCycle(tasks []data.PipelineTask, byID map[int64]data.PipelineTask) []int64 {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[int64]int, len(tasks))
+	var path []int64
+	var visit func(id int64) []int64
+	visit = func(id int64) []int64 {
+		state[id] = visiting
+		path = append(path, id)
+		for _, dependency := range byID[id].DependsOn {
+			switch state[dependency] {
+			case visiting:
+				for i, step := range path {
+					if step == dependency {
+						return append(append([]int64{}, path[i:]...), dependency)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dependency); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+	for _, task := range tasks {
+		if state[task.TaskID] == unvisited {
+			if cycle := visit(task.TaskID); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Decide applies a pending task's trigger rule to the statuses of its dependencies. A task
+// waiting for all dependencies to succeed is skipped as soon as one fails or is skipped.
+func Decide(task data.PipelineTask, statuses map[int64]string) Decision {
+	finished, succeeded, failed := 0, 0, 0
+	for _, dependency := range task.DependsOn {
+		switch statuses[dependency] {
+		case StatusSucceeded:
+			finished, succeeded = finished+1, succeeded+1
+		case StatusFailed:
+			finished, failed = finished+1, failed+1
+		case StatusSkipped:
+			finished++
+		}
+	}
+
+	switch task.Trigger {
+	case TriggerAnyFailed:
+		if finished < len(task.DependsOn) {
+			return Wait
+		}
+		if failed > 0 {
+			return Run
+		}
+		return Skip
+	case TriggerAlways:
+		if finished < len(task.DependsOn) {
+			return Wait
+		}
+		return Run
+	default:
+		if succeeded == len(task.DependsOn) {
+			return Run
+		}
+		if finished > succeeded {
+			return Skip
+		}
+		return Wait
+	}
+}
@@ -0,0 +1,105 @@
+package dag
+
+import (
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate([]data.PipelineTask{
+		{TaskID: 1},
+		{TaskID: 2, DependsOn: []int64{1}},
+		{TaskID: 3, DependsOn: []int64{1, 2}, Trigger: TriggerAlways},
+		{TaskID: 4, DependsOn: []int64{3}, Trigger: TriggerAnyFailed},
+	}))
+
+	tests := []struct {
+		name  string
+		tasks []data.PipelineTask
+		want  string
+	}{
+		{"empty", nil, "at least one task"},
+		{"duplicate task", []data.PipelineTask{{TaskID: 1}, {TaskID: 1}}, "listed twice"},
+		{"unknown trigger", []data.PipelineTask{{TaskID: 1, Trigger: "sometimes"}}, "unknown trigger"},
+		{"any_failed without dependencies", []data.PipelineTask{{TaskID: 1, Trigger: TriggerAnyFailed}}, "has no dependencies"},
+		{"self dependency", []data.PipelineTask{{TaskID: 1, DependsOn: []int64{1}}}, "depends on itself"},
+		{"unknown dependency", []data.PipelineTask{{TaskID: 1, DependsOn: []int64{9}}}, "not in the pipeline"},
+		{"cycle", []data.PipelineTask{
+			{TaskID: 1, DependsOn: []int64{3}},
+			{TaskID: 2, DependsOn: []int64{1}},
+			{TaskID: 3, DependsOn: []int64{2}},
+			{TaskID: 4},
+		}, "dependency cycle 1 -> 3 -> 2 -> 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.tasks)
+			assert.ErrorIs(t, err, ErrInvalidPipeline)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tasks := []data.PipelineTask{{TaskID: 3, DependsOn: []int64{2, 1}}, {TaskID: 4, Trigger: TriggerAlways, DependsOn: []int64{3}}}
+
+	Normalize(tasks)
+
+	assert.Equal(t, []data.PipelineTask{
+		{TaskID: 3, DependsOn: []int64{1, 2}, Trigger: TriggerAllSucceeded},
+		{TaskID: 4, DependsOn: []int64{3}, Trigger: TriggerAlways},
+	}, tasks)
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name     string
+		trigger  string
+		statuses map[int64]string
+		want     Decision
+	}{
+		{"all succeeded", TriggerAllSucceeded, map[int64]string{1: StatusSucceeded, 2: StatusSucceeded}, Run},
+		{"all succeeded, one running", TriggerAllSucceeded, map[int64]string{1: StatusSucceeded, 2: StatusRunning}, Wait},
+		{"all succeeded, one failed", TriggerAllSucceeded, map[int64]string{1: StatusFailed, 2: StatusRunning}, Skip},
+		{"all succeeded, one skipped", TriggerAllSucceeded, map[int64]string{1: StatusSucceeded, 2: StatusSkipped}, Skip},
+		{"any failed, one failed", TriggerAnyFailed, map[int64]string{1: StatusFailed, 2: StatusSucceeded}, Run},
+		{"any failed, one pending", TriggerAnyFailed, map[int64]string{1: StatusFailed, 2: StatusPending}, Wait},
+		{"any failed, none failed", TriggerAnyFailed, map[int64]string{1: StatusSucceeded, 2: StatusSkipped}, Skip},
+		{"always, all finished", TriggerAlways, map[int64]string{1: StatusFailed, 2: StatusSkipped}, Run},
+		{"always, one running", TriggerAlways, map[int64]string{1: StatusFailed, 2: StatusRunning}, Wait},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := data.PipelineTask{TaskID: 3, DependsOn: []int64{1, 2}, Trigger: tt.trigger}
+			assert.Equal(t, tt.want, Decide(task, tt.statuses))
+		})
+	}
+
+	assert.Equal(t, Run, Decide(data.PipelineTask{TaskID: 1, Trigger: TriggerAllSucceeded}, nil), "a task without dependencies runs at once")
+}
+
+func TestBuildGraph(t *testing.T) {
+	// Arrange
+	tasks := []data.PipelineTask{
+		{TaskID: 1, Trigger: TriggerAllSucceeded},
+		{TaskID: 2, Trigger: TriggerAllSucceeded, DependsOn: []int64{1}},
+		{TaskID: 3, Trigger: TriggerAlways, DependsOn: []int64{1, 2}},
+	}
+
+	// Act
+	graph := BuildGraph(7, tasks, map[int64]string{1: "orders", 2: "customers"}, map[int64]string{1: StatusSucceeded})
+
+	// Assert
+	assert.Equal(t, int64(7), graph.PipelineID)
+	assert.Equal(t, []Node{
+		{TaskID: 1, Name: "orders", Trigger: TriggerAllSucceeded, Level: 0, Status: StatusSucceeded},
+		{TaskID: 2, Name: "customers", Trigger: TriggerAllSucceeded, Level: 1},
+		{TaskID: 3, Trigger: TriggerAlways, Level: 2},
+	}, graph.Nodes)
+	assert.Equal(t, []Edge{{From: 1, To: 2}, {From: 1, To: 3}, {From: 2, To: 3}}, graph.Edges)
+}
@@ -0,0 +1,63 @@
+package dag
+
+import "github.com/eleon00/hsoetlnlm/internal/data"
+
+// Graph is a pipeline laid out for visualization.
+type Graph struct {
+	PipelineID int64  `json:"pipeline_id"`
+	RunID      *int64 `json:"run_id,omitempty"` // Run whose task statuses are shown
+	Nodes      []Node `json:"nodes"`
+	Edges      []Edge `json:"edges"`
+}
+
+// Node is a task of the pipeline.
+type Node struct {
+	TaskID  int64  `json:"task_id"`
+	Name    string `json:"name"`
+	Trigger string `json:"trigger"`
+	Level   int    `json:"level"`            // Length of the longest dependency chain leading to the task
+	Status  string `json:"status,omitempty"` // In the shown run
+}
+
+// Edge is a dependency: To runs after From.
+type Edge struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// BuildGraph lays out a validated pipeline. names and statuses are keyed by task ID; either
+// may be nil.
+func BuildGraph(pipelineID int64, tasks []data.PipelineTask, names map[int64]string, statuses map[int64]string) *Graph {
+	byID := make(map[int64]data.PipelineTask, len(tasks))
+	for _, task := range tasks {
+		byID[task.TaskID] = task
+	}
+	levels := make(map[int64]int, len(tasks))
+	var level func(id int64) int
+	level = func(id int64) int {
+		if l, ok := levels[id]; ok {
+			return l
+		}
+		l := 0
+		for _, dependency := range byID[id].DependsOn {
+			l = max(l, level(dependency)+1)
+		}
+		levels[id] = l
+		return l
+	}
+
+	graph := &Graph{PipelineID: pipelineID, Nodes: []Node{}, Edges: []Edge{}}
+	for _, task := range tasks {
+		graph.Nodes = append(graph.Nodes, Node{
+			TaskID:  task.TaskID,
+			Name:    names[task.TaskID],
+			Trigger: task.Trigger,
+			Level:   level(task.TaskID),
+			Status:  statuses[task.TaskID],
+		})
+		for _, dependency := range task.DependsOn {
+			graph.Edges = append(graph.Edges, Edge{From: dependency, To: task.TaskID})
+		}
+	}
+	return graph
+}
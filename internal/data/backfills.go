@@ -0,0 +1,312 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateBackfill inserts a backfill and its slices in one transaction, setting their IDs.
+func (db *DB) CreateBackfill(ctx context.Context, backfill *Backfill, slices []*BackfillSlice) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction for backfill: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO Backfills (ReplicationTaskID, Status, Options, TotalSlices, StartTime, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ID;`
+
+	now := time.Now()
+	var insertedID int64
+
+	err = tx.QueryRowContext(ctx, query,
+		backfill.ReplicationTaskID,
+		backfill.Status,
+		backfill.Options,
+		len(slices),
+		backfill.StartTime,
+		now,
+	).Scan(&insertedID)
+	if err != nil {
+		return 0, fmt.Errorf("error creating backfill: %w", err)
+	}
+
+	sliceQuery := `
+		INSERT INTO BackfillSlices (BackfillID, SliceIndex, RangeStart, RangeEnd, Status, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ID;`
+
+	for _, slice := range slices {
+		err := tx.QueryRowContext(ctx, sliceQuery,
+			insertedID,
+			slice.SliceIndex,
+			slice.RangeStart,
+			slice.RangeEnd,
+			slice.Status,
+			now,
+		).Scan(&slice.ID)
+		if err != nil {
+			return 0, fmt.Errorf("error creating slice %d of backfill: %w", slice.SliceIndex, err)
+		}
+		slice.BackfillID = insertedID
+		slice.CreatedAt = now
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing backfill: %w", err)
+	}
+
+	backfill.ID = insertedID
+	backfill.TotalSlices = len(slices)
+	backfill.CreatedAt = now
+	return insertedID, nil
+}
+
+// GetBackfill retrieves a backfill by its ID.
+func (db *DB) GetBackfill(ctx context.Context, id int64) (*Backfill, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, Status, Options, TotalSlices, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM Backfills
+		WHERE ID = $1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting backfill %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	backfills, err := scanBackfills(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(backfills) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return backfills[0], nil
+}
+
+// ListBackfillsForTask retrieves a task's backfills, most recent first.
+func (db *DB) ListBackfillsForTask(ctx context.Context, taskID int64) ([]*Backfill, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, Status, Options, TotalSlices, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM Backfills
+		WHERE ReplicationTaskID = $1
+		ORDER BY StartTime DESC;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing backfills for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	return scanBackfills(rows)
+}
+
+// UpdateBackfill stores a backfill's status, error, workflow ID and end time.
+func (db *DB) UpdateBackfill(ctx context.Context, backfill *Backfill) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		UPDATE Backfills
+		SET Status = $1, ErrorDetails = $2, TemporalWorkflowID = $3, EndTime = $4
+		WHERE ID = $5;`
+
+	var endTime sql.NullTime
+	if backfill.EndTime != nil {
+		endTime = sql.NullTime{Time: *backfill.EndTime, Valid: true}
+	}
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		backfill.Status,
+		sql.NullString{String: backfill.ErrorDetails, Valid: backfill.ErrorDetails != ""},
+		sql.NullString{String: backfill.TemporalWorkflowID, Valid: backfill.TemporalWorkflowID != ""},
+		endTime,
+		backfill.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating backfill %d: %w", backfill.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for backfill %d: %w", backfill.ID, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// ListBackfillSlices retrieves a backfill's slices in range order.
+func (db *DB) ListBackfillSlices(ctx context.Context, backfillID int64) ([]*BackfillSlice, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, BackfillID, SliceIndex, RangeStart, RangeEnd, Status, ReplicationRunID, ErrorDetails, StartTime, EndTime, CreatedAt
+		FROM BackfillSlices
+		WHERE BackfillID = $1
+		ORDER BY SliceIndex;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, backfillID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing slices for backfill %d: %w", backfillID, err)
+	}
+	defer rows.Close()
+
+	slices := make([]*BackfillSlice, 0)
+	for rows.Next() {
+		var slice BackfillSlice
+		var runID sql.NullInt64
+		var errorDetails sql.NullString
+		var startTime, endTime sql.NullTime
+
+		if err := rows.Scan(
+			&slice.ID,
+			&slice.BackfillID,
+			&slice.SliceIndex,
+			&slice.RangeStart,
+			&slice.RangeEnd,
+			&slice.Status,
+			&runID,
+			&errorDetails,
+			&startTime,
+			&endTime,
+			&slice.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning backfill slice row: %w", err)
+		}
+
+		if runID.Valid {
+			slice.ReplicationRunID = &runID.Int64
+		}
+		if errorDetails.Valid {
+			slice.ErrorDetails = errorDetails.String
+		}
+		if startTime.Valid {
+			slice.StartTime = &startTime.Time
+		}
+		if endTime.Valid {
+			slice.EndTime = &endTime.Time
+		}
+
+		slices = append(slices, &slice)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating backfill slice rows: %w", err)
+	}
+
+	return slices, nil
+}
+
+// UpdateBackfillSlice stores a slice's status, run, error and start and end times.
+func (db *DB) UpdateBackfillSlice(ctx context.Context, slice *BackfillSlice) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		UPDATE BackfillSlices
+		SET Status = $1, ReplicationRunID = $2, ErrorDetails = $3, StartTime = $4, EndTime = $5
+		WHERE ID = $6;`
+
+	var runID sql.NullInt64
+	if slice.ReplicationRunID != nil {
+		runID = sql.NullInt64{Int64: *slice.ReplicationRunID, Valid: true}
+	}
+	var startTime, endTime sql.NullTime
+	if slice.StartTime != nil {
+		startTime = sql.NullTime{Time: *slice.StartTime, Valid: true}
+	}
+	if slice.EndTime != nil {
+		endTime = sql.NullTime{Time: *slice.EndTime, Valid: true}
+	}
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		slice.Status,
+		runID,
+		sql.NullString{String: slice.ErrorDetails, Valid: slice.ErrorDetails != ""},
+		startTime,
+		endTime,
+		slice.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating backfill slice %d: %w", slice.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for backfill slice %d: %w", slice.ID, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// scanBackfills reads backfill rows selected in the column order used above.
+func scanBackfills(rows *sql.Rows) ([]*Backfill, error) {
+	backfills := make([]*Backfill, 0)
+	for rows.Next() {
+		var backfill Backfill
+		var errorDetails, workflowID sql.NullString
+		var endTime sql.NullTime
+
+		if err := rows.Scan(
+			&backfill.ID,
+			&backfill.ReplicationTaskID,
+			&backfill.Status,
+			&backfill.Options,
+			&backfill.TotalSlices,
+			&errorDetails,
+			&workflowID,
+			&backfill.StartTime,
+			&endTime,
+			&backfill.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning backfill row: %w", err)
+		}
+
+		if errorDetails.Valid {
+			backfill.ErrorDetails = errorDetails.String
+		}
+		if workflowID.Valid {
+			backfill.TemporalWorkflowID = workflowID.String
+		}
+		if endTime.Valid {
+			backfill.EndTime = &endTime.Time
+		}
+
+		backfills = append(backfills, &backfill)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating backfill rows: %w", err)
+	}
+
+	return backfills, nil
+}
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateCompareReport inserts a new compare report record.
+func (db *DB) CreateCompareReport(ctx context.Context, report *CompareReport) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		INSERT INTO CompareReports (ReplicationTaskID, Status, Options, TemporalWorkflowID, StartTime, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ID;`
+
+	now := time.Now()
+	var insertedID int64
+
+	err := db.SQL.QueryRowContext(ctx, query,
+		report.ReplicationTaskID,
+		report.Status,
+		sql.NullString{String: report.Options, Valid: report.Options != ""},
+		sql.NullString{String: report.TemporalWorkflowID, Valid: report.TemporalWorkflowID != ""},
+		report.StartTime,
+		now,
+	).Scan(&insertedID)
+
+	if err != nil {
+		return 0, fmt.Errorf("error creating compare report: %w", err)
+	}
+
+	report.ID = insertedID
+	report.CreatedAt = now
+	return insertedID, nil
+}
+
+// GetCompareReport retrieves a compare report by its ID.
+func (db *DB) GetCompareReport(ctx context.Context, id int64) (*CompareReport, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, Status, InSync, Options, Result, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM CompareReports
+		WHERE ID = $1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting compare report %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	reports, err := scanCompareReports(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return reports[0], nil
+}
+
+// ListCompareReportsForTask retrieves a task's compare reports, most recent first.
+func (db *DB) ListCompareReportsForTask(ctx context.Context, taskID int64) ([]*CompareReport, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, Status, InSync, Options, Result, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM CompareReports
+		WHERE ReplicationTaskID = $1
+		ORDER BY StartTime DESC;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing compare reports for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	return scanCompareReports(rows)
+}
+
+// UpdateCompareReport stores a compare report's status, outcome and workflow ID.
+func (db *DB) UpdateCompareReport(ctx context.Context, report *CompareReport) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		UPDATE CompareReports
+		SET Status = $1, InSync = $2, Result = $3, ErrorDetails = $4, TemporalWorkflowID = $5, EndTime = $6
+		WHERE ID = $7;`
+
+	var endTime sql.NullTime
+	if report.EndTime != nil {
+		endTime = sql.NullTime{Time: *report.EndTime, Valid: true}
+	}
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		report.Status,
+		report.InSync, // nil until completed
+		sql.NullString{String: report.Result, Valid: report.Result != ""},
+		sql.NullString{String: report.ErrorDetails, Valid: report.ErrorDetails != ""},
+		sql.NullString{String: report.TemporalWorkflowID, Valid: report.TemporalWorkflowID != ""},
+		endTime,
+		report.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating compare report %d: %w", report.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for compare report %d: %w", report.ID, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// scanCompareReports reads compare report rows selected in the column order used above.
+func scanCompareReports(rows *sql.Rows) ([]*CompareReport, error) {
+	reports := make([]*CompareReport, 0)
+	for rows.Next() {
+		var report CompareReport
+		var inSync sql.NullBool
+		var options, result, errorDetails, workflowID sql.NullString
+		var endTime sql.NullTime
+
+		if err := rows.Scan(
+			&report.ID,
+			&report.ReplicationTaskID,
+			&report.Status,
+			&inSync,
+			&options,
+			&result,
+			&errorDetails,
+			&workflowID,
+			&report.StartTime,
+			&endTime,
+			&report.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning compare report row: %w", err)
+		}
+
+		if inSync.Valid {
+			report.InSync = &inSync.Bool
+		}
+		if options.Valid {
+			report.Options = options.String
+		}
+		if result.Valid {
+			report.Result = result.String
+		}
+		if errorDetails.Valid {
+			report.ErrorDetails = errorDetails.String
+		}
+		if workflowID.Valid {
+			report.TemporalWorkflowID = workflowID.String
+		}
+		if endTime.Valid {
+			report.EndTime = &endTime.Time
+		}
+
+		reports = append(reports, &report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating compare report rows: %w", err)
+	}
+
+	return reports, nil
+}
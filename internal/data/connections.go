@@ -17,15 +17,15 @@ func (db *DB) CreateConnection(ctx context.Context, conn *Connection) (int64, er
 
 	// SQL Server syntax for inserting and returning the ID
 	query := `
-		INSERT INTO Connections (Name, Type, ConnectionString, CreatedAt, UpdatedAt)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO Connections (Name, Type, ConnectionString, MaxConcurrentRuns, CreatedAt, UpdatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING ID;`
 
 	now := time.Now()
 	var insertedID int64
 
 	err := db.SQL.QueryRowContext(ctx, query,
-		conn.Name, conn.Type, conn.ConnectionString, now, now,
+		conn.Name, conn.Type, conn.ConnectionString, conn.MaxConcurrentRuns, now, now,
 	).Scan(&insertedID)
 
 	if err != nil {
@@ -45,7 +45,7 @@ func (db *DB) GetConnection(ctx context.Context, id int64) (*Connection, error)
 	}
 
 	query := `
-		SELECT ID, Name, Type, ConnectionString, CreatedAt, UpdatedAt
+		SELECT ID, Name, Type, ConnectionString, MaxConcurrentRuns, CreatedAt, UpdatedAt
 		FROM Connections
 		WHERE ID = $1;`
 
@@ -57,6 +57,7 @@ func (db *DB) GetConnection(ctx context.Context, id int64) (*Connection, error)
 		&conn.Name,
 		&conn.Type,
 		&conn.ConnectionString,
+		&conn.MaxConcurrentRuns,
 		&conn.CreatedAt,
 		&conn.UpdatedAt,
 	)
@@ -78,7 +79,7 @@ func (db *DB) ListConnections(ctx context.Context) ([]*Connection, error) {
 	}
 
 	query := `
-		SELECT ID, Name, Type, ConnectionString, CreatedAt, UpdatedAt
+		SELECT ID, Name, Type, ConnectionString, MaxConcurrentRuns, CreatedAt, UpdatedAt
 		FROM Connections
 		ORDER BY Name;` // Or order by ID, CreatedAt, etc.
 
@@ -96,6 +97,7 @@ func (db *DB) ListConnections(ctx context.Context) ([]*Connection, error) {
 			&conn.Name,
 			&conn.Type,
 			&conn.ConnectionString,
+			&conn.MaxConcurrentRuns,
 			&conn.CreatedAt,
 			&conn.UpdatedAt,
 		); err != nil {
@@ -119,12 +121,12 @@ func (db *DB) UpdateConnection(ctx context.Context, conn *Connection) error {
 
 	query := `
 		UPDATE Connections
-		SET Name = $1, Type = $2, ConnectionString = $3, UpdatedAt = $4
-		WHERE ID = $5;`
+		SET Name = $1, Type = $2, ConnectionString = $3, MaxConcurrentRuns = $4, UpdatedAt = $5
+		WHERE ID = $6;`
 
 	now := time.Now()
 	result, err := db.SQL.ExecContext(ctx, query,
-		conn.Name, conn.Type, conn.ConnectionString, now, conn.ID,
+		conn.Name, conn.Type, conn.ConnectionString, conn.MaxConcurrentRuns, now, conn.ID,
 	)
 
 	if err != nil {
@@ -25,6 +25,9 @@ type Repository interface {
 	GetReplicationTask(ctx context.Context, id int64) (*ReplicationTask, error)
 	ListReplicationTasks(ctx context.Context) ([]*ReplicationTask, error)
 	UpdateReplicationTask(ctx context.Context, task *ReplicationTask) error
+	UpdateReplicationTaskWatermark(ctx context.Context, id int64, watermark string) error
+	UpdateReplicationTaskControl(ctx context.Context, id int64, status string, skipNextRun bool) error
+	ConsumeReplicationTaskSkipNext(ctx context.Context, id int64) (bool, error)
 	DeleteReplicationTask(ctx context.Context, id int64) error
 
 	// BenthosConfiguration methods (Placeholders)
@@ -38,7 +41,76 @@ type Repository interface {
 	CreateReplicationRun(ctx context.Context, run *ReplicationRun) (int64, error)
 	GetReplicationRun(ctx context.Context, id int64) (*ReplicationRun, error)
 	ListReplicationRunsForTask(ctx context.Context, taskID int64) ([]*ReplicationRun, error)
+	ListChildReplicationRuns(ctx context.Context, parentRunID int64) ([]*ReplicationRun, error)
 	UpdateReplicationRunStatus(ctx context.Context, id int64, status string, errorDetails string, endTime *time.Time) error
+	UpdateReplicationRunSchemaDrift(ctx context.Context, id int64, drift string) error
+	UpdateReplicationRunSourceFiles(ctx context.Context, id int64, files string) error
+	UpdateReplicationRunCriteria(ctx context.Context, id int64, criteria string) error
+	UpdateReplicationRunExecution(ctx context.Context, id int64, workflowID string, runID string) error
+	ListUnfinishedReplicationRuns(ctx context.Context) ([]*ReplicationRun, error)
+	GetReplicationRunByExecution(ctx context.Context, temporalRunID string) (*ReplicationRun, error)
+	UpdateReplicationRunCounts(ctx context.Context, id int64, rowsRead int64, rowsWritten int64) error
+
+	// SchemaSnapshot methods
+	CreateSchemaSnapshot(ctx context.Context, snapshot *SchemaSnapshot) (int64, error)
+	GetLatestSchemaSnapshot(ctx context.Context, taskID int64, tableName string) (*SchemaSnapshot, error)
+	ListSchemaSnapshots(ctx context.Context, taskID int64) ([]*SchemaSnapshot, error)
+	DeleteSchemaSnapshots(ctx context.Context, taskID int64) error
+
+	// ReplicationLagSample methods
+	CreateReplicationLagSample(ctx context.Context, sample *ReplicationLagSample) (int64, error)
+	ListReplicationLagSamples(ctx context.Context, taskID int64, since time.Time, limit int) ([]*ReplicationLagSample, error)
+	GetLatestReplicationLagSample(ctx context.Context, taskID int64) (*ReplicationLagSample, error)
+	ListLatestReplicationLagSamples(ctx context.Context) ([]*ReplicationLagSample, error)
+	DeleteReplicationLagSamples(ctx context.Context, taskID int64, before time.Time) (int64, error)
+
+	// ReplicationRunChunk methods
+	CreateReplicationRunChunks(ctx context.Context, chunks []*ReplicationRunChunk) error
+	ListReplicationRunChunks(ctx context.Context, runID int64) ([]*ReplicationRunChunk, error)
+	UpdateReplicationRunChunk(ctx context.Context, chunk *ReplicationRunChunk) error
+
+	// RunLease methods
+	AcquireRunLeases(ctx context.Context, holder LeaseHolder, limits []LeaseLimit) (bool, error)
+	ReleaseRunLeases(ctx context.Context, holder LeaseHolder) error
+	ListRunLeases(ctx context.Context, resourceType string, resourceID int64) ([]*RunLease, error)
+
+	// CompareReport methods
+	CreateCompareReport(ctx context.Context, report *CompareReport) (int64, error)
+	GetCompareReport(ctx context.Context, id int64) (*CompareReport, error)
+	ListCompareReportsForTask(ctx context.Context, taskID int64) ([]*CompareReport, error)
+	UpdateCompareReport(ctx context.Context, report *CompareReport) error
+
+	// Refresh methods
+	CreateRefresh(ctx context.Context, refresh *Refresh) (int64, error)
+	GetRefresh(ctx context.Context, id int64) (*Refresh, error)
+	ListRefreshesForTask(ctx context.Context, taskID int64) ([]*Refresh, error)
+	UpdateRefresh(ctx context.Context, refresh *Refresh) error
+	AddRefreshProgress(ctx context.Context, id int64, ranges int, rowsDeleted, rowsWritten int64) error
+
+	// Backfill methods
+	CreateBackfill(ctx context.Context, backfill *Backfill, slices []*BackfillSlice) (int64, error)
+	GetBackfill(ctx context.Context, id int64) (*Backfill, error)
+	ListBackfillsForTask(ctx context.Context, taskID int64) ([]*Backfill, error)
+	UpdateBackfill(ctx context.Context, backfill *Backfill) error
+	ListBackfillSlices(ctx context.Context, backfillID int64) ([]*BackfillSlice, error)
+	UpdateBackfillSlice(ctx context.Context, slice *BackfillSlice) error
+
+	// Pipeline methods
+	CreatePipeline(ctx context.Context, pipeline *Pipeline) (int64, error)
+	GetPipeline(ctx context.Context, id int64) (*Pipeline, error)
+	ListPipelines(ctx context.Context) ([]*Pipeline, error)
+	UpdatePipeline(ctx context.Context, pipeline *Pipeline) error
+	DeletePipeline(ctx context.Context, id int64) error
+
+	// PipelineRun methods
+	CreatePipelineRun(ctx context.Context, run *PipelineRun) (int64, error)
+	GetPipelineRun(ctx context.Context, id int64) (*PipelineRun, error)
+	ListPipelineRuns(ctx context.Context, pipelineID int64) ([]*PipelineRun, error)
+	UpdatePipelineRun(ctx context.Context, run *PipelineRun) error
+
+	// Event methods
+	CreateEvent(ctx context.Context, event *Event) (int64, error)
+	ListEvents(ctx context.Context, filter EventFilter) ([]*Event, error)
 
 	// Placeholder methods for other resources
 	// GetReplicationRun(ctx context.Context, id int64) (*ReplicationRun, error)
@@ -0,0 +1,119 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CreateEvent appends an event to the feed.
+func (db *DB) CreateEvent(ctx context.Context, event *Event) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		INSERT INTO Events (ReplicationTaskID, ReplicationRunID, Type, Message, Details, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ID;`
+
+	now := time.Now()
+	var insertedID int64
+
+	err := db.SQL.QueryRowContext(ctx, query,
+		event.ReplicationTaskID,
+		event.ReplicationRunID,
+		event.Type,
+		event.Message,
+		sql.NullString{String: event.Details, Valid: event.Details != ""},
+		now,
+	).Scan(&insertedID)
+
+	if err != nil {
+		return 0, fmt.Errorf("error creating event: %w", err)
+	}
+
+	event.ID = insertedID
+	event.CreatedAt = now
+	return insertedID, nil
+}
+
+// ListEvents retrieves events matching a filter, most recent first.
+func (db *DB) ListEvents(ctx context.Context, filter EventFilter) ([]*Event, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	var conditions []string
+	var args []interface{}
+	if filter.TaskID != 0 {
+		args = append(args, filter.TaskID)
+		conditions = append(conditions, fmt.Sprintf("ReplicationTaskID = $%d", len(args)))
+	}
+	if filter.RunID != 0 {
+		args = append(args, filter.RunID)
+		conditions = append(conditions, fmt.Sprintf("ReplicationRunID = $%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("Type = $%d", len(args)))
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT ID, ReplicationTaskID, ReplicationRunID, Type, Message, Details, CreatedAt
+		FROM Events`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf("\n\t\tORDER BY ID DESC\n\t\tLIMIT $%d;", len(args))
+
+	rows, err := db.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*Event, 0)
+	for rows.Next() {
+		var event Event
+		var taskID, runID sql.NullInt64
+		var details sql.NullString
+
+		if err := rows.Scan(
+			&event.ID,
+			&taskID,
+			&runID,
+			&event.Type,
+			&event.Message,
+			&details,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning event row: %w", err)
+		}
+
+		if taskID.Valid {
+			event.ReplicationTaskID = &taskID.Int64
+		}
+		if runID.Valid {
+			event.ReplicationRunID = &runID.Int64
+		}
+		if details.Valid {
+			event.Details = details.String
+		}
+
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating event rows: %w", err)
+	}
+
+	return events, nil
+}
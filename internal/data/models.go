@@ -7,12 +7,13 @@ import (
 // Connection represents the Connections table.
 // Stores details for connecting to source or target systems.
 type Connection struct {
-	ID               int64     `json:"id"`
-	Name             string    `json:"name" validate:"required"`
-	Type             string    `json:"type" validate:"required"` // e.g., 'oracle', 'sqlserver', 's3', 'bigquery', 'snowflake', 'localfile'
-	ConnectionString string    `json:"connection_string"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID                int64     `json:"id"`
+	Name              string    `json:"name" validate:"required"`
+	Type              string    `json:"type" validate:"required"` // e.g., 'oracle', 'sqlserver', 'postgres', 'mysql', 's3', 'bigquery', 'snowflake', 'localfile', 'kafka', 'azure_blob', 'sftp', 'http_api'
+	ConnectionString  string    `json:"connection_string"`
+	MaxConcurrentRuns int       `json:"max_concurrent_runs,omitempty" validate:"min=0"` // Runs using the connection at once; 0 for no limit
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 // ReplicationTask represents the ReplicationTasks table.
@@ -25,8 +26,17 @@ type ReplicationTask struct {
 	Schedule              string    `json:"schedule,omitempty"`                            // Optional
 	DataSelectionCriteria string    `json:"data_selection_criteria,omitempty"`
 	TransformationRules   string    `json:"transformation_rules,omitempty"`
+	TableRules            string    `json:"table_rules,omitempty"`                          // JSON table selection rules; set for multi-table tasks
+	ColumnTypes           string    `json:"column_types,omitempty"`                         // JSON target column type overrides for created tables
+	DriftPolicy           string    `json:"drift_policy,omitempty"`                         // fail (default), ignore, add_columns or quarantine
+	Partitioning          string    `json:"partitioning,omitempty"`                         // JSON parallel chunked extraction settings for SQL sources
+	ExecutionPolicy       string    `json:"execution_policy,omitempty"`                     // JSON retry policy and timeouts of the task's runs
+	StreamConfig          string    `json:"stream_config,omitempty"`                        // JSON streaming settings; set for tasks that replicate continuously
+	MaxConcurrentRuns     int       `json:"max_concurrent_runs,omitempty" validate:"min=0"` // Runs of the task at once; 0 for no limit
 	TemporalWorkflowID    string    `json:"temporal_workflow_id,omitempty"`
-	Status                string    `json:"status" validate:"required"` // e.g., 'active', 'inactive', 'failed'
+	Watermark             string    `json:"watermark,omitempty"`        // Last incremental position (e.g. API cursor); maintained by runs
+	SkipNextRun           bool      `json:"skip_next_run,omitempty"`    // Set by skip-next; cleared by the run it skips
+	Status                string    `json:"status" validate:"required"` // e.g., 'active', 'inactive', 'paused', 'failed'
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
 }
@@ -34,14 +44,212 @@ type ReplicationTask struct {
 // ReplicationRun represents the ReplicationRuns table.
 // Stores the history and status of a specific execution of a ReplicationTask.
 type ReplicationRun struct {
-	ID                int64      `json:"id"`
-	ReplicationTaskID int64      `json:"replication_task_id"`
-	StartTime         time.Time  `json:"start_time"`
-	EndTime           *time.Time `json:"end_time,omitempty"` // Pointer allows for NULL values
-	Status            string     `json:"status"`             // e.g., 'running', 'success', 'failed'
-	ErrorDetails      string     `json:"error_details,omitempty"`
-	TemporalRunID     string     `json:"temporal_run_id,omitempty"`
-	CreatedAt         time.Time  `json:"created_at"`
+	ID                 int64      `json:"id"`
+	ReplicationTaskID  int64      `json:"replication_task_id"`
+	StartTime          time.Time  `json:"start_time"`
+	EndTime            *time.Time `json:"end_time,omitempty"` // Pointer allows for NULL values
+	Status             string     `json:"status"`             // e.g., 'running', 'success', 'failed'
+	ErrorDetails       string     `json:"error_details,omitempty"`
+	TemporalWorkflowID string     `json:"temporal_workflow_id,omitempty"` // Workflow carrying out the run, with TemporalRunID
+	TemporalRunID      string     `json:"temporal_run_id,omitempty"`
+	ParentRunID        *int64     `json:"parent_run_id,omitempty"`     // Set on the per-table runs of a multi-table run
+	TableName          string     `json:"table_name,omitempty"`        // Source table of a per-table run
+	SchemaDrift        string     `json:"schema_drift,omitempty"`      // JSON source schema drift detected by the run
+	SourceFiles        string     `json:"source_files,omitempty"`      // JSON list of the source files pinned by the run
+	Parameters         string     `json:"parameters,omitempty"`        // JSON parameters the run was triggered with
+	LogicalTime        *time.Time `json:"logical_time,omitempty"`      // Scheduled or requested time the run covers; the start time when unset
+	RenderedCriteria   string     `json:"rendered_criteria,omitempty"` // Data selection criteria as rendered for the run
+	RerunOfRunID       *int64     `json:"rerun_of_run_id,omitempty"`   // Run this run reruns
+	Streaming          bool       `json:"streaming,omitempty"`         // Set on the micro-runs of a streaming task
+	RowsRead           int64      `json:"rows_read,omitempty"`         // Records read by a micro-run
+	RowsWritten        int64      `json:"rows_written,omitempty"`      // Records written by a micro-run
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// ReplicationRunChunk represents the ReplicationRunChunks table.
+// Tracks one chunk of a partitioned run, so a failed chunk can be retried on its own.
+type ReplicationRunChunk struct {
+	ID               int64      `json:"id"`
+	ReplicationRunID int64      `json:"replication_run_id"`
+	ChunkIndex       int        `json:"chunk_index"`
+	Predicate        string     `json:"predicate"` // SQL condition on the split column
+	Status           string     `json:"status"`    // e.g., 'pending', 'running', 'completed', 'failed'
+	ErrorDetails     string     `json:"error_details,omitempty"`
+	StartTime        *time.Time `json:"start_time,omitempty"`
+	EndTime          *time.Time `json:"end_time,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// RunLease represents the RunLeases table.
+// A slot a run, backfill, compare or refresh holds on a connection or task with a concurrency
+// limit, released when it ends. Exactly one of the holder IDs is set.
+type RunLease struct {
+	ID               int64     `json:"id"`
+	ReplicationRunID *int64    `json:"replication_run_id,omitempty"`
+	BackfillID       *int64    `json:"backfill_id,omitempty"`
+	CompareReportID  *int64    `json:"compare_report_id,omitempty"`
+	RefreshID        *int64    `json:"refresh_id,omitempty"`
+	ResourceType     string    `json:"resource_type"` // 'connection' or 'task'
+	ResourceID       int64     `json:"resource_id"`
+	AcquiredAt       time.Time `json:"acquired_at"`
+}
+
+// LeaseHolder identifies what holds leases: a run, a backfill, a compare or a refresh.
+type LeaseHolder struct {
+	Type string `json:"type"` // One of the LeaseHolder constants
+	ID   int64  `json:"id"`
+}
+
+// LeaseLimit is the concurrency limit of a connection or task a run needs a lease on.
+type LeaseLimit struct {
+	ResourceType string
+	ResourceID   int64
+	Max          int
+}
+
+// SchemaSnapshot represents the SchemaSnapshots table.
+// Stores the source columns a run saw, as the baseline for detecting schema drift.
+type SchemaSnapshot struct {
+	ID                int64     `json:"id"`
+	ReplicationTaskID int64     `json:"replication_task_id"`
+	ReplicationRunID  *int64    `json:"replication_run_id,omitempty"`
+	TableName         string    `json:"table_name,omitempty"` // Source table (schema.table); empty for query-based tasks
+	Columns           string    `json:"columns"`              // JSON source columns
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// Event represents the Events table.
+// Records notable things that happened to tasks and runs, such as schema drift.
+type Event struct {
+	ID                int64     `json:"id"`
+	ReplicationTaskID *int64    `json:"replication_task_id,omitempty"`
+	ReplicationRunID  *int64    `json:"replication_run_id,omitempty"`
+	Type              string    `json:"type"` // e.g., 'schema_drift'
+	Message           string    `json:"message"`
+	Details           string    `json:"details,omitempty"` // JSON event payload
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ReplicationLagSample represents the ReplicationLagSamples table.
+// Records how far a streaming task's target trailed its source at one point in time.
+type ReplicationLagSample struct {
+	ID                int64     `json:"id"`
+	ReplicationTaskID int64     `json:"replication_task_id"`
+	ReplicationRunID  *int64    `json:"replication_run_id,omitempty"` // Micro-run the sample was measured in
+	LatencyMs         *int64    `json:"latency_ms"`                   // Source commit to target apply; null when unknown
+	Backlog           *int64    `json:"backlog"`                      // Distance behind the source; null when unknown
+	BacklogUnit       string    `json:"backlog_unit"`                 // 'bytes' (Postgres WAL) or 'messages' (Kafka offsets)
+	SourcePosition    string    `json:"source_position,omitempty"`    // e.g., the source's current WAL LSN
+	TargetPosition    string    `json:"target_position,omitempty"`    // e.g., the replication slot's confirmed LSN
+	MeasuredAt        time.Time `json:"measured_at"`
+}
+
+// EventFilter narrows an events listing. Zero values match everything.
+type EventFilter struct {
+	TaskID int64
+	RunID  int64
+	Type   string
+	Limit  int
+}
+
+// CompareReport represents the CompareReports table.
+// Stores the outcome of a source-target compare of a ReplicationTask.
+type CompareReport struct {
+	ID                 int64      `json:"id"`
+	ReplicationTaskID  int64      `json:"replication_task_id"`
+	Status             string     `json:"status"`            // e.g., 'running', 'completed', 'failed'
+	InSync             *bool      `json:"in_sync,omitempty"` // Set once completed
+	Options            string     `json:"options,omitempty"` // JSON compare options
+	Result             string     `json:"result,omitempty"`  // JSON per-table results with mismatched key ranges
+	ErrorDetails       string     `json:"error_details,omitempty"`
+	TemporalWorkflowID string     `json:"temporal_workflow_id,omitempty"`
+	StartTime          time.Time  `json:"start_time"`
+	EndTime            *time.Time `json:"end_time,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// Refresh represents the Refreshes table.
+// Tracks the resynchronization of a compare report's mismatched key ranges.
+type Refresh struct {
+	ID                 int64      `json:"id"`
+	ReplicationTaskID  int64      `json:"replication_task_id"`
+	CompareReportID    int64      `json:"compare_report_id"`
+	Status             string     `json:"status"`            // e.g., 'running', 'completed', 'failed'
+	Options            string     `json:"options,omitempty"` // JSON refresh options
+	TotalRanges        int        `json:"total_ranges"`
+	CompletedRanges    int        `json:"completed_ranges"`
+	RowsDeleted        int64      `json:"rows_deleted"`
+	RowsWritten        int64      `json:"rows_written"`
+	SkippedTables      string     `json:"skipped_tables,omitempty"` // Comma-separated tables that were not refreshed
+	ErrorDetails       string     `json:"error_details,omitempty"`
+	TemporalWorkflowID string     `json:"temporal_workflow_id,omitempty"`
+	StartTime          time.Time  `json:"start_time"`
+	EndTime            *time.Time `json:"end_time,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// Backfill represents the Backfills table.
+// Tracks the replication of a task over a date or ID range, one slice at a time.
+type Backfill struct {
+	ID                 int64      `json:"id"`
+	ReplicationTaskID  int64      `json:"replication_task_id"`
+	Status             string     `json:"status"`  // e.g., 'running', 'paused', 'completed', 'failed', 'cancelled'
+	Options            string     `json:"options"` // JSON range, step and max_parallel
+	TotalSlices        int        `json:"total_slices"`
+	ErrorDetails       string     `json:"error_details,omitempty"`
+	TemporalWorkflowID string     `json:"temporal_workflow_id,omitempty"`
+	StartTime          time.Time  `json:"start_time"`
+	EndTime            *time.Time `json:"end_time,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// BackfillSlice represents the BackfillSlices table.
+// One half-open range of a backfill, replicated by its own ReplicationRun.
+type BackfillSlice struct {
+	ID               int64      `json:"id"`
+	BackfillID       int64      `json:"backfill_id"`
+	SliceIndex       int        `json:"slice_index"`
+	RangeStart       string     `json:"range_start"`
+	RangeEnd         string     `json:"range_end"`
+	Status           string     `json:"status"` // e.g., 'pending', 'running', 'completed', 'failed', 'cancelled'
+	ReplicationRunID *int64     `json:"replication_run_id,omitempty"`
+	ErrorDetails     string     `json:"error_details,omitempty"`
+	StartTime        *time.Time `json:"start_time,omitempty"`
+	EndTime          *time.Time `json:"end_time,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Pipeline represents the Pipelines table with its PipelineTasks and PipelineTaskDependencies.
+// Groups replication tasks into a DAG that runs as one unit.
+type Pipeline struct {
+	ID          int64          `json:"id"`
+	Name        string         `json:"name" validate:"required"`
+	Description string         `json:"description,omitempty"`
+	Schedule    string         `json:"schedule,omitempty"` // Cron expression; empty to run on demand only
+	Tasks       []PipelineTask `json:"tasks" validate:"required"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// PipelineTask is a replication task of a pipeline and the tasks it runs after.
+type PipelineTask struct {
+	TaskID    int64   `json:"task_id"`
+	DependsOn []int64 `json:"depends_on,omitempty"`
+	Trigger   string  `json:"trigger,omitempty"` // e.g., 'all_succeeded' (default), 'any_failed', 'always'
+}
+
+// PipelineRun represents the PipelineRuns table.
+// Tracks one execution of a pipeline.
+type PipelineRun struct {
+	ID                 int64      `json:"id"`
+	PipelineID         int64      `json:"pipeline_id"`
+	Status             string     `json:"status"`                  // e.g., 'running', 'completed', 'failed'
+	TaskStatuses       string     `json:"task_statuses,omitempty"` // JSON task ID to status, e.g. {"3": "succeeded"}
+	ErrorDetails       string     `json:"error_details,omitempty"`
+	TemporalWorkflowID string     `json:"temporal_workflow_id,omitempty"`
+	StartTime          time.Time  `json:"start_time"`
+	EndTime            *time.Time `json:"end_time,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
 }
 
 // BenthosConfiguration represents the BenthosConfigurations table.
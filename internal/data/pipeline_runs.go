@@ -0,0 +1,172 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreatePipelineRun inserts a new pipeline run record.
+func (db *DB) CreatePipelineRun(ctx context.Context, run *PipelineRun) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		INSERT INTO PipelineRuns (PipelineID, Status, TaskStatuses, TemporalWorkflowID, StartTime, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ID;`
+
+	now := time.Now()
+	var insertedID int64
+
+	err := db.SQL.QueryRowContext(ctx, query,
+		run.PipelineID,
+		run.Status,
+		sql.NullString{String: run.TaskStatuses, Valid: run.TaskStatuses != ""},
+		sql.NullString{String: run.TemporalWorkflowID, Valid: run.TemporalWorkflowID != ""},
+		run.StartTime,
+		now,
+	).Scan(&insertedID)
+
+	if err != nil {
+		return 0, fmt.Errorf("error creating pipeline run: %w", err)
+	}
+
+	run.ID = insertedID
+	run.CreatedAt = now
+	return insertedID, nil
+}
+
+// GetPipelineRun retrieves a pipeline run by its ID.
+func (db *DB) GetPipelineRun(ctx context.Context, id int64) (*PipelineRun, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, PipelineID, Status, TaskStatuses, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM PipelineRuns
+		WHERE ID = $1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pipeline run %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	runs, err := scanPipelineRuns(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return runs[0], nil
+}
+
+// ListPipelineRuns retrieves a pipeline's runs, most recent first.
+func (db *DB) ListPipelineRuns(ctx context.Context, pipelineID int64) ([]*PipelineRun, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, PipelineID, Status, TaskStatuses, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM PipelineRuns
+		WHERE PipelineID = $1
+		ORDER BY StartTime DESC, ID DESC;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing runs for pipeline %d: %w", pipelineID, err)
+	}
+	defer rows.Close()
+
+	return scanPipelineRuns(rows)
+}
+
+// UpdatePipelineRun stores a pipeline run's status, task statuses, error and end time.
+func (db *DB) UpdatePipelineRun(ctx context.Context, run *PipelineRun) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		UPDATE PipelineRuns
+		SET Status = $1, TaskStatuses = $2, ErrorDetails = $3, EndTime = $4
+		WHERE ID = $5;`
+
+	var endTime sql.NullTime
+	if run.EndTime != nil {
+		endTime = sql.NullTime{Time: *run.EndTime, Valid: true}
+	}
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		run.Status,
+		sql.NullString{String: run.TaskStatuses, Valid: run.TaskStatuses != ""},
+		sql.NullString{String: run.ErrorDetails, Valid: run.ErrorDetails != ""},
+		endTime,
+		run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating pipeline run %d: %w", run.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for pipeline run %d: %w", run.ID, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// scanPipelineRuns reads pipeline run rows selected in the column order used above.
+func scanPipelineRuns(rows *sql.Rows) ([]*PipelineRun, error) {
+	runs := make([]*PipelineRun, 0)
+	for rows.Next() {
+		var run PipelineRun
+		var taskStatuses, errorDetails, workflowID sql.NullString
+		var endTime sql.NullTime
+
+		if err := rows.Scan(
+			&run.ID,
+			&run.PipelineID,
+			&run.Status,
+			&taskStatuses,
+			&errorDetails,
+			&workflowID,
+			&run.StartTime,
+			&endTime,
+			&run.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning pipeline run row: %w", err)
+		}
+
+		if taskStatuses.Valid {
+			run.TaskStatuses = taskStatuses.String
+		}
+		if errorDetails.Valid {
+			run.ErrorDetails = errorDetails.String
+		}
+		if workflowID.Valid {
+			run.TemporalWorkflowID = workflowID.String
+		}
+		if endTime.Valid {
+			run.EndTime = &endTime.Time
+		}
+
+		runs = append(runs, &run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pipeline run rows: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,297 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreatePipeline inserts a new pipeline with its tasks and dependencies in one transaction.
+func (db *DB) CreatePipeline(ctx context.Context, pipeline *Pipeline) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction for pipeline: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO Pipelines (Name, Description, Schedule, CreatedAt, UpdatedAt)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ID;`
+
+	now := time.Now()
+	var insertedID int64
+
+	err = tx.QueryRowContext(ctx, query,
+		pipeline.Name,
+		sql.NullString{String: pipeline.Description, Valid: pipeline.Description != ""},
+		sql.NullString{String: pipeline.Schedule, Valid: pipeline.Schedule != ""},
+		now,
+		now,
+	).Scan(&insertedID)
+	if err != nil {
+		return 0, fmt.Errorf("error creating pipeline: %w", err)
+	}
+
+	if err := insertPipelineTasks(ctx, tx, insertedID, pipeline.Tasks); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing pipeline: %w", err)
+	}
+
+	pipeline.ID = insertedID
+	pipeline.CreatedAt = now
+	pipeline.UpdatedAt = now
+	return insertedID, nil
+}
+
+// GetPipeline retrieves a pipeline and its tasks by the pipeline's ID.
+func (db *DB) GetPipeline(ctx context.Context, id int64) (*Pipeline, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `SELECT ID, Name, Description, Schedule, CreatedAt, UpdatedAt FROM Pipelines WHERE ID = $1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pipeline %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	pipelines, err := scanPipelines(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	tasks, err := db.pipelineTasks(ctx, "WHERE PipelineID = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	pipelines[0].Tasks = tasks[id]
+	return pipelines[0], nil
+}
+
+// ListPipelines retrieves all pipelines and their tasks.
+func (db *DB) ListPipelines(ctx context.Context) ([]*Pipeline, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `SELECT ID, Name, Description, Schedule, CreatedAt, UpdatedAt FROM Pipelines ORDER BY Name;`
+
+	rows, err := db.SQL.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pipelines: %w", err)
+	}
+	defer rows.Close()
+
+	pipelines, err := scanPipelines(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := db.pipelineTasks(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, pipeline := range pipelines {
+		pipeline.Tasks = tasks[pipeline.ID]
+	}
+	return pipelines, nil
+}
+
+// UpdatePipeline updates a pipeline and replaces its tasks and dependencies in one transaction.
+func (db *DB) UpdatePipeline(ctx context.Context, pipeline *Pipeline) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction for pipeline %d: %w", pipeline.ID, err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE Pipelines SET Name = $1, Description = $2, Schedule = $3, UpdatedAt = $4 WHERE ID = $5;`
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, query,
+		pipeline.Name,
+		sql.NullString{String: pipeline.Description, Valid: pipeline.Description != ""},
+		sql.NullString{String: pipeline.Schedule, Valid: pipeline.Schedule != ""},
+		now,
+		pipeline.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating pipeline %d: %w", pipeline.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for pipeline %d: %w", pipeline.ID, err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	// Dependencies go with their tasks through the cascade
+	if _, err := tx.ExecContext(ctx, `DELETE FROM PipelineTasks WHERE PipelineID = $1;`, pipeline.ID); err != nil {
+		return fmt.Errorf("error clearing tasks of pipeline %d: %w", pipeline.ID, err)
+	}
+	if err := insertPipelineTasks(ctx, tx, pipeline.ID, pipeline.Tasks); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing pipeline %d: %w", pipeline.ID, err)
+	}
+
+	pipeline.UpdatedAt = now
+	return nil
+}
+
+// DeletePipeline removes a pipeline by its ID, with its tasks and runs.
+func (db *DB) DeletePipeline(ctx context.Context, id int64) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `DELETE FROM Pipelines WHERE ID = $1;`
+
+	result, err := db.SQL.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error deleting pipeline %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for pipeline %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// insertPipelineTasks stores a pipeline's tasks, in their listed order, and then their
+// dependencies, which reference the tasks.
+func insertPipelineTasks(ctx context.Context, tx *sql.Tx, pipelineID int64, tasks []PipelineTask) error {
+	taskQuery := `
+		INSERT INTO PipelineTasks (PipelineID, ReplicationTaskID, TriggerRule, Position)
+		VALUES ($1, $2, $3, $4);`
+	for i, task := range tasks {
+		if _, err := tx.ExecContext(ctx, taskQuery, pipelineID, task.TaskID, task.Trigger, i); err != nil {
+			return fmt.Errorf("error adding task %d to pipeline %d: %w", task.TaskID, pipelineID, err)
+		}
+	}
+
+	dependencyQuery := `
+		INSERT INTO PipelineTaskDependencies (PipelineID, ReplicationTaskID, DependsOnTaskID)
+		VALUES ($1, $2, $3);`
+	for _, task := range tasks {
+		for _, dependency := range task.DependsOn {
+			if _, err := tx.ExecContext(ctx, dependencyQuery, pipelineID, task.TaskID, dependency); err != nil {
+				return fmt.Errorf("error adding dependency of task %d on task %d to pipeline %d: %w", task.TaskID, dependency, pipelineID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// pipelineTasks reads the tasks and dependencies of the pipelines matched by where, keyed
+// by pipeline ID, with each pipeline's tasks in their listed order.
+func (db *DB) pipelineTasks(ctx context.Context, where string, args ...any) (map[int64][]PipelineTask, error) {
+	rows, err := db.SQL.QueryContext(ctx, `
+		SELECT PipelineID, ReplicationTaskID, TriggerRule
+		FROM PipelineTasks `+where+`
+		ORDER BY PipelineID, Position;`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pipeline tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make(map[int64][]PipelineTask)
+	for rows.Next() {
+		var pipelineID int64
+		var task PipelineTask
+		if err := rows.Scan(&pipelineID, &task.TaskID, &task.Trigger); err != nil {
+			return nil, fmt.Errorf("error scanning pipeline task row: %w", err)
+		}
+		tasks[pipelineID] = append(tasks[pipelineID], task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pipeline task rows: %w", err)
+	}
+
+	dependencyRows, err := db.SQL.QueryContext(ctx, `
+		SELECT PipelineID, ReplicationTaskID, DependsOnTaskID
+		FROM PipelineTaskDependencies `+where+`
+		ORDER BY PipelineID, ReplicationTaskID, DependsOnTaskID;`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pipeline task dependencies: %w", err)
+	}
+	defer dependencyRows.Close()
+
+	for dependencyRows.Next() {
+		var pipelineID, taskID, dependency int64
+		if err := dependencyRows.Scan(&pipelineID, &taskID, &dependency); err != nil {
+			return nil, fmt.Errorf("error scanning pipeline task dependency row: %w", err)
+		}
+		for i := range tasks[pipelineID] {
+			if tasks[pipelineID][i].TaskID == taskID {
+				tasks[pipelineID][i].DependsOn = append(tasks[pipelineID][i].DependsOn, dependency)
+			}
+		}
+	}
+	if err := dependencyRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pipeline task dependency rows: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// scanPipelines reads pipeline rows selected in the column order used above, without tasks.
+func scanPipelines(rows *sql.Rows) ([]*Pipeline, error) {
+	pipelines := make([]*Pipeline, 0)
+	for rows.Next() {
+		var pipeline Pipeline
+		var description, schedule sql.NullString
+
+		if err := rows.Scan(
+			&pipeline.ID,
+			&pipeline.Name,
+			&description,
+			&schedule,
+			&pipeline.CreatedAt,
+			&pipeline.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning pipeline row: %w", err)
+		}
+
+		if description.Valid {
+			pipeline.Description = description.String
+		}
+		if schedule.Valid {
+			pipeline.Schedule = schedule.String
+		}
+
+		pipelines = append(pipelines, &pipeline)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pipeline rows: %w", err)
+	}
+
+	return pipelines, nil
+}
@@ -0,0 +1,203 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateRefresh inserts a new refresh record.
+func (db *DB) CreateRefresh(ctx context.Context, refresh *Refresh) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		INSERT INTO Refreshes (ReplicationTaskID, CompareReportID, Status, Options, StartTime, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ID;`
+
+	now := time.Now()
+	var insertedID int64
+
+	err := db.SQL.QueryRowContext(ctx, query,
+		refresh.ReplicationTaskID,
+		refresh.CompareReportID,
+		refresh.Status,
+		sql.NullString{String: refresh.Options, Valid: refresh.Options != ""},
+		refresh.StartTime,
+		now,
+	).Scan(&insertedID)
+
+	if err != nil {
+		return 0, fmt.Errorf("error creating refresh: %w", err)
+	}
+
+	refresh.ID = insertedID
+	refresh.CreatedAt = now
+	return insertedID, nil
+}
+
+// GetRefresh retrieves a refresh by its ID.
+func (db *DB) GetRefresh(ctx context.Context, id int64) (*Refresh, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, CompareReportID, Status, Options, TotalRanges, CompletedRanges, RowsDeleted, RowsWritten,
+		       SkippedTables, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM Refreshes
+		WHERE ID = $1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting refresh %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	refreshes, err := scanRefreshes(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(refreshes) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return refreshes[0], nil
+}
+
+// ListRefreshesForTask retrieves a task's refreshes, most recent first.
+func (db *DB) ListRefreshesForTask(ctx context.Context, taskID int64) ([]*Refresh, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, CompareReportID, Status, Options, TotalRanges, CompletedRanges, RowsDeleted, RowsWritten,
+		       SkippedTables, ErrorDetails, TemporalWorkflowID, StartTime, EndTime, CreatedAt
+		FROM Refreshes
+		WHERE ReplicationTaskID = $1
+		ORDER BY StartTime DESC;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing refreshes for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	return scanRefreshes(rows)
+}
+
+// UpdateRefresh stores a refresh's status, plan size, skipped tables, error and workflow ID.
+// Progress counters are only changed through AddRefreshProgress.
+func (db *DB) UpdateRefresh(ctx context.Context, refresh *Refresh) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		UPDATE Refreshes
+		SET Status = $1, TotalRanges = $2, SkippedTables = $3, ErrorDetails = $4, TemporalWorkflowID = $5, EndTime = $6
+		WHERE ID = $7;`
+
+	var endTime sql.NullTime
+	if refresh.EndTime != nil {
+		endTime = sql.NullTime{Time: *refresh.EndTime, Valid: true}
+	}
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		refresh.Status,
+		refresh.TotalRanges,
+		sql.NullString{String: refresh.SkippedTables, Valid: refresh.SkippedTables != ""},
+		sql.NullString{String: refresh.ErrorDetails, Valid: refresh.ErrorDetails != ""},
+		sql.NullString{String: refresh.TemporalWorkflowID, Valid: refresh.TemporalWorkflowID != ""},
+		endTime,
+		refresh.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating refresh %d: %w", refresh.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for refresh %d: %w", refresh.ID, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// AddRefreshProgress adds refreshed ranges and row counts to a refresh.
+func (db *DB) AddRefreshProgress(ctx context.Context, id int64, ranges int, rowsDeleted, rowsWritten int64) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		UPDATE Refreshes
+		SET CompletedRanges = CompletedRanges + $1, RowsDeleted = RowsDeleted + $2, RowsWritten = RowsWritten + $3
+		WHERE ID = $4;`
+
+	if _, err := db.SQL.ExecContext(ctx, query, ranges, rowsDeleted, rowsWritten, id); err != nil {
+		return fmt.Errorf("error updating progress of refresh %d: %w", id, err)
+	}
+	return nil
+}
+
+// scanRefreshes reads refresh rows selected in the column order used above.
+func scanRefreshes(rows *sql.Rows) ([]*Refresh, error) {
+	refreshes := make([]*Refresh, 0)
+	for rows.Next() {
+		var refresh Refresh
+		var options, skippedTables, errorDetails, workflowID sql.NullString
+		var endTime sql.NullTime
+
+		if err := rows.Scan(
+			&refresh.ID,
+			&refresh.ReplicationTaskID,
+			&refresh.CompareReportID,
+			&refresh.Status,
+			&options,
+			&refresh.TotalRanges,
+			&refresh.CompletedRanges,
+			&refresh.RowsDeleted,
+			&refresh.RowsWritten,
+			&skippedTables,
+			&errorDetails,
+			&workflowID,
+			&refresh.StartTime,
+			&endTime,
+			&refresh.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning refresh row: %w", err)
+		}
+
+		if options.Valid {
+			refresh.Options = options.String
+		}
+		if skippedTables.Valid {
+			refresh.SkippedTables = skippedTables.String
+		}
+		if errorDetails.Valid {
+			refresh.ErrorDetails = errorDetails.String
+		}
+		if workflowID.Valid {
+			refresh.TemporalWorkflowID = workflowID.String
+		}
+		if endTime.Valid {
+			refresh.EndTime = &endTime.Time
+		}
+
+		refreshes = append(refreshes, &refresh)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating refresh rows: %w", err)
+	}
+
+	return refreshes, nil
+}
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateReplicationLagSample records a lag measurement of a streaming task.
+func (db *DB) CreateReplicationLagSample(ctx context.Context, sample *ReplicationLagSample) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		INSERT INTO ReplicationLagSamples (ReplicationTaskID, ReplicationRunID, LatencyMs, Backlog, BacklogUnit,
+			SourcePosition, TargetPosition, MeasuredAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ID;`
+
+	var insertedID int64
+	err := db.SQL.QueryRowContext(ctx, query,
+		sample.ReplicationTaskID,
+		sample.ReplicationRunID,
+		sample.LatencyMs,
+		sample.Backlog,
+		sample.BacklogUnit,
+		sql.NullString{String: sample.SourcePosition, Valid: sample.SourcePosition != ""},
+		sql.NullString{String: sample.TargetPosition, Valid: sample.TargetPosition != ""},
+		sample.MeasuredAt,
+	).Scan(&insertedID)
+
+	if err != nil {
+		return 0, fmt.Errorf("error creating replication lag sample: %w", err)
+	}
+
+	sample.ID = insertedID
+	return insertedID, nil
+}
+
+// ListReplicationLagSamples retrieves a task's most recent samples measured since a time, at
+// most limit of them, oldest first.
+func (db *DB) ListReplicationLagSamples(ctx context.Context, taskID int64, since time.Time, limit int) ([]*ReplicationLagSample, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, ReplicationRunID, LatencyMs, Backlog, BacklogUnit, SourcePosition, TargetPosition, MeasuredAt
+		FROM (
+			SELECT ID, ReplicationTaskID, ReplicationRunID, LatencyMs, Backlog, BacklogUnit, SourcePosition, TargetPosition, MeasuredAt
+			FROM ReplicationLagSamples
+			WHERE ReplicationTaskID = $1 AND MeasuredAt >= $2
+			ORDER BY MeasuredAt DESC, ID DESC
+			LIMIT $3
+		) recent
+		ORDER BY MeasuredAt, ID;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, taskID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing replication lag samples for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	return scanReplicationLagSamples(rows)
+}
+
+// GetLatestReplicationLagSample retrieves a task's most recent sample.
+func (db *DB) GetLatestReplicationLagSample(ctx context.Context, taskID int64) (*ReplicationLagSample, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, ReplicationRunID, LatencyMs, Backlog, BacklogUnit, SourcePosition, TargetPosition, MeasuredAt
+		FROM ReplicationLagSamples
+		WHERE ReplicationTaskID = $1
+		ORDER BY MeasuredAt DESC, ID DESC
+		LIMIT 1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting replication lag sample for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	samples, err := scanReplicationLagSamples(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return samples[0], nil
+}
+
+// ListLatestReplicationLagSamples retrieves the most recent sample of every task that streams.
+func (db *DB) ListLatestReplicationLagSamples(ctx context.Context) ([]*ReplicationLagSample, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT DISTINCT ON (s.ReplicationTaskID)
+			s.ID, s.ReplicationTaskID, s.ReplicationRunID, s.LatencyMs, s.Backlog, s.BacklogUnit, s.SourcePosition, s.TargetPosition, s.MeasuredAt
+		FROM ReplicationLagSamples s
+		JOIN ReplicationTasks t ON t.ID = s.ReplicationTaskID
+		WHERE TRIM(COALESCE(t.StreamConfig, '')) <> ''
+		ORDER BY s.ReplicationTaskID, s.MeasuredAt DESC, s.ID DESC;`
+
+	rows, err := db.SQL.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error listing latest replication lag samples: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReplicationLagSamples(rows)
+}
+
+// DeleteReplicationLagSamples removes a task's samples measured before a time, returning how
+// many were removed.
+func (db *DB) DeleteReplicationLagSamples(ctx context.Context, taskID int64, before time.Time) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `DELETE FROM ReplicationLagSamples WHERE ReplicationTaskID = $1 AND MeasuredAt < $2;`
+
+	result, err := db.SQL.ExecContext(ctx, query, taskID, before)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting replication lag samples for task %d: %w", taskID, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error counting deleted replication lag samples for task %d: %w", taskID, err)
+	}
+	return deleted, nil
+}
+
+// scanReplicationLagSamples reads lag sample rows selected in the column order used above.
+func scanReplicationLagSamples(rows *sql.Rows) ([]*ReplicationLagSample, error) {
+	samples := make([]*ReplicationLagSample, 0)
+	for rows.Next() {
+		var sample ReplicationLagSample
+		var runID, latencyMs, backlog sql.NullInt64
+		var sourcePosition, targetPosition sql.NullString
+
+		if err := rows.Scan(
+			&sample.ID,
+			&sample.ReplicationTaskID,
+			&runID,
+			&latencyMs,
+			&backlog,
+			&sample.BacklogUnit,
+			&sourcePosition,
+			&targetPosition,
+			&sample.MeasuredAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning replication lag sample row: %w", err)
+		}
+
+		if runID.Valid {
+			sample.ReplicationRunID = &runID.Int64
+		}
+		if latencyMs.Valid {
+			sample.LatencyMs = &latencyMs.Int64
+		}
+		if backlog.Valid {
+			sample.Backlog = &backlog.Int64
+		}
+		sample.SourcePosition = sourcePosition.String
+		sample.TargetPosition = targetPosition.String
+
+		samples = append(samples, &sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating replication lag sample rows: %w", err)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// UpdateReplicationRunCriteria records the data selection criteria a run rendered from the
+// task's template, so retries and resumes of the run use the same criteria.
+func (db *DB) UpdateReplicationRunCriteria(ctx context.Context, id int64, criteria string) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationRuns SET RenderedCriteria = $1 WHERE ID = $2;`
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		sql.NullString{String: criteria, Valid: criteria != ""},
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating rendered criteria for replication run %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for run %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
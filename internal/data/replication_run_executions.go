@@ -0,0 +1,36 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// UpdateReplicationRunExecution records the Temporal workflow execution carrying out a run.
+func (db *DB) UpdateReplicationRunExecution(ctx context.Context, id int64, workflowID string, runID string) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationRuns SET TemporalWorkflowID = $1, TemporalRunID = $2 WHERE ID = $3;`
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		sql.NullString{String: workflowID, Valid: workflowID != ""},
+		sql.NullString{String: runID, Valid: runID != ""},
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating temporal execution for replication run %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for run %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
@@ -15,18 +15,30 @@ func (db *DB) CreateReplicationRun(ctx context.Context, run *ReplicationRun) (in
 	}
 
 	query := `
-		INSERT INTO ReplicationRuns (ReplicationTaskID, StartTime, Status, TemporalRunID, CreatedAt)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO ReplicationRuns (ReplicationTaskID, StartTime, Status, TemporalWorkflowID, TemporalRunID, ParentRunID, TableName, Parameters, LogicalTime, RenderedCriteria, RerunOfRunID, Streaming, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING ID;`
 
 	now := time.Now()
 	var insertedID int64
+	var logicalTime sql.NullTime
+	if run.LogicalTime != nil {
+		logicalTime = sql.NullTime{Time: *run.LogicalTime, Valid: true}
+	}
 
 	err := db.SQL.QueryRowContext(ctx, query,
 		run.ReplicationTaskID,
 		run.StartTime,
 		run.Status,
+		sql.NullString{String: run.TemporalWorkflowID, Valid: run.TemporalWorkflowID != ""},
 		sql.NullString{String: run.TemporalRunID, Valid: run.TemporalRunID != ""},
+		run.ParentRunID, // nil for top-level runs
+		sql.NullString{String: run.TableName, Valid: run.TableName != ""},
+		sql.NullString{String: run.Parameters, Valid: run.Parameters != ""},
+		logicalTime,
+		sql.NullString{String: run.RenderedCriteria, Valid: run.RenderedCriteria != ""},
+		run.RerunOfRunID, // nil unless the run reruns another
+		run.Streaming,
 		now,
 	).Scan(&insertedID)
 
@@ -46,14 +58,15 @@ func (db *DB) GetReplicationRun(ctx context.Context, id int64) (*ReplicationRun,
 	}
 
 	query := `
-		SELECT ID, ReplicationTaskID, StartTime, EndTime, Status, ErrorDetails, TemporalRunID, CreatedAt
+		SELECT ID, ReplicationTaskID, StartTime, EndTime, Status, ErrorDetails, TemporalWorkflowID, TemporalRunID, ParentRunID, TableName, SchemaDrift, SourceFiles, Parameters, LogicalTime, RenderedCriteria, RerunOfRunID, Streaming, RowsRead, RowsWritten, CreatedAt
 		FROM ReplicationRuns
 		WHERE ID = $1;`
 
 	row := db.SQL.QueryRowContext(ctx, query, id)
 	var run ReplicationRun
-	var endTime sql.NullTime
-	var errorDetails, temporalRunID sql.NullString
+	var endTime, logicalTime sql.NullTime
+	var errorDetails, temporalWorkflowID, temporalRunID, tableName, schemaDrift, sourceFiles, parameters, renderedCriteria sql.NullString
+	var parentRunID, rerunOfRunID sql.NullInt64
 
 	err := row.Scan(
 		&run.ID,
@@ -62,7 +75,19 @@ func (db *DB) GetReplicationRun(ctx context.Context, id int64) (*ReplicationRun,
 		&endTime,
 		&run.Status,
 		&errorDetails,
+		&temporalWorkflowID,
 		&temporalRunID,
+		&parentRunID,
+		&tableName,
+		&schemaDrift,
+		&sourceFiles,
+		&parameters,
+		&logicalTime,
+		&renderedCriteria,
+		&rerunOfRunID,
+		&run.Streaming,
+		&run.RowsRead,
+		&run.RowsWritten,
 		&run.CreatedAt,
 	)
 
@@ -79,9 +104,36 @@ func (db *DB) GetReplicationRun(ctx context.Context, id int64) (*ReplicationRun,
 	if errorDetails.Valid {
 		run.ErrorDetails = errorDetails.String
 	}
+	if temporalWorkflowID.Valid {
+		run.TemporalWorkflowID = temporalWorkflowID.String
+	}
 	if temporalRunID.Valid {
 		run.TemporalRunID = temporalRunID.String
 	}
+	if parentRunID.Valid {
+		run.ParentRunID = &parentRunID.Int64
+	}
+	if tableName.Valid {
+		run.TableName = tableName.String
+	}
+	if schemaDrift.Valid {
+		run.SchemaDrift = schemaDrift.String
+	}
+	if sourceFiles.Valid {
+		run.SourceFiles = sourceFiles.String
+	}
+	if parameters.Valid {
+		run.Parameters = parameters.String
+	}
+	if logicalTime.Valid {
+		run.LogicalTime = &logicalTime.Time
+	}
+	if renderedCriteria.Valid {
+		run.RenderedCriteria = renderedCriteria.String
+	}
+	if rerunOfRunID.Valid {
+		run.RerunOfRunID = &rerunOfRunID.Int64
+	}
 
 	return &run, nil
 }
@@ -93,10 +145,10 @@ func (db *DB) ListReplicationRunsForTask(ctx context.Context, taskID int64) ([]*
 	}
 
 	query := `
-		SELECT ID, ReplicationTaskID, StartTime, EndTime, Status, ErrorDetails, TemporalRunID, CreatedAt
+		SELECT ID, ReplicationTaskID, StartTime, EndTime, Status, ErrorDetails, TemporalWorkflowID, TemporalRunID, ParentRunID, TableName, SchemaDrift, SourceFiles, Parameters, LogicalTime, RenderedCriteria, RerunOfRunID, Streaming, RowsRead, RowsWritten, CreatedAt
 		FROM ReplicationRuns
-		WHERE ReplicationTaskID = $1
-		ORDER BY StartTime DESC;` // Show most recent first
+		WHERE ReplicationTaskID = $1 AND ParentRunID IS NULL
+		ORDER BY StartTime DESC;` // Show most recent first; per-table runs are listed under their parent
 
 	rows, err := db.SQL.QueryContext(ctx, query, taskID)
 	if err != nil {
@@ -104,11 +156,38 @@ func (db *DB) ListReplicationRunsForTask(ctx context.Context, taskID int64) ([]*
 	}
 	defer rows.Close()
 
+	return scanReplicationRuns(rows)
+}
+
+// ListChildReplicationRuns retrieves the per-table runs recorded under a multi-table run.
+func (db *DB) ListChildReplicationRuns(ctx context.Context, parentRunID int64) ([]*ReplicationRun, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, StartTime, EndTime, Status, ErrorDetails, TemporalWorkflowID, TemporalRunID, ParentRunID, TableName, SchemaDrift, SourceFiles, Parameters, LogicalTime, RenderedCriteria, RerunOfRunID, Streaming, RowsRead, RowsWritten, CreatedAt
+		FROM ReplicationRuns
+		WHERE ParentRunID = $1
+		ORDER BY TableName;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, parentRunID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing table runs for run %d: %w", parentRunID, err)
+	}
+	defer rows.Close()
+
+	return scanReplicationRuns(rows)
+}
+
+// scanReplicationRuns reads replication run rows selected in the column order used above.
+func scanReplicationRuns(rows *sql.Rows) ([]*ReplicationRun, error) {
 	runs := make([]*ReplicationRun, 0)
 	for rows.Next() {
 		var run ReplicationRun
-		var endTime sql.NullTime
-		var errorDetails, temporalRunID sql.NullString
+		var endTime, logicalTime sql.NullTime
+		var errorDetails, temporalWorkflowID, temporalRunID, tableName, schemaDrift, sourceFiles, parameters, renderedCriteria sql.NullString
+		var parentRunID, rerunOfRunID sql.NullInt64
 
 		if err := rows.Scan(
 			&run.ID,
@@ -117,7 +196,19 @@ func (db *DB) ListReplicationRunsForTask(ctx context.Context, taskID int64) ([]*
 			&endTime,
 			&run.Status,
 			&errorDetails,
+			&temporalWorkflowID,
 			&temporalRunID,
+			&parentRunID,
+			&tableName,
+			&schemaDrift,
+			&sourceFiles,
+			&parameters,
+			&logicalTime,
+			&renderedCriteria,
+			&rerunOfRunID,
+			&run.Streaming,
+			&run.RowsRead,
+			&run.RowsWritten,
 			&run.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("error scanning replication run row: %w", err)
@@ -129,14 +220,41 @@ func (db *DB) ListReplicationRunsForTask(ctx context.Context, taskID int64) ([]*
 		if errorDetails.Valid {
 			run.ErrorDetails = errorDetails.String
 		}
+		if temporalWorkflowID.Valid {
+			run.TemporalWorkflowID = temporalWorkflowID.String
+		}
 		if temporalRunID.Valid {
 			run.TemporalRunID = temporalRunID.String
 		}
+		if parentRunID.Valid {
+			run.ParentRunID = &parentRunID.Int64
+		}
+		if tableName.Valid {
+			run.TableName = tableName.String
+		}
+		if schemaDrift.Valid {
+			run.SchemaDrift = schemaDrift.String
+		}
+		if sourceFiles.Valid {
+			run.SourceFiles = sourceFiles.String
+		}
+		if parameters.Valid {
+			run.Parameters = parameters.String
+		}
+		if logicalTime.Valid {
+			run.LogicalTime = &logicalTime.Time
+		}
+		if renderedCriteria.Valid {
+			run.RenderedCriteria = renderedCriteria.String
+		}
+		if rerunOfRunID.Valid {
+			run.RerunOfRunID = &rerunOfRunID.Int64
+		}
 
 		runs = append(runs, &run)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating replication run rows: %w", err)
 	}
 
@@ -182,3 +300,85 @@ func (db *DB) UpdateReplicationRunStatus(ctx context.Context, id int64, status s
 
 	return nil
 }
+
+// UpdateReplicationRunSchemaDrift records the source schema drift a run detected.
+func (db *DB) UpdateReplicationRunSchemaDrift(ctx context.Context, id int64, drift string) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationRuns SET SchemaDrift = $1 WHERE ID = $2;`
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		sql.NullString{String: drift, Valid: drift != ""},
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating schema drift for replication run %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for run %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// UpdateReplicationRunSourceFiles records the source files a run pinned, so retries and
+// resumes of the run read the same files.
+func (db *DB) UpdateReplicationRunSourceFiles(ctx context.Context, id int64, files string) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationRuns SET SourceFiles = $1 WHERE ID = $2;`
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		sql.NullString{String: files, Valid: files != ""},
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating source files for replication run %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for run %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// UpdateReplicationRunCounts records how many records a micro-run read and wrote.
+func (db *DB) UpdateReplicationRunCounts(ctx context.Context, id int64, rowsRead int64, rowsWritten int64) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationRuns SET RowsRead = $1, RowsWritten = $2 WHERE ID = $3;`
+
+	result, err := db.SQL.ExecContext(ctx, query, rowsRead, rowsWritten, id)
+	if err != nil {
+		return fmt.Errorf("error updating counts for replication run %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for run %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
@@ -15,8 +15,8 @@ func (db *DB) CreateReplicationTask(ctx context.Context, task *ReplicationTask)
 	}
 
 	query := `
-		INSERT INTO ReplicationTasks (Name, SourceConnectionID, TargetConnectionID, Schedule, DataSelectionCriteria, TransformationRules, Status, CreatedAt, UpdatedAt)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO ReplicationTasks (Name, SourceConnectionID, TargetConnectionID, Schedule, DataSelectionCriteria, TransformationRules, TableRules, ColumnTypes, DriftPolicy, Partitioning, ExecutionPolicy, StreamConfig, MaxConcurrentRuns, Status, CreatedAt, UpdatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING ID;`
 
 	now := time.Now()
@@ -29,7 +29,14 @@ func (db *DB) CreateReplicationTask(ctx context.Context, task *ReplicationTask)
 		task.Schedule,              // Use value directly
 		task.DataSelectionCriteria, // Use value directly
 		task.TransformationRules,   // Use value directly
-		"inactive",                 // Default status on creation
+		sql.NullString{String: task.TableRules, Valid: task.TableRules != ""},
+		sql.NullString{String: task.ColumnTypes, Valid: task.ColumnTypes != ""},
+		sql.NullString{String: task.DriftPolicy, Valid: task.DriftPolicy != ""},
+		sql.NullString{String: task.Partitioning, Valid: task.Partitioning != ""},
+		sql.NullString{String: task.ExecutionPolicy, Valid: task.ExecutionPolicy != ""},
+		sql.NullString{String: task.StreamConfig, Valid: task.StreamConfig != ""},
+		task.MaxConcurrentRuns,
+		"inactive", // Default status on creation
 		now,
 		now,
 	).Scan(&insertedID)
@@ -52,14 +59,14 @@ func (db *DB) GetReplicationTask(ctx context.Context, id int64) (*ReplicationTas
 	}
 
 	query := `
-		SELECT ID, Name, SourceConnectionID, TargetConnectionID, Schedule, DataSelectionCriteria, TransformationRules, TemporalWorkflowID, Status, CreatedAt, UpdatedAt
+		SELECT ID, Name, SourceConnectionID, TargetConnectionID, Schedule, DataSelectionCriteria, TransformationRules, TableRules, ColumnTypes, DriftPolicy, Partitioning, ExecutionPolicy, StreamConfig, MaxConcurrentRuns, TemporalWorkflowID, Watermark, SkipNextRun, Status, CreatedAt, UpdatedAt
 		FROM ReplicationTasks
 		WHERE ID = $1;`
 
 	row := db.SQL.QueryRowContext(ctx, query, id)
 	var task ReplicationTask
 	// Use sql.NullString for potentially nullable string fields
-	var schedule, dataSelection, transformRules, temporalWorkflowID sql.NullString
+	var schedule, dataSelection, transformRules, tableRules, columnTypes, driftPolicy, partitioning, executionPolicy, streamConfig, temporalWorkflowID, watermark sql.NullString
 
 	err := row.Scan(
 		&task.ID,
@@ -69,7 +76,16 @@ func (db *DB) GetReplicationTask(ctx context.Context, id int64) (*ReplicationTas
 		&schedule,
 		&dataSelection,
 		&transformRules,
+		&tableRules,
+		&columnTypes,
+		&driftPolicy,
+		&partitioning,
+		&executionPolicy,
+		&streamConfig,
+		&task.MaxConcurrentRuns,
 		&temporalWorkflowID,
+		&watermark,
+		&task.SkipNextRun,
 		&task.Status,
 		&task.CreatedAt,
 		&task.UpdatedAt,
@@ -92,9 +108,30 @@ func (db *DB) GetReplicationTask(ctx context.Context, id int64) (*ReplicationTas
 	if transformRules.Valid {
 		task.TransformationRules = transformRules.String
 	}
+	if tableRules.Valid {
+		task.TableRules = tableRules.String
+	}
+	if columnTypes.Valid {
+		task.ColumnTypes = columnTypes.String
+	}
+	if driftPolicy.Valid {
+		task.DriftPolicy = driftPolicy.String
+	}
+	if partitioning.Valid {
+		task.Partitioning = partitioning.String
+	}
+	if executionPolicy.Valid {
+		task.ExecutionPolicy = executionPolicy.String
+	}
+	if streamConfig.Valid {
+		task.StreamConfig = streamConfig.String
+	}
 	if temporalWorkflowID.Valid {
 		task.TemporalWorkflowID = temporalWorkflowID.String
 	}
+	if watermark.Valid {
+		task.Watermark = watermark.String
+	}
 
 	return &task, nil
 }
@@ -106,7 +143,7 @@ func (db *DB) ListReplicationTasks(ctx context.Context) ([]*ReplicationTask, err
 	}
 
 	query := `
-		SELECT ID, Name, SourceConnectionID, TargetConnectionID, Schedule, DataSelectionCriteria, TransformationRules, TemporalWorkflowID, Status, CreatedAt, UpdatedAt
+		SELECT ID, Name, SourceConnectionID, TargetConnectionID, Schedule, DataSelectionCriteria, TransformationRules, TableRules, ColumnTypes, DriftPolicy, Partitioning, ExecutionPolicy, StreamConfig, MaxConcurrentRuns, TemporalWorkflowID, Watermark, SkipNextRun, Status, CreatedAt, UpdatedAt
 		FROM ReplicationTasks
 		ORDER BY Name;`
 
@@ -119,7 +156,7 @@ func (db *DB) ListReplicationTasks(ctx context.Context) ([]*ReplicationTask, err
 	tasks := make([]*ReplicationTask, 0)
 	for rows.Next() {
 		var task ReplicationTask
-		var schedule, dataSelection, transformRules, temporalWorkflowID sql.NullString
+		var schedule, dataSelection, transformRules, tableRules, columnTypes, driftPolicy, partitioning, executionPolicy, streamConfig, temporalWorkflowID, watermark sql.NullString
 
 		if err := rows.Scan(
 			&task.ID,
@@ -129,7 +166,16 @@ func (db *DB) ListReplicationTasks(ctx context.Context) ([]*ReplicationTask, err
 			&schedule,
 			&dataSelection,
 			&transformRules,
+			&tableRules,
+			&columnTypes,
+			&driftPolicy,
+			&partitioning,
+			&executionPolicy,
+			&streamConfig,
+			&task.MaxConcurrentRuns,
 			&temporalWorkflowID,
+			&watermark,
+			&task.SkipNextRun,
 			&task.Status,
 			&task.CreatedAt,
 			&task.UpdatedAt,
@@ -146,9 +192,30 @@ func (db *DB) ListReplicationTasks(ctx context.Context) ([]*ReplicationTask, err
 		if transformRules.Valid {
 			task.TransformationRules = transformRules.String
 		}
+		if tableRules.Valid {
+			task.TableRules = tableRules.String
+		}
+		if columnTypes.Valid {
+			task.ColumnTypes = columnTypes.String
+		}
+		if driftPolicy.Valid {
+			task.DriftPolicy = driftPolicy.String
+		}
+		if partitioning.Valid {
+			task.Partitioning = partitioning.String
+		}
+		if executionPolicy.Valid {
+			task.ExecutionPolicy = executionPolicy.String
+		}
+		if streamConfig.Valid {
+			task.StreamConfig = streamConfig.String
+		}
 		if temporalWorkflowID.Valid {
 			task.TemporalWorkflowID = temporalWorkflowID.String
 		}
+		if watermark.Valid {
+			task.Watermark = watermark.String
+		}
 
 		tasks = append(tasks, &task)
 	}
@@ -171,8 +238,8 @@ func (db *DB) UpdateReplicationTask(ctx context.Context, task *ReplicationTask)
 		UPDATE ReplicationTasks
 		SET Name = $1, SourceConnectionID = $2, TargetConnectionID = $3,
 		    Schedule = $4, DataSelectionCriteria = $5, TransformationRules = $6,
-		    TemporalWorkflowID = $7, Status = $8, UpdatedAt = $9
-		WHERE ID = $10;`
+		    TableRules = $7, ColumnTypes = $8, DriftPolicy = $9, Partitioning = $10, ExecutionPolicy = $11, StreamConfig = $12, MaxConcurrentRuns = $13, TemporalWorkflowID = $14, Status = $15, UpdatedAt = $16
+		WHERE ID = $17;`
 
 	now := time.Now()
 	result, err := db.SQL.ExecContext(ctx, query,
@@ -182,6 +249,13 @@ func (db *DB) UpdateReplicationTask(ctx context.Context, task *ReplicationTask)
 		sql.NullString{String: task.Schedule, Valid: task.Schedule != ""}, // Handle potential empty strings
 		sql.NullString{String: task.DataSelectionCriteria, Valid: task.DataSelectionCriteria != ""},
 		sql.NullString{String: task.TransformationRules, Valid: task.TransformationRules != ""},
+		sql.NullString{String: task.TableRules, Valid: task.TableRules != ""},
+		sql.NullString{String: task.ColumnTypes, Valid: task.ColumnTypes != ""},
+		sql.NullString{String: task.DriftPolicy, Valid: task.DriftPolicy != ""},
+		sql.NullString{String: task.Partitioning, Valid: task.Partitioning != ""},
+		sql.NullString{String: task.ExecutionPolicy, Valid: task.ExecutionPolicy != ""},
+		sql.NullString{String: task.StreamConfig, Valid: task.StreamConfig != ""},
+		task.MaxConcurrentRuns,
 		sql.NullString{String: task.TemporalWorkflowID, Valid: task.TemporalWorkflowID != ""},
 		task.Status,
 		now,
@@ -205,6 +279,80 @@ func (db *DB) UpdateReplicationTask(ctx context.Context, task *ReplicationTask)
 	return nil
 }
 
+// UpdateReplicationTaskWatermark stores the incremental position reached by a successful run.
+// It is kept separate from UpdateReplicationTask so API updates never reset the watermark.
+func (db *DB) UpdateReplicationTaskWatermark(ctx context.Context, id int64, watermark string) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationTasks SET Watermark = $1 WHERE ID = $2;`
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		sql.NullString{String: watermark, Valid: watermark != ""},
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating watermark for replication task %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for task %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// UpdateReplicationTaskControl sets a task's status and whether its next run is skipped.
+func (db *DB) UpdateReplicationTaskControl(ctx context.Context, id int64, status string, skipNextRun bool) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationTasks SET Status = $1, SkipNextRun = $2, UpdatedAt = $3 WHERE ID = $4;`
+
+	result, err := db.SQL.ExecContext(ctx, query, status, skipNextRun, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("error updating status for replication task %d: %w", id, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for task %d: %w", id, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// ConsumeReplicationTaskSkipNext clears a task's skip-next flag, reporting whether it was set.
+// Only one of concurrent callers sees it set.
+func (db *DB) ConsumeReplicationTaskSkipNext(ctx context.Context, id int64) (bool, error) {
+	if db == nil || db.SQL == nil {
+		return false, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `UPDATE ReplicationTasks SET SkipNextRun = FALSE WHERE ID = $1 AND SkipNextRun RETURNING ID;`
+
+	var taskID int64
+	err := db.SQL.QueryRowContext(ctx, query, id).Scan(&taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error clearing skip-next for replication task %d: %w", id, err)
+	}
+	return true, nil
+}
+
 // DeleteReplicationTask removes a replication task record by its ID.
 func (db *DB) DeleteReplicationTask(ctx context.Context, id int64) error {
 	if db == nil || db.SQL == nil {
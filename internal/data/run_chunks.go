@@ -0,0 +1,152 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateReplicationRunChunks inserts the chunks of a partitioned run in one transaction,
+// setting their IDs.
+func (db *DB) CreateReplicationRunChunks(ctx context.Context, chunks []*ReplicationRunChunk) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	tx, err := db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction for run chunks: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO ReplicationRunChunks (ReplicationRunID, ChunkIndex, Predicate, Status, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ID;`
+
+	now := time.Now()
+	for _, chunk := range chunks {
+		err := tx.QueryRowContext(ctx, query,
+			chunk.ReplicationRunID,
+			chunk.ChunkIndex,
+			chunk.Predicate,
+			chunk.Status,
+			now,
+		).Scan(&chunk.ID)
+		if err != nil {
+			return fmt.Errorf("error creating chunk %d of run %d: %w", chunk.ChunkIndex, chunk.ReplicationRunID, err)
+		}
+		chunk.CreatedAt = now
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing run chunks: %w", err)
+	}
+	return nil
+}
+
+// ListReplicationRunChunks retrieves a run's chunks in chunk order.
+func (db *DB) ListReplicationRunChunks(ctx context.Context, runID int64) ([]*ReplicationRunChunk, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationRunID, ChunkIndex, Predicate, Status, ErrorDetails, StartTime, EndTime, CreatedAt
+		FROM ReplicationRunChunks
+		WHERE ReplicationRunID = $1
+		ORDER BY ChunkIndex;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing chunks for run %d: %w", runID, err)
+	}
+	defer rows.Close()
+
+	return scanReplicationRunChunks(rows)
+}
+
+// UpdateReplicationRunChunk stores a chunk's status, error and start and end times.
+func (db *DB) UpdateReplicationRunChunk(ctx context.Context, chunk *ReplicationRunChunk) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		UPDATE ReplicationRunChunks
+		SET Status = $1, ErrorDetails = $2, StartTime = $3, EndTime = $4
+		WHERE ID = $5;`
+
+	var startTime, endTime sql.NullTime
+	if chunk.StartTime != nil {
+		startTime = sql.NullTime{Time: *chunk.StartTime, Valid: true}
+	}
+	if chunk.EndTime != nil {
+		endTime = sql.NullTime{Time: *chunk.EndTime, Valid: true}
+	}
+
+	result, err := db.SQL.ExecContext(ctx, query,
+		chunk.Status,
+		sql.NullString{String: chunk.ErrorDetails, Valid: chunk.ErrorDetails != ""},
+		startTime,
+		endTime,
+		chunk.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating run chunk %d: %w", chunk.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected for run chunk %d: %w", chunk.ID, err)
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows // ID not found
+	}
+
+	return nil
+}
+
+// scanReplicationRunChunks reads chunk rows selected in the column order used above.
+func scanReplicationRunChunks(rows *sql.Rows) ([]*ReplicationRunChunk, error) {
+	chunks := make([]*ReplicationRunChunk, 0)
+	for rows.Next() {
+		var chunk ReplicationRunChunk
+		var errorDetails sql.NullString
+		var startTime, endTime sql.NullTime
+
+		if err := rows.Scan(
+			&chunk.ID,
+			&chunk.ReplicationRunID,
+			&chunk.ChunkIndex,
+			&chunk.Predicate,
+			&chunk.Status,
+			&errorDetails,
+			&startTime,
+			&endTime,
+			&chunk.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning run chunk row: %w", err)
+		}
+
+		if errorDetails.Valid {
+			chunk.ErrorDetails = errorDetails.String
+		}
+		if startTime.Valid {
+			chunk.StartTime = &startTime.Time
+		}
+		if endTime.Valid {
+			chunk.EndTime = &endTime.Time
+		}
+
+		chunks = append(chunks, &chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating run chunk rows: %w", err)
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,162 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Resource types a run can hold a lease on.
+const (
+	LeaseResourceConnection = "connection"
+	LeaseResourceTask       = "task"
+)
+
+// Types of lease holders.
+const (
+	LeaseHolderRun      = "run"
+	LeaseHolderBackfill = "backfill"
+	LeaseHolderCompare  = "compare"
+	LeaseHolderRefresh  = "refresh"
+)
+
+// leaseHolderColumns are the RunLeases columns referencing each type of holder.
+var leaseHolderColumns = map[string]string{
+	LeaseHolderRun:      "ReplicationRunID",
+	LeaseHolderBackfill: "BackfillID",
+	LeaseHolderCompare:  "CompareReportID",
+	LeaseHolderRefresh:  "RefreshID",
+}
+
+// activeLeases selects the leases on resource $1 $2 whose holder has not ended.
+const activeLeases = `FROM RunLeases l
+		LEFT JOIN ReplicationRuns r ON r.ID = l.ReplicationRunID
+		LEFT JOIN Backfills b ON b.ID = l.BackfillID
+		LEFT JOIN CompareReports c ON c.ID = l.CompareReportID
+		LEFT JOIN Refreshes f ON f.ID = l.RefreshID
+		WHERE l.ResourceType = $1 AND l.ResourceID = $2
+		AND COALESCE(r.EndTime, b.EndTime, c.EndTime, f.EndTime) IS NULL`
+
+// leaseResourceTables are the tables whose rows are locked while leases on them are counted.
+var leaseResourceTables = map[string]string{
+	LeaseResourceConnection: "Connections",
+	LeaseResourceTask:       "ReplicationTasks",
+}
+
+// AcquireRunLeases gives a holder a lease on every limited resource, or on none of them if any
+// is at its limit. Leases of holders that have ended do not count. It reports whether the
+// leases were acquired; acquiring leases the holder already has succeeds.
+func (db *DB) AcquireRunLeases(ctx context.Context, holder LeaseHolder, limits []LeaseLimit) (bool, error) {
+	if db == nil || db.SQL == nil {
+		return false, fmt.Errorf("database connection is not initialized")
+	}
+	column, ok := leaseHolderColumns[holder.Type]
+	if !ok {
+		return false, fmt.Errorf("unknown lease holder type %q", holder.Type)
+	}
+
+	// Lock resources in a fixed order so concurrent holders cannot deadlock
+	limits = append([]LeaseLimit(nil), limits...)
+	sort.Slice(limits, func(i, j int) bool {
+		if limits[i].ResourceType != limits[j].ResourceType {
+			return limits[i].ResourceType < limits[j].ResourceType
+		}
+		return limits[i].ResourceID < limits[j].ResourceID
+	})
+
+	tx, err := db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting transaction for leases of %s %d: %w", holder.Type, holder.ID, err)
+	}
+	defer tx.Rollback()
+
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		%s
+		AND l.%s IS DISTINCT FROM $3;`, activeLeases, column)
+	for _, limit := range limits {
+		table, ok := leaseResourceTables[limit.ResourceType]
+		if !ok {
+			return false, fmt.Errorf("unknown lease resource type %q", limit.ResourceType)
+		}
+		// The row lock serializes holders acquiring leases on the same resource
+		lockQuery := fmt.Sprintf(`SELECT ID FROM %s WHERE ID = $1 FOR UPDATE;`, table)
+		var id int64
+		if err := tx.QueryRowContext(ctx, lockQuery, limit.ResourceID).Scan(&id); err != nil {
+			return false, fmt.Errorf("error locking %s %d: %w", limit.ResourceType, limit.ResourceID, err)
+		}
+		var held int
+		if err := tx.QueryRowContext(ctx, countQuery, limit.ResourceType, limit.ResourceID, holder.ID).Scan(&held); err != nil {
+			return false, fmt.Errorf("error counting leases on %s %d: %w", limit.ResourceType, limit.ResourceID, err)
+		}
+		if held >= limit.Max {
+			return false, nil
+		}
+	}
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO RunLeases (%[1]s, ResourceType, ResourceID, AcquiredAt)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (%[1]s, ResourceType, ResourceID) DO NOTHING;`, column)
+	now := time.Now()
+	for _, limit := range limits {
+		if _, err := tx.ExecContext(ctx, insertQuery, holder.ID, limit.ResourceType, limit.ResourceID, now); err != nil {
+			return false, fmt.Errorf("error creating lease on %s %d for %s %d: %w", limit.ResourceType, limit.ResourceID, holder.Type, holder.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing leases of %s %d: %w", holder.Type, holder.ID, err)
+	}
+	return true, nil
+}
+
+// ReleaseRunLeases deletes the leases a holder has.
+func (db *DB) ReleaseRunLeases(ctx context.Context, holder LeaseHolder) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	column, ok := leaseHolderColumns[holder.Type]
+	if !ok {
+		return fmt.Errorf("unknown lease holder type %q", holder.Type)
+	}
+
+	query := fmt.Sprintf(`DELETE FROM RunLeases WHERE %s = $1;`, column)
+	if _, err := db.SQL.ExecContext(ctx, query, holder.ID); err != nil {
+		return fmt.Errorf("error releasing leases of %s %d: %w", holder.Type, holder.ID, err)
+	}
+	return nil
+}
+
+// ListRunLeases retrieves the leases held on a resource by holders that have not ended, oldest
+// first.
+func (db *DB) ListRunLeases(ctx context.Context, resourceType string, resourceID int64) ([]*RunLease, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT l.ID, l.ReplicationRunID, l.BackfillID, l.CompareReportID, l.RefreshID, l.ResourceType, l.ResourceID, l.AcquiredAt
+		%s
+		ORDER BY l.AcquiredAt, l.ID;`, activeLeases)
+
+	rows, err := db.SQL.QueryContext(ctx, query, resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing leases on %s %d: %w", resourceType, resourceID, err)
+	}
+	defer rows.Close()
+
+	leases := make([]*RunLease, 0)
+	for rows.Next() {
+		var lease RunLease
+		if err := rows.Scan(&lease.ID, &lease.ReplicationRunID, &lease.BackfillID, &lease.CompareReportID, &lease.RefreshID, &lease.ResourceType, &lease.ResourceID, &lease.AcquiredAt); err != nil {
+			return nil, fmt.Errorf("error scanning lease row: %w", err)
+		}
+		leases = append(leases, &lease)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lease rows: %w", err)
+	}
+	return leases, nil
+}
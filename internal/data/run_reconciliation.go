@@ -0,0 +1,59 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ListUnfinishedReplicationRuns retrieves the runs that have not ended, oldest first, with
+// per-table runs after their parents.
+func (db *DB) ListUnfinishedReplicationRuns(ctx context.Context) ([]*ReplicationRun, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, StartTime, EndTime, Status, ErrorDetails, TemporalWorkflowID, TemporalRunID, ParentRunID, TableName, SchemaDrift, SourceFiles, Parameters, LogicalTime, RenderedCriteria, RerunOfRunID, Streaming, RowsRead, RowsWritten, CreatedAt
+		FROM ReplicationRuns
+		WHERE EndTime IS NULL
+		ORDER BY ParentRunID IS NOT NULL, StartTime, ID;`
+
+	rows, err := db.SQL.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error listing unfinished runs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanReplicationRuns(rows)
+}
+
+// GetReplicationRunByExecution retrieves the run last carried out by a Temporal workflow
+// execution, identified by its run ID.
+func (db *DB) GetReplicationRunByExecution(ctx context.Context, temporalRunID string) (*ReplicationRun, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, StartTime, EndTime, Status, ErrorDetails, TemporalWorkflowID, TemporalRunID, ParentRunID, TableName, SchemaDrift, SourceFiles, Parameters, LogicalTime, RenderedCriteria, RerunOfRunID, Streaming, RowsRead, RowsWritten, CreatedAt
+		FROM ReplicationRuns
+		WHERE TemporalRunID = $1
+		ORDER BY ID DESC
+		LIMIT 1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, temporalRunID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting run of execution %s: %w", temporalRunID, err)
+	}
+	defer rows.Close()
+
+	runs, err := scanReplicationRuns(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return runs[0], nil
+}
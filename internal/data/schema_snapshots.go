@@ -0,0 +1,136 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateSchemaSnapshot records the source columns seen by a run.
+func (db *DB) CreateSchemaSnapshot(ctx context.Context, snapshot *SchemaSnapshot) (int64, error) {
+	if db == nil || db.SQL == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		INSERT INTO SchemaSnapshots (ReplicationTaskID, ReplicationRunID, TableName, Columns, CreatedAt)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ID;`
+
+	now := time.Now()
+	var insertedID int64
+
+	err := db.SQL.QueryRowContext(ctx, query,
+		snapshot.ReplicationTaskID,
+		snapshot.ReplicationRunID,
+		snapshot.TableName,
+		snapshot.Columns,
+		now,
+	).Scan(&insertedID)
+
+	if err != nil {
+		return 0, fmt.Errorf("error creating schema snapshot: %w", err)
+	}
+
+	snapshot.ID = insertedID
+	snapshot.CreatedAt = now
+	return insertedID, nil
+}
+
+// GetLatestSchemaSnapshot retrieves the most recent snapshot of a task's source table
+// (tableName is empty for query-based tasks).
+func (db *DB) GetLatestSchemaSnapshot(ctx context.Context, taskID int64, tableName string) (*SchemaSnapshot, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, ReplicationRunID, TableName, Columns, CreatedAt
+		FROM SchemaSnapshots
+		WHERE ReplicationTaskID = $1 AND TableName = $2
+		ORDER BY ID DESC
+		LIMIT 1;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, taskID, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting schema snapshot for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	snapshots, err := scanSchemaSnapshots(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return snapshots[0], nil
+}
+
+// ListSchemaSnapshots retrieves a task's snapshots, most recent first.
+func (db *DB) ListSchemaSnapshots(ctx context.Context, taskID int64) ([]*SchemaSnapshot, error) {
+	if db == nil || db.SQL == nil {
+		return nil, fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `
+		SELECT ID, ReplicationTaskID, ReplicationRunID, TableName, Columns, CreatedAt
+		FROM SchemaSnapshots
+		WHERE ReplicationTaskID = $1
+		ORDER BY ID DESC;`
+
+	rows, err := db.SQL.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing schema snapshots for task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	return scanSchemaSnapshots(rows)
+}
+
+// DeleteSchemaSnapshots removes a task's snapshots so the next run starts a new baseline.
+func (db *DB) DeleteSchemaSnapshots(ctx context.Context, taskID int64) error {
+	if db == nil || db.SQL == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+
+	query := `DELETE FROM SchemaSnapshots WHERE ReplicationTaskID = $1;`
+
+	if _, err := db.SQL.ExecContext(ctx, query, taskID); err != nil {
+		return fmt.Errorf("error deleting schema snapshots for task %d: %w", taskID, err)
+	}
+	return nil
+}
+
+// scanSchemaSnapshots reads schema snapshot rows selected in the column order used above.
+func scanSchemaSnapshots(rows *sql.Rows) ([]*SchemaSnapshot, error) {
+	snapshots := make([]*SchemaSnapshot, 0)
+	for rows.Next() {
+		var snapshot SchemaSnapshot
+		var runID sql.NullInt64
+
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.ReplicationTaskID,
+			&runID,
+			&snapshot.TableName,
+			&snapshot.Columns,
+			&snapshot.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning schema snapshot row: %w", err)
+		}
+
+		if runID.Valid {
+			snapshot.ReplicationRunID = &runID.Int64
+		}
+
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schema snapshot rows: %w", err)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,116 @@
+package ddl
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	_ "github.com/lib/pq" // postgres driver
+	"github.com/snowflakedb/gosnowflake"
+)
+
+// Apply runs a table's CREATE TABLE IF NOT EXISTS against the target connection.
+func Apply(ctx context.Context, targetConn *data.Connection, table *Table) error {
+	if err := Exec(ctx, targetConn, table.Statement); err != nil {
+		return fmt.Errorf("error creating target table %s: %w", table.Name, err)
+	}
+	return nil
+}
+
+// Exec runs DDL statements against the target connection in order.
+func Exec(ctx context.Context, targetConn *data.Connection, statements ...string) error {
+	db, err := OpenTarget(targetConn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenTarget opens a database/sql handle on a Snowflake or Postgres target connection. The
+// caller must Close it.
+func OpenTarget(conn *data.Connection) (*sql.DB, error) {
+	params := benthos.ParseConnectionString(conn.ConnectionString)
+	switch conn.Type {
+	case "postgres":
+		dsn, err := benthos.LookupSecretRef(params["dsn"])
+		if err != nil {
+			return nil, err
+		}
+		if dsn == "" {
+			return nil, fmt.Errorf("'dsn' not found in connection string for postgres output")
+		}
+		return sql.Open("postgres", dsn)
+	case "snowflake":
+		dsn, err := snowflakeDSN(params)
+		if err != nil {
+			return nil, err
+		}
+		return sql.Open("snowflake", dsn)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedTarget, conn.Type)
+	}
+}
+
+// snowflakeDSN builds a driver DSN from the same keys the snowflake_put output uses, preferring
+// key pair auth over a password like the output does.
+func snowflakeDSN(params map[string]string) (string, error) {
+	config := &gosnowflake.Config{
+		Account:   params["account"],
+		User:      params["user"],
+		Database:  params["database"],
+		Schema:    params["schema"],
+		Role:      params["role"],
+		Warehouse: params["warehouse"],
+	}
+	if keyPath, ok := params["private_key_path"]; ok {
+		if params["private_key_passphrase"] != "" {
+			return "", fmt.Errorf("encrypted private keys are not supported for snowflake table creation")
+		}
+		key, err := readRSAPrivateKey(keyPath)
+		if err != nil {
+			return "", err
+		}
+		config.Authenticator = gosnowflake.AuthTypeJwt
+		config.PrivateKey = key
+	} else {
+		password, err := benthos.LookupSecretRef(params["password"])
+		if err != nil {
+			return "", err
+		}
+		config.Password = password
+	}
+	return gosnowflake.DSN(config)
+}
+
+func readRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	keyBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading snowflake private key: %w", err)
+	}
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, fmt.Errorf("snowflake private key %s is not PEM encoded", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing snowflake private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("snowflake private key %s is not an RSA key", path)
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,201 @@
+package ddl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// Column type overrides are pasted into DDL, so only plain type syntax is accepted.
+var typeOverride = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\(\s*\d+\s*(,\s*\d+\s*)?\))?$`)
+
+// Table is the target table derived from a task's source columns.
+type Table struct {
+	SourceTable string   `json:"source_table,omitempty"` // schema.table; empty for query-based tasks
+	Name        string   `json:"name"`                   // Qualified target name as used in DDL
+	Columns     []Column `json:"columns"`
+	Statement   string   `json:"statement"`
+}
+
+// Column is a target column and the source column it is loaded from. Source is empty for the
+// run columns a pipeline writes itself.
+type Column struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Nullable   bool   `json:"nullable"`
+	PrimaryKey bool   `json:"primary_key"`
+	Source     string `json:"source"`
+	SourceType string `json:"source_type"`
+	Override   bool   `json:"override,omitempty"` // Type came from the task's column_types
+}
+
+// ParseColumnTypes parses a task's column_types JSON: target types keyed by column name, or
+// by schema.table.column for one table of a multi-table task.
+func ParseColumnTypes(raw string) (map[string]string, error) {
+	overrides := map[string]string{}
+	if strings.TrimSpace(raw) == "" {
+		return overrides, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid column types: %w", err)
+	}
+	for key, targetType := range overrides {
+		if !typeOverride.MatchString(strings.TrimSpace(targetType)) {
+			return nil, fmt.Errorf("invalid column types: unsupported type %q for %s", targetType, key)
+		}
+	}
+	return overrides, nil
+}
+
+// Supported reports whether target tables can be created for a target connection type.
+func Supported(targetType string) bool {
+	return targetType == "snowflake" || targetType == "postgres"
+}
+
+// Derive builds the target table for a task, or for one table of a multi-table task. Columns
+// come from the source catalog for tables and from describing the task's query otherwise.
+func Derive(ctx context.Context, inspector schema.Inspector, sourceType string, task *data.ReplicationTask, targetConn *data.Connection, table *schema.TableSelection) (*Table, error) {
+	if !Supported(targetConn.Type) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedTarget, targetConn.Type)
+	}
+	overrides, err := ParseColumnTypes(task.ColumnTypes)
+	if err != nil {
+		return nil, err
+	}
+	params := benthos.ParseConnectionString(targetConn.ConnectionString)
+
+	columns, err := SourceColumns(ctx, inspector, sourceType, task, table)
+	if err != nil {
+		return nil, err
+	}
+	targetName, sourceTable := params["table"], ""
+	if table != nil {
+		targetName, sourceTable = table.Target, table.SourceName()
+	}
+	if targetName == "" {
+		return nil, fmt.Errorf("'table' not found in connection string for %s output", targetConn.Type)
+	}
+
+	result := &Table{SourceTable: sourceTable, Name: QualifiedName(targetConn.Type, params, targetName)}
+	for _, column := range columns {
+		translated := Column{
+			Name:       Identifier(targetConn.Type, column.Name),
+			Nullable:   column.Nullable,
+			PrimaryKey: column.PrimaryKey,
+			Source:     column.Name,
+			SourceType: column.DataType,
+		}
+		if override, ok := lookupOverride(overrides, sourceTable, column.Name); ok {
+			translated.Type, translated.Override = strings.ToUpper(strings.TrimSpace(override)), true
+		} else {
+			logical, err := SourceType(sourceType, column)
+			if err != nil {
+				return nil, err
+			}
+			if translated.Type, err = TargetType(targetConn.Type, logical); err != nil {
+				return nil, err
+			}
+		}
+		result.Columns = append(result.Columns, translated)
+	}
+	if targetConn.Type == "postgres" && benthos.RunColumns(params) {
+		// Written by the pipeline rather than loaded from a source column
+		result.Columns = append(result.Columns,
+			Column{Name: benthos.RunIDColumn, Type: "BIGINT", Nullable: true},
+			Column{Name: benthos.RunChunkColumn, Type: "INTEGER", Nullable: true})
+	}
+	result.Statement = CreateTableStatement(result.Name, result.Columns)
+	return result, nil
+}
+
+// SourceColumns returns the columns a task reads: those of one table of a multi-table task, or
+// the result columns of its query.
+func SourceColumns(ctx context.Context, inspector schema.Inspector, sourceType string, task *data.ReplicationTask, table *schema.TableSelection) ([]schema.Column, error) {
+	if table != nil {
+		return inspector.ListColumns(ctx, table.Schema, table.Name)
+	}
+	describer, ok := inspector.(schema.QueryDescriber)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot describe %s queries; use a multi-table task", schema.ErrUnsupportedType, sourceType)
+	}
+	return describer.DescribeQuery(ctx, task.DataSelectionCriteria)
+}
+
+// ParquetSchema renders source columns as a parquet_schema value (name:TYPE, comma-separated)
+// for Parquet file targets that don't declare one.
+func ParquetSchema(sourceType string, columns []schema.Column) (string, error) {
+	fields := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if strings.ContainsAny(column.Name, ",:") {
+			return "", fmt.Errorf("column %q cannot be written to parquet_schema; set it explicitly", column.Name)
+		}
+		logical, err := SourceType(sourceType, column)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, column.Name+":"+ParquetType(logical))
+	}
+	return strings.Join(fields, ","), nil
+}
+
+func lookupOverride(overrides map[string]string, sourceTable, column string) (string, bool) {
+	var fallback string
+	var found bool
+	for key, targetType := range overrides {
+		if sourceTable != "" && strings.EqualFold(key, sourceTable+"."+column) {
+			return targetType, true // Table-specific overrides win
+		}
+		if strings.EqualFold(key, column) {
+			fallback, found = targetType, true
+		}
+	}
+	return fallback, found
+}
+
+// Identifier folds a name to the target's default case (upper for Snowflake, lower for
+// Postgres) so it can be used unquoted, and quotes names that are not plain identifiers.
+func Identifier(targetType, name string) string {
+	return benthos.Identifier(targetType, name)
+}
+
+// QualifiedName prefixes the table with the connection's database and schema (Snowflake) or
+// schema (Postgres, default public).
+func QualifiedName(targetType string, params map[string]string, table string) string {
+	return benthos.QualifiedName(targetType, params, table)
+}
+
+// CreateTableStatement renders CREATE TABLE IF NOT EXISTS for a table.
+func CreateTableStatement(name string, columns []Column) string {
+	lines := make([]string, 0, len(columns)+1)
+	var primaryKey []string
+	for _, column := range columns {
+		line := "  " + column.Name + " " + column.Type
+		if !column.Nullable {
+			line += " NOT NULL"
+		}
+		lines = append(lines, line)
+		if column.PrimaryKey {
+			primaryKey = append(primaryKey, column.Name)
+		}
+	}
+	if len(primaryKey) > 0 {
+		lines = append(lines, "  PRIMARY KEY ("+strings.Join(primaryKey, ", ")+")")
+	}
+	return "CREATE TABLE IF NOT EXISTS " + name + " (\n" + strings.Join(lines, ",\n") + "\n);"
+}
+
+// AddColumnStatements renders ALTER TABLE statements that add columns to an existing table.
+// Added columns are always nullable, since existing rows have no values for them.
+func AddColumnStatements(name string, columns []Column) []string {
+	statements := make([]string, 0, len(columns))
+	for _, column := range columns {
+		statements = append(statements, "ALTER TABLE "+name+" ADD COLUMN IF NOT EXISTS "+column.Name+" "+column.Type+";")
+	}
+	return statements
+}
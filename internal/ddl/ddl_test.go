@@ -0,0 +1,207 @@
+package ddl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// fakeInspector serves fixed columns for any table or query.
+type fakeInspector struct {
+	columns []schema.Column
+}
+
+func (f *fakeInspector) DefaultSchema(ctx context.Context) (string, error) { return "dbo", nil }
+func (f *fakeInspector) ListSchemas(ctx context.Context) ([]string, error) {
+	return []string{"dbo"}, nil
+}
+func (f *fakeInspector) ListTables(ctx context.Context, schemaName string) ([]schema.Table, error) {
+	return nil, nil
+}
+func (f *fakeInspector) ListColumns(ctx context.Context, schemaName, table string) ([]schema.Column, error) {
+	return f.columns, nil
+}
+func (f *fakeInspector) DescribeQuery(ctx context.Context, query string) ([]schema.Column, error) {
+	return f.columns, nil
+}
+func (f *fakeInspector) Close() error { return nil }
+
+func TestSourceType_TargetTypes(t *testing.T) {
+	tests := []struct {
+		source    string
+		column    schema.Column
+		snowflake string
+		postgres  string
+	}{
+		{"sqlserver", schema.Column{DataType: "nvarchar", MaxLength: int64Ptr(200)}, "VARCHAR(200)", "VARCHAR(200)"},
+		{"sqlserver", schema.Column{DataType: "nvarchar", MaxLength: int64Ptr(-1)}, "VARCHAR", "TEXT"},
+		{"sqlserver", schema.Column{DataType: "money"}, "NUMBER(19,4)", "NUMERIC(19,4)"},
+		{"sqlserver", schema.Column{DataType: "datetimeoffset"}, "TIMESTAMP_TZ", "TIMESTAMPTZ"},
+		{"sqlserver", schema.Column{DataType: "uniqueidentifier"}, "VARCHAR(36)", "UUID"},
+		{"oracle", schema.Column{DataType: "NUMBER", Precision: int64Ptr(10), Scale: int64Ptr(0)}, "BIGINT", "BIGINT"},
+		{"oracle", schema.Column{DataType: "NUMBER", Precision: int64Ptr(12), Scale: int64Ptr(2)}, "NUMBER(12,2)", "NUMERIC(12,2)"},
+		{"oracle", schema.Column{DataType: "NUMBER"}, "NUMBER(38,10)", "NUMERIC"},
+		{"oracle", schema.Column{DataType: "DATE"}, "TIMESTAMP_NTZ", "TIMESTAMP"},
+		{"oracle", schema.Column{DataType: "TIMESTAMP(6) WITH TIME ZONE"}, "TIMESTAMP_TZ", "TIMESTAMPTZ"},
+		{"mysql", schema.Column{DataType: "bit", Precision: int64Ptr(1)}, "BOOLEAN", "BOOLEAN"},
+		{"mysql", schema.Column{DataType: "unsigned bigint"}, "NUMBER(20,0)", "NUMERIC(20,0)"},
+		{"mysql", schema.Column{DataType: "longtext"}, "VARCHAR", "TEXT"},
+		{"postgres", schema.Column{DataType: "timestamp without time zone"}, "TIMESTAMP_NTZ", "TIMESTAMP"},
+		{"postgres", schema.Column{DataType: "jsonb"}, "VARIANT", "JSONB"},
+		{"postgres", schema.Column{DataType: "int8"}, "BIGINT", "BIGINT"},
+		{"bigquery", schema.Column{DataType: "NUMERIC"}, "NUMBER(38,9)", "NUMERIC(38,9)"},
+		{"bigquery", schema.Column{DataType: "BIGNUMERIC"}, "NUMBER(38,0)", "NUMERIC(76,38)"},
+		{"bigquery", schema.Column{DataType: "ARRAY<STRING>"}, "VARIANT", "JSONB"},
+		{"bigquery", schema.Column{DataType: "TIMESTAMP"}, "TIMESTAMP_TZ", "TIMESTAMPTZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+" "+tt.column.DataType, func(t *testing.T) {
+			logical, err := SourceType(tt.source, tt.column)
+			require.NoError(t, err)
+
+			snowflake, err := TargetType("snowflake", logical)
+			require.NoError(t, err)
+			assert.Equal(t, tt.snowflake, snowflake)
+			postgres, err := TargetType("postgres", logical)
+			require.NoError(t, err)
+			assert.Equal(t, tt.postgres, postgres)
+		})
+	}
+}
+
+func TestDerive_MultiTableWithOverrides(t *testing.T) {
+	// Arrange
+	inspector := &fakeInspector{columns: []schema.Column{
+		{Name: "OrderID", DataType: "bigint", PrimaryKey: true},
+		{Name: "Amount", DataType: "decimal", Nullable: true, Precision: int64Ptr(18), Scale: int64Ptr(4)},
+		{Name: "Ship To", DataType: "nvarchar", Nullable: true, MaxLength: int64Ptr(100)},
+		{Name: "Geo", DataType: "geography", Nullable: true},
+	}}
+	task := &data.ReplicationTask{ColumnTypes: `{"amount": "number(12,2)", "dbo.Orders.Geo": "VARCHAR"}`}
+	targetConn := &data.Connection{Type: "snowflake", ConnectionString: "account=a;user=u;database=Analytics;schema=raw;table=IGNORED"}
+	table := &schema.TableSelection{Schema: "dbo", Name: "Orders", Target: "orders"}
+
+	// Act
+	result, err := Derive(context.Background(), inspector, "sqlserver", task, targetConn, table)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "dbo.Orders", result.SourceTable)
+	assert.Equal(t, "ANALYTICS.RAW.ORDERS", result.Name)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS ANALYTICS.RAW.ORDERS (\n"+
+		"  ORDERID BIGINT NOT NULL,\n"+
+		"  AMOUNT NUMBER(12,2),\n"+
+		"  \"Ship To\" VARCHAR(100),\n"+
+		"  GEO VARCHAR,\n"+
+		"  PRIMARY KEY (ORDERID)\n"+
+		");", result.Statement)
+	assert.True(t, result.Columns[1].Override)
+	assert.Equal(t, "Ship To", result.Columns[2].Source)
+}
+
+func TestDerive_QueryTaskOnPostgres(t *testing.T) {
+	inspector := &fakeInspector{columns: []schema.Column{
+		{Name: "ID", DataType: "NUMBER", Precision: int64Ptr(9), Scale: int64Ptr(0)},
+		{Name: "NOTE", DataType: "CLOB", Nullable: true},
+	}}
+	task := &data.ReplicationTask{DataSelectionCriteria: "SELECT ID, NOTE FROM APP.NOTES"}
+	targetConn := &data.Connection{Type: "postgres", ConnectionString: "dsn=env:PG_DSN;table=Notes"}
+
+	result, err := Derive(context.Background(), inspector, "oracle", task, targetConn, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS public.notes (\n  id INTEGER NOT NULL,\n  note TEXT\n);", result.Statement)
+}
+
+func TestDerive_PostgresRunColumns(t *testing.T) {
+	inspector := &fakeInspector{columns: []schema.Column{{Name: "id", DataType: "bigint", PrimaryKey: true}}}
+	task := &data.ReplicationTask{DataSelectionCriteria: "SELECT id FROM events"}
+	targetConn := &data.Connection{Type: "postgres", ConnectionString: "dsn=x;table=events;run_columns=true"}
+
+	result, err := Derive(context.Background(), inspector, "postgres", task, targetConn, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS public.events (\n"+
+		"  id BIGINT NOT NULL,\n"+
+		"  _run_id BIGINT,\n"+
+		"  _run_chunk INTEGER,\n"+
+		"  PRIMARY KEY (id)\n"+
+		");", result.Statement)
+	assert.Empty(t, result.Columns[1].Source)
+}
+
+func TestDerive_Errors(t *testing.T) {
+	inspector := &fakeInspector{columns: []schema.Column{{Name: "shape", DataType: "geometry"}}}
+	postgresTarget := &data.Connection{Type: "postgres", ConnectionString: "dsn=x;table=t"}
+
+	tests := []struct {
+		name    string
+		task    *data.ReplicationTask
+		target  *data.Connection
+		wantErr error
+		wantMsg string
+	}{
+		{"unsupported target", &data.ReplicationTask{}, &data.Connection{Type: "s3"}, ErrUnsupportedTarget, "s3"},
+		{"unmapped type", &data.ReplicationTask{}, postgresTarget, ErrNoTypeMapping, "column shape"},
+		{"missing table", &data.ReplicationTask{ColumnTypes: `{"shape": "TEXT"}`}, &data.Connection{Type: "postgres", ConnectionString: "dsn=x"}, nil, "'table' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Derive(context.Background(), inspector, "postgres", tt.task, tt.target, nil)
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestParseColumnTypes(t *testing.T) {
+	overrides, err := ParseColumnTypes(`{"amount": "NUMERIC(12, 2)", "note": "character varying"}`)
+	require.NoError(t, err)
+	assert.Len(t, overrides, 2)
+
+	_, err = ParseColumnTypes(`{"amount": "TEXT); DROP TABLE x; --"}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported type")
+	_, err = ParseColumnTypes(`["amount"]`)
+	assert.Error(t, err)
+}
+
+func TestParquetSchema(t *testing.T) {
+	columns := []schema.Column{
+		{Name: "id", DataType: "bigint"},
+		{Name: "active", DataType: "boolean"},
+		{Name: "ratio", DataType: "real"},
+		{Name: "amount", DataType: "numeric", Precision: int64Ptr(12), Scale: int64Ptr(2)},
+		{Name: "payload", DataType: "bytea"},
+	}
+	parquetSchema, err := ParquetSchema("postgres", columns)
+	require.NoError(t, err)
+	assert.Equal(t, "id:INT64,active:BOOLEAN,ratio:FLOAT,amount:UTF8,payload:BYTE_ARRAY", parquetSchema)
+
+	_, err = ParquetSchema("postgres", []schema.Column{{Name: "a:b", DataType: "text"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set it explicitly")
+}
+
+func TestAddColumnStatements(t *testing.T) {
+	statements := AddColumnStatements("ANALYTICS.RAW.ORDERS", []Column{
+		{Name: "REGION", Type: "VARCHAR(50)"},
+		{Name: `"Ship To"`, Type: "VARCHAR"},
+	})
+
+	assert.Equal(t, []string{
+		"ALTER TABLE ANALYTICS.RAW.ORDERS ADD COLUMN IF NOT EXISTS REGION VARCHAR(50);",
+		`ALTER TABLE ANALYTICS.RAW.ORDERS ADD COLUMN IF NOT EXISTS "Ship To" VARCHAR;`,
+	}, statements)
+}
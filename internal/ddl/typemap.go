@@ -0,0 +1,313 @@
+// Package ddl translates source column types into target table definitions and creates
+// target tables before a load.
+package ddl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+var (
+	// ErrUnsupportedTarget is returned for target types that tables cannot be created in.
+	ErrUnsupportedTarget = errors.New("table creation is not supported for target type")
+	// ErrNoTypeMapping is returned for source column types without a target equivalent.
+	ErrNoTypeMapping = errors.New("no type mapping")
+)
+
+// Kind is a source-independent column type.
+type Kind string
+
+const (
+	KindBool        Kind = "bool"
+	KindInt16       Kind = "int16"
+	KindInt32       Kind = "int32"
+	KindInt64       Kind = "int64"
+	KindDecimal     Kind = "decimal"
+	KindFloat32     Kind = "float32"
+	KindFloat64     Kind = "float64"
+	KindString      Kind = "string" // Length 0 means unbounded
+	KindBinary      Kind = "binary"
+	KindDate        Kind = "date"
+	KindTime        Kind = "time"
+	KindTimestamp   Kind = "timestamp"    // Without time zone
+	KindTimestampTZ Kind = "timestamp_tz" // With time zone (or UTC instant)
+	KindJSON        Kind = "json"
+	KindUUID        Kind = "uuid"
+)
+
+// LogicalType is a column type translated out of a source dialect.
+type LogicalType struct {
+	Kind      Kind
+	Length    int64 // KindString: max characters, 0 for unbounded
+	Precision int64 // KindDecimal: total digits, 0 for unbounded
+	Scale     int64
+}
+
+var sourceTypes = map[string]map[string]Kind{
+	"sqlserver": {
+		"bit": KindBool, "tinyint": KindInt16, "smallint": KindInt16, "int": KindInt32, "bigint": KindInt64,
+		"decimal": KindDecimal, "numeric": KindDecimal, "money": KindDecimal, "smallmoney": KindDecimal,
+		"float": KindFloat64, "real": KindFloat32,
+		"char": KindString, "varchar": KindString, "nchar": KindString, "nvarchar": KindString,
+		"text": KindString, "ntext": KindString, "xml": KindString, "sysname": KindString,
+		"binary": KindBinary, "varbinary": KindBinary, "image": KindBinary, "timestamp": KindBinary, "rowversion": KindBinary,
+		"date": KindDate, "time": KindTime,
+		"datetime": KindTimestamp, "datetime2": KindTimestamp, "smalldatetime": KindTimestamp,
+		"datetimeoffset": KindTimestampTZ, "uniqueidentifier": KindUUID,
+	},
+	"postgres": {
+		"boolean": KindBool, "bool": KindBool,
+		"smallint": KindInt16, "int2": KindInt16, "integer": KindInt32, "int": KindInt32, "int4": KindInt32,
+		"bigint": KindInt64, "int8": KindInt64, "numeric": KindDecimal, "decimal": KindDecimal,
+		"real": KindFloat32, "float4": KindFloat32, "double precision": KindFloat64, "float8": KindFloat64,
+		"character varying": KindString, "varchar": KindString, "character": KindString, "char": KindString, "bpchar": KindString,
+		"text": KindString, "citext": KindString, "name": KindString, "xml": KindString, "interval": KindString, "user-defined": KindString,
+		"bytea": KindBinary, "date": KindDate,
+		"time": KindTime, "time without time zone": KindTime, "time with time zone": KindTime, "timetz": KindTime,
+		"timestamp": KindTimestamp, "timestamp without time zone": KindTimestamp,
+		"timestamptz": KindTimestampTZ, "timestamp with time zone": KindTimestampTZ,
+		"json": KindJSON, "jsonb": KindJSON, "array": KindJSON, "uuid": KindUUID,
+	},
+	"mysql": {
+		"bool": KindBool, "boolean": KindBool, "tinyint": KindInt16, "smallint": KindInt16, "year": KindInt16,
+		"mediumint": KindInt32, "int": KindInt32, "integer": KindInt32, "bigint": KindInt64, "bit": KindInt64,
+		"decimal": KindDecimal, "numeric": KindDecimal, "float": KindFloat32, "double": KindFloat64, "real": KindFloat64,
+		"char": KindString, "varchar": KindString, "enum": KindString, "set": KindString,
+		"tinytext": KindString, "text": KindString, "mediumtext": KindString, "longtext": KindString,
+		"binary": KindBinary, "varbinary": KindBinary, "tinyblob": KindBinary, "blob": KindBinary, "mediumblob": KindBinary, "longblob": KindBinary,
+		"date": KindDate, "time": KindTime, "datetime": KindTimestamp, "timestamp": KindTimestamp, "json": KindJSON,
+	},
+	"oracle": {
+		"number": KindDecimal, "integer": KindDecimal, "float": KindFloat64, "binary_double": KindFloat64, "binary_float": KindFloat32,
+		"varchar2": KindString, "nvarchar2": KindString, "varchar": KindString, "char": KindString, "nchar": KindString,
+		"clob": KindString, "nclob": KindString, "long": KindString, "rowid": KindString, "urowid": KindString,
+		"raw": KindBinary, "long raw": KindBinary, "blob": KindBinary,
+		// Oracle DATE carries a time of day
+		"date": KindTimestamp, "timestamp": KindTimestamp, "timestamp with time zone": KindTimestampTZ, "timestamp with local time zone": KindTimestampTZ,
+	},
+	"bigquery": {
+		"bool": KindBool, "boolean": KindBool, "int64": KindInt64, "integer": KindInt64,
+		"float64": KindFloat64, "float": KindFloat64, "numeric": KindDecimal, "bignumeric": KindDecimal,
+		"string": KindString, "geography": KindString, "interval": KindString, "bytes": KindBinary,
+		"date": KindDate, "time": KindTime, "datetime": KindTimestamp, "timestamp": KindTimestampTZ,
+		"json": KindJSON, "record": KindJSON, "struct": KindJSON, "array": KindJSON,
+	},
+}
+
+// baseTypeName lower-cases a type name and drops length/precision modifiers, so
+// "TIMESTAMP(6) WITH TIME ZONE" becomes "timestamp with time zone" and "ARRAY<INT64>" "array".
+func baseTypeName(dataType string) string {
+	name := strings.ToLower(strings.TrimSpace(dataType))
+	if open := strings.IndexAny(name, "(<"); open >= 0 {
+		if closing := strings.LastIndexAny(name, ")>"); closing > open {
+			name = name[:open] + name[closing+1:]
+		}
+	}
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// SourceType translates a source column's type into a logical type.
+func SourceType(sourceType string, column schema.Column) (LogicalType, error) {
+	types, ok := sourceTypes[sourceType]
+	if !ok {
+		return LogicalType{}, fmt.Errorf("%w: %s", schema.ErrUnsupportedType, sourceType)
+	}
+	name := baseTypeName(column.DataType)
+	unsigned := strings.Contains(name, "unsigned")
+	name = strings.TrimSpace(strings.ReplaceAll(name, "unsigned", ""))
+	kind, ok := types[name]
+	if !ok {
+		return LogicalType{}, fmt.Errorf("%w for column %s (%s %s); add a column type override", ErrNoTypeMapping, column.Name, sourceType, column.DataType)
+	}
+
+	logical := LogicalType{Kind: kind}
+	switch kind {
+	case KindString:
+		if column.MaxLength != nil && *column.MaxLength > 0 {
+			logical.Length = *column.MaxLength
+		}
+	case KindDecimal:
+		logical.Precision, logical.Scale = decimalSize(sourceType, name, column)
+		if sourceType == "oracle" && logical.Scale == 0 && logical.Precision > 0 && logical.Precision <= 18 {
+			// Oracle has no integer types; NUMBER(p,0) holds integers
+			logical = LogicalType{Kind: integerKind(logical.Precision)}
+		}
+	case KindInt64:
+		if name == "bit" && column.Precision != nil && *column.Precision == 1 {
+			logical.Kind = KindBool
+		}
+		if unsigned && name == "bigint" {
+			logical = LogicalType{Kind: KindDecimal, Precision: 20}
+		}
+	case KindInt32:
+		if unsigned && (name == "int" || name == "integer") {
+			logical.Kind = KindInt64
+		}
+	}
+	return logical, nil
+}
+
+func decimalSize(sourceType, name string, column schema.Column) (int64, int64) {
+	switch {
+	case name == "money":
+		return 19, 4
+	case name == "smallmoney":
+		return 10, 4
+	case name == "integer" && sourceType == "oracle":
+		return 38, 0
+	case sourceType == "bigquery" && column.Precision == nil:
+		if name == "bignumeric" {
+			return 76, 38
+		}
+		return 38, 9
+	}
+	var precision, scale int64
+	if column.Precision != nil {
+		precision = *column.Precision
+	}
+	if column.Scale != nil {
+		scale = *column.Scale
+	}
+	return precision, scale
+}
+
+func integerKind(precision int64) Kind {
+	switch {
+	case precision <= 4:
+		return KindInt16
+	case precision <= 9:
+		return KindInt32
+	default:
+		return KindInt64
+	}
+}
+
+const (
+	snowflakeMaxPrecision = 38
+	postgresMaxVarchar    = 10485760
+)
+
+// TargetType renders a logical type in a target's DDL dialect.
+func TargetType(targetType string, logical LogicalType) (string, error) {
+	switch targetType {
+	case "snowflake":
+		return snowflakeType(logical), nil
+	case "postgres":
+		return postgresType(logical), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedTarget, targetType)
+	}
+}
+
+// ParquetType maps a logical type to the parquet_encode column type it is written as. Types
+// without a Parquet equivalent in parquet_encode (decimals, dates, UUIDs...) are written as text.
+func ParquetType(logical LogicalType) string {
+	switch logical.Kind {
+	case KindBool:
+		return "BOOLEAN"
+	case KindInt16, KindInt32:
+		return "INT32"
+	case KindInt64:
+		return "INT64"
+	case KindFloat32:
+		return "FLOAT"
+	case KindFloat64:
+		return "DOUBLE"
+	case KindBinary:
+		return "BYTE_ARRAY"
+	default:
+		return "UTF8"
+	}
+}
+
+func snowflakeType(logical LogicalType) string {
+	switch logical.Kind {
+	case KindBool:
+		return "BOOLEAN"
+	case KindInt16:
+		return "SMALLINT"
+	case KindInt32:
+		return "INTEGER"
+	case KindInt64:
+		return "BIGINT"
+	case KindDecimal:
+		precision, scale := logical.Precision, logical.Scale
+		if precision == 0 {
+			// Unbounded source numbers (e.g. Oracle NUMBER) keep 28 integer and 10 fraction digits
+			return "NUMBER(38,10)"
+		}
+		if precision > snowflakeMaxPrecision {
+			// Keep the integer digits and give up fraction digits
+			scale = max(0, scale-(precision-snowflakeMaxPrecision))
+			precision = snowflakeMaxPrecision
+		}
+		return fmt.Sprintf("NUMBER(%d,%d)", precision, scale)
+	case KindFloat32, KindFloat64:
+		return "FLOAT"
+	case KindString:
+		if logical.Length > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", logical.Length)
+		}
+		return "VARCHAR"
+	case KindBinary:
+		return "BINARY"
+	case KindDate:
+		return "DATE"
+	case KindTime:
+		return "TIME"
+	case KindTimestamp:
+		return "TIMESTAMP_NTZ"
+	case KindTimestampTZ:
+		return "TIMESTAMP_TZ"
+	case KindJSON:
+		return "VARIANT"
+	case KindUUID:
+		return "VARCHAR(36)"
+	}
+	return "VARCHAR"
+}
+
+func postgresType(logical LogicalType) string {
+	switch logical.Kind {
+	case KindBool:
+		return "BOOLEAN"
+	case KindInt16:
+		return "SMALLINT"
+	case KindInt32:
+		return "INTEGER"
+	case KindInt64:
+		return "BIGINT"
+	case KindDecimal:
+		if logical.Precision == 0 {
+			return "NUMERIC"
+		}
+		return fmt.Sprintf("NUMERIC(%d,%d)", logical.Precision, logical.Scale)
+	case KindFloat32:
+		return "REAL"
+	case KindFloat64:
+		return "DOUBLE PRECISION"
+	case KindString:
+		if logical.Length > 0 && logical.Length <= postgresMaxVarchar {
+			return fmt.Sprintf("VARCHAR(%d)", logical.Length)
+		}
+		return "TEXT"
+	case KindBinary:
+		return "BYTEA"
+	case KindDate:
+		return "DATE"
+	case KindTime:
+		return "TIME"
+	case KindTimestamp:
+		return "TIMESTAMP"
+	case KindTimestampTZ:
+		return "TIMESTAMPTZ"
+	case KindJSON:
+		return "JSONB"
+	case KindUUID:
+		return "UUID"
+	}
+	return "TEXT"
+}
@@ -0,0 +1,205 @@
+// Package lag measures the replication lag of streaming tasks: the latency from a change's
+// commit on the source to its apply on the target, and the backlog of changes the target has
+// yet to apply.
+package lag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// Units of a backlog.
+const (
+	UnitBytes    = "bytes"    // WAL a Postgres replication slot has not confirmed
+	UnitMessages = "messages" // Messages behind the Kafka partitions' high watermarks
+)
+
+// Measurement is the lag of a streaming task at one point in time. Unknown values are nil.
+type Measurement struct {
+	Latency        *time.Duration
+	Backlog        *int64
+	Unit           string
+	SourcePosition string
+	TargetPosition string
+}
+
+// Sample turns the measurement into the lag sample of a task's micro-run.
+func (m *Measurement) Sample(taskID int64, runID int64, at time.Time) *data.ReplicationLagSample {
+	sample := &data.ReplicationLagSample{
+		ReplicationTaskID: taskID,
+		ReplicationRunID:  &runID,
+		Backlog:           m.Backlog,
+		BacklogUnit:       m.Unit,
+		SourcePosition:    m.SourcePosition,
+		TargetPosition:    m.TargetPosition,
+		MeasuredAt:        at,
+	}
+	if m.Latency != nil {
+		ms := m.Latency.Milliseconds()
+		sample.LatencyMs = &ms
+	}
+	return sample
+}
+
+// FromPipeline measures the lag of a Kafka pipeline from the gauges of the partitions it
+// consumed from. The backlog is the sum of the partitions' backlogs. A caught-up pipeline has
+// no latency; otherwise the latency is the highest of the partitions still behind. Both are
+// unknown until the pipeline has consumed a message.
+func FromPipeline(partitions map[string]*benthos.PartitionLag) *Measurement {
+	m := &Measurement{Unit: UnitMessages}
+	var backlog int64
+	var latency time.Duration
+	known := false
+	for _, partition := range partitions {
+		if partition.Backlog == nil {
+			continue
+		}
+		known = true
+		backlog += *partition.Backlog
+		if *partition.Backlog > 0 && partition.LatencyMs != nil {
+			latency = max(latency, time.Duration(*partition.LatencyMs)*time.Millisecond)
+		}
+	}
+	if known {
+		m.Backlog = &backlog
+		m.Latency = &latency
+	}
+	return m
+}
+
+// SlotPosition is where a Postgres source's WAL and a task's replication slot stand.
+type SlotPosition struct {
+	Head      string // The source's current WAL LSN
+	Confirmed string // The slot's confirmed flush LSN, or its restart LSN before the first confirmation
+}
+
+// ReadSlot reads the position of a replication slot on a Postgres source. A slot the pipeline
+// has not created yet returns sql.ErrNoRows.
+func ReadSlot(ctx context.Context, db *sql.DB, slot string) (*SlotPosition, error) {
+	query := `
+		SELECT pg_current_wal_lsn()::text, COALESCE(confirmed_flush_lsn, restart_lsn)::text
+		FROM pg_replication_slots
+		WHERE slot_name = $1;`
+
+	var position SlotPosition
+	var confirmed sql.NullString
+	if err := db.QueryRowContext(ctx, query, slot).Scan(&position.Head, &confirmed); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read replication slot %s: %w", slot, err)
+	}
+	if !confirmed.Valid {
+		return nil, fmt.Errorf("replication slot %s has no position yet", slot)
+	}
+	position.Confirmed = confirmed.String
+	return &position, nil
+}
+
+// ParseLSN parses a Postgres LSN, e.g. 16/B374D848, into a WAL byte position.
+func ParseLSN(lsn string) (uint64, error) {
+	high, low, ok := strings.Cut(lsn, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid LSN %q", lsn)
+	}
+	h, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", lsn)
+	}
+	l, err := strconv.ParseUint(low, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", lsn)
+	}
+	return h<<32 | l, nil
+}
+
+// formatLSN formats a WAL byte position as a Postgres LSN.
+func formatLSN(position uint64) string {
+	return fmt.Sprintf("%X/%X", position>>32, position&0xFFFFFFFF)
+}
+
+// headMark records that the source's WAL had reached a position by a time.
+type headMark struct {
+	at       time.Time
+	position uint64
+}
+
+// Tracker measures the lag of a Postgres replication slot. Postgres keeps no commit time per
+// WAL position, so the tracker remembers when it saw the source's WAL reach each position. A
+// change the slot has not confirmed was committed after the last mark at or below the slot's
+// position, and by the first mark beyond it; the latency is measured from the latter, so it is
+// accurate to the time between measurements.
+type Tracker struct {
+	marks []headMark
+}
+
+// NewTracker returns a tracker that carries on from a task's previous sample, so a backlog
+// that outlives a segment of the stream keeps its age. previous may be nil.
+func NewTracker(previous *data.ReplicationLagSample) *Tracker {
+	t := &Tracker{}
+	if previous == nil || previous.BacklogUnit != UnitBytes || previous.LatencyMs == nil {
+		return t
+	}
+	head, err := ParseLSN(previous.SourcePosition)
+	if err != nil {
+		return t
+	}
+	confirmed, err := ParseLSN(previous.TargetPosition)
+	if err != nil || head <= confirmed {
+		return t
+	}
+	// The oldest unconfirmed change was committed about the previous latency before the sample
+	oldest := previous.MeasuredAt.Add(-time.Duration(*previous.LatencyMs) * time.Millisecond)
+	t.marks = []headMark{{at: oldest, position: confirmed + 1}, {at: previous.MeasuredAt, position: head}}
+	return t
+}
+
+// Observe measures the lag of a slot position read at a time.
+func (t *Tracker) Observe(at time.Time, position *SlotPosition) (*Measurement, error) {
+	head, err := ParseLSN(position.Head)
+	if err != nil {
+		return nil, err
+	}
+	confirmed, err := ParseLSN(position.Confirmed)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.marks) == 0 || head > t.marks[len(t.marks)-1].position {
+		t.marks = append(t.marks, headMark{at: at, position: head})
+	}
+	// Marks the slot has confirmed are no longer needed
+	for len(t.marks) > 0 && t.marks[0].position <= confirmed {
+		t.marks = t.marks[1:]
+	}
+
+	m := &Measurement{Unit: UnitBytes, SourcePosition: formatLSN(head), TargetPosition: formatLSN(confirmed)}
+	var backlog int64
+	var latency time.Duration
+	if head > confirmed {
+		backlog = int64(head - confirmed)
+		if len(t.marks) > 0 {
+			latency = max(at.Sub(t.marks[0].at), 0)
+		}
+	}
+	m.Backlog, m.Latency = &backlog, &latency
+	return m, nil
+}
+
+// Transition reports whether a measured latency raises a task's lag alert or clears it, given
+// whether the alert is raised. A zero threshold never alerts, and unknown latencies change
+// nothing.
+func Transition(threshold time.Duration, alerting bool, latency *time.Duration) (raise bool, clear bool) {
+	if threshold <= 0 || latency == nil {
+		return false, false
+	}
+	over := *latency > threshold
+	return over && !alerting, !over && alerting
+}
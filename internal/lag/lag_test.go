@@ -0,0 +1,135 @@
+package lag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gauge(v int64) *int64 {
+	return &v
+}
+
+func TestFromPipeline(t *testing.T) {
+	// Nothing consumed yet
+	m := FromPipeline(map[string]*benthos.PartitionLag{})
+	assert.Nil(t, m.Backlog)
+	assert.Nil(t, m.Latency)
+	assert.Equal(t, UnitMessages, m.Unit)
+
+	// Only partitions still behind count towards the latency
+	m = FromPipeline(map[string]*benthos.PartitionLag{
+		"orders/0": {LatencyMs: gauge(1500), Backlog: gauge(40)},
+		"orders/1": {LatencyMs: gauge(9000), Backlog: gauge(0)},
+		"orders/2": {LatencyMs: gauge(300), Backlog: gauge(2)},
+	})
+	assert.Equal(t, int64(42), *m.Backlog)
+	assert.Equal(t, time.Millisecond*1500, *m.Latency)
+
+	// A caught-up pipeline has no latency
+	m = FromPipeline(map[string]*benthos.PartitionLag{"orders/0": {LatencyMs: gauge(1500), Backlog: gauge(0)}})
+	assert.Equal(t, int64(0), *m.Backlog)
+	assert.Equal(t, time.Duration(0), *m.Latency)
+}
+
+func TestParseLSN(t *testing.T) {
+	position, err := ParseLSN("16/B374D848")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x16B374D848), position)
+	assert.Equal(t, "16/B374D848", formatLSN(position))
+
+	for _, lsn := range []string{"", "16B374D848", "x/1", "1/123456789"} {
+		_, err := ParseLSN(lsn)
+		assert.Error(t, err, lsn)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(nil)
+
+	// Caught up
+	m, err := tracker.Observe(start, &SlotPosition{Head: "0/1000", Confirmed: "0/1000"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), *m.Backlog)
+	assert.Equal(t, time.Duration(0), *m.Latency)
+	assert.Equal(t, UnitBytes, m.Unit)
+
+	// New WAL the slot has not confirmed is as old as the measurement that first saw it
+	_, err = tracker.Observe(start.Add(time.Second*30), &SlotPosition{Head: "0/2000", Confirmed: "0/1000"})
+	require.NoError(t, err)
+	m, err = tracker.Observe(start.Add(time.Minute), &SlotPosition{Head: "0/3000", Confirmed: "0/1800"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0x1800), *m.Backlog)
+	assert.Equal(t, time.Second*30, *m.Latency)
+	assert.Equal(t, "0/3000", m.SourcePosition)
+	assert.Equal(t, "0/1800", m.TargetPosition)
+
+	// Once the first backlog is confirmed, the latency is that of the next
+	m, err = tracker.Observe(start.Add(time.Minute*2), &SlotPosition{Head: "0/3000", Confirmed: "0/2000"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, *m.Latency)
+
+	_, err = tracker.Observe(start, &SlotPosition{Head: "bad", Confirmed: "0/1"})
+	assert.Error(t, err)
+}
+
+func TestNewTracker_CarriesOnFromPreviousSample(t *testing.T) {
+	measured := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	previous := &data.ReplicationLagSample{
+		LatencyMs:      gauge(90000),
+		BacklogUnit:    UnitBytes,
+		SourcePosition: "0/5000",
+		TargetPosition: "0/1000",
+		MeasuredAt:     measured,
+	}
+	tracker := NewTracker(previous)
+	m, err := tracker.Observe(measured.Add(time.Minute), &SlotPosition{Head: "0/6000", Confirmed: "0/1000"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute*2+time.Second*30, *m.Latency)
+
+	// Kafka samples and caught-up samples start afresh
+	previous.BacklogUnit = UnitMessages
+	assert.Empty(t, NewTracker(previous).marks)
+	previous.BacklogUnit, previous.TargetPosition = UnitBytes, "0/5000"
+	assert.Empty(t, NewTracker(previous).marks)
+}
+
+func TestTransition(t *testing.T) {
+	over, under := time.Minute*5, time.Second
+	threshold := time.Minute
+
+	raise, clear := Transition(threshold, false, &over)
+	assert.True(t, raise)
+	assert.False(t, clear)
+
+	raise, clear = Transition(threshold, true, &over)
+	assert.False(t, raise)
+	assert.False(t, clear)
+
+	raise, clear = Transition(threshold, true, &under)
+	assert.False(t, raise)
+	assert.True(t, clear)
+
+	raise, clear = Transition(threshold, true, nil)
+	assert.False(t, raise || clear, "Unknown latencies change nothing")
+	raise, clear = Transition(0, false, &over)
+	assert.False(t, raise || clear, "No threshold, no alert")
+}
+
+func TestMeasurementSample(t *testing.T) {
+	latency := time.Millisecond * 2500
+	at := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	sample := (&Measurement{Latency: &latency, Backlog: gauge(7), Unit: UnitMessages}).Sample(12, 1031, at)
+	assert.Equal(t, int64(12), sample.ReplicationTaskID)
+	assert.Equal(t, int64(1031), *sample.ReplicationRunID)
+	assert.Equal(t, int64(2500), *sample.LatencyMs)
+	assert.Equal(t, int64(7), *sample.Backlog)
+	assert.Equal(t, at, sample.MeasuredAt)
+
+	assert.Nil(t, (&Measurement{Unit: UnitMessages}).Sample(12, 1031, at).LatencyMs)
+}
@@ -0,0 +1,92 @@
+package lag
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// TaskLag is the latest lag sample of a streaming task, with what its gauges are labelled and
+// alerted with.
+type TaskLag struct {
+	TaskID    int64
+	TaskName  string
+	Threshold time.Duration // Lag alert threshold; zero for none
+	Sample    *data.ReplicationLagSample
+}
+
+// WritePrometheus writes the lag gauges of streaming tasks in the Prometheus text format.
+// Unknown values are left out.
+func WritePrometheus(w io.Writer, tasks []TaskLag) error {
+	gauges := []struct {
+		name  string
+		help  string
+		value func(task TaskLag) (float64, bool)
+		extra func(task TaskLag) string
+	}{
+		{
+			name: "hsoetlnlm_replication_lag_seconds",
+			help: "Latency from source commit to target apply of a streaming task, as last measured.",
+			value: func(task TaskLag) (float64, bool) {
+				if task.Sample.LatencyMs == nil {
+					return 0, false
+				}
+				return float64(*task.Sample.LatencyMs) / 1000, true
+			},
+		},
+		{
+			name: "hsoetlnlm_replication_backlog",
+			help: "Changes a streaming task's target trails its source by, in WAL bytes or messages.",
+			value: func(task TaskLag) (float64, bool) {
+				if task.Sample.Backlog == nil {
+					return 0, false
+				}
+				return float64(*task.Sample.Backlog), true
+			},
+			extra: func(task TaskLag) string { return fmt.Sprintf(`,unit="%s"`, escapeLabel(task.Sample.BacklogUnit)) },
+		},
+		{
+			name: "hsoetlnlm_replication_lag_measured_timestamp_seconds",
+			help: "Unix time of a streaming task's last lag measurement.",
+			value: func(task TaskLag) (float64, bool) {
+				return float64(task.Sample.MeasuredAt.UnixMilli()) / 1000, true
+			},
+		},
+		{
+			name: "hsoetlnlm_replication_lag_alert_threshold_seconds",
+			help: "Lag alert threshold of a streaming task.",
+			value: func(task TaskLag) (float64, bool) {
+				return task.Threshold.Seconds(), task.Threshold > 0
+			},
+		},
+	}
+
+	for _, gauge := range gauges {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", gauge.name, gauge.help, gauge.name); err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			value, ok := gauge.value(task)
+			if !ok {
+				continue
+			}
+			labels := fmt.Sprintf(`task_id="%d",task="%s"`, task.TaskID, escapeLabel(task.TaskName))
+			if gauge.extra != nil {
+				labels += gauge.extra(task)
+			}
+			if _, err := fmt.Fprintf(w, "%s{%s} %s\n", gauge.name, labels, strconv.FormatFloat(value, 'f', -1, 64)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,47 @@
+package lag
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	measured := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tasks := []TaskLag{
+		{
+			TaskID:    12,
+			TaskName:  `orders "cdc"`,
+			Threshold: time.Minute,
+			Sample: &data.ReplicationLagSample{
+				LatencyMs: gauge(1500), Backlog: gauge(4096), BacklogUnit: UnitBytes, MeasuredAt: measured,
+			},
+		},
+		{
+			TaskID:   14,
+			TaskName: "clicks",
+			Sample:   &data.ReplicationLagSample{BacklogUnit: UnitMessages, MeasuredAt: measured},
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, WritePrometheus(&out, tasks))
+	assert.Equal(t, `# HELP hsoetlnlm_replication_lag_seconds Latency from source commit to target apply of a streaming task, as last measured.
+# TYPE hsoetlnlm_replication_lag_seconds gauge
+hsoetlnlm_replication_lag_seconds{task_id="12",task="orders \"cdc\""} 1.5
+# HELP hsoetlnlm_replication_backlog Changes a streaming task's target trails its source by, in WAL bytes or messages.
+# TYPE hsoetlnlm_replication_backlog gauge
+hsoetlnlm_replication_backlog{task_id="12",task="orders \"cdc\"",unit="bytes"} 4096
+# HELP hsoetlnlm_replication_lag_measured_timestamp_seconds Unix time of a streaming task's last lag measurement.
+# TYPE hsoetlnlm_replication_lag_measured_timestamp_seconds gauge
+hsoetlnlm_replication_lag_measured_timestamp_seconds{task_id="12",task="orders \"cdc\""} 1792324800
+hsoetlnlm_replication_lag_measured_timestamp_seconds{task_id="14",task="clicks"} 1792324800
+# HELP hsoetlnlm_replication_lag_alert_threshold_seconds Lag alert threshold of a streaming task.
+# TYPE hsoetlnlm_replication_lag_alert_threshold_seconds gauge
+hsoetlnlm_replication_lag_alert_threshold_seconds{task_id="12",task="orders \"cdc\""} 60
+`, out.String())
+}
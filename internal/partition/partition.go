@@ -0,0 +1,325 @@
+// Package partition splits a SQL source query into chunks on a split column, so a large
+// table can be extracted by several pipelines in parallel.
+package partition
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// Chunk boundary methods.
+const (
+	MethodMinMax = "minmax" // Equal-width ranges between MIN and MAX of an integer column
+	MethodSample = "sample" // Ranges holding similar row counts, from quantiles of a random sample of the column
+)
+
+const (
+	defaultMaxParallel = 4
+	maxChunks          = 1000
+	// The sample method reads at most max(minSampleRows, chunks*sampleRowsPerChunk) values.
+	minSampleRows      = 10000
+	sampleRowsPerChunk = 100
+)
+
+var (
+	// ErrInvalidPartitioning is returned for malformed partitioning settings.
+	ErrInvalidPartitioning = errors.New("invalid partitioning")
+	// ErrNoSplitColumn is returned when the query has no column of the split column's name.
+	ErrNoSplitColumn = errors.New("split column not found")
+)
+
+// Partitioning is a task's chunked extraction settings.
+type Partitioning struct {
+	Column      string `json:"column"`
+	Chunks      int    `json:"chunks"`
+	Method      string `json:"method,omitempty"`       // minmax (default) or sample
+	MaxParallel int    `json:"max_parallel,omitempty"` // Chunks extracted at the same time
+}
+
+// Parse parses a task's partitioning JSON and fills in defaults. It returns nil when the
+// task is not partitioned.
+func Parse(raw string) (*Partitioning, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	p := &Partitioning{}
+	if err := json.Unmarshal([]byte(raw), p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPartitioning, err)
+	}
+	p.Column = strings.TrimSpace(p.Column)
+	if p.Column == "" {
+		return nil, fmt.Errorf("%w: column is required", ErrInvalidPartitioning)
+	}
+	if p.Chunks < 2 || p.Chunks > maxChunks {
+		return nil, fmt.Errorf("%w: chunks must be between 2 and %d", ErrInvalidPartitioning, maxChunks)
+	}
+	switch p.Method {
+	case "":
+		p.Method = MethodMinMax
+	case MethodMinMax, MethodSample:
+	default:
+		return nil, fmt.Errorf("%w: method must be %s or %s", ErrInvalidPartitioning, MethodMinMax, MethodSample)
+	}
+	if p.MaxParallel < 0 {
+		return nil, fmt.Errorf("%w: max_parallel must not be negative", ErrInvalidPartitioning)
+	}
+	if p.MaxParallel == 0 {
+		p.MaxParallel = defaultMaxParallel
+	}
+	return p, nil
+}
+
+// Supported reports whether a task with these connection types can be partitioned.
+func Supported(sourceType, targetType string) error {
+	switch sourceType {
+	case "sqlserver", "oracle", "postgres", "mysql":
+	default:
+		return fmt.Errorf("%w: %s sources cannot be partitioned", ErrInvalidPartitioning, sourceType)
+	}
+	if targetType == "localfile" {
+		// Each chunk would reset and publish the run's staged files
+		return fmt.Errorf("%w: localfile targets cannot be loaded by chunks", ErrInvalidPartitioning)
+	}
+	return nil
+}
+
+// Query restricts a source query to a chunk. An empty predicate leaves the query as it is.
+func Query(query, predicate string) string {
+	if predicate == "" {
+		return query
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) q WHERE %s", trimQuery(query), predicate)
+}
+
+func trimQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), ";")
+}
+
+// splitColumn is the split column of a query, quoted, with the way its values are written
+// as SQL literals.
+type splitColumn struct {
+	quoted  string
+	integer bool
+	literal func(string) (string, error)
+}
+
+// Plan computes the chunk predicates of a query. Every row falls in exactly one chunk; NULL
+// split values go to the first. Fewer chunks than requested are returned when values repeat
+// or the range is small, and a single empty predicate for an empty source.
+func Plan(ctx context.Context, sourceConn *data.Connection, query string, p *Partitioning) ([]string, error) {
+	column, err := resolveSplitColumn(ctx, sourceConn, query, p.Column)
+	if err != nil {
+		return nil, err
+	}
+	db, err := schema.OpenDB(ctx, sourceConn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	from := "(" + trimQuery(query) + ") q"
+	var bounds []string
+	switch p.Method {
+	case MethodMinMax:
+		if !column.integer {
+			return nil, fmt.Errorf("%w: minmax needs an integer split column; use method sample", ErrInvalidPartitioning)
+		}
+		var low, high sql.NullString
+		if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", column.quoted, column.quoted, from)).Scan(&low, &high); err != nil {
+			return nil, fmt.Errorf("error reading split column range: %w", err)
+		}
+		if !low.Valid || !high.Valid {
+			return []string{""}, nil
+		}
+		if bounds, err = minMaxBounds(low.String, high.String, p.Chunks); err != nil {
+			return nil, err
+		}
+	case MethodSample:
+		query, err := sampleQuery(sourceConn.Type, column.quoted, from, max(minSampleRows, p.Chunks*sampleRowsPerChunk))
+		if err != nil {
+			return nil, err
+		}
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("error sampling split column: %w", err)
+		}
+		defer rows.Close()
+		var sample []string
+		for rows.Next() {
+			var value string
+			if err := rows.Scan(&value); err != nil {
+				return nil, fmt.Errorf("error sampling split column: %w", err)
+			}
+			sample = append(sample, value)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error sampling split column: %w", err)
+		}
+		if len(sample) == 0 {
+			return []string{""}, nil
+		}
+		bounds = sampleBounds(sample, p.Chunks)
+	}
+	return predicates(column, dedupe(bounds))
+}
+
+// resolveSplitColumn finds the split column in the query's result columns, matching its name
+// case-insensitively.
+func resolveSplitColumn(ctx context.Context, sourceConn *data.Connection, query, name string) (*splitColumn, error) {
+	inspector, err := schema.Open(ctx, sourceConn)
+	if err != nil {
+		return nil, err
+	}
+	defer inspector.Close()
+	describer, ok := inspector.(schema.QueryDescriber)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot describe %s queries", ErrInvalidPartitioning, sourceConn.Type)
+	}
+	columns, err := describer.DescribeQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error describing source query: %w", err)
+	}
+
+	for _, column := range columns {
+		if !strings.EqualFold(column.Name, name) {
+			continue
+		}
+		quoted, err := schema.QuoteIdentifier(sourceConn.Type, column.Name)
+		if err != nil {
+			return nil, err
+		}
+		logical, err := ddl.SourceType(sourceConn.Type, column)
+		if err != nil {
+			return nil, fmt.Errorf("%w: split column %s: %v", ErrInvalidPartitioning, column.Name, err)
+		}
+		switch {
+		case logical.Kind == ddl.KindInt16 || logical.Kind == ddl.KindInt32 || logical.Kind == ddl.KindInt64,
+			logical.Kind == ddl.KindDecimal && logical.Scale == 0:
+			return &splitColumn{quoted: quoted, integer: true, literal: integerLiteral}, nil
+		case logical.Kind == ddl.KindString:
+			return &splitColumn{quoted: quoted, literal: stringLiteral(sourceConn.Type)}, nil
+		default:
+			return nil, fmt.Errorf("%w: split column %s must be an integer or string column, not %s", ErrInvalidPartitioning, column.Name, column.DataType)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoSplitColumn, name)
+}
+
+// minMaxBounds splits [low, high] into chunks of equal width, returning each chunk's inclusive
+// upper bound but the last's.
+func minMaxBounds(low, high string, chunks int) ([]string, error) {
+	lowValue, ok := new(big.Int).SetString(strings.TrimSpace(low), 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: split column minimum %q is not an integer", ErrInvalidPartitioning, low)
+	}
+	highValue, ok := new(big.Int).SetString(strings.TrimSpace(high), 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: split column maximum %q is not an integer", ErrInvalidPartitioning, high)
+	}
+	span := new(big.Int).Sub(highValue, lowValue)
+	span.Add(span, big.NewInt(1))
+
+	bounds := make([]string, 0, chunks-1)
+	for i := 1; i < chunks; i++ {
+		// low + span*i/chunks - 1 is the last value of chunk i
+		bound := new(big.Int).Mul(span, big.NewInt(int64(i)))
+		bound.Quo(bound, big.NewInt(int64(chunks)))
+		bound.Add(bound, lowValue)
+		bound.Sub(bound, big.NewInt(1))
+		if bound.Cmp(lowValue) >= 0 && bound.Cmp(highValue) < 0 {
+			bounds = append(bounds, bound.String())
+		}
+	}
+	return bounds, nil
+}
+
+// sampleQuery reads up to rows random non-NULL values of the split column, sorted by the
+// source so bounds follow its collation. The random pick is a top-N sort: it holds only the
+// sampled values in memory, but still scans the whole query once.
+func sampleQuery(connType, column, from string, rows int) (string, error) {
+	var sample string
+	switch connType {
+	case "sqlserver":
+		sample = fmt.Sprintf("SELECT TOP (%d) %s FROM %s WHERE %s IS NOT NULL ORDER BY NEWID()", rows, column, from, column)
+	case "oracle":
+		sample = fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL ORDER BY DBMS_RANDOM.VALUE FETCH FIRST %d ROWS ONLY", column, from, column, rows)
+	case "postgres":
+		sample = fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL ORDER BY random() LIMIT %d", column, from, column, rows)
+	case "mysql":
+		sample = fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL ORDER BY RAND() LIMIT %d", column, from, column, rows)
+	default:
+		return "", fmt.Errorf("%w: %s sources cannot be sampled", ErrInvalidPartitioning, connType)
+	}
+	return fmt.Sprintf("SELECT %s FROM (%s) s ORDER BY %s", column, sample, column), nil
+}
+
+// sampleBounds picks the inclusive upper bound of each chunk but the last from an ascending
+// sample: the largest value of each of chunks equal slices of it.
+func sampleBounds(sample []string, chunks int) []string {
+	bounds := make([]string, 0, chunks-1)
+	for i := 1; i < chunks; i++ {
+		if end := i * len(sample) / chunks; end > 0 {
+			bounds = append(bounds, sample[end-1])
+		}
+	}
+	return bounds
+}
+
+func dedupe(bounds []string) []string {
+	var unique []string
+	for i, bound := range bounds {
+		if i == 0 || bound != bounds[i-1] {
+			unique = append(unique, bound)
+		}
+	}
+	return unique
+}
+
+// predicates turns ascending inclusive upper bounds into one condition per chunk.
+func predicates(column *splitColumn, bounds []string) ([]string, error) {
+	literals := make([]string, len(bounds))
+	for i, bound := range bounds {
+		literal, err := column.literal(bound)
+		if err != nil {
+			return nil, err
+		}
+		literals[i] = literal
+	}
+	if len(literals) == 0 {
+		return []string{""}, nil // One chunk holds everything
+	}
+
+	c := column.quoted
+	result := []string{fmt.Sprintf("(%s <= %s OR %s IS NULL)", c, literals[0], c)}
+	for i := 1; i < len(literals); i++ {
+		result = append(result, fmt.Sprintf("%s > %s AND %s <= %s", c, literals[i-1], c, literals[i]))
+	}
+	return append(result, fmt.Sprintf("%s > %s", c, literals[len(literals)-1])), nil
+}
+
+var integerValue = regexp.MustCompile(`^-?\d+$`)
+
+func integerLiteral(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !integerValue.MatchString(value) {
+		return "", fmt.Errorf("%w: split value %q is not an integer", ErrInvalidPartitioning, value)
+	}
+	return value, nil
+}
+
+// stringLiteral quotes split values in a source's dialect.
+func stringLiteral(connType string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		return schema.QuoteString(connType, value)
+	}
+}
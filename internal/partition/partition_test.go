@@ -0,0 +1,119 @@
+package partition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse("")
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	p, err = Parse(`{"column": " OrderID ", "chunks": 8}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Partitioning{Column: "OrderID", Chunks: 8, Method: MethodMinMax, MaxParallel: 4}, p)
+
+	for _, raw := range []string{
+		`{"chunks": 8}`,
+		`{"column": "id", "chunks": 1}`,
+		`{"column": "id", "chunks": 5000}`,
+		`{"column": "id", "chunks": 4, "method": "hash"}`,
+		`{"column": "id", "chunks": 4, "max_parallel": -1}`,
+		`"id"`,
+	} {
+		_, err := Parse(raw)
+		assert.ErrorIs(t, err, ErrInvalidPartitioning, raw)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	assert.NoError(t, Supported("sqlserver", "snowflake"))
+	assert.ErrorIs(t, Supported("kafka", "snowflake"), ErrInvalidPartitioning)
+	assert.ErrorIs(t, Supported("postgres", "localfile"), ErrInvalidPartitioning)
+}
+
+func TestMinMaxBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		low, high string
+		chunks    int
+		want      []string
+	}{
+		{"even split", "1", "100", 4, []string{"25", "50", "75"}},
+		{"negative range", "-10", "9", 2, []string{"-1"}},
+		{"fewer values than chunks", "1", "3", 10, []string{"1", "1", "1", "2", "2", "2"}},
+		{"single value", "7", "7", 4, []string{}},
+		{"beyond int64", "0", "99999999999999999999", 2, []string{"49999999999999999999"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounds, err := minMaxBounds(tt.low, tt.high, tt.chunks)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, bounds)
+		})
+	}
+
+	_, err := minMaxBounds("1.5", "10", 2)
+	assert.ErrorIs(t, err, ErrInvalidPartitioning)
+}
+
+func TestSampleBounds(t *testing.T) {
+	assert.Equal(t, []string{"b", "d", "f"}, sampleBounds([]string{"a", "b", "c", "d", "e", "f", "g", "h"}, 4))
+	assert.Equal(t, []string{"a", "a", "b"}, sampleBounds([]string{"a", "b", "c"}, 5), "Small samples repeat bounds, which dedupe drops")
+	assert.Empty(t, sampleBounds([]string{"a"}, 2))
+}
+
+func TestSampleQuery(t *testing.T) {
+	query, err := sampleQuery("postgres", `"OrderID"`, "(SELECT * FROM orders) q", 10000)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT "OrderID" FROM (SELECT "OrderID" FROM (SELECT * FROM orders) q WHERE "OrderID" IS NOT NULL ORDER BY random() LIMIT 10000) s ORDER BY "OrderID"`, query)
+
+	query, err = sampleQuery("sqlserver", "[OrderID]", "(SELECT * FROM orders) q", 500)
+	require.NoError(t, err)
+	assert.Contains(t, query, "SELECT TOP (500) [OrderID]")
+	assert.Contains(t, query, "ORDER BY NEWID()")
+
+	_, err = sampleQuery("bigquery", "`id`", "t", 10)
+	assert.ErrorIs(t, err, ErrInvalidPartitioning)
+}
+
+func TestPredicates(t *testing.T) {
+	// Arrange
+	integer := &splitColumn{quoted: `"id"`, integer: true, literal: integerLiteral}
+	text := &splitColumn{quoted: "[Code]", literal: stringLiteral("sqlserver")}
+
+	// Act
+	integerPredicates, err := predicates(integer, dedupe([]string{"1", "1", "2"}))
+	require.NoError(t, err)
+	textPredicates, err := predicates(text, []string{"M'c"})
+	require.NoError(t, err)
+	single, err := predicates(integer, nil)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, []string{`("id" <= 1 OR "id" IS NULL)`, `"id" > 1 AND "id" <= 2`, `"id" > 2`}, integerPredicates)
+	assert.Equal(t, []string{"([Code] <= N'M''c' OR [Code] IS NULL)", "[Code] > N'M''c'"}, textPredicates)
+	assert.Equal(t, []string{""}, single)
+
+	_, err = predicates(integer, []string{"1; DROP TABLE x"})
+	assert.ErrorIs(t, err, ErrInvalidPartitioning)
+}
+
+func TestStringLiteral(t *testing.T) {
+	quoted, err := stringLiteral("mysql")(`a\'b`)
+	require.NoError(t, err)
+	assert.Equal(t, `'a\\''b'`, quoted)
+
+	quoted, err = stringLiteral("oracle")("O'Neil")
+	require.NoError(t, err)
+	assert.Equal(t, "'O''Neil'", quoted)
+}
+
+func TestQuery(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM t", Query("SELECT * FROM t", ""))
+	assert.Equal(t, `SELECT * FROM (SELECT * FROM t) q WHERE "id" > 5`, Query(" SELECT * FROM t; ", `"id" > 5`))
+}
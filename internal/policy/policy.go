@@ -0,0 +1,138 @@
+// Package policy parses a task's execution policy: how its activities are retried and how
+// long its pipelines may run before they time out.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxAttempts         = 100
+	minActivityTimeout  = time.Second * 10
+	maxActivityTimeout  = time.Hour * 24
+	minPipelineTimeout  = time.Minute
+	maxPipelineTimeout  = time.Hour * 24 * 7
+	minHeartbeatTimeout = time.Second * 10
+)
+
+// ErrInvalidPolicy is returned for malformed execution policies.
+var ErrInvalidPolicy = errors.New("invalid execution policy")
+
+var errorTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.*]*$`)
+
+// Policy is a task's execution policy with defaults filled in.
+type Policy struct {
+	MaxAttempts        int32         `json:"max_attempts"`
+	InitialInterval    time.Duration `json:"initial_interval"`
+	BackoffCoefficient float64       `json:"backoff_coefficient"`
+	MaximumInterval    time.Duration `json:"maximum_interval"`
+	ActivityTimeout    time.Duration `json:"activity_timeout"`  // Bookkeeping activities (runs, drift checks, table creation)
+	PipelineTimeout    time.Duration `json:"pipeline_timeout"`  // One pipeline execution (a task, table or chunk)
+	HeartbeatTimeout   time.Duration `json:"heartbeat_timeout"` // Pipelines not heard from for this long are retried
+	NonRetryableErrors []string      `json:"non_retryable_errors,omitempty"`
+}
+
+// config is the JSON stored on the task. Durations are Go duration strings, e.g. 90s or 2h.
+type config struct {
+	MaxAttempts        *int32   `json:"max_attempts"`
+	InitialInterval    string   `json:"initial_interval"`
+	BackoffCoefficient *float64 `json:"backoff_coefficient"`
+	MaximumInterval    string   `json:"maximum_interval"`
+	ActivityTimeout    string   `json:"activity_timeout"`
+	PipelineTimeout    string   `json:"pipeline_timeout"`
+	HeartbeatTimeout   string   `json:"heartbeat_timeout"`
+	NonRetryableErrors []string `json:"non_retryable_errors"`
+}
+
+// Default is the policy of tasks that set none.
+func Default() *Policy {
+	return &Policy{
+		MaxAttempts:        3,
+		InitialInterval:    time.Second,
+		BackoffCoefficient: 2.0,
+		MaximumInterval:    time.Minute * 5,
+		ActivityTimeout:    time.Minute * 5,
+		PipelineTimeout:    time.Hour,
+		HeartbeatTimeout:   time.Minute * 2,
+	}
+}
+
+// Parse parses a task's execution policy JSON. Unset values take their defaults; an empty
+// policy is the default policy.
+func Parse(raw string) (*Policy, error) {
+	p := Default()
+	if strings.TrimSpace(raw) == "" {
+		return p, nil
+	}
+	c := &config{}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+
+	if c.MaxAttempts != nil {
+		p.MaxAttempts = *c.MaxAttempts
+	}
+	if c.BackoffCoefficient != nil {
+		p.BackoffCoefficient = *c.BackoffCoefficient
+	}
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"initial_interval", c.InitialInterval, &p.InitialInterval},
+		{"maximum_interval", c.MaximumInterval, &p.MaximumInterval},
+		{"activity_timeout", c.ActivityTimeout, &p.ActivityTimeout},
+		{"pipeline_timeout", c.PipelineTimeout, &p.PipelineTimeout},
+		{"heartbeat_timeout", c.HeartbeatTimeout, &p.HeartbeatTimeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		value, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %q is not a duration like 90s or 2h", ErrInvalidPolicy, d.name, d.value)
+		}
+		*d.dest = value
+	}
+	for _, name := range c.NonRetryableErrors {
+		name = strings.TrimSpace(name)
+		if !errorTypePattern.MatchString(name) {
+			return nil, fmt.Errorf("%w: %q is not an error type name", ErrInvalidPolicy, name)
+		}
+		p.NonRetryableErrors = append(p.NonRetryableErrors, name)
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Policy) validate() error {
+	switch {
+	case p.MaxAttempts < 1 || p.MaxAttempts > maxAttempts:
+		return fmt.Errorf("%w: max_attempts must be between 1 and %d", ErrInvalidPolicy, maxAttempts)
+	case p.InitialInterval <= 0:
+		return fmt.Errorf("%w: initial_interval must be positive", ErrInvalidPolicy)
+	case p.BackoffCoefficient < 1:
+		return fmt.Errorf("%w: backoff_coefficient must be at least 1", ErrInvalidPolicy)
+	case p.MaximumInterval < p.InitialInterval:
+		return fmt.Errorf("%w: maximum_interval must not be shorter than initial_interval", ErrInvalidPolicy)
+	case p.ActivityTimeout < minActivityTimeout || p.ActivityTimeout > maxActivityTimeout:
+		return fmt.Errorf("%w: activity_timeout must be between %v and %v", ErrInvalidPolicy, minActivityTimeout, maxActivityTimeout)
+	case p.PipelineTimeout < minPipelineTimeout || p.PipelineTimeout > maxPipelineTimeout:
+		return fmt.Errorf("%w: pipeline_timeout must be between %v and %v", ErrInvalidPolicy, minPipelineTimeout, maxPipelineTimeout)
+	case p.HeartbeatTimeout < minHeartbeatTimeout || p.HeartbeatTimeout > p.PipelineTimeout:
+		return fmt.Errorf("%w: heartbeat_timeout must be between %v and the pipeline_timeout", ErrInvalidPolicy, minHeartbeatTimeout)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Defaults(t *testing.T) {
+	p, err := Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), p)
+
+	p, err = Parse("{}")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), p)
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse(`{
+		"max_attempts": 5,
+		"initial_interval": "10s",
+		"backoff_coefficient": 1.5,
+		"maximum_interval": "10m",
+		"activity_timeout": "2m",
+		"pipeline_timeout": "6h",
+		"heartbeat_timeout": "5m",
+		"non_retryable_errors": ["ConfigGenerationFailed", " SchemaDrift "]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Policy{
+		MaxAttempts:        5,
+		InitialInterval:    time.Second * 10,
+		BackoffCoefficient: 1.5,
+		MaximumInterval:    time.Minute * 10,
+		ActivityTimeout:    time.Minute * 2,
+		PipelineTimeout:    time.Hour * 6,
+		HeartbeatTimeout:   time.Minute * 5,
+		NonRetryableErrors: []string{"ConfigGenerationFailed", "SchemaDrift"},
+	}, p)
+
+	// Unset values keep their defaults
+	p, err = Parse(`{"pipeline_timeout": "4h"}`)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour*4, p.PipelineTimeout)
+	assert.Equal(t, int32(3), p.MaxAttempts)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		`{"max_attempts": 0}`,
+		`{"max_attempts": 1000}`,
+		`{"initial_interval": "soon"}`,
+		`{"initial_interval": "-1s"}`,
+		`{"backoff_coefficient": 0.5}`,
+		`{"initial_interval": "10m", "maximum_interval": "1m"}`,
+		`{"activity_timeout": "1s"}`,
+		`{"pipeline_timeout": "30s"}`,
+		`{"pipeline_timeout": "720h"}`,
+		`{"heartbeat_timeout": "2h"}`,
+		`{"heartbeat_timeout": "1s"}`,
+		`{"non_retryable_errors": ["not a type"]}`,
+		`{"max_attempt": 3}`,
+		`[]`,
+	} {
+		_, err := Parse(raw)
+		assert.ErrorIs(t, err, ErrInvalidPolicy, raw)
+	}
+}
@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// bigQueryID restricts dataset names before they are embedded in a query.
+var bigQueryID = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// bigQueryInspector lists datasets and tables through the BigQuery API. Credentials come from
+// the environment (Application Default Credentials), as for the pipeline input.
+type bigQueryInspector struct {
+	client  *bigquery.Client
+	project string
+	dataset string
+}
+
+func openBigQuery(ctx context.Context, params map[string]string) (*bigQueryInspector, error) {
+	project, ok := params["project"]
+	if !ok {
+		return nil, fmt.Errorf("'project' not found in connection string for BigQuery")
+	}
+	client, err := bigquery.NewClient(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("error creating BigQuery client: %w", err)
+	}
+	return &bigQueryInspector{client: client, project: project, dataset: params["dataset"]}, nil
+}
+
+func (i *bigQueryInspector) DefaultSchema(ctx context.Context) (string, error) {
+	return i.dataset, nil
+}
+
+func (i *bigQueryInspector) ListSchemas(ctx context.Context) ([]string, error) {
+	datasets := []string{}
+	it := i.client.Datasets(ctx)
+	for {
+		ds, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return datasets, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing datasets: %w", err)
+		}
+		datasets = append(datasets, ds.DatasetID)
+	}
+}
+
+func (i *bigQueryInspector) ListTables(ctx context.Context, dataset string) ([]Table, error) {
+	if !bigQueryID.MatchString(dataset) {
+		return nil, fmt.Errorf("invalid BigQuery dataset name: %s", dataset)
+	}
+	// __TABLES__ carries row counts without scanning the tables; type 1 is a table
+	query := i.client.Query(fmt.Sprintf(
+		"SELECT dataset_id, table_id, row_count FROM `%s.%s.__TABLES__` WHERE type = 1 ORDER BY table_id",
+		i.project, dataset))
+	it, err := query.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tables in %s: %w", dataset, err)
+	}
+
+	tables := []Table{}
+	for {
+		var row struct {
+			DatasetID string `bigquery:"dataset_id"`
+			TableID   string `bigquery:"table_id"`
+			RowCount  int64  `bigquery:"row_count"`
+		}
+		err := it.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			return tables, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading table row: %w", err)
+		}
+		rowCount := row.RowCount
+		tables = append(tables, Table{Schema: row.DatasetID, Name: row.TableID, RowEstimate: &rowCount})
+	}
+}
+
+func (i *bigQueryInspector) ListColumns(ctx context.Context, dataset, table string) ([]Column, error) {
+	md, err := i.client.Dataset(dataset).Table(table).Metadata(ctx)
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil, ErrTableNotFound
+		}
+		return nil, fmt.Errorf("error reading metadata of %s.%s: %w", dataset, table, err)
+	}
+
+	primaryKey := map[string]bool{}
+	if md.TableConstraints != nil && md.TableConstraints.PrimaryKey != nil {
+		for _, name := range md.TableConstraints.PrimaryKey.Columns {
+			primaryKey[name] = true
+		}
+	}
+
+	columns := make([]Column, 0, len(md.Schema))
+	for idx, field := range md.Schema {
+		dataType := string(field.Type)
+		if field.Repeated {
+			dataType = "ARRAY<" + dataType + ">"
+		}
+		column := Column{
+			Name:       field.Name,
+			Position:   idx + 1,
+			DataType:   dataType,
+			Nullable:   !field.Required && !field.Repeated,
+			PrimaryKey: primaryKey[field.Name],
+		}
+		if field.MaxLength > 0 {
+			column.MaxLength = &field.MaxLength
+		}
+		if field.Precision > 0 {
+			column.Precision = &field.Precision
+			column.Scale = &field.Scale
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func (i *bigQueryInspector) Close() error {
+	return i.client.Close()
+}
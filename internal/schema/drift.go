@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Drift policies decide what a run does when its source schema changed since the last run.
+const (
+	DriftPolicyFail       = "fail"        // Fail the run (default)
+	DriftPolicyIgnore     = "ignore"      // Record the drift and load as usual
+	DriftPolicyAddColumns = "add_columns" // Add new columns to the target as nullable, then load
+	DriftPolicyQuarantine = "quarantine"  // Skip the load until the drift is accepted
+)
+
+// ParseDriftPolicy validates a task's drift policy, defaulting to fail.
+func ParseDriftPolicy(raw string) (string, error) {
+	switch policy := strings.TrimSpace(raw); policy {
+	case "":
+		return DriftPolicyFail, nil
+	case DriftPolicyFail, DriftPolicyIgnore, DriftPolicyAddColumns, DriftPolicyQuarantine:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid drift policy %q: must be one of fail, ignore, add_columns, quarantine", raw)
+	}
+}
+
+// Drift lists the column changes between two snapshots of a source table or query.
+type Drift struct {
+	Added   []Column       `json:"added,omitempty"`
+	Dropped []Column       `json:"dropped,omitempty"`
+	Changed []ColumnChange `json:"changed,omitempty"`
+}
+
+// ColumnChange is a column whose type or nullability changed.
+type ColumnChange struct {
+	Name     string `json:"name"`
+	Previous Column `json:"previous"`
+	Current  Column `json:"current"`
+}
+
+// Empty reports whether nothing changed.
+func (d *Drift) Empty() bool {
+	return len(d.Added) == 0 && len(d.Dropped) == 0 && len(d.Changed) == 0
+}
+
+// String summarizes the drift, e.g. "added: region; dropped: fax; changed: amount (decimal(10,2) -> decimal(18,4))".
+func (d *Drift) String() string {
+	var parts []string
+	if len(d.Added) > 0 {
+		parts = append(parts, "added: "+columnNames(d.Added))
+	}
+	if len(d.Dropped) > 0 {
+		parts = append(parts, "dropped: "+columnNames(d.Dropped))
+	}
+	if len(d.Changed) > 0 {
+		changes := make([]string, 0, len(d.Changed))
+		for _, change := range d.Changed {
+			changes = append(changes, fmt.Sprintf("%s (%s -> %s)", change.Name, columnType(change.Previous), columnType(change.Current)))
+		}
+		parts = append(parts, "changed: "+strings.Join(changes, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Diff compares the previous and current columns of a table. Columns are matched by
+// case-insensitive name; reordering alone is not drift.
+func Diff(previous, current []Column) *Drift {
+	drift := &Drift{}
+	before := make(map[string]Column, len(previous))
+	for _, column := range previous {
+		before[strings.ToLower(column.Name)] = column
+	}
+	seen := make(map[string]bool, len(current))
+	for _, column := range current {
+		key := strings.ToLower(column.Name)
+		seen[key] = true
+		old, ok := before[key]
+		if !ok {
+			drift.Added = append(drift.Added, column)
+			continue
+		}
+		if columnType(old) != columnType(column) {
+			drift.Changed = append(drift.Changed, ColumnChange{Name: column.Name, Previous: old, Current: column})
+		}
+	}
+	for _, column := range previous {
+		if !seen[strings.ToLower(column.Name)] {
+			drift.Dropped = append(drift.Dropped, column)
+		}
+	}
+	return drift
+}
+
+// columnType renders a column's type with its size and nullability, e.g. nvarchar(50) or
+// decimal(18,4) not null.
+func columnType(column Column) string {
+	name := strings.ToLower(column.DataType)
+	switch {
+	case column.Precision != nil && column.Scale != nil:
+		name += fmt.Sprintf("(%d,%d)", *column.Precision, *column.Scale)
+	case column.Precision != nil:
+		name += fmt.Sprintf("(%d)", *column.Precision)
+	case column.MaxLength != nil:
+		name += fmt.Sprintf("(%d)", *column.MaxLength)
+	}
+	if !column.Nullable {
+		name += " not null"
+	}
+	return name
+}
+
+func columnNames(columns []Column) string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	return strings.Join(names, ", ")
+}
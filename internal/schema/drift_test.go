@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestDiff(t *testing.T) {
+	// Arrange
+	previous := []Column{
+		{Name: "OrderID", DataType: "bigint"},
+		{Name: "Amount", DataType: "decimal", Nullable: true, Precision: int64Ptr(10), Scale: int64Ptr(2)},
+		{Name: "Fax", DataType: "varchar", Nullable: true, MaxLength: int64Ptr(20)},
+		{Name: "Note", DataType: "nvarchar", Nullable: true, MaxLength: int64Ptr(100)},
+	}
+	current := []Column{
+		{Name: "Note", DataType: "nvarchar", Nullable: true, MaxLength: int64Ptr(100)},
+		{Name: "orderid", DataType: "BIGINT"},
+		{Name: "Amount", DataType: "decimal", Nullable: true, Precision: int64Ptr(18), Scale: int64Ptr(4)},
+		{Name: "Region", DataType: "nvarchar", Nullable: true, MaxLength: int64Ptr(50)},
+	}
+
+	// Act
+	drift := Diff(previous, current)
+
+	// Assert
+	require.False(t, drift.Empty())
+	assert.Equal(t, []Column{current[3]}, drift.Added)
+	assert.Equal(t, []Column{previous[2]}, drift.Dropped)
+	require.Len(t, drift.Changed, 1)
+	assert.Equal(t, "Amount", drift.Changed[0].Name)
+	assert.Equal(t, "added: Region; dropped: Fax; changed: Amount (decimal(10,2) -> decimal(18,4))", drift.String())
+}
+
+func TestDiff_NoDrift(t *testing.T) {
+	columns := []Column{{Name: "id", DataType: "int"}, {Name: "name", DataType: "text", Nullable: true}}
+	reordered := []Column{columns[1], columns[0]}
+
+	assert.True(t, Diff(columns, reordered).Empty(), "Reordering columns is not drift")
+}
+
+func TestDiff_NullabilityChange(t *testing.T) {
+	drift := Diff([]Column{{Name: "id", DataType: "int"}}, []Column{{Name: "id", DataType: "int", Nullable: true}})
+
+	require.Len(t, drift.Changed, 1)
+	assert.Equal(t, "changed: id (int not null -> int)", drift.String())
+}
+
+func TestParseDriftPolicy(t *testing.T) {
+	policy, err := ParseDriftPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, DriftPolicyFail, policy, "Fail is the default")
+
+	for _, raw := range []string{"fail", "ignore", "add_columns", "quarantine"} {
+		policy, err := ParseDriftPolicy(raw)
+		require.NoError(t, err)
+		assert.Equal(t, raw, policy)
+	}
+
+	_, err = ParseDriftPolicy("drop_columns")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid drift policy")
+}
@@ -0,0 +1,98 @@
+// Package schema introspects source databases so tasks can be built by picking tables.
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+var (
+	// ErrUnsupportedType is returned for connection types that cannot be introspected.
+	ErrUnsupportedType = errors.New("schema discovery is not supported for connection type")
+	// ErrTableNotFound is returned when a table does not exist or has no visible columns.
+	ErrTableNotFound = errors.New("table not found")
+	// ErrSchemaRequired is returned when no schema is given and the source has no default.
+	ErrSchemaRequired = errors.New("schema is required")
+)
+
+// Table is a table found in a source schema.
+type Table struct {
+	Schema      string `json:"schema"`
+	Name        string `json:"name"`
+	RowEstimate *int64 `json:"row_estimate,omitempty"` // From catalog statistics; nil when unknown
+}
+
+// Column describes a table column.
+type Column struct {
+	Name       string `json:"name"`
+	Position   int    `json:"position"`
+	DataType   string `json:"data_type"` // Source type name, e.g. nvarchar, NUMBER, INT64
+	Nullable   bool   `json:"nullable"`
+	PrimaryKey bool   `json:"primary_key"`
+	MaxLength  *int64 `json:"max_length,omitempty"`
+	Precision  *int64 `json:"precision,omitempty"`
+	Scale      *int64 `json:"scale,omitempty"`
+}
+
+// Inspector lists the schemas, tables and columns of a source connection.
+type Inspector interface {
+	// DefaultSchema is the schema used when a request doesn't name one.
+	DefaultSchema(ctx context.Context) (string, error)
+	ListSchemas(ctx context.Context) ([]string, error)
+	ListTables(ctx context.Context, schema string) ([]Table, error)
+	ListColumns(ctx context.Context, schema, table string) ([]Column, error)
+	Close() error
+}
+
+// QueryDescriber reports the result columns of a query without fetching rows. SQL sources
+// implement it; primary keys are never reported.
+type QueryDescriber interface {
+	DescribeQuery(ctx context.Context, query string) ([]Column, error)
+}
+
+// Open connects to the system behind a connection. The caller must Close the inspector.
+func Open(ctx context.Context, conn *data.Connection) (Inspector, error) {
+	params := benthos.ParseConnectionString(conn.ConnectionString)
+	switch conn.Type {
+	case "bigquery":
+		inspector, err := openBigQuery(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return inspector, nil
+	case "sqlserver", "oracle", "postgres", "mysql":
+		inspector, err := openSQLConnection(ctx, conn, params)
+		if err != nil {
+			return nil, err
+		}
+		return inspector, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, conn.Type)
+	}
+}
+
+// OpenDB opens a database/sql handle on a relational source connection (sqlserver, oracle,
+// postgres or mysql). The caller must Close it.
+func OpenDB(ctx context.Context, conn *data.Connection) (*sql.DB, error) {
+	inspector, err := openSQLConnection(ctx, conn, benthos.ParseConnectionString(conn.ConnectionString))
+	if err != nil {
+		return nil, err
+	}
+	return inspector.db, nil
+}
+
+func openSQLConnection(ctx context.Context, conn *data.Connection, params map[string]string) (*sqlInspector, error) {
+	dsn, err := benthos.LookupSecretRef(params["dsn"])
+	if err != nil {
+		return nil, err
+	}
+	if dsn == "" {
+		return nil, fmt.Errorf("'dsn' not found in connection string for %s", conn.Type)
+	}
+	return openSQL(ctx, conn.Type, dsn)
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		conn    data.Connection
+		wantErr string
+	}{
+		{"unsupported type", data.Connection{Type: "s3", ConnectionString: "bucket=b"}, "not supported for connection type: s3"},
+		{"missing dsn", data.Connection{Type: "postgres", ConnectionString: "user=x"}, "'dsn' not found"},
+		{"unset secret", data.Connection{Type: "mysql", ConnectionString: "dsn=env:HSOETLNLM_TEST_UNSET_DSN"}, "is not set in the environment"},
+		{"missing project", data.Connection{Type: "bigquery", ConnectionString: "dataset=d"}, "'project' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspector, err := Open(context.Background(), &tt.conn)
+			require.Error(t, err)
+			assert.Nil(t, inspector)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDialects_BindParameters(t *testing.T) {
+	// Each driver uses its own placeholder syntax for the schema and table parameters
+	assert.Contains(t, dialects["sqlserver"].columns, "c.TABLE_SCHEMA = @p1 AND c.TABLE_NAME = @p2")
+	assert.Contains(t, dialects["postgres"].columns, "c.TABLE_SCHEMA = $1 AND c.TABLE_NAME = $2")
+	assert.Contains(t, dialects["mysql"].columns, "c.TABLE_SCHEMA = ? AND c.TABLE_NAME = ?")
+	assert.Contains(t, dialects["oracle"].columns, "c.owner = :1 AND c.table_name = :2")
+	for name, d := range dialects {
+		assert.NotEmpty(t, d.defaultSchema, name)
+		assert.NotEmpty(t, d.schemas, name)
+		assert.NotEmpty(t, d.tables, name)
+	}
+}
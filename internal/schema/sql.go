@@ -0,0 +1,263 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"  // mysql driver
+	_ "github.com/lib/pq"               // postgres driver
+	_ "github.com/microsoft/go-mssqldb" // sqlserver driver
+	_ "github.com/sijms/go-ora/v2"      // oracle driver
+)
+
+// dialect holds the catalog queries for one database type. Queries take the schema (and
+// table) as bind parameters.
+type dialect struct {
+	driver        string
+	defaultSchema string
+	schemas       string
+	tables        string // schema, name, row estimate
+	columns       string // name, position, type, nullable, max length, precision, scale, is pk
+}
+
+// informationSchemaColumns lists columns with their primary key flag from the standard
+// INFORMATION_SCHEMA views shared by SQL Server, Postgres and MySQL.
+func informationSchemaColumns(schemaParam, tableParam string) string {
+	return fmt.Sprintf(`
+		SELECT c.COLUMN_NAME, c.ORDINAL_POSITION, c.DATA_TYPE, c.IS_NULLABLE,
+			c.CHARACTER_MAXIMUM_LENGTH, c.NUMERIC_PRECISION, c.NUMERIC_SCALE,
+			CASE WHEN pk.COLUMN_NAME IS NULL THEN 0 ELSE 1 END
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		LEFT JOIN (
+			SELECT ku.TABLE_SCHEMA, ku.TABLE_NAME, ku.COLUMN_NAME
+			FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+			JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE ku
+				ON ku.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+				AND ku.TABLE_SCHEMA = tc.TABLE_SCHEMA
+				AND ku.TABLE_NAME = tc.TABLE_NAME
+			WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY'
+		) pk ON pk.TABLE_SCHEMA = c.TABLE_SCHEMA AND pk.TABLE_NAME = c.TABLE_NAME AND pk.COLUMN_NAME = c.COLUMN_NAME
+		WHERE c.TABLE_SCHEMA = %s AND c.TABLE_NAME = %s
+		ORDER BY c.ORDINAL_POSITION;`, schemaParam, tableParam)
+}
+
+var dialects = map[string]dialect{
+	"sqlserver": {
+		driver:        "sqlserver",
+		defaultSchema: `SELECT SCHEMA_NAME();`,
+		schemas:       `SELECT name FROM sys.schemas WHERE schema_id < 16384 AND name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') ORDER BY name;`,
+		tables: `
+			SELECT s.name, t.name, SUM(p.rows)
+			FROM sys.tables t
+			JOIN sys.schemas s ON s.schema_id = t.schema_id
+			LEFT JOIN sys.partitions p ON p.object_id = t.object_id AND p.index_id IN (0, 1)
+			WHERE s.name = @p1
+			GROUP BY s.name, t.name
+			ORDER BY t.name;`,
+		columns: informationSchemaColumns("@p1", "@p2"),
+	},
+	"postgres": {
+		driver:        "postgres",
+		defaultSchema: `SELECT current_schema();`,
+		schemas: `
+			SELECT schema_name FROM information_schema.schemata
+			WHERE schema_name NOT IN ('pg_catalog', 'information_schema')
+				AND schema_name NOT LIKE 'pg\_toast%' AND schema_name NOT LIKE 'pg\_temp%'
+			ORDER BY schema_name;`,
+		tables: `
+			SELECT n.nspname, c.relname, CASE WHEN c.reltuples < 0 THEN NULL ELSE c.reltuples::bigint END
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind IN ('r', 'p') AND n.nspname = $1
+			ORDER BY c.relname;`,
+		columns: informationSchemaColumns("$1", "$2"),
+	},
+	"mysql": {
+		driver:        "mysql",
+		defaultSchema: `SELECT DATABASE();`,
+		schemas: `
+			SELECT schema_name FROM information_schema.schemata
+			WHERE schema_name NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')
+			ORDER BY schema_name;`,
+		tables: `
+			SELECT table_schema, table_name, table_rows
+			FROM information_schema.tables
+			WHERE table_schema = ? AND table_type = 'BASE TABLE'
+			ORDER BY table_name;`,
+		columns: informationSchemaColumns("?", "?"),
+	},
+	"oracle": {
+		driver:        "oracle",
+		defaultSchema: `SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM dual`,
+		schemas:       `SELECT username FROM all_users WHERE oracle_maintained = 'N' ORDER BY username`,
+		tables: `
+			SELECT owner, table_name, num_rows
+			FROM all_tables
+			WHERE owner = :1
+			ORDER BY table_name`,
+		columns: `
+			SELECT c.column_name, c.column_id, c.data_type, c.nullable,
+				c.char_length, c.data_precision, c.data_scale,
+				CASE WHEN pk.column_name IS NULL THEN 0 ELSE 1 END
+			FROM all_tab_columns c
+			LEFT JOIN (
+				SELECT cc.owner, cc.table_name, cc.column_name
+				FROM all_constraints k
+				JOIN all_cons_columns cc ON cc.owner = k.owner AND cc.constraint_name = k.constraint_name
+				WHERE k.constraint_type = 'P'
+			) pk ON pk.owner = c.owner AND pk.table_name = c.table_name AND pk.column_name = c.column_name
+			WHERE c.owner = :1 AND c.table_name = :2
+			ORDER BY c.column_id`,
+	},
+}
+
+// sqlInspector introspects a database/sql source through its system catalogs.
+type sqlInspector struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func openSQL(ctx context.Context, connType, dsn string) (*sqlInspector, error) {
+	d, ok := dialects[connType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, connType)
+	}
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s connection: %w", connType, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to %s: %w", connType, err)
+	}
+	return &sqlInspector{db: db, dialect: d}, nil
+}
+
+func (i *sqlInspector) DefaultSchema(ctx context.Context) (string, error) {
+	var schema sql.NullString
+	if err := i.db.QueryRowContext(ctx, i.dialect.defaultSchema).Scan(&schema); err != nil {
+		return "", fmt.Errorf("error reading default schema: %w", err)
+	}
+	return schema.String, nil
+}
+
+func (i *sqlInspector) ListSchemas(ctx context.Context) ([]string, error) {
+	rows, err := i.db.QueryContext(ctx, i.dialect.schemas)
+	if err != nil {
+		return nil, fmt.Errorf("error listing schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning schema row: %w", err)
+		}
+		schemas = append(schemas, name)
+	}
+	return schemas, rows.Err()
+}
+
+func (i *sqlInspector) ListTables(ctx context.Context, schema string) ([]Table, error) {
+	rows, err := i.db.QueryContext(ctx, i.dialect.tables, schema)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tables in %s: %w", schema, err)
+	}
+	defer rows.Close()
+
+	tables := []Table{}
+	for rows.Next() {
+		var table Table
+		var estimate sql.NullInt64
+		if err := rows.Scan(&table.Schema, &table.Name, &estimate); err != nil {
+			return nil, fmt.Errorf("error scanning table row: %w", err)
+		}
+		table.RowEstimate = nullInt64Ptr(estimate)
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+func (i *sqlInspector) ListColumns(ctx context.Context, schema, table string) ([]Column, error) {
+	rows, err := i.db.QueryContext(ctx, i.dialect.columns, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("error listing columns of %s.%s: %w", schema, table, err)
+	}
+	defer rows.Close()
+
+	columns := []Column{}
+	for rows.Next() {
+		var column Column
+		var nullable string
+		var maxLength, precision, scale sql.NullInt64
+		var primaryKey int
+		if err := rows.Scan(&column.Name, &column.Position, &column.DataType, &nullable,
+			&maxLength, &precision, &scale, &primaryKey); err != nil {
+			return nil, fmt.Errorf("error scanning column row: %w", err)
+		}
+		column.Nullable = nullable == "YES" || nullable == "Y"
+		column.PrimaryKey = primaryKey == 1
+		column.MaxLength = nullInt64Ptr(maxLength)
+		column.Precision = nullInt64Ptr(precision)
+		column.Scale = nullInt64Ptr(scale)
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, ErrTableNotFound
+	}
+	return columns, nil
+}
+
+func (i *sqlInspector) DescribeQuery(ctx context.Context, query string) ([]Column, error) {
+	// Wrap the query so no rows are read; every supported dialect accepts a derived table alias
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	rows, err := i.db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM (%s) q WHERE 1 = 0", query))
+	if err != nil {
+		return nil, fmt.Errorf("error describing query: %w", err)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("error describing query: %w", err)
+	}
+	columns := make([]Column, 0, len(types))
+	for idx, columnType := range types {
+		column := Column{
+			Name:     columnType.Name(),
+			Position: idx + 1,
+			DataType: strings.ToLower(columnType.DatabaseTypeName()),
+			Nullable: true, // Assume nullable unless the driver knows better
+		}
+		if nullable, ok := columnType.Nullable(); ok {
+			column.Nullable = nullable
+		}
+		if length, ok := columnType.Length(); ok && length > 0 && length < math.MaxInt32 {
+			column.MaxLength = &length
+		}
+		if precision, scale, ok := columnType.DecimalSize(); ok {
+			column.Precision = &precision
+			column.Scale = &scale
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func (i *sqlInspector) Close() error {
+	return i.db.Close()
+}
+
+func nullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
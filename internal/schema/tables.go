@@ -0,0 +1,225 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTargetName  = "{table}"
+	defaultMaxParallel = 4
+)
+
+// TableRules select the tables a multi-table replication task copies. They are stored as JSON
+// in the task's table_rules field.
+type TableRules struct {
+	// Tables are schema.table glob patterns (* and ?); a leading ! excludes matching tables.
+	// A pattern without a schema matches the table name in every schema.
+	Tables []string `json:"tables"`
+	// TargetName names the target table; {schema} and {table} are replaced. Defaults to {table}.
+	TargetName string `json:"target_name,omitempty"`
+	// TargetNames maps schema.table to an explicit target name, overriding TargetName.
+	TargetNames map[string]string `json:"target_names,omitempty"`
+	// MaxParallel caps the tables replicated at once. Defaults to 4.
+	MaxParallel int `json:"max_parallel,omitempty"`
+
+	include []tablePattern
+	exclude []tablePattern
+}
+
+type tablePattern struct {
+	schema string // lower-cased; "*" when the pattern has no schema
+	table  string
+}
+
+// TableSelection is a source table picked by a task's rules and the target it loads into.
+type TableSelection struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Target string `json:"target"`
+}
+
+// SourceName is the schema-qualified source table name.
+func (t TableSelection) SourceName() string {
+	return t.Schema + "." + t.Name
+}
+
+// ParseTableRules parses and validates a task's table rules. It returns nil for an empty
+// string, i.e. a single-table task.
+func ParseTableRules(raw string) (*TableRules, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var rules TableRules
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("invalid table rules: %w", err)
+	}
+	for _, raw := range rules.Tables {
+		pattern, exclude := strings.CutPrefix(strings.TrimSpace(raw), "!")
+		if pattern == "" {
+			return nil, fmt.Errorf("invalid table rules: empty table pattern")
+		}
+		parsed := tablePattern{schema: "*", table: strings.ToLower(pattern)}
+		if schemaPart, tablePart, ok := strings.Cut(pattern, "."); ok {
+			parsed = tablePattern{schema: strings.ToLower(schemaPart), table: strings.ToLower(tablePart)}
+		}
+		if _, err := path.Match(parsed.schema+"."+parsed.table, ""); err != nil {
+			return nil, fmt.Errorf("invalid table rules: bad pattern %q", raw)
+		}
+		if exclude {
+			rules.exclude = append(rules.exclude, parsed)
+		} else {
+			rules.include = append(rules.include, parsed)
+		}
+	}
+	if len(rules.include) == 0 {
+		return nil, fmt.Errorf("invalid table rules: at least one table pattern to include is required")
+	}
+	if rules.MaxParallel < 0 {
+		return nil, fmt.Errorf("invalid table rules: max_parallel must not be negative")
+	}
+	if rules.MaxParallel == 0 {
+		rules.MaxParallel = defaultMaxParallel
+	}
+	if rules.TargetName == "" {
+		rules.TargetName = defaultTargetName
+	}
+	return &rules, nil
+}
+
+// Select returns the tables matched by an include pattern and no exclude pattern, in input
+// order, with their target names. Matching is case-insensitive.
+func (r *TableRules) Select(tables []Table) []TableSelection {
+	selected := []TableSelection{}
+	for _, table := range tables {
+		if !matchAny(r.include, table) || matchAny(r.exclude, table) {
+			continue
+		}
+		selected = append(selected, TableSelection{Schema: table.Schema, Name: table.Name, Target: r.targetName(table)})
+	}
+	return selected
+}
+
+func (r *TableRules) targetName(table Table) string {
+	source := table.Schema + "." + table.Name
+	for key, target := range r.TargetNames {
+		if strings.EqualFold(key, source) {
+			return target
+		}
+	}
+	return strings.NewReplacer("{schema}", table.Schema, "{table}", table.Name).Replace(r.TargetName)
+}
+
+// schemas returns the schemas named by the include patterns, or nil when a pattern can match
+// any schema and every schema has to be listed.
+func (r *TableRules) schemas() []string {
+	seen := map[string]bool{}
+	for _, pattern := range r.include {
+		if strings.ContainsAny(pattern.schema, "*?[") {
+			return nil
+		}
+		seen[pattern.schema] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func matchAny(patterns []tablePattern, table Table) bool {
+	for _, pattern := range patterns {
+		schemaMatch, _ := path.Match(pattern.schema, strings.ToLower(table.Schema))
+		tableMatch, _ := path.Match(pattern.table, strings.ToLower(table.Name))
+		if schemaMatch && tableMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveTables lists the source tables and applies the rules to them. Only the schemas the
+// rules name are listed, unless a pattern uses a schema wildcard.
+func ResolveTables(ctx context.Context, inspector Inspector, rules *TableRules) ([]TableSelection, error) {
+	available, err := inspector.ListSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wanted := rules.schemas()
+	tables := []Table{}
+	for _, name := range available {
+		if wanted != nil && !containsFold(wanted, name) {
+			continue
+		}
+		found, err := inspector.ListTables(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, found...)
+	}
+	return rules.Select(tables), nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// QuoteTableName quotes a schema-qualified table name for a source's SQL dialect.
+func QuoteTableName(connType, schemaName, table string) (string, error) {
+	if connType == "bigquery" {
+		if !bigQueryID.MatchString(schemaName) || !bigQueryID.MatchString(table) {
+			return "", fmt.Errorf("invalid bigquery table name: %s.%s", schemaName, table)
+		}
+		return "`" + schemaName + "." + table + "`", nil
+	}
+	quotedSchema, err := QuoteIdentifier(connType, schemaName)
+	if err != nil {
+		return "", err
+	}
+	quotedTable, err := QuoteIdentifier(connType, table)
+	if err != nil {
+		return "", err
+	}
+	return quotedSchema + "." + quotedTable, nil
+}
+
+// QuoteIdentifier quotes a column or table name in a source's SQL dialect.
+func QuoteIdentifier(connType, name string) (string, error) {
+	switch connType {
+	case "sqlserver":
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]", nil
+	case "postgres", "oracle":
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`, nil
+	case "mysql", "bigquery":
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`", nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, connType)
+	}
+}
+
+// QuoteString quotes a value as a string literal in a source's SQL dialect. MySQL and BigQuery
+// treat backslashes in literals as escapes, so they are escaped too.
+func QuoteString(connType, value string) (string, error) {
+	switch connType {
+	case "sqlserver":
+		return "N'" + strings.ReplaceAll(value, "'", "''") + "'", nil
+	case "postgres", "oracle":
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'", nil
+	case "mysql":
+		return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", "''") + "'", nil
+	case "bigquery":
+		return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", `\'`) + "'", nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, connType)
+	}
+}
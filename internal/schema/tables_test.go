@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInspector serves tables from memory and records the schemas it was asked to list.
+type fakeInspector struct {
+	tables []Table
+	listed []string
+}
+
+func (f *fakeInspector) DefaultSchema(ctx context.Context) (string, error) { return "dbo", nil }
+
+func (f *fakeInspector) ListSchemas(ctx context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	schemas := []string{}
+	for _, table := range f.tables {
+		if !seen[table.Schema] {
+			seen[table.Schema] = true
+			schemas = append(schemas, table.Schema)
+		}
+	}
+	return schemas, nil
+}
+
+func (f *fakeInspector) ListTables(ctx context.Context, schema string) ([]Table, error) {
+	f.listed = append(f.listed, schema)
+	tables := []Table{}
+	for _, table := range f.tables {
+		if table.Schema == schema {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+func (f *fakeInspector) ListColumns(ctx context.Context, schema, table string) ([]Column, error) {
+	return nil, ErrTableNotFound
+}
+
+func (f *fakeInspector) Close() error { return nil }
+
+func TestResolveTables_IncludeExcludeAndTargetNames(t *testing.T) {
+	// Arrange
+	inspector := &fakeInspector{tables: []Table{
+		{Schema: "dbo", Name: "Orders"},
+		{Schema: "dbo", Name: "audit_log"},
+		{Schema: "dbo", Name: "Customers"},
+		{Schema: "sales", Name: "Regions"},
+		{Schema: "hr", Name: "Employees"},
+	}}
+	rules, err := ParseTableRules(`{
+		"tables": ["DBO.*", "sales.regions", "!dbo.AUDIT_*"],
+		"target_name": "{schema}_{table}",
+		"target_names": {"dbo.customers": "CUSTOMER_MASTER"}
+	}`)
+	require.NoError(t, err)
+
+	// Act
+	selected, err := ResolveTables(context.Background(), inspector, rules)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []TableSelection{
+		{Schema: "dbo", Name: "Orders", Target: "dbo_Orders"},
+		{Schema: "dbo", Name: "Customers", Target: "CUSTOMER_MASTER"},
+		{Schema: "sales", Name: "Regions", Target: "sales_Regions"},
+	}, selected)
+	assert.Equal(t, []string{"dbo", "sales"}, inspector.listed, "Only schemas named by the rules are listed")
+	assert.Equal(t, 4, rules.MaxParallel)
+}
+
+func TestResolveTables_PatternWithoutSchemaListsEverySchema(t *testing.T) {
+	inspector := &fakeInspector{tables: []Table{
+		{Schema: "dbo", Name: "orders"},
+		{Schema: "archive", Name: "orders"},
+		{Schema: "archive", Name: "invoices"},
+	}}
+	rules, err := ParseTableRules(`{"tables": ["orders"], "max_parallel": 2}`)
+	require.NoError(t, err)
+
+	selected, err := ResolveTables(context.Background(), inspector, rules)
+
+	require.NoError(t, err)
+	assert.Equal(t, []TableSelection{
+		{Schema: "dbo", Name: "orders", Target: "orders"},
+		{Schema: "archive", Name: "orders", Target: "orders"},
+	}, selected)
+	assert.Equal(t, []string{"dbo", "archive"}, inspector.listed)
+	assert.Equal(t, 2, rules.MaxParallel)
+}
+
+func TestParseTableRules(t *testing.T) {
+	rules, err := ParseTableRules("  ")
+	require.NoError(t, err)
+	assert.Nil(t, rules, "An empty value means a single-table task")
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{"not json", `dbo.*`, "invalid table rules"},
+		{"only excludes", `{"tables": ["!dbo.audit_*"]}`, "at least one table pattern to include"},
+		{"empty pattern", `{"tables": ["dbo.*", "!"]}`, "empty table pattern"},
+		{"bad glob", `{"tables": ["dbo.[a"]}`, "bad pattern"},
+		{"negative parallelism", `{"tables": ["dbo.*"], "max_parallel": -1}`, "max_parallel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTableRules(tt.raw)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestQuoteTableName(t *testing.T) {
+	tests := []struct {
+		connType string
+		want     string
+	}{
+		{"sqlserver", "[dbo].[Order]]s]"},
+		{"postgres", `"dbo"."Order]s"`},
+		{"oracle", `"dbo"."Order]s"`},
+		{"mysql", "`dbo`.`Order]s`"},
+	}
+	for _, tt := range tests {
+		got, err := QuoteTableName(tt.connType, "dbo", "Order]s")
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.connType)
+	}
+
+	got, err := QuoteTableName("bigquery", "analytics", "events_2026")
+	require.NoError(t, err)
+	assert.Equal(t, "`analytics.events_2026`", got)
+	_, err = QuoteTableName("bigquery", "analytics", "events`; DROP")
+	assert.Error(t, err)
+	_, err = QuoteTableName("s3", "a", "b")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := []struct {
+		connType string
+		want     string
+	}{
+		{"sqlserver", `N'O''Neil\'`},
+		{"postgres", `'O''Neil\'`},
+		{"oracle", `'O''Neil\'`},
+		{"mysql", `'O''Neil\\'`},
+		{"bigquery", `'O\'Neil\\'`},
+	}
+	for _, tt := range tests {
+		got, err := QuoteString(tt.connType, `O'Neil\`)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.connType)
+	}
+
+	_, err := QuoteString("s3", "a")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
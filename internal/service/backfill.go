@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/backfill"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// ErrBackfillNotActive is returned for pauses, resumes and cancels of backfills not in a state
+// that allows them.
+var ErrBackfillNotActive = errors.New("backfill is not active")
+
+// StartBackfill records a backfill of a task over the range in options, with one pending
+// slice per step, and starts its BackfillWorkflow.
+func (s *service) StartBackfill(ctx context.Context, taskID int64, options string) (*data.Backfill, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	opts, err := backfill.Parse(options)
+	if err != nil {
+		return nil, err
+	}
+	slices, err := backfill.Plan(opts)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.repo.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TableRules != "" {
+		return nil, fmt.Errorf("%w: multi-table tasks have no source query", backfill.ErrInvalidBackfill)
+	}
+	sourceConn, err := s.repo.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+	}
+	if err := backfill.Supported(sourceConn.Type); err != nil {
+		return nil, err
+	}
+
+	record := &data.Backfill{
+		ReplicationTaskID: taskID,
+		Status:            "running",
+		Options:           options,
+		StartTime:         time.Now(),
+	}
+	sliceRecords := make([]*data.BackfillSlice, len(slices))
+	for i, slice := range slices {
+		sliceRecords[i] = &data.BackfillSlice{SliceIndex: i, RangeStart: slice.Start, RangeEnd: slice.End, Status: "pending"}
+	}
+	if _, err := s.repo.CreateBackfill(ctx, record, sliceRecords); err != nil {
+		return nil, err
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would start backfill %d of task %d (WorkflowClient not available)\n", record.ID, taskID)
+		return record, nil
+	}
+	workflowID, err := WorkflowClientImpl.StartBackfill(ctx, record.ID)
+	if err != nil {
+		now := time.Now()
+		record.Status, record.ErrorDetails, record.EndTime = "failed", err.Error(), &now
+		if updateErr := s.repo.UpdateBackfill(ctx, record); updateErr != nil {
+			fmt.Printf("Warning: failed to mark backfill %d failed: %v\n", record.ID, updateErr)
+		}
+		return nil, fmt.Errorf("failed to start backfill of task %d: %w", taskID, err)
+	}
+	record.TemporalWorkflowID = workflowID
+	if err := s.repo.UpdateBackfill(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetBackfill gets a backfill by ID.
+func (s *service) GetBackfill(ctx context.Context, id int64) (*data.Backfill, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.GetBackfill(ctx, id)
+}
+
+// ListBackfills lists a task's backfills, most recent first.
+func (s *service) ListBackfills(ctx context.Context, taskID int64) ([]*data.Backfill, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetReplicationTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListBackfillsForTask(ctx, taskID)
+}
+
+// ListBackfillSlices lists a backfill's slices in range order.
+func (s *service) ListBackfillSlices(ctx context.Context, backfillID int64) ([]*data.BackfillSlice, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetBackfill(ctx, backfillID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListBackfillSlices(ctx, backfillID)
+}
+
+// UpdateBackfill stores a backfill's status and outcome.
+func (s *service) UpdateBackfill(ctx context.Context, record *data.Backfill) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateBackfill(ctx, record)
+}
+
+// UpdateBackfillSlice stores a slice's status and run.
+func (s *service) UpdateBackfillSlice(ctx context.Context, slice *data.BackfillSlice) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateBackfillSlice(ctx, slice)
+}
+
+// PauseBackfill asks a running backfill to stop starting slices. Slices already running finish.
+func (s *service) PauseBackfill(ctx context.Context, id int64) (*data.Backfill, error) {
+	return s.controlBackfill(ctx, id, backfill.ActionPause, "running")
+}
+
+// ResumeBackfill asks a paused backfill to start slices again.
+func (s *service) ResumeBackfill(ctx context.Context, id int64) (*data.Backfill, error) {
+	return s.controlBackfill(ctx, id, backfill.ActionResume, "paused")
+}
+
+// CancelBackfill cancels a running or paused backfill. Pending slices are not started; slices
+// already running are stopped at their next pipeline heartbeat.
+func (s *service) CancelBackfill(ctx context.Context, id int64) (*data.Backfill, error) {
+	return s.controlBackfill(ctx, id, backfill.ActionCancel, "running", "paused")
+}
+
+// controlBackfill sends action to the workflow of a backfill in one of the given statuses.
+func (s *service) controlBackfill(ctx context.Context, id int64, action string, statuses ...string) (*data.Backfill, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	record, err := s.repo.GetBackfill(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	allowed := false
+	for _, status := range statuses {
+		allowed = allowed || record.Status == status
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: backfill %d is %s", ErrBackfillNotActive, id, record.Status)
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would %s backfill %d (WorkflowClient not available)\n", action, id)
+		return record, nil
+	}
+	if action == backfill.ActionCancel {
+		err = WorkflowClientImpl.CancelWorkflow(ctx, record.TemporalWorkflowID)
+	} else {
+		err = WorkflowClientImpl.SignalBackfill(ctx, record.TemporalWorkflowID, action)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s backfill %d: %w", action, id, err)
+	}
+	return record, nil
+}
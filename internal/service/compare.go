@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// StartCompare records a compare report for a task and starts its CompareWorkflow.
+// options is the compare options JSON (empty for defaults).
+func (s *service) StartCompare(ctx context.Context, taskID int64, options string) (*data.CompareReport, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := compare.ParseOptions(options); err != nil {
+		return nil, err
+	}
+	task, err := s.repo.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	sourceConn, err := s.repo.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source connection %d: %w", task.SourceConnectionID, err)
+	}
+	targetConn, err := s.repo.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target connection %d: %w", task.TargetConnectionID, err)
+	}
+	if err := compare.Supported(sourceConn.Type, targetConn.Type); err != nil {
+		return nil, err
+	}
+
+	report := &data.CompareReport{
+		ReplicationTaskID: taskID,
+		Status:            "running",
+		Options:           options,
+		StartTime:         time.Now(),
+	}
+	if _, err := s.repo.CreateCompareReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would start compare %d of task %d (WorkflowClient not available)\n", report.ID, taskID)
+		return report, nil
+	}
+	workflowID, err := WorkflowClientImpl.StartCompare(ctx, report.ID)
+	if err != nil {
+		now := time.Now()
+		report.Status, report.ErrorDetails, report.EndTime = "failed", err.Error(), &now
+		if updateErr := s.repo.UpdateCompareReport(ctx, report); updateErr != nil {
+			fmt.Printf("Warning: failed to mark compare report %d failed: %v\n", report.ID, updateErr)
+		}
+		return nil, fmt.Errorf("failed to start compare of task %d: %w", taskID, err)
+	}
+	report.TemporalWorkflowID = workflowID
+	if err := s.repo.UpdateCompareReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetCompareReport gets a compare report by ID.
+func (s *service) GetCompareReport(ctx context.Context, id int64) (*data.CompareReport, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.GetCompareReport(ctx, id)
+}
+
+// ListCompareReports lists a task's compare history, most recent first.
+func (s *service) ListCompareReports(ctx context.Context, taskID int64) ([]*data.CompareReport, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetReplicationTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCompareReportsForTask(ctx, taskID)
+}
+
+// UpdateCompareReport stores a compare report's outcome.
+func (s *service) UpdateCompareReport(ctx context.Context, report *data.CompareReport) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateCompareReport(ctx, report)
+}
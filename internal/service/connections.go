@@ -55,3 +55,14 @@ func (s *service) DeleteConnection(ctx context.Context, id int64) error {
 	fmt.Printf("Service: Calling repo.DeleteConnection for ID %d\n", id)
 	return s.repo.DeleteConnection(ctx, id)
 }
+
+// ListConnectionLeases lists the leases runs hold on a connection's concurrency limit.
+func (s *service) ListConnectionLeases(ctx context.Context, id int64) ([]*data.RunLease, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetConnection(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRunLeases(ctx, data.LeaseResourceConnection, id)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/dag"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/stream"
+	"github.com/robfig/cron"
+)
+
+// CreatePipeline validates and stores a pipeline, then schedules it when it has a schedule.
+func (s *service) CreatePipeline(ctx context.Context, pipeline *data.Pipeline) (int64, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("service requires an initialized repository")
+	}
+	if err := s.validatePipeline(ctx, pipeline); err != nil {
+		return 0, err
+	}
+	id, err := s.repo.CreatePipeline(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	if pipeline.Schedule != "" {
+		if err := s.schedulePipeline(ctx, id, pipeline.Schedule); err != nil {
+			return id, err
+		}
+	}
+	return id, nil
+}
+
+// GetPipeline gets a pipeline with its tasks by ID.
+func (s *service) GetPipeline(ctx context.Context, id int64) (*data.Pipeline, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.GetPipeline(ctx, id)
+}
+
+// ListPipelines lists all pipelines with their tasks.
+func (s *service) ListPipelines(ctx context.Context) ([]*data.Pipeline, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.ListPipelines(ctx)
+}
+
+// UpdatePipeline validates and stores a pipeline, replacing its tasks, and reschedules it
+// when its schedule changed.
+func (s *service) UpdatePipeline(ctx context.Context, pipeline *data.Pipeline) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	existing, err := s.repo.GetPipeline(ctx, pipeline.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validatePipeline(ctx, pipeline); err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePipeline(ctx, pipeline); err != nil {
+		return err
+	}
+	if pipeline.Schedule != existing.Schedule {
+		return s.schedulePipeline(ctx, pipeline.ID, pipeline.Schedule)
+	}
+	return nil
+}
+
+// DeletePipeline unschedules and deletes a pipeline. Runs in progress carry on.
+func (s *service) DeletePipeline(ctx context.Context, id int64) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	pipeline, err := s.repo.GetPipeline(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pipeline.Schedule != "" {
+		if err := s.schedulePipeline(ctx, id, ""); err != nil {
+			return err
+		}
+	}
+	return s.repo.DeletePipeline(ctx, id)
+}
+
+// RunPipeline starts a run of a pipeline now, outside its schedule, and returns the
+// workflow ID of the run.
+func (s *service) RunPipeline(ctx context.Context, id int64) (string, error) {
+	if s.repo == nil {
+		return "", fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetPipeline(ctx, id); err != nil {
+		return "", err
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would run pipeline %d (WorkflowClient not available)\n", id)
+		return fmt.Sprintf("mock-pipeline-%d", id), nil
+	}
+	workflowID, err := WorkflowClientImpl.RunPipeline(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to run pipeline %d: %w", id, err)
+	}
+	return workflowID, nil
+}
+
+// CreatePipelineRun records the start of a pipeline run.
+func (s *service) CreatePipelineRun(ctx context.Context, run *data.PipelineRun) (int64, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.CreatePipelineRun(ctx, run)
+}
+
+// GetPipelineRun gets a pipeline run by ID.
+func (s *service) GetPipelineRun(ctx context.Context, id int64) (*data.PipelineRun, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.GetPipelineRun(ctx, id)
+}
+
+// ListPipelineRuns lists a pipeline's runs, most recent first.
+func (s *service) ListPipelineRuns(ctx context.Context, pipelineID int64) ([]*data.PipelineRun, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetPipeline(ctx, pipelineID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPipelineRuns(ctx, pipelineID)
+}
+
+// UpdatePipelineRun stores a pipeline run's progress and outcome.
+func (s *service) UpdatePipelineRun(ctx context.Context, run *data.PipelineRun) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdatePipelineRun(ctx, run)
+}
+
+// GetPipelineDAG lays out a pipeline for visualization, with the task statuses of its most
+// recent run.
+func (s *service) GetPipelineDAG(ctx context.Context, id int64) (*dag.Graph, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	pipeline, err := s.repo.GetPipeline(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(pipeline.Tasks))
+	for _, task := range pipeline.Tasks {
+		replicationTask, err := s.repo.GetReplicationTask(ctx, task.TaskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch task %d of pipeline %d: %w", task.TaskID, id, err)
+		}
+		names[task.TaskID] = replicationTask.Name
+	}
+
+	runs, err := s.repo.ListPipelineRuns(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var statuses map[int64]string
+	if len(runs) > 0 && runs[0].TaskStatuses != "" {
+		if err := json.Unmarshal([]byte(runs[0].TaskStatuses), &statuses); err != nil {
+			return nil, fmt.Errorf("invalid task statuses in pipeline run %d: %w", runs[0].ID, err)
+		}
+	}
+
+	graph := dag.BuildGraph(id, pipeline.Tasks, names, statuses)
+	if len(runs) > 0 {
+		graph.RunID = &runs[0].ID
+	}
+	return graph, nil
+}
+
+// validatePipeline normalizes a pipeline's tasks and checks its DAG, its tasks and its schedule.
+func (s *service) validatePipeline(ctx context.Context, pipeline *data.Pipeline) error {
+	dag.Normalize(pipeline.Tasks)
+	if err := dag.Validate(pipeline.Tasks); err != nil {
+		return err
+	}
+	for _, task := range pipeline.Tasks {
+		replicationTask, err := s.repo.GetReplicationTask(ctx, task.TaskID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: task %d does not exist", dag.ErrInvalidPipeline, task.TaskID)
+			}
+			return err
+		}
+		// A streaming task never finishes, so its dependents would never start
+		if stream.Enabled(replicationTask.StreamConfig) {
+			return fmt.Errorf("%w: task %d streams", dag.ErrInvalidPipeline, task.TaskID)
+		}
+	}
+	if pipeline.Schedule != "" {
+		if _, err := cron.ParseStandard(pipeline.Schedule); err != nil {
+			return fmt.Errorf("%w: invalid schedule %q: %v", dag.ErrInvalidPipeline, pipeline.Schedule, err)
+		}
+	}
+	return nil
+}
+
+// schedulePipeline replaces a pipeline's scheduled workflow; an empty schedule removes it.
+func (s *service) schedulePipeline(ctx context.Context, id int64, schedule string) error {
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would schedule pipeline %d with %q (WorkflowClient not available)\n", id, schedule)
+		return nil
+	}
+	if err := WorkflowClientImpl.SchedulePipeline(ctx, id, schedule); err != nil {
+		return fmt.Errorf("failed to schedule pipeline %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// StartRefresh records a refresh of a compare report's mismatched ranges and starts its
+// RefreshWorkflow. options is the refresh options JSON (empty for defaults).
+func (s *service) StartRefresh(ctx context.Context, reportID int64, options string) (*data.Refresh, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	opts, err := compare.ParseRefreshOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.repo.GetCompareReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == "running" {
+		return nil, fmt.Errorf("%w: compare report %d is still running", compare.ErrNothingToRefresh, reportID)
+	}
+	var results []compare.TableResult
+	if report.Result != "" {
+		if err := json.Unmarshal([]byte(report.Result), &results); err != nil {
+			return nil, fmt.Errorf("invalid result in compare report %d: %w", reportID, err)
+		}
+	}
+	mismatched := false
+	for _, result := range results {
+		if len(result.MismatchedRanges) > 0 && opts.Selects(result.Table) {
+			mismatched = true
+		}
+	}
+	if !mismatched {
+		return nil, fmt.Errorf("%w: compare report %d found no mismatched ranges in the selected tables", compare.ErrNothingToRefresh, reportID)
+	}
+
+	refresh := &data.Refresh{
+		ReplicationTaskID: report.ReplicationTaskID,
+		CompareReportID:   reportID,
+		Status:            "running",
+		Options:           options,
+		StartTime:         time.Now(),
+	}
+	if _, err := s.repo.CreateRefresh(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would start refresh %d of compare report %d (WorkflowClient not available)\n", refresh.ID, reportID)
+		return refresh, nil
+	}
+	workflowID, err := WorkflowClientImpl.StartRefresh(ctx, refresh.ID)
+	if err != nil {
+		now := time.Now()
+		refresh.Status, refresh.ErrorDetails, refresh.EndTime = "failed", err.Error(), &now
+		if updateErr := s.repo.UpdateRefresh(ctx, refresh); updateErr != nil {
+			fmt.Printf("Warning: failed to mark refresh %d failed: %v\n", refresh.ID, updateErr)
+		}
+		return nil, fmt.Errorf("failed to start refresh of compare report %d: %w", reportID, err)
+	}
+	refresh.TemporalWorkflowID = workflowID
+	if err := s.repo.UpdateRefresh(ctx, refresh); err != nil {
+		return nil, err
+	}
+	return refresh, nil
+}
+
+// GetRefresh gets a refresh by ID.
+func (s *service) GetRefresh(ctx context.Context, id int64) (*data.Refresh, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.GetRefresh(ctx, id)
+}
+
+// ListRefreshes lists a task's refreshes, most recent first.
+func (s *service) ListRefreshes(ctx context.Context, taskID int64) ([]*data.Refresh, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetReplicationTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRefreshesForTask(ctx, taskID)
+}
+
+// UpdateRefresh stores a refresh's status and outcome.
+func (s *service) UpdateRefresh(ctx context.Context, refresh *data.Refresh) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateRefresh(ctx, refresh)
+}
+
+// AddRefreshProgress adds refreshed ranges and row counts to a refresh.
+func (s *service) AddRefreshProgress(ctx context.Context, id int64, ranges int, rowsDeleted, rowsWritten int64) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.AddRefreshProgress(ctx, id, ranges, rowsDeleted, rowsWritten)
+}
@@ -2,28 +2,70 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
 	"github.com/eleon00/hsoetlnlm/internal/data"
 )
 
 // WorkflowClient defines the interface for scheduling and managing workflows
 type WorkflowClient interface {
-	ScheduleReplicationTask(ctx context.Context, taskID int64, scheduleExpression string) (string, error)
+	ScheduleReplicationTask(ctx context.Context, taskID int64, scheduleExpression string, trigger *criteria.Trigger) (string, error)
 	CancelWorkflow(ctx context.Context, workflowID string) error
+	StartCompare(ctx context.Context, reportID int64) (string, error)
+	StartRefresh(ctx context.Context, refreshID int64) (string, error)
+	ResumeReplicationRun(ctx context.Context, taskID int64, runID int64) (string, error)
+	SchedulePipeline(ctx context.Context, pipelineID int64, schedule string) error
+	RunPipeline(ctx context.Context, pipelineID int64) (string, error)
+	StartBackfill(ctx context.Context, backfillID int64) (string, error)
+	SignalBackfill(ctx context.Context, workflowID string, action string) error
+	SignalReplicationTask(ctx context.Context, taskID int64, action string) error
+	CancelReplicationRun(ctx context.Context, workflowID string, runID string) error
+	DescribeWorkflowExecution(ctx context.Context, workflowID string, runID string) (*WorkflowExecution, error)
+	ListOpenReplicationExecutions(ctx context.Context) ([]*WorkflowExecution, error)
 }
 
+// ErrRunNotResumable is returned for resumes of runs that did not fail, of per-table runs, or
+// of streaming micro-runs.
+var ErrRunNotResumable = errors.New("run cannot be resumed")
+
+// ErrTaskWorkflowRunning is returned by the WorkflowClient when a run cannot be started because
+// the task's workflow (a scheduled run, a paused run or a pipeline's run of the task) is open.
+var ErrTaskWorkflowRunning = errors.New("task workflow is already running")
+
 // WorkflowClientImpl is a global variable to hold the workflow client implementation
 var WorkflowClientImpl WorkflowClient
 
-// StartReplicationTask starts a replication task using Temporal
-func (s *service) StartReplicationTask(ctx context.Context, taskID int64) (string, error) {
+// StartReplicationTask starts a replication task using Temporal. The trigger's parameters and
+// logical time are passed to the run for rendering the task's criteria template, which is
+// checked against them before the run starts.
+func (s *service) StartReplicationTask(ctx context.Context, taskID int64, trigger *criteria.Trigger) (string, error) {
 	// Check if the task exists
 	task, err := s.GetReplicationTask(ctx, taskID)
 	if err != nil {
 		return "", fmt.Errorf("failed to retrieve task %d: %w", taskID, err)
 	}
+	if task.Status == TaskStatusPaused {
+		return "", fmt.Errorf("%w: task %d", ErrTaskPaused, taskID)
+	}
+	if trigger == nil {
+		trigger = &criteria.Trigger{}
+	}
+	if criteria.IsTemplate(task.DataSelectionCriteria) {
+		sourceConn, err := s.GetConnection(ctx, task.SourceConnectionID)
+		if err != nil {
+			return "", fmt.Errorf("failed to retrieve source connection %d of task %d: %w", task.SourceConnectionID, taskID, err)
+		}
+		if sourceConn == nil {
+			return "", fmt.Errorf("source connection %d not found for task %d", task.SourceConnectionID, taskID)
+		}
+		vars := criteria.NewVars(taskID, 0, time.Now(), trigger.LogicalTime, task.Watermark, trigger.Params)
+		if _, err := criteria.Render(task.DataSelectionCriteria, sourceConn.Type, vars); err != nil {
+			return "", fmt.Errorf("task %d: %w", taskID, err)
+		}
+	}
 
 	if WorkflowClientImpl == nil {
 		// For development/testing without Temporal, just return a success message
@@ -32,7 +74,7 @@ func (s *service) StartReplicationTask(ctx context.Context, taskID int64) (strin
 	}
 
 	// Use our workflow client to schedule the task
-	workflowID, err := WorkflowClientImpl.ScheduleReplicationTask(ctx, taskID, task.Schedule)
+	workflowID, err := WorkflowClientImpl.ScheduleReplicationTask(ctx, taskID, task.Schedule, trigger)
 	if err != nil {
 		return "", fmt.Errorf("failed to schedule replication task %d: %w", taskID, err)
 	}
@@ -67,6 +109,91 @@ func (s *service) GetReplicationTaskStatus(ctx context.Context, taskID int64) (s
 	return "unknown", nil
 }
 
+// ResumeReplicationRun continues a failed, quarantined or cancelled run from its checkpoints:
+// completed tables and chunks are skipped, pinned source files are reused, and rows written by
+// the failed attempts are cleared from targets that record runs. While the task's workflow is
+// open the resume is rejected with ErrTaskWorkflowRunning and the run keeps its status.
+func (s *service) ResumeReplicationRun(ctx context.Context, runID int64) (*data.ReplicationRun, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	run, err := s.repo.GetReplicationRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.ParentRunID != nil {
+		return nil, fmt.Errorf("%w: run %d is a table run; resume its parent run %d", ErrRunNotResumable, runID, *run.ParentRunID)
+	}
+	if run.Streaming {
+		return nil, fmt.Errorf("%w: run %d is a micro-run of a streaming task; start the task again instead", ErrRunNotResumable, runID)
+	}
+	if run.Status != "failed" && run.Status != "quarantined" && run.Status != "cancelled" {
+		return nil, fmt.Errorf("%w: run %d is %s; only failed, quarantined or cancelled runs can be resumed", ErrRunNotResumable, runID, run.Status)
+	}
+	task, err := s.repo.GetReplicationTask(ctx, run.ReplicationTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve task %d: %w", run.ReplicationTaskID, err)
+	}
+	if task.Status == TaskStatusPaused {
+		return nil, fmt.Errorf("%w: task %d; resume the task first", ErrTaskPaused, task.ID)
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would resume run %d of task %d (WorkflowClient not available)\n", runID, run.ReplicationTaskID)
+		return run, nil
+	}
+	// Marked running before the start, so the update cannot overwrite the status of a resume
+	// that ends quickly
+	previous := *run
+	if err := s.repo.UpdateReplicationRunStatus(ctx, runID, "running", "", nil); err != nil {
+		// Non-fatal: the workflow marks the run running itself
+		fmt.Printf("Warning: failed to mark run %d running: %v\n", runID, err)
+	}
+	if _, err := WorkflowClientImpl.ResumeReplicationRun(ctx, run.ReplicationTaskID, runID); err != nil {
+		// Nothing carries the run out, so put back the status it had
+		if updateErr := s.repo.UpdateReplicationRunStatus(ctx, runID, previous.Status, previous.ErrorDetails, previous.EndTime); updateErr != nil {
+			fmt.Printf("Warning: failed to restore status of run %d: %v\n", runID, updateErr)
+		}
+		return nil, fmt.Errorf("failed to resume run %d: %w", runID, err)
+	}
+	run.Status, run.ErrorDetails, run.EndTime = "running", "", nil
+	return run, nil
+}
+
+// UpdateReplicationRunCounts records how many records a micro-run read and wrote.
+func (s *service) UpdateReplicationRunCounts(ctx context.Context, runID int64, rowsRead int64, rowsWritten int64) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateReplicationRunCounts(ctx, runID, rowsRead, rowsWritten)
+}
+
+// EndStreamingMicroRuns ends the unfinished micro-runs of a streaming task as failed with
+// reason. A task streams from one workflow execution at a time, so a new segment of the stream
+// uses it to close the micro-runs of an attempt or execution that stopped without doing so.
+func (s *service) EndStreamingMicroRuns(ctx context.Context, taskID int64, reason string) (int, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("service requires an initialized repository")
+	}
+	runs, err := s.repo.ListUnfinishedReplicationRuns(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list unfinished runs: %w", err)
+	}
+	ended := 0
+	now := time.Now()
+	for _, run := range runs {
+		if run.ReplicationTaskID != taskID || !run.Streaming {
+			continue
+		}
+		if err := s.repo.UpdateReplicationRunStatus(ctx, run.ID, "failed", reason, &now); err != nil {
+			return ended, fmt.Errorf("failed to end micro-run %d: %w", run.ID, err)
+		}
+		ended++
+	}
+	return ended, nil
+}
+
 // ListReplicationRuns lists all runs for a specific replication task
 func (s *service) ListReplicationRuns(ctx context.Context, taskID int64) ([]*data.ReplicationRun, error) {
 	if s.repo == nil {
@@ -83,6 +210,14 @@ func (s *service) GetReplicationRunDetails(ctx context.Context, runID int64) (*d
 	return s.repo.GetReplicationRun(ctx, runID)
 }
 
+// ListReplicationRunTables lists the per-table runs of a multi-table replication run
+func (s *service) ListReplicationRunTables(ctx context.Context, runID int64) ([]*data.ReplicationRun, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.ListChildReplicationRuns(ctx, runID)
+}
+
 // CreateReplicationRun calls the repository to create a new run record.
 func (s *service) CreateReplicationRun(ctx context.Context, run *data.ReplicationRun) (int64, error) {
 	if s.repo == nil {
@@ -98,3 +233,60 @@ func (s *service) UpdateReplicationRunStatus(ctx context.Context, id int64, stat
 	}
 	return s.repo.UpdateReplicationRunStatus(ctx, id, status, errorDetails, endTime)
 }
+
+// UpdateReplicationRunCriteria records the data selection criteria a run rendered.
+func (s *service) UpdateReplicationRunCriteria(ctx context.Context, runID int64, rendered string) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateReplicationRunCriteria(ctx, runID, rendered)
+}
+
+// UpdateReplicationRunSourceFiles records the source files a run pinned.
+func (s *service) UpdateReplicationRunSourceFiles(ctx context.Context, runID int64, files string) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateReplicationRunSourceFiles(ctx, runID, files)
+}
+
+// CreateRunChunks records the chunks of a partitioned run.
+func (s *service) CreateRunChunks(ctx context.Context, chunks []*data.ReplicationRunChunk) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.CreateReplicationRunChunks(ctx, chunks)
+}
+
+// ListRunChunks lists the chunks of a partitioned run in chunk order.
+func (s *service) ListRunChunks(ctx context.Context, runID int64) ([]*data.ReplicationRunChunk, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.ListReplicationRunChunks(ctx, runID)
+}
+
+// UpdateRunChunk updates a chunk's status, error and times.
+func (s *service) UpdateRunChunk(ctx context.Context, chunk *data.ReplicationRunChunk) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateReplicationRunChunk(ctx, chunk)
+}
+
+// AcquireRunLeases gives a run, backfill, compare or refresh a lease on each limited
+// connection or task, or on none if any is at its limit, and reports whether it did.
+func (s *service) AcquireRunLeases(ctx context.Context, holder data.LeaseHolder, limits []data.LeaseLimit) (bool, error) {
+	if s.repo == nil {
+		return false, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.AcquireRunLeases(ctx, holder, limits)
+}
+
+// ReleaseRunLeases releases the leases held by a run, backfill, compare or refresh.
+func (s *service) ReleaseRunLeases(ctx context.Context, holder data.LeaseHolder) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.ReleaseRunLeases(ctx, holder)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/lag"
+	"github.com/eleon00/hsoetlnlm/internal/stream"
+)
+
+// lagSampleRetention is how long a streaming task's lag samples are kept.
+const lagSampleRetention = time.Hour * 24 * 7
+
+// ReplicationLag is a streaming task's lag over a period: the samples measured in it, oldest
+// first, and the latest of them.
+type ReplicationLag struct {
+	TaskID           int64                        `json:"task_id"`
+	Streaming        bool                         `json:"streaming"`
+	AlertThresholdMs *int64                       `json:"alert_threshold_ms,omitempty"`
+	Current          *data.ReplicationLagSample   `json:"current"`
+	Samples          []*data.ReplicationLagSample `json:"samples"`
+}
+
+// RecordReplicationLagSample stores a lag measurement of a streaming task.
+func (s *service) RecordReplicationLagSample(ctx context.Context, sample *data.ReplicationLagSample) (int64, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.CreateReplicationLagSample(ctx, sample)
+}
+
+// GetLatestReplicationLagSample retrieves a task's last lag measurement, or nil if it has none.
+func (s *service) GetLatestReplicationLagSample(ctx context.Context, taskID int64) (*data.ReplicationLagSample, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	sample, err := s.repo.GetLatestReplicationLagSample(ctx, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return sample, err
+}
+
+// PruneReplicationLagSamples removes a task's lag samples older than their retention.
+func (s *service) PruneReplicationLagSamples(ctx context.Context, taskID int64) (int64, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.DeleteReplicationLagSamples(ctx, taskID, time.Now().Add(-lagSampleRetention))
+}
+
+// GetReplicationLag retrieves a task's lag samples measured since a time, at most limit of
+// them. Tasks that no longer stream keep their samples until they expire.
+func (s *service) GetReplicationLag(ctx context.Context, taskID int64, since time.Time, limit int) (*ReplicationLag, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	task, err := s.repo.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := s.repo.ListReplicationLagSamples(ctx, taskID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplicationLag{TaskID: taskID, Streaming: stream.Enabled(task.StreamConfig), Samples: samples}
+	if result.Streaming {
+		// Stored configurations were validated, so a parse error only leaves out the threshold
+		if config, err := stream.Parse(task.StreamConfig); err == nil && config.LagAlertThreshold > 0 {
+			threshold := config.LagAlertThreshold.Milliseconds()
+			result.AlertThresholdMs = &threshold
+		}
+	}
+	if len(samples) > 0 {
+		result.Current = samples[len(samples)-1]
+	}
+	return result, nil
+}
+
+// ListStreamingTaskLag retrieves the latest lag sample of every streaming task, with the task's
+// name and alert threshold.
+func (s *service) ListStreamingTaskLag(ctx context.Context) ([]lag.TaskLag, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	samples, err := s.repo.ListLatestReplicationLagSamples(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]lag.TaskLag, 0, len(samples))
+	for _, sample := range samples {
+		task, err := s.repo.GetReplicationTask(ctx, sample.ReplicationTaskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch task %d for its lag: %w", sample.ReplicationTaskID, err)
+		}
+		taskLag := lag.TaskLag{TaskID: task.ID, TaskName: task.Name, Sample: sample}
+		if config, err := stream.Parse(task.StreamConfig); err == nil {
+			taskLag.Threshold = config.LagAlertThreshold
+		}
+		tasks = append(tasks, taskLag)
+	}
+	return tasks, nil
+}
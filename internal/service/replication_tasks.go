@@ -46,6 +46,14 @@ func (s *service) UpdateReplicationTask(ctx context.Context, task *data.Replicat
 	return s.repo.UpdateReplicationTask(ctx, task)
 }
 
+// UpdateReplicationTaskWatermark records the incremental position reached by a successful run.
+func (s *service) UpdateReplicationTaskWatermark(ctx context.Context, id int64, watermark string) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateReplicationTaskWatermark(ctx, id, watermark)
+}
+
 // DeleteReplicationTask handles the business logic for deleting a replication task by ID.
 func (s *service) DeleteReplicationTask(ctx context.Context, id int64) error {
 	if s.repo == nil {
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// ErrRunNotCancellable is returned for cancels of runs that have ended, of per-table runs, of
+// streaming micro-runs, and of runs without a recorded workflow.
+var ErrRunNotCancellable = errors.New("run cannot be cancelled")
+
+// ErrRunNotRerunnable is returned for reruns of runs that have not ended, of per-table runs, or
+// of streaming micro-runs.
+var ErrRunNotRerunnable = errors.New("run cannot be rerun")
+
+// CancelReplicationRun cancels the workflow execution carrying out a run. The workflow records
+// the run as cancelled as it stops.
+func (s *service) CancelReplicationRun(ctx context.Context, runID int64) (*data.ReplicationRun, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	run, err := s.repo.GetReplicationRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case run.ParentRunID != nil:
+		return nil, fmt.Errorf("%w: run %d is a table run; cancel its parent run %d", ErrRunNotCancellable, runID, *run.ParentRunID)
+	case run.Streaming:
+		return nil, fmt.Errorf("%w: run %d is a micro-run of a streaming task; stop the task instead", ErrRunNotCancellable, runID)
+	case run.EndTime != nil:
+		return nil, fmt.Errorf("%w: run %d has ended as %s", ErrRunNotCancellable, runID, run.Status)
+	case run.TemporalWorkflowID == "":
+		return nil, fmt.Errorf("%w: run %d has no recorded workflow", ErrRunNotCancellable, runID)
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would cancel run %d of task %d (WorkflowClient not available)\n", runID, run.ReplicationTaskID)
+		return run, nil
+	}
+	if err := WorkflowClientImpl.CancelReplicationRun(ctx, run.TemporalWorkflowID, run.TemporalRunID); err != nil {
+		return nil, fmt.Errorf("failed to cancel run %d: %w", runID, err)
+	}
+	return run, nil
+}
+
+// RerunReplicationRun starts a new run of an ended run's task, linked to it. The new run takes
+// the original's parameters, logical time and rendered criteria, so it reads the same data
+// even if the task's watermark has moved since. It runs as a resume with no checkpoints.
+// While the task's workflow is open the rerun is rejected with ErrTaskWorkflowRunning and the
+// new run is closed as failed.
+func (s *service) RerunReplicationRun(ctx context.Context, runID int64) (*data.ReplicationRun, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	original, err := s.repo.GetReplicationRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if original.ParentRunID != nil {
+		return nil, fmt.Errorf("%w: run %d is a table run; rerun its parent run %d", ErrRunNotRerunnable, runID, *original.ParentRunID)
+	}
+	if original.Streaming {
+		return nil, fmt.Errorf("%w: run %d is a micro-run of a streaming task", ErrRunNotRerunnable, runID)
+	}
+	if original.EndTime == nil {
+		return nil, fmt.Errorf("%w: run %d is %s", ErrRunNotRerunnable, runID, original.Status)
+	}
+	task, err := s.repo.GetReplicationTask(ctx, original.ReplicationTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve task %d: %w", original.ReplicationTaskID, err)
+	}
+	if task.Status == TaskStatusPaused {
+		return nil, fmt.Errorf("%w: task %d; resume the task first", ErrTaskPaused, task.ID)
+	}
+
+	run := &data.ReplicationRun{
+		ReplicationTaskID: original.ReplicationTaskID,
+		StartTime:         time.Now(),
+		Status:            "loading",
+		Parameters:        original.Parameters,
+		LogicalTime:       original.LogicalTime,
+		RenderedCriteria:  original.RenderedCriteria,
+		RerunOfRunID:      &original.ID,
+	}
+	if run.ID, err = s.repo.CreateReplicationRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create rerun of run %d: %w", runID, err)
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would rerun run %d of task %d as run %d (WorkflowClient not available)\n", runID, run.ReplicationTaskID, run.ID)
+		return run, nil
+	}
+	if _, err := WorkflowClientImpl.ResumeReplicationRun(ctx, run.ReplicationTaskID, run.ID); err != nil {
+		// Close the new run so it does not look pending forever
+		now := time.Now()
+		if updateErr := s.repo.UpdateReplicationRunStatus(ctx, run.ID, "failed", err.Error(), &now); updateErr != nil {
+			fmt.Printf("Warning: failed to close rerun %d: %v\n", run.ID, updateErr)
+		}
+		return nil, fmt.Errorf("failed to rerun run %d: %w", runID, err)
+	}
+	return run, nil
+}
+
+// UpdateReplicationRunExecution records the workflow execution carrying out a run.
+func (s *service) UpdateReplicationRunExecution(ctx context.Context, runID int64, workflowID string, temporalRunID string) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateReplicationRunExecution(ctx, runID, workflowID, temporalRunID)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// Workflow execution statuses, as reported by the WorkflowClient.
+const (
+	ExecutionStatusRunning        = "running"
+	ExecutionStatusCompleted      = "completed"
+	ExecutionStatusFailed         = "failed"
+	ExecutionStatusCancelled      = "cancelled"
+	ExecutionStatusTerminated     = "terminated"
+	ExecutionStatusContinuedAsNew = "continued_as_new"
+	ExecutionStatusTimedOut       = "timed_out"
+)
+
+const (
+	// orphanGracePeriod is how long a new execution may take to create or reopen its run
+	// before it counts as orphaned, or before the run it may be reopening is reconciled.
+	orphanGracePeriod = time.Minute * 10
+	// unrecordedRunTimeout ends runs with no recorded execution, such as backfill slices, once
+	// they are older than the longest workflow run timeout.
+	unrecordedRunTimeout = time.Hour * 24 * 30
+)
+
+// WorkflowExecution is the state of a Temporal workflow execution.
+type WorkflowExecution struct {
+	WorkflowID string     `json:"workflow_id"`
+	RunID      string     `json:"run_id"`
+	Status     string     `json:"status"`
+	StartTime  time.Time  `json:"start_time"`
+	CloseTime  *time.Time `json:"close_time,omitempty"`
+}
+
+// ReconciledRun is a run the reconciler ended, with the reason stored as its error details.
+type ReconciledRun struct {
+	RunID  int64  `json:"run_id"`
+	TaskID int64  `json:"task_id"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// SkippedRun is a run, or a running task workflow, that could not be checked in a pass, e.g.
+// because Temporal or the database did not answer. The next pass checks it again.
+type SkippedRun struct {
+	RunID      int64  `json:"run_id,omitempty"`
+	TaskID     int64  `json:"task_id,omitempty"`
+	WorkflowID string `json:"workflow_id,omitempty"`
+	Error      string `json:"error"`
+}
+
+// RunReconciliation is the outcome of one reconciliation of runs against Temporal.
+type RunReconciliation struct {
+	CheckedRuns        int                  `json:"checked_runs"`
+	ReconciledRuns     []*ReconciledRun     `json:"reconciled_runs"`
+	OrphanedExecutions []*WorkflowExecution `json:"orphaned_executions"` // Running task workflows without a run
+	SkippedRuns        []*SkippedRun        `json:"skipped_runs"`
+}
+
+// ReconcileReplicationRuns ends the runs left unfinished by workflows that stopped without
+// recording their outcome, e.g. after a worker crash or a workflow timeout. Runs of executions
+// that timed out become timed_out, those of executions that closed otherwise or that Temporal
+// no longer knows become failed, and per-table runs follow their parent. Running task
+// workflows that no run records are reported, not stopped. A run that cannot be checked is
+// reported as skipped; only failing to list runs or workflows fails the pass.
+func (s *service) ReconcileReplicationRuns(ctx context.Context) (*RunReconciliation, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	runs, err := s.repo.ListUnfinishedReplicationRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unfinished runs: %w", err)
+	}
+
+	report := &RunReconciliation{
+		CheckedRuns:        len(runs),
+		ReconciledRuns:     make([]*ReconciledRun, 0),
+		OrphanedExecutions: make([]*WorkflowExecution, 0),
+		SkippedRuns:        make([]*SkippedRun, 0),
+	}
+	unfinished := make(map[int64]bool, len(runs))
+	for _, run := range runs {
+		unfinished[run.ID] = true
+	}
+	now := time.Now()
+	// Parents come first, so per-table runs see the status their parent was just given
+	for _, run := range runs {
+		status, reason, err := s.reconcileRun(ctx, run, unfinished, now)
+		if err == nil && status != "" {
+			if err = s.repo.UpdateReplicationRunStatus(ctx, run.ID, status, reason, &now); err != nil {
+				err = fmt.Errorf("failed to end run %d: %w", run.ID, err)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Warning: skipping reconciliation of run %d: %v\n", run.ID, err)
+			report.SkippedRuns = append(report.SkippedRuns, &SkippedRun{
+				RunID: run.ID, TaskID: run.ReplicationTaskID, WorkflowID: run.TemporalWorkflowID, Error: err.Error(),
+			})
+			continue
+		}
+		if status == "" {
+			continue
+		}
+		unfinished[run.ID] = false
+		report.ReconciledRuns = append(report.ReconciledRuns, &ReconciledRun{
+			RunID: run.ID, TaskID: run.ReplicationTaskID, Status: status, Reason: reason,
+		})
+	}
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Println("Development mode: would look for task workflows without a run (WorkflowClient not available)")
+		return report, nil
+	}
+	executions, err := WorkflowClientImpl.ListOpenReplicationExecutions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running task workflows: %w", err)
+	}
+	for _, execution := range executions {
+		if now.Sub(execution.StartTime) < orphanGracePeriod {
+			continue
+		}
+		_, err := s.repo.GetReplicationRunByExecution(ctx, execution.RunID)
+		if errors.Is(err, sql.ErrNoRows) {
+			report.OrphanedExecutions = append(report.OrphanedExecutions, execution)
+			continue
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to look up run of workflow %s: %w", execution.WorkflowID, err)
+			fmt.Printf("Warning: skipping workflow %s: %v\n", execution.WorkflowID, err)
+			report.SkippedRuns = append(report.SkippedRuns, &SkippedRun{WorkflowID: execution.WorkflowID, Error: err.Error()})
+		}
+	}
+	return report, nil
+}
+
+// reconcileRun decides whether an unfinished run is orphaned. It returns the status and reason
+// to end the run with, or an empty status to leave it alone.
+func (s *service) reconcileRun(ctx context.Context, run *data.ReplicationRun, unfinished map[int64]bool, now time.Time) (string, string, error) {
+	if run.ParentRunID != nil {
+		if unfinished[*run.ParentRunID] {
+			return "", "", nil
+		}
+		parent, err := s.repo.GetReplicationRun(ctx, *run.ParentRunID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to retrieve parent run %d of run %d: %w", *run.ParentRunID, run.ID, err)
+		}
+		return "failed", fmt.Sprintf("Parent run %d ended as %s without ending this table run", parent.ID, parent.Status), nil
+	}
+
+	if run.TemporalWorkflowID == "" {
+		if now.Sub(run.StartTime) < unrecordedRunTimeout {
+			return "", "", nil
+		}
+		return "timed_out", fmt.Sprintf("No workflow execution was recorded and the run did not end within %v", unrecordedRunTimeout), nil
+	}
+	if WorkflowClientImpl == nil {
+		return "", "", nil
+	}
+
+	execution, err := WorkflowClientImpl.DescribeWorkflowExecution(ctx, run.TemporalWorkflowID, run.TemporalRunID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to describe workflow of run %d: %w", run.ID, err)
+	}
+	if execution == nil {
+		return "failed", fmt.Sprintf("Workflow %s is unknown to Temporal, so the run can no longer end", run.TemporalWorkflowID), nil
+	}
+	if execution.Status == ExecutionStatusRunning {
+		return "", "", nil
+	}
+
+	// A newer execution of the task's workflow may carry the run on, or be reopening it for a
+	// resume before recording itself on it
+	latest, err := WorkflowClientImpl.DescribeWorkflowExecution(ctx, run.TemporalWorkflowID, "")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to describe workflow %s: %w", run.TemporalWorkflowID, err)
+	}
+	if latest != nil && latest.Status == ExecutionStatusRunning &&
+		(execution.Status == ExecutionStatusContinuedAsNew || now.Sub(latest.StartTime) < orphanGracePeriod) {
+		return "", "", nil
+	}
+
+	closed := "an unknown time"
+	if execution.CloseTime != nil {
+		closed = execution.CloseTime.Format(time.RFC3339)
+	}
+	if execution.Status == ExecutionStatusTimedOut {
+		return "timed_out", fmt.Sprintf("Workflow %s timed out at %s without recording the run's outcome", execution.WorkflowID, closed), nil
+	}
+	return "failed", fmt.Sprintf("Workflow %s ended as %s at %s without recording the run's outcome", execution.WorkflowID, execution.Status, closed), nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// schemaDiscoveryTimeout bounds a single introspection request against a source.
+const schemaDiscoveryTimeout = 30 * time.Second
+
+// openInspector loads a connection and connects to the system behind it.
+func (s *service) openInspector(ctx context.Context, connID int64) (schema.Inspector, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	conn, err := s.repo.GetConnection(ctx, connID)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Open(ctx, conn)
+}
+
+// ListSourceSchemas lists the schemas (datasets for BigQuery) of a source connection.
+func (s *service) ListSourceSchemas(ctx context.Context, connID int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, schemaDiscoveryTimeout)
+	defer cancel()
+
+	inspector, err := s.openInspector(ctx, connID)
+	if err != nil {
+		return nil, err
+	}
+	defer inspector.Close()
+	return inspector.ListSchemas(ctx)
+}
+
+// ListSourceTables lists the tables of a schema, defaulting to the connection's current schema.
+func (s *service) ListSourceTables(ctx context.Context, connID int64, schemaName string) ([]schema.Table, error) {
+	ctx, cancel := context.WithTimeout(ctx, schemaDiscoveryTimeout)
+	defer cancel()
+
+	inspector, err := s.openInspector(ctx, connID)
+	if err != nil {
+		return nil, err
+	}
+	defer inspector.Close()
+
+	if schemaName, err = resolveSchemaName(ctx, inspector, schemaName); err != nil {
+		return nil, err
+	}
+	return inspector.ListTables(ctx, schemaName)
+}
+
+// ListSourceColumns lists a table's columns, defaulting to the connection's current schema.
+func (s *service) ListSourceColumns(ctx context.Context, connID int64, schemaName, table string) ([]schema.Column, error) {
+	ctx, cancel := context.WithTimeout(ctx, schemaDiscoveryTimeout)
+	defer cancel()
+
+	inspector, err := s.openInspector(ctx, connID)
+	if err != nil {
+		return nil, err
+	}
+	defer inspector.Close()
+
+	if schemaName, err = resolveSchemaName(ctx, inspector, schemaName); err != nil {
+		return nil, err
+	}
+	return inspector.ListColumns(ctx, schemaName, table)
+}
+
+func resolveSchemaName(ctx context.Context, inspector schema.Inspector, schemaName string) (string, error) {
+	if schemaName != "" {
+		return schemaName, nil
+	}
+	schemaName, err := inspector.DefaultSchema(ctx)
+	if err != nil {
+		return "", err
+	}
+	if schemaName == "" {
+		return "", fmt.Errorf("%w: no schema given and the connection has no default", schema.ErrSchemaRequired)
+	}
+	return schemaName, nil
+}
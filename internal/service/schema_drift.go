@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// RecordSchemaSnapshot stores the source columns seen by a run.
+func (s *service) RecordSchemaSnapshot(ctx context.Context, snapshot *data.SchemaSnapshot) (int64, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.CreateSchemaSnapshot(ctx, snapshot)
+}
+
+// GetLatestSchemaSnapshot returns the drift baseline of a task's source table.
+func (s *service) GetLatestSchemaSnapshot(ctx context.Context, taskID int64, tableName string) (*data.SchemaSnapshot, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.GetLatestSchemaSnapshot(ctx, taskID, tableName)
+}
+
+// ListSchemaSnapshots lists a task's schema snapshots, most recent first.
+func (s *service) ListSchemaSnapshots(ctx context.Context, taskID int64) ([]*data.SchemaSnapshot, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetReplicationTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSchemaSnapshots(ctx, taskID)
+}
+
+// ResetSchemaSnapshots accepts a task's current source schema: snapshots are removed and
+// the next run records a new baseline.
+func (s *service) ResetSchemaSnapshots(ctx context.Context, taskID int64) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	if _, err := s.repo.GetReplicationTask(ctx, taskID); err != nil {
+		return err
+	}
+	return s.repo.DeleteSchemaSnapshots(ctx, taskID)
+}
+
+// UpdateReplicationRunSchemaDrift records the schema drift a run detected.
+func (s *service) UpdateReplicationRunSchemaDrift(ctx context.Context, runID int64, drift string) error {
+	if s.repo == nil {
+		return fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.UpdateReplicationRunSchemaDrift(ctx, runID, drift)
+}
+
+// RecordEvent appends an event to the feed.
+func (s *service) RecordEvent(ctx context.Context, event *data.Event) (int64, error) {
+	if s.repo == nil {
+		return 0, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.CreateEvent(ctx, event)
+}
+
+// ListEvents lists events matching a filter, most recent first.
+func (s *service) ListEvents(ctx context.Context, filter data.EventFilter) ([]*data.Event, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.ListEvents(ctx, filter)
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"time"
 
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
+	"github.com/eleon00/hsoetlnlm/internal/dag"
 	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/lag"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
 	// Add other necessary imports like models, etc. later
 )
 
@@ -18,13 +23,21 @@ type Service interface {
 	ListConnections(ctx context.Context) ([]*data.Connection, error)
 	UpdateConnection(ctx context.Context, conn *data.Connection) error
 	DeleteConnection(ctx context.Context, id int64) error
+	ListConnectionLeases(ctx context.Context, id int64) ([]*data.RunLease, error)
+
+	// Source schema discovery methods
+	ListSourceSchemas(ctx context.Context, connID int64) ([]string, error)
+	ListSourceTables(ctx context.Context, connID int64, schemaName string) ([]schema.Table, error)
+	ListSourceColumns(ctx context.Context, connID int64, schemaName, table string) ([]schema.Column, error)
 
 	// ReplicationTask methods
 	CreateReplicationTask(ctx context.Context, task *data.ReplicationTask) (int64, error)
 	GetReplicationTask(ctx context.Context, id int64) (*data.ReplicationTask, error)
 	ListReplicationTasks(ctx context.Context) ([]*data.ReplicationTask, error)
 	UpdateReplicationTask(ctx context.Context, task *data.ReplicationTask) error
+	UpdateReplicationTaskWatermark(ctx context.Context, id int64, watermark string) error
 	DeleteReplicationTask(ctx context.Context, id int64) error
+	PreviewTargetDDL(ctx context.Context, taskID int64) ([]*ddl.Table, error)
 
 	// BenthosConfiguration methods
 	CreateBenthosConfig(ctx context.Context, config *data.BenthosConfiguration) (int64, error)
@@ -34,13 +47,87 @@ type Service interface {
 	DeleteBenthosConfig(ctx context.Context, id int64) error
 
 	// Replication execution methods (using Temporal)
-	StartReplicationTask(ctx context.Context, taskID int64) (string, error)
+	StartReplicationTask(ctx context.Context, taskID int64, trigger *criteria.Trigger) (string, error)
 	StopReplicationTask(ctx context.Context, taskID int64) error
+	PauseReplicationTask(ctx context.Context, id int64) (*data.ReplicationTask, error)
+	ResumeReplicationTask(ctx context.Context, id int64) (*data.ReplicationTask, error)
+	SkipNextReplicationRun(ctx context.Context, id int64) (*data.ReplicationTask, error)
+	ConsumeSkipNextRun(ctx context.Context, id int64) (bool, error)
 	GetReplicationTaskStatus(ctx context.Context, taskID int64) (string, error)
 	ListReplicationRuns(ctx context.Context, taskID int64) ([]*data.ReplicationRun, error)
 	GetReplicationRunDetails(ctx context.Context, runID int64) (*data.ReplicationRun, error)
+	ResumeReplicationRun(ctx context.Context, runID int64) (*data.ReplicationRun, error)
+	CancelReplicationRun(ctx context.Context, runID int64) (*data.ReplicationRun, error)
+	RerunReplicationRun(ctx context.Context, runID int64) (*data.ReplicationRun, error)
+	ListReplicationRunTables(ctx context.Context, runID int64) ([]*data.ReplicationRun, error)
 	CreateReplicationRun(ctx context.Context, run *data.ReplicationRun) (int64, error)
 	UpdateReplicationRunStatus(ctx context.Context, id int64, status string, errorDetails string, endTime *time.Time) error
+	UpdateReplicationRunSchemaDrift(ctx context.Context, runID int64, drift string) error
+	UpdateReplicationRunSourceFiles(ctx context.Context, runID int64, files string) error
+	UpdateReplicationRunCriteria(ctx context.Context, runID int64, rendered string) error
+	UpdateReplicationRunExecution(ctx context.Context, runID int64, workflowID string, temporalRunID string) error
+	UpdateReplicationRunCounts(ctx context.Context, runID int64, rowsRead int64, rowsWritten int64) error
+	EndStreamingMicroRuns(ctx context.Context, taskID int64, reason string) (int, error)
+	CreateRunChunks(ctx context.Context, chunks []*data.ReplicationRunChunk) error
+	ListRunChunks(ctx context.Context, runID int64) ([]*data.ReplicationRunChunk, error)
+	UpdateRunChunk(ctx context.Context, chunk *data.ReplicationRunChunk) error
+	AcquireRunLeases(ctx context.Context, holder data.LeaseHolder, limits []data.LeaseLimit) (bool, error)
+	ReleaseRunLeases(ctx context.Context, holder data.LeaseHolder) error
+	ReconcileReplicationRuns(ctx context.Context) (*RunReconciliation, error)
+
+	// Schema drift methods
+	RecordSchemaSnapshot(ctx context.Context, snapshot *data.SchemaSnapshot) (int64, error)
+	GetLatestSchemaSnapshot(ctx context.Context, taskID int64, tableName string) (*data.SchemaSnapshot, error)
+	ListSchemaSnapshots(ctx context.Context, taskID int64) ([]*data.SchemaSnapshot, error)
+	ResetSchemaSnapshots(ctx context.Context, taskID int64) error
+
+	// Source-target compare methods
+	StartCompare(ctx context.Context, taskID int64, options string) (*data.CompareReport, error)
+	GetCompareReport(ctx context.Context, id int64) (*data.CompareReport, error)
+	ListCompareReports(ctx context.Context, taskID int64) ([]*data.CompareReport, error)
+	UpdateCompareReport(ctx context.Context, report *data.CompareReport) error
+
+	// Refresh methods
+	StartRefresh(ctx context.Context, reportID int64, options string) (*data.Refresh, error)
+	GetRefresh(ctx context.Context, id int64) (*data.Refresh, error)
+	ListRefreshes(ctx context.Context, taskID int64) ([]*data.Refresh, error)
+	UpdateRefresh(ctx context.Context, refresh *data.Refresh) error
+	AddRefreshProgress(ctx context.Context, id int64, ranges int, rowsDeleted, rowsWritten int64) error
+
+	// Backfill methods
+	StartBackfill(ctx context.Context, taskID int64, options string) (*data.Backfill, error)
+	GetBackfill(ctx context.Context, id int64) (*data.Backfill, error)
+	ListBackfills(ctx context.Context, taskID int64) ([]*data.Backfill, error)
+	ListBackfillSlices(ctx context.Context, backfillID int64) ([]*data.BackfillSlice, error)
+	UpdateBackfill(ctx context.Context, backfill *data.Backfill) error
+	UpdateBackfillSlice(ctx context.Context, slice *data.BackfillSlice) error
+	PauseBackfill(ctx context.Context, id int64) (*data.Backfill, error)
+	ResumeBackfill(ctx context.Context, id int64) (*data.Backfill, error)
+	CancelBackfill(ctx context.Context, id int64) (*data.Backfill, error)
+
+	// Pipeline methods
+	CreatePipeline(ctx context.Context, pipeline *data.Pipeline) (int64, error)
+	GetPipeline(ctx context.Context, id int64) (*data.Pipeline, error)
+	ListPipelines(ctx context.Context) ([]*data.Pipeline, error)
+	UpdatePipeline(ctx context.Context, pipeline *data.Pipeline) error
+	DeletePipeline(ctx context.Context, id int64) error
+	RunPipeline(ctx context.Context, id int64) (string, error)
+	GetPipelineDAG(ctx context.Context, id int64) (*dag.Graph, error)
+	CreatePipelineRun(ctx context.Context, run *data.PipelineRun) (int64, error)
+	GetPipelineRun(ctx context.Context, id int64) (*data.PipelineRun, error)
+	ListPipelineRuns(ctx context.Context, pipelineID int64) ([]*data.PipelineRun, error)
+	UpdatePipelineRun(ctx context.Context, run *data.PipelineRun) error
+
+	// Replication lag methods
+	RecordReplicationLagSample(ctx context.Context, sample *data.ReplicationLagSample) (int64, error)
+	GetLatestReplicationLagSample(ctx context.Context, taskID int64) (*data.ReplicationLagSample, error)
+	PruneReplicationLagSamples(ctx context.Context, taskID int64) (int64, error)
+	GetReplicationLag(ctx context.Context, taskID int64, since time.Time, limit int) (*ReplicationLag, error)
+	ListStreamingTaskLag(ctx context.Context) ([]lag.TaskLag, error)
+
+	// Event feed methods
+	RecordEvent(ctx context.Context, event *data.Event) (int64, error)
+	ListEvents(ctx context.Context, filter data.EventFilter) ([]*data.Event, error)
 
 	// ... other business logic methods
 }
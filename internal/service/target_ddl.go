@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// PreviewTargetDDL derives the CREATE TABLE statements a task's runs would execute on its target,
+// one per table for multi-table tasks. Nothing is executed.
+func (s *service) PreviewTargetDDL(ctx context.Context, taskID int64) ([]*ddl.Table, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	ctx, cancel := context.WithTimeout(ctx, schemaDiscoveryTimeout)
+	defer cancel()
+
+	task, err := s.repo.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	targetConn, err := s.repo.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ddl.Supported(targetConn.Type) {
+		return nil, fmt.Errorf("%w: %s", ddl.ErrUnsupportedTarget, targetConn.Type)
+	}
+	rules, err := schema.ParseTableRules(task.TableRules)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceConn, err := s.repo.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, err
+	}
+	inspector, err := schema.Open(ctx, sourceConn)
+	if err != nil {
+		return nil, err
+	}
+	defer inspector.Close()
+
+	if rules == nil {
+		// Templated criteria are described as they render now, without run parameters
+		vars := criteria.NewVars(task.ID, 0, time.Now(), nil, task.Watermark, nil)
+		if task.DataSelectionCriteria, err = criteria.Render(task.DataSelectionCriteria, sourceConn.Type, vars); err != nil {
+			return nil, err
+		}
+		table, err := ddl.Derive(ctx, inspector, sourceConn.Type, task, targetConn, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []*ddl.Table{table}, nil
+	}
+	selected, err := schema.ResolveTables(ctx, inspector, rules)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]*ddl.Table, 0, len(selected))
+	for i := range selected {
+		table, err := ddl.Derive(ctx, inspector, sourceConn.Type, task, targetConn, &selected[i])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+)
+
+// Task statuses set by pause and resume.
+const (
+	TaskStatusActive = "active"
+	TaskStatusPaused = "paused"
+)
+
+// TaskControlSignal is the signal a task's ReplicationWorkflow receives pause and resume on.
+const TaskControlSignal = "task-control"
+
+// Task control actions.
+const (
+	TaskActionPause    = "pause"
+	TaskActionResume   = "resume"
+	TaskActionSkipNext = "skip-next"
+)
+
+// ErrTaskPaused is returned for runs and pauses of a paused task.
+var ErrTaskPaused = errors.New("task is paused")
+
+// ErrTaskNotPaused is returned for resumes of a task that is not paused.
+var ErrTaskNotPaused = errors.New("task is not paused")
+
+// PauseReplicationTask pauses a task. Its run in progress starts no new tables, chunks or
+// pipelines until the task is resumed, and runs started meanwhile wait. Schedules,
+// checkpoints and the skip-next flag are kept.
+func (s *service) PauseReplicationTask(ctx context.Context, id int64) (*data.ReplicationTask, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	task, err := s.repo.GetReplicationTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status == TaskStatusPaused {
+		return nil, fmt.Errorf("%w: task %d", ErrTaskPaused, id)
+	}
+	return s.controlReplicationTask(ctx, task, TaskStatusPaused, TaskActionPause)
+}
+
+// ResumeReplicationTask resumes a paused task. Its waiting run continues where it stopped.
+func (s *service) ResumeReplicationTask(ctx context.Context, id int64) (*data.ReplicationTask, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	task, err := s.repo.GetReplicationTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != TaskStatusPaused {
+		return nil, fmt.Errorf("%w: task %d is %s", ErrTaskNotPaused, id, task.Status)
+	}
+	return s.controlReplicationTask(ctx, task, TaskStatusActive, TaskActionResume)
+}
+
+// SkipNextReplicationRun marks a task so its next run ends as skipped without replicating. A
+// run already in progress is not affected.
+func (s *service) SkipNextReplicationRun(ctx context.Context, id int64) (*data.ReplicationTask, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("service requires an initialized repository")
+	}
+	task, err := s.repo.GetReplicationTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateReplicationTaskControl(ctx, id, task.Status, true); err != nil {
+		return nil, fmt.Errorf("failed to mark next run of task %d skipped: %w", id, err)
+	}
+	task.SkipNextRun = true
+	return task, nil
+}
+
+// ConsumeSkipNextRun clears a task's skip-next flag, reporting whether the caller's run is the
+// one to skip.
+func (s *service) ConsumeSkipNextRun(ctx context.Context, id int64) (bool, error) {
+	if s.repo == nil {
+		return false, fmt.Errorf("service requires an initialized repository")
+	}
+	return s.repo.ConsumeReplicationTaskSkipNext(ctx, id)
+}
+
+// controlReplicationTask stores a task's new status, then signals action to its workflow. The
+// status is stored first so runs that start in between see it.
+func (s *service) controlReplicationTask(ctx context.Context, task *data.ReplicationTask, status string, action string) (*data.ReplicationTask, error) {
+	if err := s.repo.UpdateReplicationTaskControl(ctx, task.ID, status, task.SkipNextRun); err != nil {
+		return nil, fmt.Errorf("failed to store status of task %d: %w", task.ID, err)
+	}
+	task.Status = status
+
+	if WorkflowClientImpl == nil {
+		// For development/testing without Temporal
+		fmt.Printf("Development mode: would %s replication task %d (WorkflowClient not available)\n", action, task.ID)
+		return task, nil
+	}
+	if err := WorkflowClientImpl.SignalReplicationTask(ctx, task.ID, action); err != nil {
+		return nil, fmt.Errorf("failed to %s task %d: %w", action, task.ID, err)
+	}
+	return task, nil
+}
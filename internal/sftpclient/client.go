@@ -0,0 +1,175 @@
+package sftpclient
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Config holds the resolved settings needed to open an SFTP session.
+type Config struct {
+	Address             string // host:port
+	Username            string
+	Password            string
+	PrivateKeyFile      string
+	PrivateKeyPass      string
+	KnownHostsFile      string
+	InsecureSkipHostKey bool // Only for local test servers
+}
+
+// Client wraps an SFTP session and its underlying SSH connection.
+type Client struct {
+	ssh  *ssh.Client
+	sftp *sftp.Client
+}
+
+// Dial opens an SFTP session using password or private key authentication.
+func Dial(cfg Config) (*Client, error) {
+	auth, err := authMethods(cfg)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := hostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sshClient, err := ssh.Dial("tcp", cfg.Address, &ssh.ClientConfig{
+		User:            cfg.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to sftp server %s: %w", cfg.Address, err)
+	}
+
+	sftpClient, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("error starting sftp session on %s: %w", cfg.Address, err)
+	}
+
+	return &Client{ssh: sshClient, sftp: sftpClient}, nil
+}
+
+// Close ends the SFTP session and SSH connection.
+func (c *Client) Close() error {
+	sftpErr := c.sftp.Close()
+	sshErr := c.ssh.Close()
+	if sftpErr != nil {
+		return sftpErr
+	}
+	return sshErr
+}
+
+// Glob expands the patterns into a sorted, de-duplicated list of regular files.
+func (c *Client) Glob(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+	for _, pattern := range patterns {
+		matches, err := c.sftp.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding sftp glob %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if seen[match] {
+				continue
+			}
+			info, err := c.sftp.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("error reading sftp file %s: %w", match, err)
+			}
+			if info.Mode().IsRegular() {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Archive moves each file under dir/batch with a server-side rename, keeping the file's path
+// below it so that files of the same name in different directories or batches do not collide.
+// An existing archived file is never overwritten. A file already moved by an earlier attempt,
+// i.e. gone from its place but present in the archive, is skipped.
+func (c *Client) Archive(files []string, dir, batch string) error {
+	for _, file := range files {
+		target := path.Join(dir, batch, strings.TrimPrefix(path.Clean(file), "/"))
+		_, targetErr := c.sftp.Lstat(target)
+		if targetErr == nil {
+			if _, err := c.sftp.Lstat(file); errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("error archiving sftp file %s: %s already exists", file, target)
+		}
+		if !errors.Is(targetErr, os.ErrNotExist) {
+			return fmt.Errorf("error checking sftp archive file %s: %w", target, targetErr)
+		}
+		if err := c.sftp.MkdirAll(path.Dir(target)); err != nil {
+			return fmt.Errorf("error creating sftp archive directory %s: %w", path.Dir(target), err)
+		}
+		if err := c.sftp.PosixRename(file, target); err != nil {
+			return fmt.Errorf("error archiving sftp file %s to %s: %w", file, target, err)
+		}
+	}
+	return nil
+}
+
+// Remove deletes each file.
+func (c *Client) Remove(files []string) error {
+	for _, file := range files {
+		if err := c.sftp.Remove(file); err != nil {
+			return fmt.Errorf("error deleting sftp file %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// authMethods prefers key-file auth and falls back to password auth.
+func authMethods(cfg Config) ([]ssh.AuthMethod, error) {
+	if cfg.PrivateKeyFile != "" {
+		keyBytes, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sftp private key file: %w", err)
+		}
+		var signer ssh.Signer
+		if cfg.PrivateKeyPass != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(cfg.PrivateKeyPass))
+		} else {
+			signer, err = ssh.ParsePrivateKey(keyBytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing sftp private key: %w", err)
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
+	}
+	if cfg.Password != "" {
+		return []ssh.AuthMethod{ssh.Password(cfg.Password)}, nil
+	}
+	return nil, fmt.Errorf("sftp requires a password or private key file")
+}
+
+// hostKeyCallback verifies the server against a known_hosts file unless explicitly disabled.
+func hostKeyCallback(cfg Config) (ssh.HostKeyCallback, error) {
+	if cfg.KnownHostsFile != "" {
+		callback, err := knownhosts.New(cfg.KnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading sftp known_hosts file: %w", err)
+		}
+		return callback, nil
+	}
+	if cfg.InsecureSkipHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	return nil, fmt.Errorf("sftp requires a known_hosts file or insecure_skip_host_key=true")
+}
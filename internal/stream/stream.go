@@ -0,0 +1,101 @@
+// Package stream parses a task's stream configuration: how often a streaming task's pipeline
+// is rolled up into micro-runs, how long one workflow execution keeps it running, and how its
+// replication lag is measured and alerted on.
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	minRollupInterval  = time.Second * 10
+	maxRollupInterval  = time.Hour * 6
+	maxSegmentDuration = time.Hour * 12 // Half the workflow run timeout
+	minLagInterval     = time.Second * 5
+	maxLagInterval     = time.Hour
+)
+
+// ErrInvalidConfig is returned for malformed stream configurations.
+var ErrInvalidConfig = errors.New("invalid stream configuration")
+
+// Config is a streaming task's configuration with defaults filled in.
+type Config struct {
+	RollupInterval    time.Duration `json:"rollup_interval"`     // Length of one micro-run
+	SegmentDuration   time.Duration `json:"segment_duration"`    // Pipeline lifetime per workflow execution
+	LagInterval       time.Duration `json:"lag_interval"`        // Time between replication lag measurements
+	LagAlertThreshold time.Duration `json:"lag_alert_threshold"` // Latency that raises an alert; zero for none
+}
+
+// config is the JSON stored on the task. Durations are Go duration strings, e.g. 90s or 2h.
+type config struct {
+	RollupInterval    string `json:"rollup_interval"`
+	SegmentDuration   string `json:"segment_duration"`
+	LagInterval       string `json:"lag_interval"`
+	LagAlertThreshold string `json:"lag_alert_threshold"`
+}
+
+// Default is the configuration of streaming tasks that set nothing else.
+func Default() *Config {
+	return &Config{
+		RollupInterval:  time.Minute,
+		SegmentDuration: time.Hour,
+		LagInterval:     time.Second * 30,
+	}
+}
+
+// Enabled reports whether a task's stream configuration puts it in streaming mode.
+func Enabled(raw string) bool {
+	return strings.TrimSpace(raw) != ""
+}
+
+// Parse parses a task's stream configuration JSON. Unset values take their defaults. An empty
+// configuration is not parsed; the task is not streaming.
+func Parse(raw string) (*Config, error) {
+	c := Default()
+	if !Enabled(raw) {
+		return c, nil
+	}
+	stored := &config{}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(stored); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"rollup_interval", stored.RollupInterval, &c.RollupInterval},
+		{"segment_duration", stored.SegmentDuration, &c.SegmentDuration},
+		{"lag_interval", stored.LagInterval, &c.LagInterval},
+		{"lag_alert_threshold", stored.LagAlertThreshold, &c.LagAlertThreshold},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		value, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %q is not a duration like 90s or 2h", ErrInvalidConfig, d.name, d.value)
+		}
+		*d.dest = value
+	}
+
+	switch {
+	case c.RollupInterval < minRollupInterval || c.RollupInterval > maxRollupInterval:
+		return nil, fmt.Errorf("%w: rollup_interval must be between %v and %v", ErrInvalidConfig, minRollupInterval, maxRollupInterval)
+	case c.SegmentDuration < c.RollupInterval || c.SegmentDuration > maxSegmentDuration:
+		return nil, fmt.Errorf("%w: segment_duration must be between the rollup_interval and %v", ErrInvalidConfig, maxSegmentDuration)
+	case c.LagInterval < minLagInterval || c.LagInterval > maxLagInterval:
+		return nil, fmt.Errorf("%w: lag_interval must be between %v and %v", ErrInvalidConfig, minLagInterval, maxLagInterval)
+	case c.LagAlertThreshold < 0:
+		return nil, fmt.Errorf("%w: lag_alert_threshold cannot be negative", ErrInvalidConfig)
+	}
+	return c, nil
+}
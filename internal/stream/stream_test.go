@@ -0,0 +1,54 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, Enabled(""))
+	assert.False(t, Enabled("  "))
+	assert.True(t, Enabled("{}"))
+}
+
+func TestParse(t *testing.T) {
+	c, err := Parse("{}")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), c)
+
+	c, err = Parse(`{"rollup_interval": "5m", "segment_duration": "6h"}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Config{RollupInterval: time.Minute * 5, SegmentDuration: time.Hour * 6, LagInterval: time.Second * 30}, c)
+
+	c, err = Parse(`{"lag_interval": "10s", "lag_alert_threshold": "2m"}`)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second*10, c.LagInterval)
+	assert.Equal(t, time.Minute*2, c.LagAlertThreshold)
+
+	// Unset values keep their defaults
+	c, err = Parse(`{"rollup_interval": "30s"}`)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, c.SegmentDuration)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		`{"rollup_interval": "often"}`,
+		`{"rollup_interval": "1s"}`,
+		`{"rollup_interval": "12h"}`,
+		`{"segment_duration": "30s"}`,
+		`{"segment_duration": "24h"}`,
+		`{"rollup_interval": "2h", "segment_duration": "1h"}`,
+		`{"lag_interval": "1s"}`,
+		`{"lag_interval": "2h"}`,
+		`{"lag_alert_threshold": "-1m"}`,
+		`{"interval": "1m"}`,
+		`[]`,
+	} {
+		_, err := Parse(raw)
+		assert.ErrorIs(t, err, ErrInvalidConfig, raw)
+	}
+}
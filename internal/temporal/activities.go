@@ -2,11 +2,19 @@ package temporal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/eleon00/hsoetlnlm/internal/backfill"
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
 	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/partition"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
 	"github.com/eleon00/hsoetlnlm/internal/service"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 
 	// Import the new benthos package
 	. "github.com/eleon00/hsoetlnlm/internal/benthos" // Using dot import for brevity, or remove dot and prefix calls with benthos.
@@ -32,14 +40,44 @@ func (a *ActivitiesImpl) LoadReplicationTask(ctx context.Context, taskID int64)
 	return task, nil
 }
 
-// CreateReplicationRun creates a new replication run record
-func (a *ActivitiesImpl) CreateReplicationRun(ctx context.Context, taskID int64) (*data.ReplicationRun, error) {
+// CreateReplicationRun creates a new replication run record, with the parameters and logical
+// time of its trigger (nil for none) and the workflow execution calling it, so the run can be
+// cancelled on its own
+func (a *ActivitiesImpl) CreateReplicationRun(ctx context.Context, taskID int64, trigger *criteria.Trigger) (*data.ReplicationRun, error) {
+	execution := activity.GetInfo(ctx).WorkflowExecution
+	return a.createReplicationRun(ctx, taskID, trigger, execution.ID, execution.RunID)
+}
+
+// ReopenReplicationRun marks a resumed (or rerun) run running under the workflow execution
+// calling it.
+func (a *ActivitiesImpl) ReopenReplicationRun(ctx context.Context, runID int64) error {
+	execution := activity.GetInfo(ctx).WorkflowExecution
+	if err := a.svc.UpdateReplicationRunExecution(ctx, runID, execution.ID, execution.RunID); err != nil {
+		return fmt.Errorf("failed to record workflow of run %d: %w", runID, err)
+	}
+	return a.UpdateReplicationRunStatus(ctx, runID, string(ReplicationWorkflowStateRunning), "")
+}
+
+// createReplicationRun creates a run record carried out by the given workflow execution; empty
+// IDs for runs that cannot be cancelled on their own.
+func (a *ActivitiesImpl) createReplicationRun(ctx context.Context, taskID int64, trigger *criteria.Trigger, workflowID string, temporalRunID string) (*data.ReplicationRun, error) {
 	// Call the service to create the run record in the database
 	run := &data.ReplicationRun{
-		ReplicationTaskID: taskID,
-		StartTime:         time.Now(),                              // Activity start time as DB start time
-		Status:            string(ReplicationWorkflowStateLoading), // Initial status after creation
-		// TemporalRunID can be added later if needed, maybe via update
+		ReplicationTaskID:  taskID,
+		StartTime:          time.Now(),                              // Activity start time as DB start time
+		Status:             string(ReplicationWorkflowStateLoading), // Initial status after creation
+		TemporalWorkflowID: workflowID,
+		TemporalRunID:      temporalRunID,
+	}
+	if trigger != nil {
+		if len(trigger.Params) > 0 {
+			params, err := json.Marshal(trigger.Params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode parameters of run for task %d: %w", taskID, err)
+			}
+			run.Parameters = string(params)
+		}
+		run.LogicalTime = trigger.LogicalTime
 	}
 
 	newID, err := a.svc.CreateReplicationRun(ctx, run)
@@ -52,39 +90,106 @@ func (a *ActivitiesImpl) CreateReplicationRun(ctx context.Context, taskID int64)
 }
 
 // ExecuteBenthosPipelineActivity generates config and runs the Benthos pipeline
-func (a *ActivitiesImpl) ExecuteBenthosPipelineActivity(ctx context.Context, taskID int64, runID int64) (string, error) {
+func (a *ActivitiesImpl) ExecuteBenthosPipelineActivity(ctx context.Context, taskID int64, runID int64) (*PipelineResult, error) {
+	return a.executePipeline(ctx, taskID, runID, nil, nil, nil)
+}
+
+// executePipeline runs a task's pipeline for a run. For the per-table runs of a multi-table
+// task, table replaces the task's query and names the target table. A chunk restricts the
+// query to one chunk of a partitioned run, and a backfill slice passes its range bounds to the
+// query as arguments.
+func (a *ActivitiesImpl) executePipeline(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection, chunk *data.ReplicationRunChunk, slice *data.BackfillSlice) (*PipelineResult, error) {
 	// 1. Fetch the task details
 	task, err := a.svc.GetReplicationTask(ctx, taskID)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch task %d for benthos execution: %w", taskID, err)
+		return nil, fmt.Errorf("failed to fetch task %d for benthos execution: %w", taskID, err)
 	}
 	if task == nil {
-		return "", fmt.Errorf("task %d not found for benthos execution", taskID)
+		return nil, fmt.Errorf("task %d not found for benthos execution", taskID)
 	}
 
 	// 2. Fetch source connection details
 	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+		return nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
 	}
 	if sourceConn == nil {
-		return "", fmt.Errorf("source connection %d not found for task %d", task.SourceConnectionID, taskID)
+		return nil, fmt.Errorf("source connection %d not found for task %d", task.SourceConnectionID, taskID)
 	}
 
 	// 3. Fetch target connection details
 	targetConn, err := a.svc.GetConnection(ctx, task.TargetConnectionID)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch target connection %d for task %d: %w", task.TargetConnectionID, taskID, err)
+		return nil, fmt.Errorf("failed to fetch target connection %d for task %d: %w", task.TargetConnectionID, taskID, err)
 	}
 	if targetConn == nil {
-		return "", fmt.Errorf("target connection %d not found for task %d", task.TargetConnectionID, taskID)
+		return nil, fmt.Errorf("target connection %d not found for task %d", task.TargetConnectionID, taskID)
+	}
+
+	runContext := RunContext{RunID: runID}
+	if table == nil {
+		if err := a.renderRunCriteria(ctx, task, runID); err != nil {
+			return nil, err
+		}
+	} else {
+		query, err := tableQuery(sourceConn, *table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build query for table %s of task %d: %w", table.SourceName(), taskID, err)
+		}
+		task.DataSelectionCriteria = query
+		runContext.TargetTable = table.Target
+	}
+	if chunk != nil {
+		task.DataSelectionCriteria = partition.Query(task.DataSelectionCriteria, chunk.Predicate)
+		runContext.Chunk = chunk.ChunkIndex
+	}
+	if slice != nil {
+		runContext.ArgsMapping = backfill.ArgsMapping(backfill.Slice{Start: slice.RangeStart, End: slice.RangeEnd})
+	}
+	run, err := a.svc.GetReplicationRunDetails(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch run %d for task %d: %w", runID, taskID, err)
+	}
+
+	// Pin glob-based file sources to the files present when the run first started, for
+	// post-run archive/delete and so retries and resumes read the same files
+	sourceFiles, err := a.pinSourceFiles(ctx, task, sourceConn, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source files for task %d: %w", taskID, err)
+	}
+	if sourceFiles != nil {
+		if len(sourceFiles) == 0 {
+			return &PipelineResult{Output: "no source files matched"}, nil
+		}
+		task.DataSelectionCriteria = strings.Join(sourceFiles, ",")
+	}
+
+	// Seed incremental source state (e.g. API cursor) from the task's watermark
+	if err := prepareSourceState(task, sourceConn); err != nil {
+		return nil, fmt.Errorf("failed to prepare source state for task %d: %w", taskID, err)
+	}
+
+	// Start file targets from an empty staging area for this run
+	if err := prepareTargetFiles(task, targetConn, runID); err != nil {
+		return nil, fmt.Errorf("failed to prepare target files for task %d: %w", taskID, err)
+	}
+	// Remove rows an earlier attempt of this run (or chunk) wrote before writing them again
+	if err := clearRunRows(ctx, targetConn, runContext); err != nil {
+		return nil, fmt.Errorf("failed to clear earlier rows of run %d for task %d: %w", runID, taskID, err)
 	}
 
 	// 4. Generate the Benthos configuration
 	// We need to import the benthos package (assuming it's created as internal/benthos)
-	configYAML, err := GenerateBenthosConfig(*task, *sourceConn, *targetConn)
+	runContext.StartTime = run.StartTime
+	if runContext.Columns, err = targetColumns(ctx, task, sourceConn, targetConn, table); err != nil {
+		return nil, fmt.Errorf("failed to derive target columns for task %d: %w", taskID, err)
+	}
+	if runContext.ParquetSchema, err = parquetSchema(ctx, task, sourceConn, targetConn, table); err != nil {
+		return nil, fmt.Errorf("failed to derive parquet schema for task %d: %w", taskID, err)
+	}
+	configYAML, err := GenerateBenthosConfigForRun(*task, *sourceConn, *targetConn, runContext)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate benthos config for task %d: %w", taskID, err)
+		return nil, temporal.NewApplicationError(fmt.Sprintf("failed to generate benthos config for task %d", taskID), ErrorTypeConfigGeneration, err)
 	}
 
 	// 5. Execute the Benthos pipeline
@@ -96,15 +201,25 @@ func (a *ActivitiesImpl) ExecuteBenthosPipelineActivity(ctx context.Context, tas
 	}
 
 	// Execute Benthos (from internal/benthos)
-	// Use a timeout from the activity context
+	// Use a timeout from the activity context, heartbeating so a lost worker is noticed early
+	stopHeartbeats := startHeartbeats(ctx)
 	executionOutput, err := ExecuteBenthosPipeline(ctx, configYAML)
+	stopHeartbeats()
 	if err != nil {
 		// Benthos execution failed
-		return executionOutput, fmt.Errorf("benthos execution failed for task %d: %w", taskID, err)
+		return &PipelineResult{Output: executionOutput}, temporal.NewApplicationError(fmt.Sprintf("benthos execution failed for task %d", taskID), ErrorTypeBenthosExecution, err)
 	}
 
 	// Benthos execution succeeded (according to os/exec)
-	return executionOutput, nil
+	targetFiles, err := publishTargetFiles(task, targetConn, runID)
+	if err != nil {
+		return &PipelineResult{Output: executionOutput}, fmt.Errorf("failed to publish target files for task %d: %w", taskID, err)
+	}
+	if err := a.persistSourceState(ctx, task, sourceConn); err != nil {
+		// Non-fatal: retrying would re-deliver the data; the next run resumes from the older watermark
+		fmt.Printf("Warning: %v\n", err)
+	}
+	return &PipelineResult{Output: executionOutput, SourceFiles: sourceFiles, TargetFiles: targetFiles}, nil
 }
 
 // GenerateBenthosConfig generates a Benthos configuration for the task
@@ -176,7 +291,9 @@ func (a *ActivitiesImpl) StopBenthosPipeline(ctx context.Context, processID stri
 func (a *ActivitiesImpl) UpdateReplicationRunStatus(ctx context.Context, runID int64, status string, errorMsg string) error {
 	// Determine end time based on status
 	var endTime *time.Time
-	if status == string(ReplicationWorkflowStateCompleted) || status == string(ReplicationWorkflowStateFailed) {
+	switch ReplicationWorkflowState(status) {
+	case ReplicationWorkflowStateCompleted, ReplicationWorkflowStateFailed, ReplicationWorkflowStateQuarantined, ReplicationWorkflowStateSkipped,
+		ReplicationWorkflowStateCancelled, ReplicationWorkflowStateTimedOut:
 		now := time.Now()
 		endTime = &now
 	}
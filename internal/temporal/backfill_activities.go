@@ -0,0 +1,172 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/backfill"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/policy"
+)
+
+// EventTypeBackfill is the event recorded when a backfill finishes.
+const EventTypeBackfill = "backfill"
+
+// Slice statuses.
+const (
+	SliceStatusPending   = "pending"
+	SliceStatusRunning   = "running"
+	SliceStatusCompleted = "completed"
+	SliceStatusFailed    = "failed"
+	SliceStatusCancelled = "cancelled"
+)
+
+// BackfillPlan lists the slices of a backfill, how many may run at once and the execution
+// policy of the task they run.
+type BackfillPlan struct {
+	TaskID      int64                `json:"task_id"`
+	Slices      []data.BackfillSlice `json:"slices"`
+	MaxParallel int                  `json:"max_parallel"`
+	Policy      *policy.Policy       `json:"policy"`
+}
+
+// PlanBackfillActivity loads the slices recorded for a backfill.
+func (a *ActivitiesImpl) PlanBackfillActivity(ctx context.Context, backfillID int64) (*BackfillPlan, error) {
+	record, err := a.svc.GetBackfill(ctx, backfillID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch backfill %d: %w", backfillID, err)
+	}
+	opts, err := backfill.Parse(record.Options)
+	if err != nil {
+		return nil, err
+	}
+	p, err := a.LoadExecutionPolicyActivity(ctx, record.ReplicationTaskID)
+	if err != nil {
+		return nil, err
+	}
+	slices, err := a.svc.ListBackfillSlices(ctx, backfillID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slices of backfill %d: %w", backfillID, err)
+	}
+
+	plan := &BackfillPlan{TaskID: record.ReplicationTaskID, MaxParallel: opts.MaxParallel, Policy: p}
+	for _, slice := range slices {
+		plan.Slices = append(plan.Slices, *slice)
+	}
+	return plan, nil
+}
+
+// ExecuteBackfillSliceActivity runs the task's pipeline over one slice of a backfill, with the
+// slice's range as the query arguments. Each slice has its own run, created on the first
+// attempt and reused by retries so rows of an earlier attempt are replaced.
+func (a *ActivitiesImpl) ExecuteBackfillSliceActivity(ctx context.Context, backfillID int64, taskID int64, sliceID int64) (*PipelineResult, error) {
+	slices, err := a.svc.ListBackfillSlices(ctx, backfillID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list slices of backfill %d: %w", backfillID, err)
+	}
+	var slice *data.BackfillSlice
+	for _, candidate := range slices {
+		if candidate.ID == sliceID {
+			slice = candidate
+		}
+	}
+	if slice == nil {
+		return nil, fmt.Errorf("slice %d not found in backfill %d", sliceID, backfillID)
+	}
+
+	if slice.ReplicationRunID == nil {
+		// Slice runs are cancelled with their backfill, so no workflow is recorded on them
+		run, err := a.createReplicationRun(ctx, taskID, nil, "", "")
+		if err != nil {
+			return nil, err
+		}
+		slice.ReplicationRunID = &run.ID
+	}
+	runID := *slice.ReplicationRunID
+
+	now := time.Now()
+	slice.Status, slice.ErrorDetails, slice.StartTime, slice.EndTime = SliceStatusRunning, "", &now, nil
+	if err := a.svc.UpdateBackfillSlice(ctx, slice); err != nil {
+		// The run must be stored on the slice, or a retry would start another one
+		return nil, fmt.Errorf("failed to mark slice %d of backfill %d running: %w", slice.SliceIndex, backfillID, err)
+	}
+
+	result, err := a.executePipeline(ctx, taskID, runID, nil, nil, slice)
+
+	end := time.Now()
+	runStatus, errorMessage := ReplicationWorkflowStateCompleted, ""
+	slice.Status, slice.EndTime = SliceStatusCompleted, &end
+	switch {
+	case ctx.Err() != nil:
+		// Cancelled with the backfill, which the pipeline's heartbeats delivered
+		runStatus, errorMessage = ReplicationWorkflowStateFailed, "Cancelled with the backfill"
+		slice.Status, slice.ErrorDetails = SliceStatusCancelled, ""
+	case err != nil:
+		runStatus, errorMessage = ReplicationWorkflowStateFailed, err.Error()
+		slice.Status, slice.ErrorDetails = SliceStatusFailed, err.Error()
+	}
+	// Record the outcome even when the activity was cancelled
+	ctx = context.WithoutCancel(ctx)
+	if updateErr := a.svc.UpdateReplicationRunStatus(ctx, runID, string(runStatus), errorMessage, &end); updateErr != nil {
+		fmt.Printf("Warning: failed to store status of run %d: %v\n", runID, updateErr)
+	}
+	if updateErr := a.svc.UpdateBackfillSlice(ctx, slice); updateErr != nil {
+		fmt.Printf("Warning: failed to store status of slice %d of backfill %d: %v\n", slice.SliceIndex, backfillID, updateErr)
+	}
+	return result, err
+}
+
+// SetBackfillStatusActivity stores the status of a backfill being paused or resumed.
+func (a *ActivitiesImpl) SetBackfillStatusActivity(ctx context.Context, backfillID int64, status string) error {
+	record, err := a.svc.GetBackfill(ctx, backfillID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch backfill %d: %w", backfillID, err)
+	}
+	record.Status = status
+	if err := a.svc.UpdateBackfill(ctx, record); err != nil {
+		return fmt.Errorf("failed to store status of backfill %d: %w", backfillID, err)
+	}
+	return nil
+}
+
+// CompleteBackfillActivity stores the outcome of a backfill, marks the slices it never started
+// as cancelled and records the outcome as an event.
+func (a *ActivitiesImpl) CompleteBackfillActivity(ctx context.Context, backfillID int64, status string, errorMessage string) error {
+	record, err := a.svc.GetBackfill(ctx, backfillID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch backfill %d: %w", backfillID, err)
+	}
+	slices, err := a.svc.ListBackfillSlices(ctx, backfillID)
+	if err != nil {
+		return fmt.Errorf("failed to list slices of backfill %d: %w", backfillID, err)
+	}
+	counts := make(map[string]int)
+	for _, slice := range slices {
+		if slice.Status == SliceStatusPending {
+			slice.Status = SliceStatusCancelled
+			if err := a.svc.UpdateBackfillSlice(ctx, slice); err != nil {
+				return fmt.Errorf("failed to cancel slice %d of backfill %d: %w", slice.SliceIndex, backfillID, err)
+			}
+		}
+		counts[slice.Status]++
+	}
+
+	now := time.Now()
+	record.Status, record.ErrorDetails, record.EndTime = status, errorMessage, &now
+	if err := a.svc.UpdateBackfill(ctx, record); err != nil {
+		return fmt.Errorf("failed to store backfill %d: %w", backfillID, err)
+	}
+
+	_, err = a.svc.RecordEvent(ctx, &data.Event{
+		ReplicationTaskID: &record.ReplicationTaskID,
+		Type:              EventTypeBackfill,
+		Message: fmt.Sprintf("Backfill %d %s: %d of %d slices completed, %d failed, %d cancelled",
+			backfillID, record.Status, counts[SliceStatusCompleted], record.TotalSlices, counts[SliceStatusFailed], counts[SliceStatusCancelled]),
+		Details: fmt.Sprintf(`{"backfill_id": %d}`, backfillID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record backfill event for backfill %d: %w", backfillID, err)
+	}
+	return nil
+}
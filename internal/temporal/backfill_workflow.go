@@ -0,0 +1,120 @@
+package temporal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/backfill"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"go.temporal.io/sdk/workflow"
+)
+
+// BackfillWorkflow replicates a task over the slices of a backfill, running up to the
+// backfill's max_parallel slices at once. Pause and resume arrive on the backfill control
+// signal: a paused backfill starts no new slices until it is resumed. A failed slice does not
+// stop the others. The outcome is stored when the workflow completes, fails or is cancelled.
+func BackfillWorkflow(ctx workflow.Context, backfillID int64) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting backfill workflow", "backfillID", backfillID)
+	ctx = workflow.WithActivityOptions(ctx, replicationActivityOptions())
+
+	err := runBackfill(ctx, backfillID)
+	status, errorMessage := "completed", ""
+	switch {
+	case ctx.Err() != nil:
+		status = "cancelled"
+	case err != nil:
+		status, errorMessage = "failed", err.Error()
+	}
+
+	// Use a disconnected context so the outcome is recorded even if the workflow was cancelled
+	dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+	err2 := workflow.ExecuteActivity(dcCtx, "CompleteBackfillActivity", backfillID, status, errorMessage).Get(dcCtx, nil)
+	if err2 != nil {
+		logger.Error("Failed to store backfill outcome", "error", err2, "backfillID", backfillID)
+	}
+	return err
+}
+
+func runBackfill(ctx workflow.Context, backfillID int64) error {
+	logger := workflow.GetLogger(ctx)
+
+	var plan *BackfillPlan
+	if err := workflow.ExecuteActivity(ctx, "PlanBackfillActivity", backfillID).Get(ctx, &plan); err != nil {
+		return err
+	}
+	// One slot of the concurrency limits covers all the backfill's slices
+	release, err := holdLeases(ctx, plan.TaskID, data.LeaseHolder{Type: data.LeaseHolderBackfill, ID: backfillID})
+	defer release()
+	if err != nil {
+		return err
+	}
+	ctx = workflow.WithActivityOptions(ctx, taskActivityOptions(plan.Policy))
+	if err := workflow.ExecuteActivity(ctx, "CreateTargetTableActivity", plan.TaskID, int64(0), (*schema.TableSelection)(nil)).Get(ctx, nil); err != nil {
+		return err
+	}
+
+	paused := false
+	signals := workflow.GetSignalChannel(ctx, backfill.ControlSignal)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for {
+			var action string
+			signals.Receive(ctx, &action)
+			status := ""
+			switch {
+			case action == backfill.ActionPause && !paused:
+				paused, status = true, "paused"
+			case action == backfill.ActionResume && paused:
+				paused, status = false, "running"
+			default:
+				continue
+			}
+			logger.Info("Backfill control signal", "backfillID", backfillID, "action", action)
+			if err := workflow.ExecuteActivity(ctx, "SetBackfillStatusActivity", backfillID, status).Get(ctx, nil); err != nil {
+				logger.Error("Failed to store backfill status", "error", err, "status", status)
+			}
+		}
+	})
+
+	maxParallel := max(plan.MaxParallel, 1)
+	// Cancelled slices are waited for so they record their status before the backfill's
+	sliceOptions := taskPipelineOptions(plan.Policy)
+	sliceOptions.WaitForCancellation = true
+	sliceCtx := workflow.WithActivityOptions(ctx, sliceOptions)
+	running, attempted := 0, 0
+	var failed []string
+	for _, slice := range plan.Slices {
+		if slice.Status == SliceStatusCompleted {
+			continue
+		}
+		// A paused task holds its backfill too, though the backfill's own status is left alone
+		if err := awaitTaskActive(ctx, plan.TaskID); err != nil {
+			break
+		}
+		if err := workflow.Await(ctx, func() bool { return !paused && running < maxParallel }); err != nil {
+			break
+		}
+		running++
+		attempted++
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			defer func() { running-- }()
+			err := workflow.ExecuteActivity(sliceCtx, "ExecuteBackfillSliceActivity", backfillID, plan.TaskID, slice.ID).Get(ctx, nil)
+			if err != nil && ctx.Err() == nil {
+				logger.Error("Backfill slice failed", "slice", slice.SliceIndex, "error", err)
+				failed = append(failed, fmt.Sprintf("%s..%s: %v", slice.RangeStart, slice.RangeEnd, err))
+			}
+		})
+	}
+
+	// Wait for the slices already started, even when cancelled, so their futures settle
+	dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+	_ = workflow.Await(dcCtx, func() bool { return running == 0 })
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d slices failed: %s", len(failed), attempted, strings.Join(failed, "; "))
+	}
+	return nil
+}
@@ -2,11 +2,15 @@ package temporal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/sdk/client"
 
+	"github.com/eleon00/hsoetlnlm/internal/backfill"
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
 	"github.com/eleon00/hsoetlnlm/internal/service"
 )
 
@@ -74,7 +78,7 @@ func (c *Client) ExecuteWorkflow(ctx context.Context, options client.StartWorkfl
 }
 
 // ScheduleReplicationTask starts or schedules a replication task workflow
-func (c *Client) ScheduleReplicationTask(ctx context.Context, taskID int64, scheduleExpression string) (string, error) {
+func (c *Client) ScheduleReplicationTask(ctx context.Context, taskID int64, scheduleExpression string, trigger *criteria.Trigger) (string, error) {
 	// Default options
 	options := client.StartWorkflowOptions{
 		ID:                  fmt.Sprintf("replication-task-%d", taskID),
@@ -87,7 +91,7 @@ func (c *Client) ScheduleReplicationTask(ctx context.Context, taskID int64, sche
 	}
 
 	// Execute the workflow
-	run, err := c.ExecuteWorkflow(ctx, options, ReplicationWorkflow, taskID)
+	run, err := c.ExecuteWorkflow(ctx, options, ReplicationWorkflow, taskID, trigger)
 	if err != nil {
 		return "", fmt.Errorf("failed to start replication workflow for task %d: %w", taskID, err)
 	}
@@ -96,6 +100,132 @@ func (c *Client) ScheduleReplicationTask(ctx context.Context, taskID int64, sche
 	return run.GetID(), nil
 }
 
+// ResumeReplicationRun starts a ResumeReplicationWorkflow for a failed run. It shares the task's
+// workflow ID, so a task never has a new run and a resumed run going at the same time; while
+// the task's workflow is open it fails with service.ErrTaskWorkflowRunning.
+func (c *Client) ResumeReplicationRun(ctx context.Context, taskID int64, runID int64) (string, error) {
+	options := client.StartWorkflowOptions{
+		ID:                  fmt.Sprintf("replication-task-%d", taskID),
+		TaskQueue:           "replication-tasks",
+		WorkflowRunTimeout:  time.Hour * 24,
+		WorkflowTaskTimeout: time.Minute * 10,
+		// Otherwise an open execution of the task is returned and the run is never carried out
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+
+	run, err := c.ExecuteWorkflow(ctx, options, ResumeReplicationWorkflow, taskID, runID)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &alreadyStarted) {
+		return "", fmt.Errorf("%w: task %d", service.ErrTaskWorkflowRunning, taskID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to start resume workflow for run %d: %w", runID, err)
+	}
+	return run.GetID(), nil
+}
+
+// StartCompare starts the CompareWorkflow of a compare report
+func (c *Client) StartCompare(ctx context.Context, reportID int64) (string, error) {
+	options := client.StartWorkflowOptions{
+		ID:                 fmt.Sprintf("compare-report-%d", reportID),
+		TaskQueue:          "replication-tasks",
+		WorkflowRunTimeout: time.Hour * 72, // Compares of large tables can take days
+	}
+
+	run, err := c.ExecuteWorkflow(ctx, options, CompareWorkflow, reportID)
+	if err != nil {
+		return "", fmt.Errorf("failed to start compare workflow for report %d: %w", reportID, err)
+	}
+	return run.GetID(), nil
+}
+
+// StartRefresh starts the RefreshWorkflow of a refresh
+func (c *Client) StartRefresh(ctx context.Context, refreshID int64) (string, error) {
+	options := client.StartWorkflowOptions{
+		ID:                 fmt.Sprintf("refresh-%d", refreshID),
+		TaskQueue:          "replication-tasks",
+		WorkflowRunTimeout: time.Hour * 72,
+	}
+
+	run, err := c.ExecuteWorkflow(ctx, options, RefreshWorkflow, refreshID)
+	if err != nil {
+		return "", fmt.Errorf("failed to start refresh workflow for refresh %d: %w", refreshID, err)
+	}
+	return run.GetID(), nil
+}
+
+// StartBackfill starts the BackfillWorkflow of a backfill
+func (c *Client) StartBackfill(ctx context.Context, backfillID int64) (string, error) {
+	options := client.StartWorkflowOptions{
+		ID:                 fmt.Sprintf("backfill-%d", backfillID),
+		TaskQueue:          "replication-tasks",
+		WorkflowRunTimeout: time.Hour * 24 * 30, // Backfills of years of data can run for weeks
+	}
+
+	run, err := c.ExecuteWorkflow(ctx, options, BackfillWorkflow, backfillID)
+	if err != nil {
+		return "", fmt.Errorf("failed to start backfill workflow for backfill %d: %w", backfillID, err)
+	}
+	return run.GetID(), nil
+}
+
+// SignalBackfill sends a pause or resume action to a running BackfillWorkflow
+func (c *Client) SignalBackfill(ctx context.Context, workflowID string, action string) error {
+	return c.tc.SignalWorkflow(ctx, workflowID, "", backfill.ControlSignal, action)
+}
+
+// SignalReplicationTask sends a pause or resume action to the task's running ReplicationWorkflow,
+// if it has one. Without one there is nothing to signal: runs read the task's status when they
+// start.
+func (c *Client) SignalReplicationTask(ctx context.Context, taskID int64, action string) error {
+	err := c.tc.SignalWorkflow(ctx, fmt.Sprintf("replication-task-%d", taskID), "", service.TaskControlSignal, action)
+	var notFound *serviceerror.NotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return err
+	}
+	return nil
+}
+
+// SchedulePipeline replaces the cron workflow of a pipeline, terminating the previous one
+// (and a scheduled run in progress with it). An empty schedule only removes the workflow.
+func (c *Client) SchedulePipeline(ctx context.Context, pipelineID int64, schedule string) error {
+	workflowID := fmt.Sprintf("pipeline-schedule-%d", pipelineID)
+	err := c.tc.TerminateWorkflow(ctx, workflowID, "", "pipeline schedule changed")
+	var notFound *serviceerror.NotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to stop schedule of pipeline %d: %w", pipelineID, err)
+	}
+	if schedule == "" {
+		return nil
+	}
+
+	options := client.StartWorkflowOptions{
+		ID:                 workflowID,
+		TaskQueue:          "replication-tasks",
+		CronSchedule:       schedule,
+		WorkflowRunTimeout: time.Hour * 24, // Per scheduled run
+	}
+	if _, err := c.ExecuteWorkflow(ctx, options, PipelineWorkflow, pipelineID); err != nil {
+		return fmt.Errorf("failed to schedule pipeline workflow for pipeline %d: %w", pipelineID, err)
+	}
+	return nil
+}
+
+// RunPipeline starts a PipelineWorkflow for an on-demand run of a pipeline
+func (c *Client) RunPipeline(ctx context.Context, pipelineID int64) (string, error) {
+	options := client.StartWorkflowOptions{
+		ID:                 fmt.Sprintf("pipeline-%d-run-%d", pipelineID, time.Now().UnixNano()),
+		TaskQueue:          "replication-tasks",
+		WorkflowRunTimeout: time.Hour * 24,
+	}
+
+	run, err := c.ExecuteWorkflow(ctx, options, PipelineWorkflow, pipelineID)
+	if err != nil {
+		return "", fmt.Errorf("failed to start pipeline workflow for pipeline %d: %w", pipelineID, err)
+	}
+	return run.GetID(), nil
+}
+
 // CancelWorkflow cancels a running workflow
 func (c *Client) CancelWorkflow(ctx context.Context, workflowID string) error {
 	// In a real implementation, we might need to look up the current run ID
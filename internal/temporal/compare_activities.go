@@ -0,0 +1,219 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"go.temporal.io/sdk/activity"
+
+	. "github.com/eleon00/hsoetlnlm/internal/benthos"
+)
+
+// EventTypeCompareMismatch is the event recorded when a compare finds source and target out of sync.
+const EventTypeCompareMismatch = "compare_mismatch"
+
+// ComparePlan lists the tables a compare covers.
+type ComparePlan struct {
+	TaskID      int64           `json:"task_id"`
+	Tables      []compare.Table `json:"tables"`
+	MaxParallel int             `json:"max_parallel"`
+}
+
+// compareProgress is heartbeated after each key range so a retried activity resumes
+// where the last attempt stopped.
+type compareProgress struct {
+	NextRange int                 `json:"next_range"`
+	Result    compare.TableResult `json:"result"`
+}
+
+// loadCompare loads a compare report with its options, task and connections.
+func (a *ActivitiesImpl) loadCompare(ctx context.Context, reportID int64) (*compare.Options, *data.ReplicationTask, *data.Connection, *data.Connection, error) {
+	report, err := a.svc.GetCompareReport(ctx, reportID)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to fetch compare report %d: %w", reportID, err)
+	}
+	opts, err := compare.ParseOptions(report.Options)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	task, err := a.svc.GetReplicationTask(ctx, report.ReplicationTaskID)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to fetch task %d for compare: %w", report.ReplicationTaskID, err)
+	}
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, task.ID, err)
+	}
+	targetConn, err := a.svc.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to fetch target connection %d for task %d: %w", task.TargetConnectionID, task.ID, err)
+	}
+	return opts, task, sourceConn, targetConn, nil
+}
+
+// PlanCompareActivity lists the tables of a compare: the task's resolved tables (optionally
+// narrowed by the report's options) or its query and target table.
+func (a *ActivitiesImpl) PlanCompareActivity(ctx context.Context, reportID int64) (*ComparePlan, error) {
+	opts, task, sourceConn, targetConn, err := a.loadCompare(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Tables) > 0 && task.TableRules == "" {
+		return nil, fmt.Errorf("tables can only be chosen for multi-table tasks")
+	}
+	tables, err := compareTables(ctx, task, sourceConn, targetConn)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ComparePlan{TaskID: task.ID, MaxParallel: opts.MaxParallel}
+	for _, table := range tables {
+		if opts.Selects(table.Name) {
+			plan.Tables = append(plan.Tables, table)
+		}
+	}
+	if len(plan.Tables) < len(opts.Tables) {
+		return nil, fmt.Errorf("%d of the %d requested tables are not replicated by task %d", len(opts.Tables)-len(plan.Tables), len(opts.Tables), task.ID)
+	}
+	if len(plan.Tables) == 0 {
+		return nil, fmt.Errorf("task %d selects no source tables", task.ID)
+	}
+	return plan, nil
+}
+
+// compareTables lists a task's source tables with their target tables, or its query for
+// single-table tasks.
+func compareTables(ctx context.Context, task *data.ReplicationTask, sourceConn, targetConn *data.Connection) ([]compare.Table, error) {
+	rules, err := schema.ParseTableRules(task.TableRules)
+	if err != nil {
+		return nil, fmt.Errorf("task %d: %w", task.ID, err)
+	}
+	if rules == nil {
+		target := ParseConnectionString(targetConn.ConnectionString)["table"]
+		if target == "" {
+			return nil, fmt.Errorf("'table' not found in connection string for %s target", targetConn.Type)
+		}
+		// Templated criteria are compared as they render now, without run parameters
+		query, err := criteria.Render(task.DataSelectionCriteria, sourceConn.Type, criteria.NewVars(task.ID, 0, time.Now(), nil, task.Watermark, nil))
+		if err != nil {
+			return nil, fmt.Errorf("task %d: %w", task.ID, err)
+		}
+		return []compare.Table{{Name: "query", Query: query, Target: target}}, nil
+	}
+
+	inspector, err := schema.Open(ctx, sourceConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source catalog for task %d: %w", task.ID, err)
+	}
+	defer inspector.Close()
+	selections, err := schema.ResolveTables(ctx, inspector, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source tables for task %d: %w", task.ID, err)
+	}
+	tables := make([]compare.Table, 0, len(selections))
+	for _, table := range selections {
+		tables = append(tables, compare.Table{Name: table.SourceName(), Schema: table.Schema, Table: table.Name, Target: table.Target})
+	}
+	return tables, nil
+}
+
+// CompareTableActivity compares one table range by range, heartbeating its progress.
+func (a *ActivitiesImpl) CompareTableActivity(ctx context.Context, reportID int64, table compare.Table) (*compare.TableResult, error) {
+	opts, _, sourceConn, targetConn, err := a.loadCompare(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	session, err := compare.Open(ctx, sourceConn, targetConn, table, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compare of %s: %w", table.Name, err)
+	}
+	defer session.Close()
+
+	ranges, err := session.Ranges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split %s into key ranges: %w", table.Name, err)
+	}
+	progress := compareProgress{Result: compare.TableResult{Table: table.Name, Target: table.Target, KeyColumns: session.KeyColumns()}}
+	if activity.HasHeartbeatDetails(ctx) {
+		var previous compareProgress
+		if err := activity.GetHeartbeatDetails(ctx, &previous); err == nil && previous.NextRange <= len(ranges) {
+			progress = previous
+		}
+	}
+
+	for ; progress.NextRange < len(ranges); progress.NextRange++ {
+		result, err := session.CompareRange(ctx, ranges[progress.NextRange])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s: %w", table.Name, err)
+		}
+		progress.Result.Add(result, opts.MaxRowDiffs)
+		activity.RecordHeartbeat(ctx, compareProgress{NextRange: progress.NextRange + 1, Result: progress.Result})
+	}
+	return &progress.Result, nil
+}
+
+// CompleteCompareReportActivity stores the outcome of a compare and records an event when
+// source and target are out of sync.
+func (a *ActivitiesImpl) CompleteCompareReportActivity(ctx context.Context, reportID int64, results []compare.TableResult, errorMessage string) error {
+	report, err := a.svc.GetCompareReport(ctx, reportID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch compare report %d: %w", reportID, err)
+	}
+
+	inSync, mismatchedRanges, mismatchedTables := errorMessage == "", 0, []string{}
+	var failedTables []string
+	for _, result := range results {
+		if result.Error != "" {
+			failedTables = append(failedTables, result.Table)
+		}
+		if len(result.MismatchedRanges) > 0 {
+			mismatchedRanges += len(result.MismatchedRanges)
+			mismatchedTables = append(mismatchedTables, result.Table)
+		}
+		inSync = inSync && result.InSync()
+	}
+	if errorMessage == "" && len(failedTables) > 0 {
+		errorMessage = fmt.Sprintf("%d of %d tables could not be compared: %s", len(failedTables), len(results), strings.Join(failedTables, ", "))
+	}
+
+	resultJSON, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("failed to encode compare results: %w", err)
+	}
+	now := time.Now()
+	report.Status, report.Result, report.ErrorDetails, report.EndTime = "completed", string(resultJSON), errorMessage, &now
+	if errorMessage != "" {
+		report.Status = "failed"
+	}
+	if report.Status == "completed" || mismatchedRanges > 0 {
+		report.InSync = &inSync
+	}
+	if err := a.svc.UpdateCompareReport(ctx, report); err != nil {
+		return fmt.Errorf("failed to store compare report %d: %w", reportID, err)
+	}
+
+	if mismatchedRanges > 0 {
+		task, err := a.svc.GetReplicationTask(ctx, report.ReplicationTaskID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch task %d for compare event: %w", report.ReplicationTaskID, err)
+		}
+		_, err = a.svc.RecordEvent(ctx, &data.Event{
+			ReplicationTaskID: &task.ID,
+			Type:              EventTypeCompareMismatch,
+			Message: fmt.Sprintf("Compare %d of task %s found %d mismatched key ranges in %s",
+				reportID, task.Name, mismatchedRanges, strings.Join(mismatchedTables, ", ")),
+			Details: fmt.Sprintf(`{"compare_report_id": %d}`, reportID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record compare event for report %d: %w", reportID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package temporal
+
+import (
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"go.temporal.io/sdk/workflow"
+)
+
+// CompareWorkflow compares a task's source and target for a compare report: row counts and
+// hash aggregates per key range for every table, at most MaxParallel tables at a time. The
+// outcome is stored on the report, also when the workflow fails or is cancelled.
+func CompareWorkflow(ctx workflow.Context, reportID int64) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting compare workflow", "reportID", reportID)
+	ctx = workflow.WithActivityOptions(ctx, replicationActivityOptions())
+
+	results, err := runCompare(ctx, reportID)
+	errorMessage := ""
+	if err != nil {
+		errorMessage = err.Error()
+	}
+
+	// Use a disconnected context so the outcome is recorded even if the workflow was cancelled
+	dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+	err2 := workflow.ExecuteActivity(dcCtx, "CompleteCompareReportActivity", reportID, results, errorMessage).Get(dcCtx, nil)
+	if err2 != nil {
+		logger.Error("Failed to store compare report", "error", err2, "reportID", reportID)
+	}
+	return err
+}
+
+func runCompare(ctx workflow.Context, reportID int64) ([]compare.TableResult, error) {
+	logger := workflow.GetLogger(ctx)
+
+	var plan *ComparePlan
+	if err := workflow.ExecuteActivity(ctx, "PlanCompareActivity", reportID).Get(ctx, &plan); err != nil {
+		return nil, err
+	}
+	release, err := holdLeases(ctx, plan.TaskID, data.LeaseHolder{Type: data.LeaseHolderCompare, ID: reportID})
+	defer release()
+	if err != nil {
+		return nil, err
+	}
+	maxParallel := max(plan.MaxParallel, 1)
+
+	compareCtx := workflow.WithActivityOptions(ctx, compareActivityOptions())
+	results := make([]compare.TableResult, len(plan.Tables))
+	selector := workflow.NewSelector(ctx)
+	running := 0
+	for i, table := range plan.Tables {
+		if running == maxParallel {
+			selector.Select(ctx) // Wait for a table to finish before starting the next
+			running--
+		}
+		future := workflow.ExecuteActivity(compareCtx, "CompareTableActivity", reportID, table)
+		selector.AddFuture(future, func(f workflow.Future) {
+			if err := f.Get(ctx, &results[i]); err != nil {
+				logger.Error("Table compare failed", "table", table.Name, "error", err)
+				results[i] = compare.TableResult{Table: table.Name, Target: table.Target, Error: err.Error()}
+			}
+		})
+		running++
+	}
+	for ; running > 0; running-- {
+		selector.Select(ctx)
+	}
+	return results, ctx.Err()
+}
+
+// compareActivityOptions allow a table compare to run for hours, heartbeating per key range.
+func compareActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour * 24,
+		HeartbeatTimeout:    time.Minute * 5,
+		RetryPolicy:         replicationRetryPolicy(),
+	}
+}
@@ -0,0 +1,107 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/policy"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Application error types of pipeline failures, for use in a task's non_retryable_errors.
+const (
+	ErrorTypeConfigGeneration  = "ConfigGenerationFailed"
+	ErrorTypeBenthosExecution  = "BenthosExecutionFailed"
+	ErrorTypeInvalidExecPolicy = "InvalidExecutionPolicy"
+	ErrorTypeChunkCheckpoint   = "ChunkCheckpointFailed"
+)
+
+// LoadExecutionPolicyActivity parses the execution policy of a task. Workflows read it once
+// when they start, so a policy edited mid-run applies from the next run.
+func (a *ActivitiesImpl) LoadExecutionPolicyActivity(ctx context.Context, taskID int64) (*policy.Policy, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task %d: %w", taskID, err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task %d not found", taskID)
+	}
+	p, err := policy.Parse(task.ExecutionPolicy)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", taskID, err), ErrorTypeInvalidExecPolicy, nil)
+	}
+	return p, nil
+}
+
+// taskActivityOptions are the options of a task's bookkeeping activities under its policy
+// (the default policy when p is nil).
+func taskActivityOptions(p *policy.Policy) workflow.ActivityOptions {
+	if p == nil {
+		p = policy.Default()
+	}
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: p.ActivityTimeout,
+		RetryPolicy:         taskRetryPolicy(p),
+	}
+}
+
+// taskPipelineOptions are the options of a task's pipeline activities under its policy.
+// Pipelines heartbeat while Benthos runs, so a lost worker is noticed after the heartbeat
+// timeout rather than the pipeline timeout.
+func taskPipelineOptions(p *policy.Policy) workflow.ActivityOptions {
+	if p == nil {
+		p = policy.Default()
+	}
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: p.PipelineTimeout,
+		HeartbeatTimeout:    p.HeartbeatTimeout,
+		RetryPolicy:         taskRetryPolicy(p),
+	}
+}
+
+func taskRetryPolicy(p *policy.Policy) *temporal.RetryPolicy {
+	if p == nil {
+		p = policy.Default()
+	}
+	return &temporal.RetryPolicy{
+		InitialInterval:        p.InitialInterval,
+		BackoffCoefficient:     p.BackoffCoefficient,
+		MaximumInterval:        p.MaximumInterval,
+		MaximumAttempts:        p.MaxAttempts,
+		NonRetryableErrorTypes: p.NonRetryableErrors,
+	}
+}
+
+// startHeartbeats heartbeats the activity of ctx until the returned function is called, often
+// enough to stay within its heartbeat timeout. Outside an activity, or without a heartbeat
+// timeout, it does nothing.
+func startHeartbeats(ctx context.Context) func() {
+	if !activity.IsActivity(ctx) {
+		return func() {}
+	}
+	timeout := activity.GetInfo(ctx).HeartbeatTimeout
+	if timeout <= 0 {
+		return func() {}
+	}
+	interval := max(timeout/3, time.Second)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				activity.RecordHeartbeat(ctx)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,138 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/partition"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Chunk statuses.
+const (
+	ChunkStatusPending   = "pending"
+	ChunkStatusRunning   = "running"
+	ChunkStatusCompleted = "completed"
+	ChunkStatusFailed    = "failed"
+)
+
+// ChunkPlan lists the chunks of a partitioned run.
+type ChunkPlan struct {
+	Chunks      []data.ReplicationRunChunk `json:"chunks"`
+	MaxParallel int                        `json:"max_parallel"`
+}
+
+// PlanRunChunksActivity splits a run of a partitioned task into chunks on the task's split
+// column and records them. It returns nil when the task is not partitioned, and for the
+// tables of a multi-table task that have no column of the split column's name. The chunks
+// already recorded for the run are returned as they are, so a retried plan or a resumed run
+// keeps its completed chunks.
+func (a *ActivitiesImpl) PlanRunChunksActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection) (*ChunkPlan, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task %d for chunk planning: %w", taskID, err)
+	}
+	p, err := partition.Parse(task.Partitioning)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", taskID, err), "InvalidPartitioning", nil)
+	}
+	if p == nil {
+		return nil, nil
+	}
+
+	existing, err := a.svc.ListRunChunks(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks of run %d: %w", runID, err)
+	}
+	if len(existing) > 0 {
+		return newChunkPlan(existing, p), nil
+	}
+
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+	}
+	targetConn, err := a.svc.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch target connection %d for task %d: %w", task.TargetConnectionID, taskID, err)
+	}
+	if err := partition.Supported(sourceConn.Type, targetConn.Type); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", taskID, err), "InvalidPartitioning", nil)
+	}
+
+	if table == nil {
+		if err := a.renderRunCriteria(ctx, task, runID); err != nil {
+			return nil, err
+		}
+	}
+	query := task.DataSelectionCriteria
+	if table != nil {
+		if query, err = tableQuery(sourceConn, *table); err != nil {
+			return nil, fmt.Errorf("failed to build query for table %s of task %d: %w", table.SourceName(), taskID, err)
+		}
+	}
+	predicates, err := partition.Plan(ctx, sourceConn, query, p)
+	switch {
+	case table != nil && errors.Is(err, partition.ErrNoSplitColumn):
+		// Not every table of a multi-table task has the split column; those run in one piece
+		fmt.Printf("Warning: table %s of task %d is not partitioned: %v\n", table.SourceName(), taskID, err)
+		return nil, nil
+	case errors.Is(err, partition.ErrInvalidPartitioning), errors.Is(err, partition.ErrNoSplitColumn):
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", taskID, err), "InvalidPartitioning", nil)
+	case err != nil:
+		return nil, fmt.Errorf("failed to plan chunks of run %d: %w", runID, err)
+	}
+
+	chunks := make([]*data.ReplicationRunChunk, len(predicates))
+	for i, predicate := range predicates {
+		chunks[i] = &data.ReplicationRunChunk{
+			ReplicationRunID: runID,
+			ChunkIndex:       i,
+			Predicate:        predicate,
+			Status:           ChunkStatusPending,
+		}
+	}
+	if err := a.svc.CreateRunChunks(ctx, chunks); err != nil {
+		return nil, fmt.Errorf("failed to record chunks of run %d: %w", runID, err)
+	}
+	return newChunkPlan(chunks, p), nil
+}
+
+func newChunkPlan(chunks []*data.ReplicationRunChunk, p *partition.Partitioning) *ChunkPlan {
+	plan := &ChunkPlan{MaxParallel: p.MaxParallel}
+	for _, chunk := range chunks {
+		plan.Chunks = append(plan.Chunks, *chunk)
+	}
+	return plan
+}
+
+// ExecuteChunkPipelineActivity runs the pipeline for one chunk of a partitioned run (of a
+// table of a multi-table run when table is set), recording the chunk's status. A loaded chunk
+// whose completion cannot be recorded fails, retryably: resumes skip only recorded chunks.
+func (a *ActivitiesImpl) ExecuteChunkPipelineActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection, chunk data.ReplicationRunChunk) (*PipelineResult, error) {
+	now := time.Now()
+	chunk.Status, chunk.ErrorDetails, chunk.StartTime, chunk.EndTime = ChunkStatusRunning, "", &now, nil
+	if err := a.svc.UpdateRunChunk(ctx, &chunk); err != nil {
+		// Non-fatal: the chunk's status only reports progress
+		fmt.Printf("Warning: failed to mark chunk %d of run %d running: %v\n", chunk.ChunkIndex, runID, err)
+	}
+
+	result, err := a.executePipeline(ctx, taskID, runID, table, &chunk, nil)
+
+	end := time.Now()
+	chunk.Status, chunk.EndTime = ChunkStatusCompleted, &end
+	if err != nil {
+		chunk.Status, chunk.ErrorDetails = ChunkStatusFailed, err.Error()
+	}
+	if updateErr := a.svc.UpdateRunChunk(ctx, &chunk); updateErr != nil {
+		if err == nil {
+			return result, temporal.NewApplicationError(fmt.Sprintf("chunk %d of run %d was loaded but its checkpoint could not be stored", chunk.ChunkIndex, runID), ErrorTypeChunkCheckpoint, updateErr)
+		}
+		fmt.Printf("Warning: failed to store status of chunk %d of run %d: %v\n", chunk.ChunkIndex, runID, updateErr)
+	}
+	return result, err
+}
@@ -0,0 +1,77 @@
+package temporal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/policy"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"go.temporal.io/sdk/workflow"
+)
+
+// executeChunks runs a partitioned run as one pipeline activity per chunk, at most
+// plan.MaxParallel at a time, so a failed chunk is retried on its own instead of restarting
+// the whole extraction. Chunks already completed by the run are skipped. It returns false
+// without running anything when the task (or table) is not partitioned. Chunk pipelines run
+// under the run's execution policy p, and none is started while control is paused.
+func executeChunks(ctx workflow.Context, taskID int64, runID int64, table *schema.TableSelection, p *policy.Policy, control *runControl) (bool, error) {
+	logger := workflow.GetLogger(ctx)
+
+	var plan *ChunkPlan
+	if err := workflow.ExecuteActivity(ctx, "PlanRunChunksActivity", taskID, runID, table).Get(ctx, &plan); err != nil {
+		return true, fmt.Errorf("failed to plan chunks: %w", err)
+	}
+	if plan == nil {
+		return false, nil
+	}
+	maxParallel := plan.MaxParallel
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+	logger.Info("Extracting in chunks", "chunks", len(plan.Chunks), "max_parallel", maxParallel)
+
+	pipelineCtx := workflow.WithActivityOptions(ctx, taskPipelineOptions(p))
+	selector := workflow.NewSelector(ctx)
+	var failed []int
+	var err error
+	running := 0
+	for _, chunk := range plan.Chunks {
+		if chunk.Status == ChunkStatusCompleted {
+			continue
+		}
+		if running == maxParallel {
+			selector.Select(ctx) // Wait for a chunk to finish before starting the next
+			running--
+		}
+		if err = control.wait(ctx, runID, ReplicationWorkflowStateRunning); err != nil {
+			break
+		}
+		future := workflow.ExecuteActivity(pipelineCtx, "ExecuteChunkPipelineActivity", taskID, runID, table, chunk)
+		selector.AddFuture(future, func(f workflow.Future) {
+			var result PipelineResult
+			if err := f.Get(ctx, &result); err != nil {
+				logger.Error("Chunk extraction failed", "chunk", chunk.ChunkIndex, "error", err, "output", result.Output)
+				failed = append(failed, chunk.ChunkIndex)
+			}
+		})
+		running++
+	}
+	for ; running > 0; running-- {
+		selector.Select(ctx)
+	}
+	if err != nil {
+		return true, err
+	}
+
+	if len(failed) > 0 {
+		sort.Ints(failed)
+		indexes := make([]string, len(failed))
+		for i, index := range failed {
+			indexes[i] = fmt.Sprint(index)
+		}
+		return true, fmt.Errorf("%d of %d chunks failed: %s", len(failed), len(plan.Chunks), strings.Join(indexes, ", "))
+	}
+	logger.Info("All chunks extracted", "chunks", len(plan.Chunks))
+	return true, nil
+}
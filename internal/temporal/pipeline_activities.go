@@ -0,0 +1,102 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/dag"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"go.temporal.io/sdk/activity"
+)
+
+// EventTypePipeline is the event recorded when a pipeline run finishes.
+const EventTypePipeline = "pipeline"
+
+// PipelineRunPlan is a started pipeline run and the tasks it runs.
+type PipelineRunPlan struct {
+	RunID int64               `json:"run_id"`
+	Tasks []data.PipelineTask `json:"tasks"`
+}
+
+// StartPipelineRunActivity records a run of a pipeline, with every task pending, and returns
+// the pipeline's tasks as they are now, so later edits don't change a run in progress.
+func (a *ActivitiesImpl) StartPipelineRunActivity(ctx context.Context, pipelineID int64) (*PipelineRunPlan, error) {
+	pipeline, err := a.svc.GetPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pipeline %d: %w", pipelineID, err)
+	}
+	statuses := make(map[int64]string, len(pipeline.Tasks))
+	for _, task := range pipeline.Tasks {
+		statuses[task.TaskID] = dag.StatusPending
+	}
+	encoded, err := json.Marshal(statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task statuses: %w", err)
+	}
+
+	run := &data.PipelineRun{
+		PipelineID:         pipelineID,
+		Status:             "running",
+		TaskStatuses:       string(encoded),
+		TemporalWorkflowID: activity.GetInfo(ctx).WorkflowExecution.ID,
+		StartTime:          time.Now(),
+	}
+	if _, err := a.svc.CreatePipelineRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create run of pipeline %d: %w", pipelineID, err)
+	}
+	return &PipelineRunPlan{RunID: run.ID, Tasks: pipeline.Tasks}, nil
+}
+
+// UpdatePipelineRunTasksActivity stores the task statuses of a pipeline run in progress.
+func (a *ActivitiesImpl) UpdatePipelineRunTasksActivity(ctx context.Context, runID int64, statuses map[int64]string) error {
+	run, err := a.svc.GetPipelineRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pipeline run %d: %w", runID, err)
+	}
+	encoded, err := json.Marshal(statuses)
+	if err != nil {
+		return fmt.Errorf("failed to encode task statuses: %w", err)
+	}
+	run.TaskStatuses = string(encoded)
+	if err := a.svc.UpdatePipelineRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to store task statuses of pipeline run %d: %w", runID, err)
+	}
+	return nil
+}
+
+// CompletePipelineRunActivity stores the outcome of a pipeline run and records a pipeline event.
+func (a *ActivitiesImpl) CompletePipelineRunActivity(ctx context.Context, runID int64, statuses map[int64]string, errorMessage string) error {
+	run, err := a.svc.GetPipelineRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pipeline run %d: %w", runID, err)
+	}
+	encoded, err := json.Marshal(statuses)
+	if err != nil {
+		return fmt.Errorf("failed to encode task statuses: %w", err)
+	}
+	now := time.Now()
+	run.Status, run.TaskStatuses, run.ErrorDetails, run.EndTime = "completed", string(encoded), errorMessage, &now
+	if errorMessage != "" {
+		run.Status = "failed"
+	}
+	if err := a.svc.UpdatePipelineRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to store pipeline run %d: %w", runID, err)
+	}
+
+	counts := make(map[string]int)
+	for _, status := range statuses {
+		counts[status]++
+	}
+	_, err = a.svc.RecordEvent(ctx, &data.Event{
+		Type: EventTypePipeline,
+		Message: fmt.Sprintf("Run %d of pipeline %d %s: %d succeeded, %d failed, %d skipped",
+			runID, run.PipelineID, run.Status, counts[dag.StatusSucceeded], counts[dag.StatusFailed], counts[dag.StatusSkipped]),
+		Details: fmt.Sprintf(`{"pipeline_id": %d, "pipeline_run_id": %d}`, run.PipelineID, runID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record pipeline event for pipeline run %d: %w", runID, err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package temporal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
+	"github.com/eleon00/hsoetlnlm/internal/dag"
+	"github.com/robfig/cron"
+	"go.temporal.io/sdk/workflow"
+)
+
+// PipelineWorkflow runs a pipeline: each task is a child ReplicationWorkflow, started once its
+// dependencies have finished in a way its trigger rule accepts, and skipped once they can no
+// longer do so. Independent tasks run in parallel. The task statuses are stored on the
+// pipeline run as they change, and the outcome also when the workflow fails or is cancelled.
+func PipelineWorkflow(ctx workflow.Context, pipelineID int64) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting pipeline workflow", "pipelineID", pipelineID)
+	ctx = workflow.WithActivityOptions(ctx, replicationActivityOptions())
+
+	var plan *PipelineRunPlan
+	if err := workflow.ExecuteActivity(ctx, "StartPipelineRunActivity", pipelineID).Get(ctx, &plan); err != nil {
+		return fmt.Errorf("failed to start pipeline run: %w", err)
+	}
+
+	statuses := make(map[int64]string, len(plan.Tasks))
+	for _, task := range plan.Tasks {
+		statuses[task.TaskID] = dag.StatusPending
+	}
+	err := runPipeline(ctx, plan, statuses, &criteria.Trigger{LogicalTime: scheduledTime(ctx)})
+	errorMessage := ""
+	if err != nil {
+		errorMessage = err.Error()
+	}
+
+	// Use a disconnected context so the outcome is recorded even if the workflow was cancelled
+	dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+	err2 := workflow.ExecuteActivity(dcCtx, "CompletePipelineRunActivity", plan.RunID, statuses, errorMessage).Get(dcCtx, nil)
+	if err2 != nil {
+		logger.Error("Failed to store pipeline run outcome", "error", err2, "runID", plan.RunID)
+	}
+	return err
+}
+
+// scheduledTime is the cron time of a scheduled run, or nil for a run started on demand.
+// Temporal creates each run of a cron workflow when the previous one closes and holds back its
+// first workflow task until the schedule next fires, so the cron time is the schedule's next
+// time after the execution's start. It does not move when the first workflow task is delayed.
+func scheduledTime(ctx workflow.Context) *time.Time {
+	info := workflow.GetInfo(ctx)
+	if info.CronSchedule == "" {
+		return nil
+	}
+	schedule, err := cron.ParseStandard(info.CronSchedule)
+	if err != nil {
+		// Schedules are validated when they are saved; Temporal's own syntax extensions are not
+		workflow.GetLogger(ctx).Warn("Cannot parse cron schedule; using the start time", "schedule", info.CronSchedule, "error", err)
+		start := info.WorkflowStartTime
+		return &start
+	}
+	scheduled := schedule.Next(info.WorkflowStartTime.UTC())
+	return &scheduled
+}
+
+// runPipeline walks the pipeline's DAG, updating statuses as tasks start and finish. Each task
+// run is started with trigger, which carries the pipeline's logical time.
+func runPipeline(ctx workflow.Context, plan *PipelineRunPlan, statuses map[int64]string, trigger *criteria.Trigger) error {
+	logger := workflow.GetLogger(ctx)
+	selector := workflow.NewSelector(ctx)
+	running := 0
+	for {
+		// Starting or skipping a task can settle the tasks that depend on it, so repeat until
+		// nothing changes. Tasks are visited in their listed order to keep the workflow
+		// deterministic.
+		for changed := true; changed; {
+			changed = false
+			for _, task := range plan.Tasks {
+				if statuses[task.TaskID] != dag.StatusPending {
+					continue
+				}
+				switch dag.Decide(task, statuses) {
+				case dag.Run:
+					startPipelineTask(ctx, selector, task.TaskID, trigger, statuses)
+					running++
+				case dag.Skip:
+					logger.Info("Skipping pipeline task", "taskID", task.TaskID, "trigger", task.Trigger)
+					statuses[task.TaskID] = dag.StatusSkipped
+				default:
+					continue
+				}
+				changed = true
+			}
+		}
+
+		// The activity's failures only delay the progress shown for the run
+		if err := workflow.ExecuteActivity(ctx, "UpdatePipelineRunTasksActivity", plan.RunID, statuses).Get(ctx, nil); err != nil {
+			logger.Error("Failed to store pipeline task statuses", "error", err, "runID", plan.RunID)
+		}
+		if running == 0 {
+			break
+		}
+		selector.Select(ctx) // Wait for a task to finish
+		running--
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var failed []string
+	for _, task := range plan.Tasks {
+		if statuses[task.TaskID] == dag.StatusFailed {
+			failed = append(failed, fmt.Sprint(task.TaskID))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d tasks failed: %s", len(failed), len(plan.Tasks), strings.Join(failed, ", "))
+	}
+	logger.Info("Pipeline run completed", "runID", plan.RunID, "tasks", len(plan.Tasks))
+	return nil
+}
+
+// startPipelineTask starts a task's ReplicationWorkflow as a child. It uses the task's own
+// workflow ID, so a task already running on its own schedule fails in the pipeline instead
+// of running twice.
+func startPipelineTask(ctx workflow.Context, selector workflow.Selector, taskID int64, trigger *criteria.Trigger, statuses map[int64]string) {
+	logger := workflow.GetLogger(ctx)
+	statuses[taskID] = dag.StatusRunning
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:         fmt.Sprintf("replication-task-%d", taskID),
+		WorkflowRunTimeout: time.Hour * 24,
+	})
+	future := workflow.ExecuteChildWorkflow(childCtx, ReplicationWorkflow, taskID, trigger)
+	selector.AddFuture(future, func(f workflow.Future) {
+		if err := f.Get(ctx, nil); err != nil {
+			logger.Error("Pipeline task failed", "taskID", taskID, "error", err)
+			statuses[taskID] = dag.StatusFailed
+			return
+		}
+		statuses[taskID] = dag.StatusSucceeded
+	})
+}
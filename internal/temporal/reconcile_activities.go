@@ -0,0 +1,12 @@
+package temporal
+
+import (
+	"context"
+
+	"github.com/eleon00/hsoetlnlm/internal/service"
+)
+
+// ReconcileRunsActivity reconciles the unfinished runs with their workflow executions.
+func (a *ActivitiesImpl) ReconcileRunsActivity(ctx context.Context) (*service.RunReconciliation, error) {
+	return a.svc.ReconcileReplicationRuns(ctx)
+}
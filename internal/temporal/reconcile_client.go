@@ -0,0 +1,105 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+
+	"github.com/eleon00/hsoetlnlm/internal/service"
+)
+
+// DescribeWorkflowExecution returns the state of a workflow execution (the latest one of the
+// workflow for an empty runID), or nil if Temporal has no record of it.
+func (c *Client) DescribeWorkflowExecution(ctx context.Context, workflowID string, runID string) (*service.WorkflowExecution, error) {
+	resp, err := c.tc.DescribeWorkflowExecution(ctx, workflowID, runID)
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe workflow %s: %w", workflowID, err)
+	}
+	return workflowExecution(resp.GetWorkflowExecutionInfo()), nil
+}
+
+// ListOpenReplicationExecutions lists the running ReplicationWorkflow and
+// ResumeReplicationWorkflow executions. It needs a visibility store that supports list queries.
+func (c *Client) ListOpenReplicationExecutions(ctx context.Context) ([]*service.WorkflowExecution, error) {
+	request := &workflowservice.ListWorkflowExecutionsRequest{
+		Query: "WorkflowType IN ('ReplicationWorkflow', 'ResumeReplicationWorkflow') AND ExecutionStatus = 'Running'",
+	}
+	executions := make([]*service.WorkflowExecution, 0)
+	for {
+		resp, err := c.tc.ListWorkflow(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list running replication workflows: %w", err)
+		}
+		for _, info := range resp.GetExecutions() {
+			executions = append(executions, workflowExecution(info))
+		}
+		if len(resp.GetNextPageToken()) == 0 {
+			return executions, nil
+		}
+		request.NextPageToken = resp.GetNextPageToken()
+	}
+}
+
+// executionStatuses maps Temporal's execution statuses to the WorkflowClient's.
+var executionStatuses = map[enums.WorkflowExecutionStatus]string{
+	enums.WORKFLOW_EXECUTION_STATUS_RUNNING:          service.ExecutionStatusRunning,
+	enums.WORKFLOW_EXECUTION_STATUS_COMPLETED:        service.ExecutionStatusCompleted,
+	enums.WORKFLOW_EXECUTION_STATUS_FAILED:           service.ExecutionStatusFailed,
+	enums.WORKFLOW_EXECUTION_STATUS_CANCELED:         service.ExecutionStatusCancelled,
+	enums.WORKFLOW_EXECUTION_STATUS_TERMINATED:       service.ExecutionStatusTerminated,
+	enums.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW: service.ExecutionStatusContinuedAsNew,
+	enums.WORKFLOW_EXECUTION_STATUS_TIMED_OUT:        service.ExecutionStatusTimedOut,
+}
+
+func workflowExecution(info *workflowpb.WorkflowExecutionInfo) *service.WorkflowExecution {
+	execution := &service.WorkflowExecution{
+		WorkflowID: info.GetExecution().GetWorkflowId(),
+		RunID:      info.GetExecution().GetRunId(),
+		Status:     executionStatuses[info.GetStatus()],
+		StartTime:  info.GetStartTime().AsTime(),
+	}
+	if execution.Status == "" {
+		execution.Status = strings.ToLower(strings.TrimPrefix(info.GetStatus().String(), "WORKFLOW_EXECUTION_STATUS_"))
+	}
+	if info.GetCloseTime() != nil {
+		closeTime := info.GetCloseTime().AsTime()
+		execution.CloseTime = &closeTime
+	}
+	return execution
+}
+
+// ScheduleRunReconciler replaces the cron workflow that reconciles unfinished runs with
+// Temporal. An empty schedule only removes the workflow.
+func (c *Client) ScheduleRunReconciler(ctx context.Context, schedule string) error {
+	err := c.tc.TerminateWorkflow(ctx, RunReconcilerWorkflowID, "", "reconciler schedule changed")
+	var notFound *serviceerror.NotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to stop run reconciler: %w", err)
+	}
+	if schedule == "" {
+		return nil
+	}
+
+	options := client.StartWorkflowOptions{
+		ID:                 RunReconcilerWorkflowID,
+		TaskQueue:          "replication-tasks",
+		CronSchedule:       schedule,
+		WorkflowRunTimeout: time.Hour, // Per scheduled run
+	}
+	if _, err := c.ExecuteWorkflow(ctx, options, ReconcileRunsWorkflow); err != nil {
+		return fmt.Errorf("failed to schedule run reconciler: %w", err)
+	}
+	return nil
+}
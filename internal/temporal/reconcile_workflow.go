@@ -0,0 +1,32 @@
+package temporal
+
+import (
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/eleon00/hsoetlnlm/internal/service"
+)
+
+// RunReconcilerWorkflowID is the workflow ID of the scheduled ReconcileRunsWorkflow.
+const RunReconcilerWorkflowID = "replication-run-reconciler"
+
+// ReconcileRunsWorkflow ends the runs whose workflows stopped without recording their outcome
+// and reports task workflows running without a run. It runs on a cron schedule.
+func ReconcileRunsWorkflow(ctx workflow.Context) (*service.RunReconciliation, error) {
+	logger := workflow.GetLogger(ctx)
+	ctx = workflow.WithActivityOptions(ctx, replicationActivityOptions())
+
+	var report *service.RunReconciliation
+	if err := workflow.ExecuteActivity(ctx, "ReconcileRunsActivity").Get(ctx, &report); err != nil {
+		return nil, err
+	}
+	for _, run := range report.ReconciledRuns {
+		logger.Warn("Ended orphaned run", "runID", run.RunID, "taskID", run.TaskID, "status", run.Status, "reason", run.Reason)
+	}
+	for _, execution := range report.OrphanedExecutions {
+		logger.Warn("Task workflow has no run", "workflowID", execution.WorkflowID, "runID", execution.RunID)
+	}
+	for _, skipped := range report.SkippedRuns {
+		logger.Warn("Could not check run", "runID", skipped.RunID, "workflowID", skipped.WorkflowID, "error", skipped.Error)
+	}
+	return report, nil
+}
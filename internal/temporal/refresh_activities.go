@@ -0,0 +1,163 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// EventTypeRefresh is the event recorded when a refresh of mismatched ranges finishes.
+const EventTypeRefresh = "refresh"
+
+// RefreshPlan lists the tables and key ranges a refresh rewrites.
+type RefreshPlan struct {
+	TaskID int64                  `json:"task_id"`
+	Tables []compare.RefreshTable `json:"tables"`
+}
+
+// loadRefresh loads a refresh with its options, task and connections.
+func (a *ActivitiesImpl) loadRefresh(ctx context.Context, refreshID int64) (*data.Refresh, *compare.RefreshOptions, *data.ReplicationTask, *data.Connection, *data.Connection, error) {
+	refresh, err := a.svc.GetRefresh(ctx, refreshID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to fetch refresh %d: %w", refreshID, err)
+	}
+	opts, err := compare.ParseRefreshOptions(refresh.Options)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	task, err := a.svc.GetReplicationTask(ctx, refresh.ReplicationTaskID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to fetch task %d for refresh: %w", refresh.ReplicationTaskID, err)
+	}
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, task.ID, err)
+	}
+	targetConn, err := a.svc.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("failed to fetch target connection %d for task %d: %w", task.TargetConnectionID, task.ID, err)
+	}
+	return refresh, opts, task, sourceConn, targetConn, nil
+}
+
+// PlanRefreshActivity picks the mismatched ranges of the refresh's compare report and stores
+// the number of ranges and the skipped tables on the refresh.
+func (a *ActivitiesImpl) PlanRefreshActivity(ctx context.Context, refreshID int64) (*RefreshPlan, error) {
+	refresh, opts, task, sourceConn, targetConn, err := a.loadRefresh(ctx, refreshID)
+	if err != nil {
+		return nil, err
+	}
+	report, err := a.svc.GetCompareReport(ctx, refresh.CompareReportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch compare report %d: %w", refresh.CompareReportID, err)
+	}
+	var results []compare.TableResult
+	if err := json.Unmarshal([]byte(report.Result), &results); err != nil {
+		return nil, fmt.Errorf("invalid result in compare report %d: %w", report.ID, err)
+	}
+	tables, err := compareTables(ctx, task, sourceConn, targetConn)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RefreshPlan{TaskID: task.ID}
+	var skipped []string
+	plan.Tables, skipped = compare.PlanRefresh(results, tables, opts)
+	refresh.TotalRanges = 0
+	for _, table := range plan.Tables {
+		refresh.TotalRanges += len(table.Ranges)
+	}
+	refresh.SkippedTables = strings.Join(skipped, ",")
+	if err := a.svc.UpdateRefresh(ctx, refresh); err != nil {
+		return nil, fmt.Errorf("failed to store plan of refresh %d: %w", refreshID, err)
+	}
+	if len(plan.Tables) == 0 {
+		// Retrying cannot help
+		return nil, temporal.NewNonRetryableApplicationError("nothing to refresh: every mismatched table was compared as one range; set full_tables to rewrite whole tables", "NothingToRefresh", nil)
+	}
+	return plan, nil
+}
+
+// RefreshTableActivity rewrites a table's ranges one by one, adding each range to the
+// refresh's progress and heartbeating so a retried activity skips finished ranges. It stops
+// before the next range once the refreshed task is paused, and returns how many ranges it
+// rewrote.
+func (a *ActivitiesImpl) RefreshTableActivity(ctx context.Context, refreshID int64, table compare.RefreshTable) (int, error) {
+	_, opts, task, sourceConn, targetConn, err := a.loadRefresh(ctx, refreshID)
+	if err != nil {
+		return 0, err
+	}
+	session, err := compare.Open(ctx, sourceConn, targetConn, table.Table, &compare.Options{KeyColumns: table.KeyColumns})
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", table.Table.Name, err)
+	}
+	defer session.Close()
+
+	next := 0
+	if activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &next); err != nil {
+			next = 0
+		}
+	}
+	for ; next < len(table.Ranges); next++ {
+		paused, err := a.taskPaused(ctx, task.ID)
+		if err != nil {
+			return next, err
+		}
+		if paused {
+			return next, nil
+		}
+		result, err := session.RefreshRange(ctx, table.Ranges[next], opts.Mode, opts.BatchSize)
+		if err != nil {
+			return next, fmt.Errorf("failed to refresh %s: %w", describeRange(table.Ranges[next]), err)
+		}
+		if err := a.svc.AddRefreshProgress(ctx, refreshID, 1, result.RowsDeleted, result.RowsWritten); err != nil {
+			// Non-fatal: the range is written, only the counters lag
+			fmt.Printf("Warning: %v\n", err)
+		}
+		activity.RecordHeartbeat(ctx, next+1)
+	}
+	return next, nil
+}
+
+func describeRange(keyRange *compare.KeyRange) string {
+	if keyRange == nil {
+		return "the whole table"
+	}
+	return fmt.Sprintf("keys %d..%d", keyRange.Low, keyRange.High)
+}
+
+// CompleteRefreshActivity stores the outcome of a refresh and records it as an event.
+func (a *ActivitiesImpl) CompleteRefreshActivity(ctx context.Context, refreshID int64, errorMessage string) error {
+	refresh, err := a.svc.GetRefresh(ctx, refreshID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch refresh %d: %w", refreshID, err)
+	}
+	now := time.Now()
+	refresh.Status, refresh.ErrorDetails, refresh.EndTime = "completed", errorMessage, &now
+	if errorMessage != "" {
+		refresh.Status = "failed"
+	}
+	if err := a.svc.UpdateRefresh(ctx, refresh); err != nil {
+		return fmt.Errorf("failed to store refresh %d: %w", refreshID, err)
+	}
+
+	_, err = a.svc.RecordEvent(ctx, &data.Event{
+		ReplicationTaskID: &refresh.ReplicationTaskID,
+		Type:              EventTypeRefresh,
+		Message: fmt.Sprintf("Refresh %d of compare report %d %s: %d of %d ranges, %d rows deleted, %d rows written",
+			refreshID, refresh.CompareReportID, refresh.Status, refresh.CompletedRanges, refresh.TotalRanges, refresh.RowsDeleted, refresh.RowsWritten),
+		Details: fmt.Sprintf(`{"refresh_id": %d, "compare_report_id": %d}`, refreshID, refresh.CompareReportID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record refresh event for refresh %d: %w", refreshID, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package temporal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RefreshWorkflow resynchronizes the mismatched key ranges of a compare report: each range is
+// re-read from the source and written to the target, one table at a time. Progress is stored
+// on the refresh after every range, and the outcome also when the workflow fails or is
+// cancelled.
+func RefreshWorkflow(ctx workflow.Context, refreshID int64) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting refresh workflow", "refreshID", refreshID)
+	ctx = workflow.WithActivityOptions(ctx, replicationActivityOptions())
+
+	err := runRefresh(ctx, refreshID)
+	errorMessage := ""
+	if err != nil {
+		errorMessage = err.Error()
+	}
+
+	// Use a disconnected context so the outcome is recorded even if the workflow was cancelled
+	dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+	err2 := workflow.ExecuteActivity(dcCtx, "CompleteRefreshActivity", refreshID, errorMessage).Get(dcCtx, nil)
+	if err2 != nil {
+		logger.Error("Failed to store refresh outcome", "error", err2, "refreshID", refreshID)
+	}
+	return err
+}
+
+func runRefresh(ctx workflow.Context, refreshID int64) error {
+	logger := workflow.GetLogger(ctx)
+
+	var plan *RefreshPlan
+	if err := workflow.ExecuteActivity(ctx, "PlanRefreshActivity", refreshID).Get(ctx, &plan); err != nil {
+		return err
+	}
+	release, err := holdLeases(ctx, plan.TaskID, data.LeaseHolder{Type: data.LeaseHolderRefresh, ID: refreshID})
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	// Tables are refreshed one at a time to limit the write load on the target
+	refreshCtx := workflow.WithActivityOptions(ctx, compareActivityOptions())
+	var failed []string
+	for _, table := range plan.Tables {
+		if err := refreshTable(ctx, refreshCtx, refreshID, plan.TaskID, table); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.Error("Table refresh failed", "table", table.Table.Name, "error", err)
+			failed = append(failed, fmt.Sprintf("%s: %v", table.Table.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d tables failed to refresh: %s", len(failed), len(plan.Tables), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// refreshTable rewrites a table's ranges. The activity stops early when the task is paused;
+// the remaining ranges are then started again once the task is resumed.
+func refreshTable(ctx, refreshCtx workflow.Context, refreshID, taskID int64, table compare.RefreshTable) error {
+	for len(table.Ranges) > 0 {
+		if err := awaitTaskActive(ctx, taskID); err != nil {
+			return err
+		}
+		var done int
+		if err := workflow.ExecuteActivity(refreshCtx, "RefreshTableActivity", refreshID, table).Get(ctx, &done); err != nil {
+			return err
+		}
+		table.Ranges = table.Ranges[done:]
+	}
+	return nil
+}
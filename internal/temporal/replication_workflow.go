@@ -4,29 +4,45 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
 	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"github.com/eleon00/hsoetlnlm/internal/stream"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
 // ReplicationWorkflow implements data replication using Temporal
-// It orchestrates the entire process from loading task config to running Benthos
-func ReplicationWorkflow(ctx workflow.Context, taskID int64) error {
+// It orchestrates the entire process from loading task config to running Benthos. The trigger
+// (nil for none) holds the parameters and logical time the task's criteria are rendered with.
+// Streaming tasks hand over to StreamingReplicationWorkflow instead.
+func ReplicationWorkflow(ctx workflow.Context, taskID int64, trigger *criteria.Trigger) error {
+	var task *data.ReplicationTask
+	taskCtx := workflow.WithActivityOptions(ctx, replicationActivityOptions())
+	if err := workflow.ExecuteActivity(taskCtx, "LoadReplicationTask", taskID).Get(taskCtx, &task); err != nil {
+		return err
+	}
+	if stream.Enabled(task.StreamConfig) {
+		return workflow.NewContinueAsNewError(ctx, StreamingReplicationWorkflow, StreamParams{TaskID: taskID})
+	}
+	return replicate(ctx, taskID, 0, trigger)
+}
+
+// ResumeReplicationWorkflow continues a failed run of a task from its checkpoints. Tables and
+// chunks the run completed are skipped; the rest run again under the same run.
+func ResumeReplicationWorkflow(ctx workflow.Context, taskID int64, runID int64) error {
+	return replicate(ctx, taskID, runID, nil)
+}
+
+// replicate runs a task, recording a new run started with trigger, or continuing resumeRunID
+// when it is set.
+func replicate(ctx workflow.Context, taskID int64, resumeRunID int64, trigger *criteria.Trigger) error {
 	logger := workflow.GetLogger(ctx)
-	logger.Info("Starting replication workflow", "taskID", taskID)
+	logger.Info("Starting replication workflow", "taskID", taskID, "resumeRunID", resumeRunID)
 
 	// Workflow options
-	retryPolicy := &temporal.RetryPolicy{
-		InitialInterval:    time.Second,
-		BackoffCoefficient: 2.0,
-		MaximumInterval:    time.Minute * 5,
-		MaximumAttempts:    3,
-	}
-	options := workflow.ActivityOptions{
-		StartToCloseTimeout: time.Minute * 5,
-		RetryPolicy:         retryPolicy,
-	}
-	ctx = workflow.WithActivityOptions(ctx, options)
+	ctx = workflow.WithActivityOptions(ctx, replicationActivityOptions())
+	control := newRunControl(ctx, taskID)
 
 	// Initialize workflow parameters
 	params := WorkflowParams{
@@ -37,13 +53,19 @@ func ReplicationWorkflow(ctx workflow.Context, taskID int64) error {
 
 	// Defer cleanup/status update in case of workflow errors/cancellation
 	defer func() {
-		if ctx.Err() != nil || params.State != ReplicationWorkflowStateCompleted {
-			if params.State != ReplicationWorkflowStateFailed {
+		finished := params.State == ReplicationWorkflowStateCompleted || params.State == ReplicationWorkflowStateQuarantined ||
+			params.State == ReplicationWorkflowStateSkipped
+		if ctx.Err() != nil || !finished {
+			now := workflow.Now(ctx)
+			switch {
+			case ctx.Err() != nil:
+				// Cancelled, by cancelling the run or stopping the task
+				params.State, params.ErrorMessage, params.EndTime = ReplicationWorkflowStateCancelled, "Run cancelled", &now
+			case params.State != ReplicationWorkflowStateFailed:
 				params.State = ReplicationWorkflowStateFailed
 				if params.ErrorMessage == "" {
-					params.ErrorMessage = fmt.Sprintf("Workflow failed or cancelled: %v", ctx.Err())
+					params.ErrorMessage = "Workflow failed"
 				}
-				now := workflow.Now(ctx)
 				params.EndTime = &now
 			}
 			// Use a disconnected context for the final status update to ensure it runs
@@ -60,45 +82,97 @@ func ReplicationWorkflow(ctx workflow.Context, taskID int64) error {
 		}
 	}()
 
-	// Step 1: Create a replication run record in the database
-	var run *data.ReplicationRun
-	err := workflow.ExecuteActivity(ctx, "CreateReplicationRun", taskID).Get(ctx, &run)
+	// Step 1: Create a replication run record in the database, or reopen the resumed run
+	var err error
+	if resumeRunID != 0 {
+		params.ReplicationRunID = resumeRunID
+		err = workflow.ExecuteActivity(ctx, "ReopenReplicationRun", resumeRunID).Get(ctx, nil)
+	} else {
+		// A cron run covers its scheduled fire time, not the moment the activity happens to run
+		if trigger == nil || trigger.LogicalTime == nil {
+			if logical := scheduledTime(ctx); logical != nil {
+				scheduled := criteria.Trigger{LogicalTime: logical}
+				if trigger != nil {
+					scheduled.Params = trigger.Params
+				}
+				trigger = &scheduled
+			}
+		}
+		var run *data.ReplicationRun
+		if err = workflow.ExecuteActivity(ctx, "CreateReplicationRun", taskID, trigger).Get(ctx, &run); err == nil {
+			params.ReplicationRunID = run.ID
+		}
+	}
 	if err != nil {
 		params.ErrorMessage = fmt.Sprintf("Failed to create replication run: %v", err)
 		return err // Error handled by defer
 	}
-	params.ReplicationRunID = run.ID
 	params.State = ReplicationWorkflowStateLoading // Run created, now loading task
 
-	// Step 2: Execute Benthos Pipeline Activity
-	// This activity now handles loading task, connections, generating config, and running benthos.
-	// Use a longer timeout for the Benthos execution itself.
-	benthosActivityOpts := workflow.ActivityOptions{
-		StartToCloseTimeout: time.Hour * 1,   // Example: Allow 1 hour for the pipeline run
-		HeartbeatTimeout:    time.Minute * 2, // Send heartbeats during long runs
-		RetryPolicy:         retryPolicy,     // Reuse the defined retry policy
+	// A paused task's run waits to be resumed; the task's skip-next flag skips it
+	skip, err := startRun(ctx, control, &params)
+	if err != nil {
+		params.ErrorMessage = fmt.Sprintf("Failed to start run: %v", err)
+		return err // Error handled by defer
+	}
+	if skip {
+		logger.Info("Run skipped by the task's skip-next flag", "taskID", taskID)
+		params.State = ReplicationWorkflowStateSkipped
+		// The activity logs and swallows its own failures
+		_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus",
+			params.ReplicationRunID, string(params.State), "Skipped by skip-next").Get(ctx, nil)
+		return nil
 	}
-	benthosCtx := workflow.WithActivityOptions(ctx, benthosActivityOpts)
 
-	var benthosOutput string
-	err = workflow.ExecuteActivity(benthosCtx, "ExecuteBenthosPipelineActivity", taskID, params.ReplicationRunID).Get(benthosCtx, &benthosOutput)
-	if err != nil {
-		// Error occurred during Benthos execution
-		params.ErrorMessage = fmt.Sprintf("Benthos pipeline execution failed: %v", err)
-		// Benthos output might contain useful error info
-		logger.Error("Benthos execution failed", "error", err, "output", benthosOutput)
+	// The task's execution policy applies to every activity of the run from here on
+	if err = workflow.ExecuteActivity(ctx, "LoadExecutionPolicyActivity", taskID).Get(ctx, &params.Policy); err != nil {
+		params.ErrorMessage = fmt.Sprintf("Failed to load execution policy: %v", err)
 		return err // Error handled by defer
 	}
+	ctx = workflow.WithActivityOptions(ctx, taskActivityOptions(params.Policy))
 
-	// Benthos pipeline completed successfully (according to the activity)
-	logger.Info("Benthos pipeline executed successfully.", "output_snippet", truncateString(benthosOutput, 200))
+	// Wait for a slot of the concurrency limits of the task and its connections, and free it
+	// however the run ends
+	defer func() {
+		dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+		if err := workflow.ExecuteActivity(dcCtx, "ReleaseRunLeasesActivity", params.ReplicationRunID).Get(dcCtx, nil); err != nil {
+			logger.Error("Failed to release run leases", "error", err, "RunID", params.ReplicationRunID)
+		}
+	}()
+	if err = acquireRunLeases(ctx, &params); err != nil {
+		params.ErrorMessage = fmt.Sprintf("Failed to acquire concurrency leases: %v", err)
+		return err // Error handled by defer
+	}
 
-	// Step 3: Update run status to completed
+	// Step 2: Multi-table tasks fan out to a child workflow per table; others run one pipeline
+	var plan *TablePlan
+	err = workflow.ExecuteActivity(ctx, "ResolveTaskTablesActivity", taskID).Get(ctx, &plan)
+	if err != nil {
+		params.ErrorMessage = fmt.Sprintf("Failed to resolve task tables: %v", err)
+		return err // Error handled by defer
+	}
+	if plan != nil {
+		logger.Info("Replicating tables", "tables", len(plan.Tables), "max_parallel", plan.MaxParallel)
+		params.State = ReplicationWorkflowStateRunning
+		// The activity logs and swallows its own failures
+		_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus",
+			params.ReplicationRunID, string(params.State), "").Get(ctx, nil)
+		if err = replicateTables(ctx, taskID, params.ReplicationRunID, plan, params.Policy, control); err != nil {
+			params.ErrorMessage = err.Error()
+			return err // Error handled by defer
+		}
+	} else if err = executeTaskPipeline(ctx, &params, control); err != nil {
+		return err // Error handled by defer
+	}
+
+	// Step 3: Update run status to completed (or quarantined when drift skipped the load)
 	now := workflow.Now(ctx)
 	params.EndTime = &now
-	params.State = ReplicationWorkflowStateCompleted
+	if params.State != ReplicationWorkflowStateQuarantined {
+		params.State = ReplicationWorkflowStateCompleted
+	}
 	err = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus",
-		params.ReplicationRunID, string(params.State), "").Get(ctx, nil)
+		params.ReplicationRunID, string(params.State), params.ErrorMessage).Get(ctx, nil)
 	if err != nil {
 		logger.Error("Failed to update replication run status to completed", "error", err)
 		// Continue even though update failed, workflow itself succeeded.
@@ -108,6 +182,84 @@ func ReplicationWorkflow(ctx workflow.Context, taskID int64) error {
 	return nil
 }
 
+// executeTaskPipeline runs a single-table task's pipeline and finalizes its source files,
+// recording the error message on params when a step fails. A quarantined load leaves
+// params in the quarantined state and returns nil. A paused run waits before extracting.
+func executeTaskPipeline(ctx workflow.Context, params *WorkflowParams, control *runControl) error {
+	logger := workflow.GetLogger(ctx)
+
+	// Check the source schema against the last run's before touching the target
+	var drift *DriftOutcome
+	err := workflow.ExecuteActivity(ctx, "CheckSchemaDriftActivity", params.TaskID, params.ReplicationRunID, (*schema.TableSelection)(nil)).Get(ctx, &drift)
+	if err != nil {
+		params.ErrorMessage = fmt.Sprintf("Schema drift check failed: %v", err)
+		return err
+	}
+	if drift.Quarantine {
+		logger.Warn("Load quarantined because of schema drift", "drift", drift.Summary)
+		params.State = ReplicationWorkflowStateQuarantined
+		params.ErrorMessage = fmt.Sprintf("Load skipped because of source schema drift (%s)", drift.Summary)
+		return nil
+	}
+
+	// Create the target table first if the target connection asks for it
+	err = workflow.ExecuteActivity(ctx, "CreateTargetTableActivity", params.TaskID, params.ReplicationRunID, (*schema.TableSelection)(nil)).Get(ctx, nil)
+	if err != nil {
+		params.ErrorMessage = fmt.Sprintf("Failed to create target table: %v", err)
+		return err
+	}
+
+	// Partitioned tasks extract in chunks, each retried on its own
+	partitioned, err := executeChunks(ctx, params.TaskID, params.ReplicationRunID, nil, params.Policy, control)
+	if err != nil {
+		params.ErrorMessage = fmt.Sprintf("Chunked extraction failed: %v", err)
+		return err
+	}
+	if partitioned {
+		return nil
+	}
+
+	if err = control.wait(ctx, params.ReplicationRunID, params.State); err != nil {
+		params.ErrorMessage = fmt.Sprintf("Run cancelled while paused: %v", err)
+		return err
+	}
+
+	// This activity handles loading task, connections, generating config, and running benthos.
+	benthosCtx := workflow.WithActivityOptions(ctx, taskPipelineOptions(params.Policy))
+
+	var result PipelineResult
+	err = workflow.ExecuteActivity(benthosCtx, "ExecuteBenthosPipelineActivity", params.TaskID, params.ReplicationRunID).Get(benthosCtx, &result)
+	if err != nil {
+		// Error occurred during Benthos execution
+		params.ErrorMessage = fmt.Sprintf("Benthos pipeline execution failed: %v", err)
+		// Benthos output might contain useful error info
+		logger.Error("Benthos execution failed", "error", err, "output", result.Output)
+		return err
+	}
+
+	// Benthos pipeline completed successfully (according to the activity)
+	logger.Info("Benthos pipeline executed successfully.", "output_snippet", truncateString(result.Output, 200), "target_files", len(result.TargetFiles))
+
+	// Archive or delete consumed source files only once the data has been delivered
+	if len(result.SourceFiles) > 0 {
+		err = workflow.ExecuteActivity(ctx, "FinalizeSourceFilesActivity", params.TaskID, result.SourceFiles, params.ReplicationRunID).Get(ctx, nil)
+		if err != nil {
+			params.ErrorMessage = fmt.Sprintf("Data was delivered but finalizing source files failed: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// replicationActivityOptions are the defaults for the short bookkeeping activities.
+func replicationActivityOptions() workflow.ActivityOptions {
+	return taskActivityOptions(nil)
+}
+
+func replicationRetryPolicy() *temporal.RetryPolicy {
+	return taskRetryPolicy(nil)
+}
+
 // Helper function to truncate strings for logging (can be shared or moved)
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
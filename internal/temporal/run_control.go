@@ -0,0 +1,9 @@
+package temporal
+
+import "context"
+
+// CancelReplicationRun cancels one execution of a task's workflow, leaving later executions of
+// the same workflow ID alone.
+func (c *Client) CancelReplicationRun(ctx context.Context, workflowID string, runID string) error {
+	return c.tc.CancelWorkflow(ctx, workflowID, runID)
+}
@@ -0,0 +1,68 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"go.temporal.io/sdk/temporal"
+)
+
+// renderRunCriteria replaces a single-table task's data selection criteria with their rendering
+// for run. The first rendering is recorded on the run and reused by later attempts and resumes
+// of it, like pinned source files, so a run reads the same data even if the task's watermark
+// moves in between. Without a run the criteria are rendered for the current time, with no
+// parameters.
+func (a *ActivitiesImpl) renderRunCriteria(ctx context.Context, task *data.ReplicationTask, runID int64) error {
+	if task.DataSelectionCriteria == "" || task.TableRules != "" {
+		return nil
+	}
+	sourceType := ""
+	if criteria.IsTemplate(task.DataSelectionCriteria) {
+		sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, task.ID, err)
+		}
+		if sourceConn == nil {
+			return fmt.Errorf("source connection %d not found for task %d", task.SourceConnectionID, task.ID)
+		}
+		sourceType = sourceConn.Type
+	}
+	if runID == 0 {
+		rendered, err := criteria.Render(task.DataSelectionCriteria, sourceType, criteria.NewVars(task.ID, 0, time.Now(), nil, task.Watermark, nil))
+		if err != nil {
+			return temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", task.ID, err), "InvalidCriteriaTemplate", nil)
+		}
+		task.DataSelectionCriteria = rendered
+		return nil
+	}
+
+	run, err := a.svc.GetReplicationRunDetails(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch run %d for task %d: %w", runID, task.ID, err)
+	}
+	if run.RenderedCriteria != "" {
+		task.DataSelectionCriteria = run.RenderedCriteria
+		return nil
+	}
+	var params map[string]string
+	if run.Parameters != "" {
+		if err := json.Unmarshal([]byte(run.Parameters), &params); err != nil {
+			return fmt.Errorf("invalid parameters on run %d: %w", runID, err)
+		}
+	}
+	vars := criteria.NewVars(task.ID, runID, run.StartTime, run.LogicalTime, task.Watermark, params)
+	rendered, err := criteria.Render(task.DataSelectionCriteria, sourceType, vars)
+	if err != nil {
+		// Retrying cannot help
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", task.ID, err), "InvalidCriteriaTemplate", nil)
+	}
+	if err := a.svc.UpdateReplicationRunCriteria(ctx, runID, rendered); err != nil {
+		return fmt.Errorf("failed to record criteria of run %d: %w", runID, err)
+	}
+	task.DataSelectionCriteria = rendered
+	return nil
+}
@@ -0,0 +1,142 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"go.temporal.io/sdk/workflow"
+)
+
+// leaseRetryInterval is how long a queued run waits before trying for its leases again.
+const leaseRetryInterval = time.Second * 30
+
+// AcquireRunLeasesActivity takes a slot of every concurrency limit a run of the task is under:
+// the max_concurrent_runs of its source and target connections and of the task itself. It
+// reports false, taking no slot, if any of them is full.
+func (a *ActivitiesImpl) AcquireRunLeasesActivity(ctx context.Context, taskID int64, runID int64) (bool, error) {
+	return a.acquireLeases(ctx, taskID, data.LeaseHolder{Type: data.LeaseHolderRun, ID: runID}, true)
+}
+
+// ReleaseRunLeasesActivity frees the slots held by a run.
+func (a *ActivitiesImpl) ReleaseRunLeasesActivity(ctx context.Context, runID int64) error {
+	return a.ReleaseLeasesActivity(ctx, data.LeaseHolder{Type: data.LeaseHolderRun, ID: runID})
+}
+
+// AcquireLeasesActivity takes a slot of the limits of the task's connections for a backfill,
+// compare or refresh of the task. A backfill replicates the task, so it also takes a slot of
+// the task's own limit. It reports false, taking no slot, if any of them is full.
+func (a *ActivitiesImpl) AcquireLeasesActivity(ctx context.Context, taskID int64, holder data.LeaseHolder) (bool, error) {
+	return a.acquireLeases(ctx, taskID, holder, holder.Type == data.LeaseHolderBackfill)
+}
+
+// ReleaseLeasesActivity frees the slots held by a run, backfill, compare or refresh.
+func (a *ActivitiesImpl) ReleaseLeasesActivity(ctx context.Context, holder data.LeaseHolder) error {
+	if err := a.svc.ReleaseRunLeases(ctx, holder); err != nil {
+		return fmt.Errorf("failed to release leases of %s %d: %w", holder.Type, holder.ID, err)
+	}
+	return nil
+}
+
+// acquireLeases takes a slot for holder of the limits of the task's connections, and of the
+// task itself if withTask is set.
+func (a *ActivitiesImpl) acquireLeases(ctx context.Context, taskID int64, holder data.LeaseHolder, withTask bool) (bool, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch task %d: %w", taskID, err)
+	}
+	if task == nil {
+		return false, fmt.Errorf("task %d not found", taskID)
+	}
+
+	var limits []data.LeaseLimit
+	if withTask && task.MaxConcurrentRuns > 0 {
+		limits = append(limits, data.LeaseLimit{ResourceType: data.LeaseResourceTask, ResourceID: task.ID, Max: task.MaxConcurrentRuns})
+	}
+	connectionIDs := []int64{task.SourceConnectionID}
+	if task.TargetConnectionID != task.SourceConnectionID {
+		connectionIDs = append(connectionIDs, task.TargetConnectionID)
+	}
+	for _, connID := range connectionIDs {
+		conn, err := a.svc.GetConnection(ctx, connID)
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch connection %d for task %d: %w", connID, taskID, err)
+		}
+		if conn == nil {
+			return false, fmt.Errorf("connection %d not found for task %d", connID, taskID)
+		}
+		if conn.MaxConcurrentRuns > 0 {
+			limits = append(limits, data.LeaseLimit{ResourceType: data.LeaseResourceConnection, ResourceID: conn.ID, Max: conn.MaxConcurrentRuns})
+		}
+	}
+	if len(limits) == 0 {
+		return true, nil
+	}
+
+	acquired, err := a.svc.AcquireRunLeases(ctx, holder, limits)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire leases for %s %d: %w", holder.Type, holder.ID, err)
+	}
+	return acquired, nil
+}
+
+// acquireRunLeases waits until the run of params holds its leases. While it waits the run is
+// queued, trying again every leaseRetryInterval.
+func acquireRunLeases(ctx workflow.Context, params *WorkflowParams) error {
+	logger := workflow.GetLogger(ctx)
+	for {
+		var acquired bool
+		if err := workflow.ExecuteActivity(ctx, "AcquireRunLeasesActivity", params.TaskID, params.ReplicationRunID).Get(ctx, &acquired); err != nil {
+			return err
+		}
+		if acquired {
+			if params.State == ReplicationWorkflowStateQueued {
+				params.State = ReplicationWorkflowStateLoading
+				_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus",
+					params.ReplicationRunID, string(params.State), "").Get(ctx, nil)
+			}
+			return nil
+		}
+		if params.State != ReplicationWorkflowStateQueued {
+			logger.Info("Run queued behind concurrency limits", "taskID", params.TaskID, "runID", params.ReplicationRunID)
+			params.State = ReplicationWorkflowStateQueued
+			// The activity logs and swallows its own failures
+			_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus",
+				params.ReplicationRunID, string(params.State), "").Get(ctx, nil)
+		}
+		if err := workflow.Sleep(ctx, leaseRetryInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// holdLeases waits until holder has its leases on the limits of the task, trying again every
+// leaseRetryInterval. The returned function frees them; it is to be deferred, and frees them
+// however the workflow ends.
+func holdLeases(ctx workflow.Context, taskID int64, holder data.LeaseHolder) (func(), error) {
+	logger := workflow.GetLogger(ctx)
+	release := func() {
+		dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+		if err := workflow.ExecuteActivity(dcCtx, "ReleaseLeasesActivity", holder).Get(dcCtx, nil); err != nil {
+			logger.Error("Failed to release leases", "error", err, "holder", holder.Type, "id", holder.ID)
+		}
+	}
+	waited := false
+	for {
+		var acquired bool
+		if err := workflow.ExecuteActivity(ctx, "AcquireLeasesActivity", taskID, holder).Get(ctx, &acquired); err != nil {
+			return release, err
+		}
+		if acquired {
+			return release, nil
+		}
+		if !waited {
+			logger.Info("Waiting for concurrency limits", "taskID", taskID, "holder", holder.Type, "id", holder.ID)
+			waited = true
+		}
+		if err := workflow.Sleep(ctx, leaseRetryInterval); err != nil {
+			return release, err
+		}
+	}
+}
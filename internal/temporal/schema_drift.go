@@ -0,0 +1,189 @@
+package temporal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"go.temporal.io/sdk/temporal"
+)
+
+// EventTypeSchemaDrift is the event recorded when a run finds its source schema changed.
+const EventTypeSchemaDrift = "schema_drift"
+
+// DriftOutcome tells a workflow how to continue after a schema drift check.
+type DriftOutcome struct {
+	Drifted    bool   `json:"drifted"`
+	Quarantine bool   `json:"quarantine"` // Skip the load; the drift has to be accepted first
+	Summary    string `json:"summary,omitempty"`
+}
+
+// driftRecord is the drift stored on the run and in the event details.
+type driftRecord struct {
+	Table  string `json:"table,omitempty"`
+	Policy string `json:"policy"`
+	*schema.Drift
+}
+
+// CheckSchemaDriftActivity snapshots the source columns of a task (or one table of a
+// multi-table task), diffs them against the previous snapshot and applies the task's drift
+// policy. Sources without a catalog (files, APIs, Kafka) are not checked.
+func (a *ActivitiesImpl) CheckSchemaDriftActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection) (*DriftOutcome, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task %d for schema drift check: %w", taskID, err)
+	}
+	if table == nil && task.TableRules != "" {
+		return &DriftOutcome{}, nil // Checked per table by the table runs
+	}
+	policy, err := schema.ParseDriftPolicy(task.DriftPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("task %d: %w", taskID, err)
+	}
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+	}
+
+	inspector, err := schema.Open(ctx, sourceConn)
+	if errors.Is(err, schema.ErrUnsupportedType) {
+		return &DriftOutcome{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source catalog for task %d: %w", taskID, err)
+	}
+	defer inspector.Close()
+
+	var columns []schema.Column
+	var tableName string
+	if table != nil {
+		tableName = table.SourceName()
+		columns, err = inspector.ListColumns(ctx, table.Schema, table.Name)
+	} else if describer, ok := inspector.(schema.QueryDescriber); ok {
+		if err := a.renderRunCriteria(ctx, task, runID); err != nil {
+			return nil, err
+		}
+		columns, err = describer.DescribeQuery(ctx, task.DataSelectionCriteria)
+	} else {
+		return &DriftOutcome{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source columns for task %d: %w", taskID, err)
+	}
+
+	previous, err := a.svc.GetLatestSchemaSnapshot(ctx, taskID, tableName)
+	if errors.Is(err, sql.ErrNoRows) {
+		// First run (or the drift was accepted): this run's columns become the baseline
+		return &DriftOutcome{}, a.recordSchemaSnapshot(ctx, taskID, runID, tableName, columns)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema snapshot for task %d: %w", taskID, err)
+	}
+	var previousColumns []schema.Column
+	if err := json.Unmarshal([]byte(previous.Columns), &previousColumns); err != nil {
+		return nil, fmt.Errorf("invalid schema snapshot %d: %w", previous.ID, err)
+	}
+
+	drift := schema.Diff(previousColumns, columns)
+	if drift.Empty() {
+		return &DriftOutcome{}, nil
+	}
+	outcome := &DriftOutcome{Drifted: true, Summary: drift.String()}
+	if err := a.recordSchemaDrift(ctx, task, runID, driftRecord{Table: tableName, Policy: policy, Drift: drift}); err != nil {
+		// Non-fatal: the policy still applies
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	switch policy {
+	case schema.DriftPolicyFail:
+		// Retrying cannot help; the baseline is kept so later runs fail too until the drift is accepted
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("source schema drift detected (%s)", outcome.Summary), "SchemaDrift", nil)
+	case schema.DriftPolicyQuarantine:
+		outcome.Quarantine = true
+		return outcome, nil
+	case schema.DriftPolicyAddColumns:
+		if err := a.addTargetColumns(ctx, task, sourceConn, inspector, table, drift.Added); err != nil {
+			return nil, fmt.Errorf("failed to add new columns to the target of task %d: %w", taskID, err)
+		}
+	}
+	return outcome, a.recordSchemaSnapshot(ctx, taskID, runID, tableName, columns)
+}
+
+func (a *ActivitiesImpl) recordSchemaSnapshot(ctx context.Context, taskID int64, runID int64, tableName string, columns []schema.Column) error {
+	columnsJSON, err := json.Marshal(columns)
+	if err != nil {
+		return fmt.Errorf("failed to encode source columns: %w", err)
+	}
+	_, err = a.svc.RecordSchemaSnapshot(ctx, &data.SchemaSnapshot{
+		ReplicationTaskID: taskID,
+		ReplicationRunID:  &runID,
+		TableName:         tableName,
+		Columns:           string(columnsJSON),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record schema snapshot for task %d: %w", taskID, err)
+	}
+	return nil
+}
+
+// recordSchemaDrift stores the drift on the run and in the events feed.
+func (a *ActivitiesImpl) recordSchemaDrift(ctx context.Context, task *data.ReplicationTask, runID int64, record driftRecord) error {
+	details, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema drift: %w", err)
+	}
+	if err := a.svc.UpdateReplicationRunSchemaDrift(ctx, runID, string(details)); err != nil {
+		return fmt.Errorf("failed to record schema drift on run %d: %w", runID, err)
+	}
+
+	source := "source"
+	if record.Table != "" {
+		source = record.Table
+	}
+	_, err = a.svc.RecordEvent(ctx, &data.Event{
+		ReplicationTaskID: &task.ID,
+		ReplicationRunID:  &runID,
+		Type:              EventTypeSchemaDrift,
+		Message:           fmt.Sprintf("Schema drift in %s of task %s (policy %s): %s", source, task.Name, record.Policy, record.Drift.String()),
+		Details:           string(details),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record schema drift event for run %d: %w", runID, err)
+	}
+	return nil
+}
+
+// addTargetColumns adds new source columns to a Snowflake or Postgres target table as
+// nullable columns. Other targets pick up new fields without changes.
+func (a *ActivitiesImpl) addTargetColumns(ctx context.Context, task *data.ReplicationTask, sourceConn *data.Connection, inspector schema.Inspector, table *schema.TableSelection, added []schema.Column) error {
+	if len(added) == 0 {
+		return nil
+	}
+	targetConn, err := a.svc.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return err
+	}
+	if !ddl.Supported(targetConn.Type) {
+		return nil
+	}
+
+	targetTable, err := ddl.Derive(ctx, inspector, sourceConn.Type, task, targetConn, table)
+	if err != nil {
+		return err
+	}
+	var columns []ddl.Column
+	for _, column := range targetTable.Columns {
+		for _, source := range added {
+			if strings.EqualFold(column.Source, source.Name) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return ddl.Exec(ctx, targetConn, ddl.AddColumnStatements(targetTable.Name, columns)...)
+}
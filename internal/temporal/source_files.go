@@ -0,0 +1,107 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/sftpclient"
+
+	. "github.com/eleon00/hsoetlnlm/internal/benthos"
+)
+
+// resolveSourceFiles pins glob-based SFTP sources with a post-run action to the files present
+// when the run starts, so the archive/delete step touches exactly the files that were replicated.
+// It returns nil when the source needs no post-run action.
+func resolveSourceFiles(task *data.ReplicationTask, sourceConn *data.Connection) ([]string, error) {
+	if sourceConn.Type != "sftp" {
+		return nil, nil
+	}
+	params := ParseConnectionString(sourceConn.ConnectionString)
+	action, err := SFTPSourceAction(params)
+	if err != nil || action == SFTPOnSuccessNone {
+		return nil, err
+	}
+
+	client, err := dialSFTP(params)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	files, err := client.Glob(SplitList(task.DataSelectionCriteria))
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// pinSourceFiles returns the source files a run reads: the files recorded by an earlier attempt
+// or chunk of the run, or else the files resolved now, which are then recorded on the run.
+func (a *ActivitiesImpl) pinSourceFiles(ctx context.Context, task *data.ReplicationTask, sourceConn *data.Connection, run *data.ReplicationRun) ([]string, error) {
+	if run.SourceFiles != "" {
+		var files []string
+		if err := json.Unmarshal([]byte(run.SourceFiles), &files); err != nil {
+			return nil, fmt.Errorf("invalid source files on run %d: %w", run.ID, err)
+		}
+		return files, nil
+	}
+	files, err := resolveSourceFiles(task, sourceConn)
+	if err != nil || len(files) == 0 {
+		return files, err
+	}
+	encoded, err := json.Marshal(files)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.svc.UpdateReplicationRunSourceFiles(ctx, run.ID, string(encoded)); err != nil {
+		return nil, fmt.Errorf("failed to record source files of run %d: %w", run.ID, err)
+	}
+	return files, nil
+}
+
+// FinalizeSourceFilesActivity archives or deletes the source files consumed by a successful run.
+// Archived files are kept under a directory named after the run.
+func (a *ActivitiesImpl) FinalizeSourceFilesActivity(ctx context.Context, taskID int64, files []string, runID int64) error {
+	if len(files) == 0 {
+		return nil
+	}
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch task %d for source file finalization: %w", taskID, err)
+	}
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+	}
+
+	params := ParseConnectionString(sourceConn.ConnectionString)
+	action, err := SFTPSourceAction(params)
+	if err != nil {
+		return err
+	}
+	if action == SFTPOnSuccessNone {
+		return nil
+	}
+
+	client, err := dialSFTP(params)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if action == SFTPOnSuccessArchive {
+		return client.Archive(files, params["archive_dir"], fmt.Sprintf("run-%d", runID))
+	}
+	return client.Remove(files)
+}
+
+// dialSFTP opens an SFTP session from parsed connection string parameters.
+func dialSFTP(params map[string]string) (*sftpclient.Client, error) {
+	cfg, err := SFTPClientConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	return sftpclient.Dial(cfg)
+}
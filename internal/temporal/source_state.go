@@ -0,0 +1,44 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+
+	. "github.com/eleon00/hsoetlnlm/internal/benthos"
+)
+
+// prepareSourceState seeds the local state a source resumes from, using the task's persisted watermark.
+func prepareSourceState(task *data.ReplicationTask, sourceConn *data.Connection) error {
+	if sourceConn.Type != "http_api" {
+		return nil
+	}
+	sel, err := ParseHTTPAPISelection(task.DataSelectionCriteria)
+	if err != nil {
+		return err
+	}
+	if sel.Pagination.Type == HTTPPaginationNone {
+		return nil
+	}
+	return SeedHTTPAPIState(task.ID, sel, task.Watermark)
+}
+
+// persistSourceState stores the position reached by a successful run as the task's watermark,
+// so the next run pulls incrementally instead of starting over.
+func (a *ActivitiesImpl) persistSourceState(ctx context.Context, task *data.ReplicationTask, sourceConn *data.Connection) error {
+	if sourceConn.Type != "http_api" {
+		return nil
+	}
+	watermark, err := ReadHTTPAPIWatermark(task.ID)
+	if err != nil {
+		return err
+	}
+	if watermark == "" || watermark == task.Watermark {
+		return nil
+	}
+	if err := a.svc.UpdateReplicationTaskWatermark(ctx, task.ID, watermark); err != nil {
+		return fmt.Errorf("failed to persist watermark for task %d: %w", task.ID, err)
+	}
+	return nil
+}
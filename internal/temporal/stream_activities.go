@@ -0,0 +1,202 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/stream"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Application error types of streaming failures.
+const (
+	ErrorTypeStreamingUnsupported = "StreamingUnsupported"
+	ErrorTypeInvalidStreamConfig  = "InvalidStreamConfig"
+)
+
+// streamStopTimeout is how long a stopped pipeline may take to deliver the messages in flight.
+const streamStopTimeout = time.Second * 30
+
+// StreamSegmentResult sums up one segment of a streaming task: the micro-runs it rolled up and
+// the records they read and wrote.
+type StreamSegmentResult struct {
+	MicroRuns   int   `json:"micro_runs"`
+	RowsRead    int64 `json:"rows_read"`
+	RowsWritten int64 `json:"rows_written"`
+}
+
+// RunStreamSegmentActivity keeps a streaming task's pipeline running for one segment of the
+// stream. Every rollup interval it ends the current micro-run with the records read and
+// written since the last one, and starts the next; every lag interval it measures the task's
+// replication lag. The segment ends, and the pipeline is stopped, after the segment duration
+// or when the activity is cancelled.
+func (a *ActivitiesImpl) RunStreamSegmentActivity(ctx context.Context, taskID int64) (*StreamSegmentResult, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task %d for streaming: %w", taskID, err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task %d not found for streaming", taskID)
+	}
+	config, err := stream.Parse(task.StreamConfig)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", taskID, err), ErrorTypeInvalidStreamConfig, nil)
+	}
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+	}
+	if sourceConn == nil {
+		return nil, fmt.Errorf("source connection %d not found for task %d", task.SourceConnectionID, taskID)
+	}
+	if !benthos.SupportsStreaming(sourceConn.Type) {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %s sources cannot stream", taskID, sourceConn.Type), ErrorTypeStreamingUnsupported, nil)
+	}
+	targetConn, err := a.svc.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch target connection %d for task %d: %w", task.TargetConnectionID, taskID, err)
+	}
+	if targetConn == nil {
+		return nil, fmt.Errorf("target connection %d not found for task %d", task.TargetConnectionID, taskID)
+	}
+
+	// Micro-runs still open were left by an earlier attempt or execution that stopped abruptly
+	if _, err := a.svc.EndStreamingMicroRuns(ctx, taskID, "Stream interrupted before the micro-run was rolled up"); err != nil {
+		return nil, err
+	}
+
+	run, err := a.startMicroRun(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	address, err := benthos.LocalHTTPAddress()
+	if err != nil {
+		a.endMicroRun(ctx, run.ID, nil, err)
+		return nil, err
+	}
+	runContext := benthos.RunContext{RunID: run.ID, StartTime: run.StartTime, Streaming: true, HTTPAddress: address}
+	configYAML, err := benthos.GenerateBenthosConfigForRun(*task, *sourceConn, *targetConn, runContext)
+	if err != nil {
+		a.endMicroRun(ctx, run.ID, nil, err)
+		return nil, temporal.NewApplicationError(fmt.Sprintf("failed to generate benthos config for task %d", taskID), ErrorTypeConfigGeneration, err)
+	}
+	// Lag is only monitored; a stream whose lag cannot be measured still runs
+	monitor, err := a.newLagMonitor(ctx, task, sourceConn, config.LagAlertThreshold, address)
+	if err != nil {
+		fmt.Printf("Warning: lag of task %d will not be measured: %v\n", taskID, err)
+	} else {
+		defer monitor.Close()
+	}
+
+	pipeline, err := benthos.StartBenthosPipeline(configYAML)
+	if err != nil {
+		a.endMicroRun(ctx, run.ID, nil, err)
+		return nil, temporal.NewApplicationError(fmt.Sprintf("benthos execution failed for task %d", taskID), ErrorTypeBenthosExecution, err)
+	}
+	stopHeartbeats := startHeartbeats(ctx)
+	defer stopHeartbeats()
+
+	result := &StreamSegmentResult{}
+	last := &benthos.PipelineMetrics{}
+	// rollUp ends the current micro-run with the records counted since the last rollup
+	rollUp := func(ctx context.Context) *data.ReplicationRun {
+		counts, err := benthos.ReadPipelineMetrics(ctx, address)
+		if err != nil {
+			// The counts are only missed for this micro-run; the next one includes them
+			fmt.Printf("Warning: failed to read counts of micro-run %d: %v\n", run.ID, err)
+			counts = last
+		}
+		delta := &benthos.PipelineMetrics{Received: counts.Received - last.Received, Sent: counts.Sent - last.Sent}
+		last = counts
+		a.endMicroRun(ctx, run.ID, delta, nil)
+		result.MicroRuns++
+		result.RowsRead += delta.Received
+		result.RowsWritten += delta.Sent
+		return run
+	}
+
+	rollups := time.NewTicker(config.RollupInterval)
+	defer rollups.Stop()
+	segmentEnd := time.NewTimer(config.SegmentDuration)
+	defer segmentEnd.Stop()
+	lagMeasurements := time.NewTicker(config.LagInterval)
+	defer lagMeasurements.Stop()
+	for {
+		select {
+		case <-lagMeasurements.C:
+			if monitor != nil {
+				monitor.record(ctx, run.ID)
+			}
+
+		case <-rollups.C:
+			rollUp(ctx)
+			if run, err = a.startMicroRun(ctx, taskID); err != nil {
+				_ = pipeline.Stop(streamStopTimeout)
+				return nil, err
+			}
+
+		case <-segmentEnd.C:
+			rollUp(ctx)
+			if err := pipeline.Stop(streamStopTimeout); err != nil {
+				fmt.Printf("Warning: stream of task %d: %v\n", taskID, err)
+			}
+			return result, nil
+
+		case <-ctx.Done():
+			// Paused, stopped or timed out; the micro-run still records what was delivered
+			dcCtx := context.WithoutCancel(ctx)
+			rollUp(dcCtx)
+			if err := pipeline.Stop(streamStopTimeout); err != nil {
+				fmt.Printf("Warning: stream of task %d: %v\n", taskID, err)
+			}
+			return result, ctx.Err()
+
+		case <-pipeline.Done():
+			err := pipeline.Err()
+			a.endMicroRun(ctx, run.ID, nil, err)
+			return nil, temporal.NewApplicationError(fmt.Sprintf("benthos execution failed for task %d", taskID), ErrorTypeBenthosExecution, err)
+		}
+	}
+}
+
+// startMicroRun records a running micro-run of a streaming task, carried out by the workflow
+// execution calling the activity.
+func (a *ActivitiesImpl) startMicroRun(ctx context.Context, taskID int64) (*data.ReplicationRun, error) {
+	execution := activity.GetInfo(ctx).WorkflowExecution
+	run := &data.ReplicationRun{
+		ReplicationTaskID:  taskID,
+		StartTime:          time.Now(),
+		Status:             string(ReplicationWorkflowStateRunning),
+		TemporalWorkflowID: execution.ID,
+		TemporalRunID:      execution.RunID,
+		Streaming:          true,
+	}
+	id, err := a.svc.CreateReplicationRun(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create micro-run for task %d: %w", taskID, err)
+	}
+	run.ID = id
+	return run, nil
+}
+
+// endMicroRun ends a micro-run as completed with the counts of its records, or as failed with
+// cause. Failures to store the outcome are logged; the next segment ends micro-runs left open.
+func (a *ActivitiesImpl) endMicroRun(ctx context.Context, runID int64, counts *benthos.PipelineMetrics, cause error) {
+	now := time.Now()
+	status, errorMessage := ReplicationWorkflowStateCompleted, ""
+	if cause != nil {
+		status, errorMessage = ReplicationWorkflowStateFailed, cause.Error()
+	}
+	if counts != nil {
+		if err := a.svc.UpdateReplicationRunCounts(ctx, runID, counts.Received, counts.Sent); err != nil {
+			fmt.Printf("Warning: failed to store counts of micro-run %d: %v\n", runID, err)
+		}
+	}
+	if err := a.svc.UpdateReplicationRunStatus(ctx, runID, string(status), errorMessage, &now); err != nil {
+		fmt.Printf("Warning: failed to store status of micro-run %d: %v\n", runID, err)
+	}
+}
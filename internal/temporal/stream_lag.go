@@ -0,0 +1,130 @@
+package temporal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/benthos"
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/lag"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"github.com/eleon00/hsoetlnlm/internal/service"
+)
+
+// Events recorded when a streaming task's lag crosses its alert threshold.
+const (
+	EventTypeReplicationLag          = "replication_lag"
+	EventTypeReplicationLagRecovered = "replication_lag_recovered"
+)
+
+// lagMonitor measures the replication lag of a streaming task while a segment runs, stores it
+// as lag samples and raises or clears the task's lag alert.
+type lagMonitor struct {
+	svc       service.Service
+	task      *data.ReplicationTask
+	threshold time.Duration
+	address   string // HTTP server of the pipeline, whose gauges hold the lag of Kafka sources
+	db        *sql.DB
+	slot      string // Replication slot of Postgres sources, read through db
+	tracker   *lag.Tracker
+	alerting  bool
+}
+
+// newLagMonitor prepares the lag measurements of a streaming task's segment. The alert carries
+// on from the task's last sample, and samples past their retention are removed.
+func (a *ActivitiesImpl) newLagMonitor(ctx context.Context, task *data.ReplicationTask, sourceConn *data.Connection, threshold time.Duration, address string) (*lagMonitor, error) {
+	previous, err := a.svc.GetLatestReplicationLagSample(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch last lag sample of task %d: %w", task.ID, err)
+	}
+	if _, err := a.svc.PruneReplicationLagSamples(ctx, task.ID); err != nil {
+		return nil, fmt.Errorf("failed to prune lag samples of task %d: %w", task.ID, err)
+	}
+
+	m := &lagMonitor{svc: a.svc, task: task, threshold: threshold, address: address}
+	if previous != nil && previous.LatencyMs != nil && threshold > 0 {
+		m.alerting = time.Duration(*previous.LatencyMs)*time.Millisecond > threshold
+	}
+	if sourceConn.Type == "postgres" {
+		if m.db, err = schema.OpenDB(ctx, sourceConn); err != nil {
+			return nil, fmt.Errorf("failed to connect to the source of task %d for its lag: %w", task.ID, err)
+		}
+		m.slot = benthos.PostgresCDCSlot(*sourceConn, *task)
+		m.tracker = lag.NewTracker(previous)
+	}
+	return m, nil
+}
+
+// Close closes the monitor's source connection, if it has one.
+func (m *lagMonitor) Close() {
+	if m.db != nil {
+		_ = m.db.Close()
+	}
+}
+
+// measure reads the task's current lag: from the replication slot of Postgres sources, and
+// from the pipeline's gauges otherwise.
+func (m *lagMonitor) measure(ctx context.Context) (*lag.Measurement, error) {
+	if m.db == nil {
+		metrics, err := benthos.ReadPipelineMetrics(ctx, m.address)
+		if err != nil {
+			return nil, err
+		}
+		return lag.FromPipeline(metrics.Partitions), nil
+	}
+	position, err := lag.ReadSlot(ctx, m.db, m.slot)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The pipeline has not created its slot yet
+		return &lag.Measurement{Unit: lag.UnitBytes}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m.tracker.Observe(time.Now(), position)
+}
+
+// record measures the task's lag during a micro-run, stores it and raises or clears the alert.
+// Failures are logged; lag is measured again at the next interval.
+func (m *lagMonitor) record(ctx context.Context, runID int64) {
+	measured, err := m.measure(ctx)
+	if err != nil {
+		fmt.Printf("Warning: failed to measure lag of task %d: %v\n", m.task.ID, err)
+		return
+	}
+	sample := measured.Sample(m.task.ID, runID, time.Now())
+	if _, err := m.svc.RecordReplicationLagSample(ctx, sample); err != nil {
+		fmt.Printf("Warning: failed to store lag of task %d: %v\n", m.task.ID, err)
+		return
+	}
+
+	raise, clear := lag.Transition(m.threshold, m.alerting, measured.Latency)
+	if !raise && !clear {
+		return
+	}
+	event := &data.Event{
+		ReplicationTaskID: &m.task.ID,
+		ReplicationRunID:  &runID,
+		Type:              EventTypeReplicationLag,
+		Message: fmt.Sprintf("Replication lag of task %s is %v, over its threshold of %v",
+			m.task.Name, measured.Latency.Round(time.Second), m.threshold),
+	}
+	if clear {
+		event.Type = EventTypeReplicationLagRecovered
+		event.Message = fmt.Sprintf("Replication lag of task %s is back to %v, within its threshold of %v",
+			m.task.Name, measured.Latency.Round(time.Second), m.threshold)
+	}
+	backlog := "null"
+	if sample.Backlog != nil {
+		backlog = fmt.Sprintf("%d", *sample.Backlog)
+	}
+	event.Details = fmt.Sprintf(`{"latency_ms": %d, "backlog": %s, "backlog_unit": %q, "threshold_ms": %d}`,
+		*sample.LatencyMs, backlog, sample.BacklogUnit, m.threshold.Milliseconds())
+	if _, err := m.svc.RecordEvent(ctx, event); err != nil {
+		fmt.Printf("Warning: failed to record lag alert of task %d: %v\n", m.task.ID, err)
+		return
+	}
+	m.alerting = raise
+}
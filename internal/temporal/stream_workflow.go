@@ -0,0 +1,99 @@
+package temporal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/policy"
+	"github.com/eleon00/hsoetlnlm/internal/service"
+	"github.com/eleon00/hsoetlnlm/internal/stream"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// StreamParams are the parameters of one execution of a streaming task's workflow.
+type StreamParams struct {
+	TaskID  int64 `json:"task_id"`
+	Segment int   `json:"segment"` // Number of segments streamed by earlier executions
+}
+
+// StreamingReplicationWorkflow keeps a streaming task's pipeline running. Each execution streams
+// one segment, rolled up into a micro-run every rollup interval, then continues as new so the
+// workflow's history stays bounded. While the task is paused no segment runs; a pause stops
+// the running segment. The workflow ends once the task no longer has a stream config.
+func StreamingReplicationWorkflow(ctx workflow.Context, params StreamParams) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting stream segment", "taskID", params.TaskID, "segment", params.Segment)
+
+	ctx = workflow.WithActivityOptions(ctx, replicationActivityOptions())
+	control := newRunControl(ctx, params.TaskID)
+
+	var task *data.ReplicationTask
+	seen := control.signals
+	if err := workflow.ExecuteActivity(ctx, "LoadReplicationTask", params.TaskID).Get(ctx, &task); err != nil {
+		return err
+	}
+	if !stream.Enabled(task.StreamConfig) {
+		logger.Info("Task no longer streams; stream ended", "taskID", params.TaskID)
+		return nil
+	}
+	config, err := stream.Parse(task.StreamConfig)
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("task %d: %v", params.TaskID, err), ErrorTypeInvalidStreamConfig, nil)
+	}
+	var p *policy.Policy
+	if err := workflow.ExecuteActivity(ctx, "LoadExecutionPolicyActivity", params.TaskID).Get(ctx, &p); err != nil {
+		return err
+	}
+	ctx = workflow.WithActivityOptions(ctx, taskActivityOptions(p))
+
+	// The task's status is checked again on every resume signal, and every leaseRetryInterval
+	// in case a resume was received before the workflow saw the task paused
+	assumed := false
+	for task.Status == service.TaskStatusPaused {
+		if control.signals == seen {
+			control.paused, assumed = true, true
+		}
+		if _, err := workflow.AwaitWithTimeout(ctx, leaseRetryInterval, func() bool { return !control.paused }); err != nil {
+			return err
+		}
+		seen = control.signals
+		if err := workflow.ExecuteActivity(ctx, "LoadReplicationTask", params.TaskID).Get(ctx, &task); err != nil {
+			return err
+		}
+	}
+	// Only the pause assumed from the task's status is cleared; a signal received since the
+	// status was read is newer
+	if assumed && control.signals == seen {
+		control.paused = false
+	}
+
+	// The segment's activity outlives the segment by the time its pipeline may take to stop
+	segmentOptions := taskPipelineOptions(p)
+	segmentOptions.StartToCloseTimeout = config.SegmentDuration + streamStopTimeout + time.Minute*5
+	segmentOptions.WaitForCancellation = true
+	segmentCtx, cancelSegment := workflow.WithCancel(workflow.WithActivityOptions(ctx, segmentOptions))
+	segment := workflow.ExecuteActivity(segmentCtx, "RunStreamSegmentActivity", params.TaskID)
+	if err := workflow.Await(ctx, func() bool { return segment.IsReady() || control.paused }); err != nil {
+		// Stopped; the cancellation reaches the segment, which rolls up its last micro-run
+		dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+		_ = segment.Get(dcCtx, nil)
+		return err
+	}
+	if !segment.IsReady() {
+		logger.Info("Task paused; stopping stream segment", "taskID", params.TaskID)
+		cancelSegment()
+	}
+	var result *StreamSegmentResult
+	if err := segment.Get(ctx, &result); err != nil && !temporal.IsCanceledError(err) {
+		return err
+	}
+	if result != nil {
+		logger.Info("Stream segment ended", "taskID", params.TaskID, "microRuns", result.MicroRuns,
+			"rowsRead", result.RowsRead, "rowsWritten", result.RowsWritten)
+	}
+
+	// A paused task waits in the next execution
+	return workflow.NewContinueAsNewError(ctx, StreamingReplicationWorkflow, StreamParams{TaskID: params.TaskID, Segment: params.Segment + 1})
+}
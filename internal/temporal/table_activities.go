@@ -0,0 +1,95 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+)
+
+// TablePlan lists the tables a multi-table run replicates.
+type TablePlan struct {
+	Tables      []schema.TableSelection `json:"tables"`
+	MaxParallel int                     `json:"max_parallel"`
+}
+
+// ResolveTaskTablesActivity expands a multi-table task's table rules against the source
+// catalog. It returns nil for single-table tasks.
+func (a *ActivitiesImpl) ResolveTaskTablesActivity(ctx context.Context, taskID int64) (*TablePlan, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task %d for table resolution: %w", taskID, err)
+	}
+	rules, err := schema.ParseTableRules(task.TableRules)
+	if err != nil {
+		return nil, fmt.Errorf("task %d: %w", taskID, err)
+	}
+	if rules == nil {
+		return nil, nil
+	}
+
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+	}
+	inspector, err := schema.Open(ctx, sourceConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source catalog for task %d: %w", taskID, err)
+	}
+	defer inspector.Close()
+
+	tables, err := schema.ResolveTables(ctx, inspector, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source tables for task %d: %w", taskID, err)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("table rules of task %d matched no tables", taskID)
+	}
+	return &TablePlan{Tables: tables, MaxParallel: rules.MaxParallel}, nil
+}
+
+// CreateTableRun records the run of one table under a multi-table run. The table's existing
+// run is returned when the parent run is being resumed (or the activity is retried).
+func (a *ActivitiesImpl) CreateTableRun(ctx context.Context, taskID int64, parentRunID int64, tableName string) (*data.ReplicationRun, error) {
+	existing, err := a.svc.ListReplicationRunTables(ctx, parentRunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list table runs of run %d: %w", parentRunID, err)
+	}
+	for _, run := range existing {
+		if run.TableName == tableName {
+			return run, nil
+		}
+	}
+
+	run := &data.ReplicationRun{
+		ReplicationTaskID: taskID,
+		StartTime:         time.Now(),
+		Status:            string(ReplicationWorkflowStateLoading),
+		ParentRunID:       &parentRunID,
+		TableName:         tableName,
+	}
+
+	newID, err := a.svc.CreateReplicationRun(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run for table %s of run %d: %w", tableName, parentRunID, err)
+	}
+	run.ID = newID
+
+	return run, nil
+}
+
+// ExecuteTablePipelineActivity runs the pipeline for one table of a multi-table run.
+func (a *ActivitiesImpl) ExecuteTablePipelineActivity(ctx context.Context, taskID int64, runID int64, table schema.TableSelection) (*PipelineResult, error) {
+	return a.executePipeline(ctx, taskID, runID, &table, nil, nil)
+}
+
+// tableQuery selects every row of a source table.
+func tableQuery(sourceConn *data.Connection, table schema.TableSelection) (string, error) {
+	name, err := schema.QuoteTableName(sourceConn.Type, table.Schema, table.Name)
+	if err != nil {
+		return "", err
+	}
+	return "SELECT * FROM " + name, nil
+}
@@ -0,0 +1,125 @@
+package temporal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/policy"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"go.temporal.io/sdk/workflow"
+)
+
+// TableWorkflowParams identifies one table of a multi-table replication run.
+type TableWorkflowParams struct {
+	TaskID      int64                 `json:"task_id"`
+	ParentRunID int64                 `json:"parent_run_id"`
+	Table       schema.TableSelection `json:"table"`
+	Policy      *policy.Policy        `json:"policy,omitempty"` // Execution policy of the parent run
+}
+
+// replicateTables starts a TableReplicationWorkflow per table, at most plan.MaxParallel at a
+// time, and waits for all of them under the run's execution policy p. While the run is paused
+// no new table is started. It fails if any table failed.
+func replicateTables(ctx workflow.Context, taskID int64, runID int64, plan *TablePlan, p *policy.Policy, control *runControl) error {
+	logger := workflow.GetLogger(ctx)
+	parentWorkflowID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	maxParallel := plan.MaxParallel
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	selector := workflow.NewSelector(ctx)
+	var failed []string
+	var err error
+	running := 0
+	for _, table := range plan.Tables {
+		if running == maxParallel {
+			selector.Select(ctx) // Wait for a table to finish before starting the next
+			running--
+		}
+		if err = control.wait(ctx, runID, ReplicationWorkflowStateRunning); err != nil {
+			break
+		}
+		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID: fmt.Sprintf("%s-%s", parentWorkflowID, table.SourceName()),
+		})
+		future := workflow.ExecuteChildWorkflow(childCtx, TableReplicationWorkflow, TableWorkflowParams{
+			TaskID:      taskID,
+			ParentRunID: runID,
+			Table:       table,
+			Policy:      p,
+		})
+		selector.AddFuture(future, func(f workflow.Future) {
+			if err := f.Get(ctx, nil); err != nil {
+				logger.Error("Table replication failed", "table", table.SourceName(), "error", err)
+				failed = append(failed, table.SourceName())
+			}
+		})
+		running++
+	}
+	for ; running > 0; running-- {
+		selector.Select(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%d of %d tables failed: %s", len(failed), len(plan.Tables), strings.Join(failed, ", "))
+	}
+	logger.Info("All tables replicated", "tables", len(plan.Tables))
+	return nil
+}
+
+// TableReplicationWorkflow replicates one table of a multi-table task, recording its own run
+// under the parent run.
+func TableReplicationWorkflow(ctx workflow.Context, params TableWorkflowParams) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Starting table replication", "taskID", params.TaskID, "table", params.Table.SourceName(), "target", params.Table.Target)
+	ctx = workflow.WithActivityOptions(ctx, taskActivityOptions(params.Policy))
+
+	var run *data.ReplicationRun
+	err := workflow.ExecuteActivity(ctx, "CreateTableRun", params.TaskID, params.ParentRunID, params.Table.SourceName()).Get(ctx, &run)
+	if err != nil {
+		return err
+	}
+	if run.Status == string(ReplicationWorkflowStateCompleted) {
+		// Completed by an earlier attempt of a resumed run
+		logger.Info("Table already replicated", "table", params.Table.SourceName(), "runID", run.ID)
+		return nil
+	}
+
+	state, errorMessage := ReplicationWorkflowStateCompleted, ""
+	var drift *DriftOutcome
+	if err = workflow.ExecuteActivity(ctx, "CheckSchemaDriftActivity", params.TaskID, run.ID, &params.Table).Get(ctx, &drift); err != nil {
+		state, errorMessage = ReplicationWorkflowStateFailed, fmt.Sprintf("Schema drift check failed: %v", err)
+	} else if drift.Quarantine {
+		// Not a failure: the other tables of the run carry on
+		logger.Warn("Table load quarantined because of schema drift", "table", params.Table.SourceName(), "drift", drift.Summary)
+		state, errorMessage = ReplicationWorkflowStateQuarantined, fmt.Sprintf("Load skipped because of source schema drift (%s)", drift.Summary)
+	} else if err = workflow.ExecuteActivity(ctx, "CreateTargetTableActivity", params.TaskID, run.ID, &params.Table).Get(ctx, nil); err != nil {
+		state, errorMessage = ReplicationWorkflowStateFailed, fmt.Sprintf("Failed to create target table: %v", err)
+	} else if partitioned, chunkErr := executeChunks(ctx, params.TaskID, run.ID, &params.Table, params.Policy, nil); partitioned {
+		if err = chunkErr; err != nil {
+			state, errorMessage = ReplicationWorkflowStateFailed, fmt.Sprintf("Chunked extraction failed: %v", err)
+		}
+	} else {
+		pipelineCtx := workflow.WithActivityOptions(ctx, taskPipelineOptions(params.Policy))
+		var result PipelineResult
+		err = workflow.ExecuteActivity(pipelineCtx, "ExecuteTablePipelineActivity", params.TaskID, run.ID, params.Table).Get(pipelineCtx, &result)
+		if err != nil {
+			state, errorMessage = ReplicationWorkflowStateFailed, fmt.Sprintf("Benthos pipeline execution failed: %v", err)
+			logger.Error("Benthos execution failed", "error", err, "output", result.Output)
+		}
+	}
+
+	// Use a disconnected context so the outcome is recorded even if the parent was cancelled
+	dcCtx, _ := workflow.NewDisconnectedContext(ctx)
+	if updateErr := workflow.ExecuteActivity(dcCtx, "UpdateReplicationRunStatus", run.ID, string(state), errorMessage).Get(dcCtx, nil); updateErr != nil {
+		logger.Error("Failed to update table run status", "error", updateErr, "RunID", run.ID)
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package temporal
+
+import (
+	"context"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+
+	. "github.com/eleon00/hsoetlnlm/internal/benthos"
+)
+
+// prepareTargetFiles clears the staging area of targets that publish files on completion,
+// so a retried attempt does not publish files from the failed one.
+func prepareTargetFiles(task *data.ReplicationTask, targetConn *data.Connection, runID int64) error {
+	if targetConn.Type != "localfile" {
+		return nil
+	}
+	return ResetLocalFileStaging(ParseConnectionString(targetConn.ConnectionString), task.ID, runID)
+}
+
+// publishTargetFiles moves a successful run's staged files into place and writes its manifest.
+// It returns the published paths, or nil for targets that write directly.
+func publishTargetFiles(task *data.ReplicationTask, targetConn *data.Connection, runID int64) ([]string, error) {
+	if targetConn.Type != "localfile" {
+		return nil, nil
+	}
+	manifest, err := PublishLocalFiles(ParseConnectionString(targetConn.ConnectionString), task.ID, runID)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(manifest.Files))
+	for _, file := range manifest.Files {
+		files = append(files, file.Path)
+	}
+	return files, nil
+}
+
+// clearRunRows deletes the rows an earlier attempt of the run (or chunk) wrote to a target that
+// records runs on its rows, so retries and resumes do not load them twice.
+func clearRunRows(ctx context.Context, targetConn *data.Connection, run RunContext) error {
+	statement, err := PostgresRunCleanup(*targetConn, run)
+	if err != nil || statement == "" {
+		return err
+	}
+	return ddl.Exec(ctx, targetConn, statement)
+}
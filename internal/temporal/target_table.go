@@ -0,0 +1,105 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/ddl"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+
+	. "github.com/eleon00/hsoetlnlm/internal/benthos"
+)
+
+// CreateTargetTableActivity runs CREATE TABLE IF NOT EXISTS for the task's target table (or one
+// table of a multi-table task) when the target connection sets create_table=true. The columns
+// of a query-based task come from its criteria as rendered for runID (0 before any run).
+func (a *ActivitiesImpl) CreateTargetTableActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection) error {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch task %d for table creation: %w", taskID, err)
+	}
+	targetConn, err := a.svc.GetConnection(ctx, task.TargetConnectionID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch target connection %d for task %d: %w", task.TargetConnectionID, taskID, err)
+	}
+	createTable, _ := strconv.ParseBool(ParseConnectionString(targetConn.ConnectionString)["create_table"])
+	if !createTable || !ddl.Supported(targetConn.Type) || (table == nil && task.TableRules != "") {
+		return nil
+	}
+	sourceConn, err := a.svc.GetConnection(ctx, task.SourceConnectionID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch source connection %d for task %d: %w", task.SourceConnectionID, taskID, err)
+	}
+
+	if table == nil {
+		if err := a.renderRunCriteria(ctx, task, runID); err != nil {
+			return err
+		}
+	}
+
+	targetTable, err := deriveTargetTable(ctx, task, sourceConn, targetConn, table)
+	if err != nil {
+		return fmt.Errorf("failed to derive target table for task %d: %w", taskID, err)
+	}
+	return ddl.Apply(ctx, targetConn, targetTable)
+}
+
+// deriveTargetTable translates the source columns of a task (or one of its tables) into the
+// target table definition.
+func deriveTargetTable(ctx context.Context, task *data.ReplicationTask, sourceConn, targetConn *data.Connection, table *schema.TableSelection) (*ddl.Table, error) {
+	inspector, err := schema.Open(ctx, sourceConn)
+	if err != nil {
+		return nil, err
+	}
+	defer inspector.Close()
+	return ddl.Derive(ctx, inspector, sourceConn.Type, task, targetConn, table)
+}
+
+// targetColumns maps source fields to the columns of column-wise targets (postgres) that don't
+// list their columns in the connection string.
+func targetColumns(ctx context.Context, task *data.ReplicationTask, sourceConn, targetConn *data.Connection, table *schema.TableSelection) ([]ColumnMapping, error) {
+	if targetConn.Type != "postgres" {
+		return nil, nil
+	}
+	if _, ok := ParseConnectionString(targetConn.ConnectionString)["columns"]; ok {
+		return nil, nil
+	}
+	targetTable, err := deriveTargetTable(ctx, task, sourceConn, targetConn, table)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]ColumnMapping, 0, len(targetTable.Columns))
+	for _, column := range targetTable.Columns {
+		if column.Source == "" {
+			continue // Run columns are added by the output itself
+		}
+		columns = append(columns, ColumnMapping{Source: column.Source, Target: column.Name})
+	}
+	return columns, nil
+}
+
+// parquetSchema derives the parquet_schema of Parquet file targets that don't set one from the
+// source columns. Sources without a catalog (files, streams) leave the target's own setting,
+// so the missing schema is reported when the config is generated.
+func parquetSchema(ctx context.Context, task *data.ReplicationTask, sourceConn, targetConn *data.Connection, table *schema.TableSelection) (string, error) {
+	params := ParseConnectionString(targetConn.ConnectionString)
+	if _, ok := params["parquet_schema"]; ok || params["format"] != FormatParquet {
+		return "", nil
+	}
+	inspector, err := schema.Open(ctx, sourceConn)
+	if errors.Is(err, schema.ErrUnsupportedType) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer inspector.Close()
+	columns, err := ddl.SourceColumns(ctx, inspector, sourceConn.Type, task, table)
+	if err != nil {
+		return "", err
+	}
+	return ddl.ParquetSchema(sourceConn.Type, columns)
+}
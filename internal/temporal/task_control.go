@@ -0,0 +1,166 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eleon00/hsoetlnlm/internal/service"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RunStart tells a new run whether to wait for its paused task, or to skip itself.
+type RunStart struct {
+	Paused bool `json:"paused"`
+	Skip   bool `json:"skip"`
+}
+
+// CheckRunStartActivity reads whether a task is paused and, if it is not, takes its skip-next
+// flag for the calling run.
+func (a *ActivitiesImpl) CheckRunStartActivity(ctx context.Context, taskID int64) (*RunStart, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch task %d: %w", taskID, err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task %d not found", taskID)
+	}
+	if task.Status == service.TaskStatusPaused {
+		return &RunStart{Paused: true}, nil
+	}
+	skip, err := a.svc.ConsumeSkipNextRun(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skip-next of task %d: %w", taskID, err)
+	}
+	return &RunStart{Skip: skip}, nil
+}
+
+// runControl holds the pause state of a replication run, kept up to date from the task control
+// signal. A nil runControl is never paused.
+type runControl struct {
+	paused  bool
+	signals int // Pause and resume signals received, so readers of the task's status can tell whether a signal overtook them
+}
+
+// newRunControl starts receiving the task control signal of the workflow of ctx.
+func newRunControl(ctx workflow.Context, taskID int64) *runControl {
+	logger := workflow.GetLogger(ctx)
+	control := &runControl{}
+	signals := workflow.GetSignalChannel(ctx, service.TaskControlSignal)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for {
+			var action string
+			signals.Receive(ctx, &action)
+			switch action {
+			case service.TaskActionPause:
+				control.paused = true
+			case service.TaskActionResume:
+				control.paused = false
+			default:
+				continue
+			}
+			control.signals++
+			logger.Info("Task control signal", "taskID", taskID, "action", action)
+		}
+	})
+	return control
+}
+
+// wait blocks while the run is paused, showing the run as paused meanwhile and as state once
+// it is resumed. Pipelines already started are not affected; wait is called before starting
+// new ones.
+func (c *runControl) wait(ctx workflow.Context, runID int64, state ReplicationWorkflowState) error {
+	if c == nil || !c.paused {
+		return nil
+	}
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Run paused", "runID", runID)
+	// The activity logs and swallows its own failures
+	_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus", runID, string(ReplicationWorkflowStatePaused), "").Get(ctx, nil)
+	if err := workflow.Await(ctx, func() bool { return !c.paused }); err != nil {
+		return err
+	}
+	logger.Info("Run resumed", "runID", runID)
+	_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus", runID, string(state), "").Get(ctx, nil)
+	return nil
+}
+
+// startRun waits while the run's task is paused, then reports whether the task's skip-next
+// flag skips the run. The task's status is checked again on every resume signal, and every
+// leaseRetryInterval in case a resume was received before the run saw the task paused.
+func startRun(ctx workflow.Context, control *runControl, params *WorkflowParams) (bool, error) {
+	waited := false
+	for {
+		seen := control.signals
+		var start *RunStart
+		if err := workflow.ExecuteActivity(ctx, "CheckRunStartActivity", params.TaskID).Get(ctx, &start); err != nil {
+			return false, err
+		}
+		if !start.Paused {
+			// Only the pause assumed from the task's status is cleared; a signal received since
+			// the status was read is newer
+			if waited && control.signals == seen {
+				control.paused = false
+			}
+			if waited {
+				// The activity logs and swallows its own failures
+				_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus", params.ReplicationRunID, string(params.State), "").Get(ctx, nil)
+			}
+			return start.Skip, nil
+		}
+		if !waited {
+			workflow.GetLogger(ctx).Info("Task paused; run waiting to start", "taskID", params.TaskID, "runID", params.ReplicationRunID)
+			_ = workflow.ExecuteActivity(ctx, "UpdateReplicationRunStatus", params.ReplicationRunID, string(ReplicationWorkflowStatePaused), "").Get(ctx, nil)
+			waited = true
+		}
+		if control.signals == seen {
+			control.paused = true
+		}
+		if _, err := workflow.AwaitWithTimeout(ctx, leaseRetryInterval, func() bool { return !control.paused }); err != nil {
+			return false, err
+		}
+	}
+}
+
+// taskPaused reads whether a task is paused.
+func (a *ActivitiesImpl) taskPaused(ctx context.Context, taskID int64) (bool, error) {
+	task, err := a.svc.GetReplicationTask(ctx, taskID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch task %d: %w", taskID, err)
+	}
+	if task == nil {
+		return false, fmt.Errorf("task %d not found", taskID)
+	}
+	return task.Status == service.TaskStatusPaused, nil
+}
+
+// CheckTaskPausedActivity reads whether a task is paused. Unlike CheckRunStartActivity it
+// leaves the task's skip-next flag alone.
+func (a *ActivitiesImpl) CheckTaskPausedActivity(ctx context.Context, taskID int64) (bool, error) {
+	return a.taskPaused(ctx, taskID)
+}
+
+// awaitTaskActive blocks while a task is paused, reading its status every leaseRetryInterval.
+// Backfills and refreshes call it before starting each slice or table, since they run outside
+// the task's replication workflow and so do not receive its control signal.
+func awaitTaskActive(ctx workflow.Context, taskID int64) error {
+	waited := false
+	for {
+		var paused bool
+		if err := workflow.ExecuteActivity(ctx, "CheckTaskPausedActivity", taskID).Get(ctx, &paused); err != nil {
+			return err
+		}
+		if !paused {
+			if waited {
+				workflow.GetLogger(ctx).Info("Task resumed", "taskID", taskID)
+			}
+			return nil
+		}
+		if !waited {
+			workflow.GetLogger(ctx).Info("Task paused; waiting to continue", "taskID", taskID)
+			waited = true
+		}
+		if err := workflow.Sleep(ctx, leaseRetryInterval); err != nil {
+			return err
+		}
+	}
+}
@@ -38,6 +38,14 @@ func NewWorker(client *Client, svc service.Service, opts *WorkerOptions) (*Worke
 
 	// Register workflow handlers
 	w.RegisterWorkflow(ReplicationWorkflow)
+	w.RegisterWorkflow(ResumeReplicationWorkflow)
+	w.RegisterWorkflow(TableReplicationWorkflow)
+	w.RegisterWorkflow(CompareWorkflow)
+	w.RegisterWorkflow(RefreshWorkflow)
+	w.RegisterWorkflow(BackfillWorkflow)
+	w.RegisterWorkflow(PipelineWorkflow)
+	w.RegisterWorkflow(ReconcileRunsWorkflow)
+	w.RegisterWorkflow(StreamingReplicationWorkflow)
 
 	// Register activity handlers
 	activities := NewActivities(svc)
@@ -4,7 +4,12 @@ import (
 	"context"
 	"time"
 
+	"github.com/eleon00/hsoetlnlm/internal/compare"
+	"github.com/eleon00/hsoetlnlm/internal/criteria"
 	"github.com/eleon00/hsoetlnlm/internal/data"
+	"github.com/eleon00/hsoetlnlm/internal/policy"
+	"github.com/eleon00/hsoetlnlm/internal/schema"
+	"github.com/eleon00/hsoetlnlm/internal/service"
 )
 
 // ReplicationWorkflowState represents the current state of a replication workflow
@@ -13,6 +18,10 @@ type ReplicationWorkflowState string
 const (
 	// ReplicationWorkflowStateInitialized is the initial state when a workflow is created
 	ReplicationWorkflowStateInitialized ReplicationWorkflowState = "initialized"
+	// ReplicationWorkflowStateQueued is the state while a run waits for a connection or task concurrency slot
+	ReplicationWorkflowStateQueued ReplicationWorkflowState = "queued"
+	// ReplicationWorkflowStatePaused is the state while a run's task is paused
+	ReplicationWorkflowStatePaused ReplicationWorkflowState = "paused"
 	// ReplicationWorkflowStateLoading is the state when loading task configuration
 	ReplicationWorkflowStateLoading ReplicationWorkflowState = "loading"
 	// ReplicationWorkflowStateGeneratingConfig is the state when generating Benthos config
@@ -25,6 +34,14 @@ const (
 	ReplicationWorkflowStateCompleted ReplicationWorkflowState = "completed"
 	// ReplicationWorkflowStateFailed is the state when replication fails
 	ReplicationWorkflowStateFailed ReplicationWorkflowState = "failed"
+	// ReplicationWorkflowStateQuarantined is the state when a load was skipped because of schema drift
+	ReplicationWorkflowStateQuarantined ReplicationWorkflowState = "quarantined"
+	// ReplicationWorkflowStateSkipped is the state of a run skipped by its task's skip-next flag
+	ReplicationWorkflowStateSkipped ReplicationWorkflowState = "skipped"
+	// ReplicationWorkflowStateCancelled is the state of a run whose workflow was cancelled
+	ReplicationWorkflowStateCancelled ReplicationWorkflowState = "cancelled"
+	// ReplicationWorkflowStateTimedOut is the state the run reconciler gives runs whose workflow timed out
+	ReplicationWorkflowStateTimedOut ReplicationWorkflowState = "timed_out"
 )
 
 // WorkflowParams contains parameters needed by replication workflows
@@ -37,6 +54,14 @@ type WorkflowParams struct {
 	BenthosConfigID  *int64                   `json:"benthos_config_id,omitempty"`
 	BenthosProcessID string                   `json:"benthos_process_id,omitempty"`
 	ReplicationRunID int64                    `json:"replication_run_id,omitempty"`
+	Policy           *policy.Policy           `json:"policy,omitempty"` // Task execution policy, loaded once the run is created
+}
+
+// PipelineResult is the outcome of a Benthos pipeline execution
+type PipelineResult struct {
+	Output      string   `json:"output"`
+	SourceFiles []string `json:"source_files,omitempty"` // Files consumed by the run that need a post-run action
+	TargetFiles []string `json:"target_files,omitempty"` // Files published to a localfile target
 }
 
 // Activities interface defines activity methods used by replication workflows
@@ -45,10 +70,99 @@ type Activities interface {
 	LoadReplicationTask(ctx context.Context, taskID int64) (*data.ReplicationTask, error)
 
 	// CreateReplicationRun creates a new replication run record in the database
-	CreateReplicationRun(ctx context.Context, taskID int64) (*data.ReplicationRun, error)
+	CreateReplicationRun(ctx context.Context, taskID int64, trigger *criteria.Trigger) (*data.ReplicationRun, error)
+
+	// ReopenReplicationRun marks a resumed or rerun run running under the calling workflow execution
+	ReopenReplicationRun(ctx context.Context, runID int64) error
+
+	// LoadExecutionPolicyActivity parses the task's retry policy and timeouts
+	LoadExecutionPolicyActivity(ctx context.Context, taskID int64) (*policy.Policy, error)
+
+	// AcquireRunLeasesActivity takes a slot of the run's connection and task concurrency limits; false if any is full
+	AcquireRunLeasesActivity(ctx context.Context, taskID int64, runID int64) (bool, error)
+
+	// ReleaseRunLeasesActivity frees the concurrency slots held by a run
+	ReleaseRunLeasesActivity(ctx context.Context, runID int64) error
+	// AcquireLeasesActivity takes a slot of the task's connection limits for a backfill, compare or refresh; false if any is full
+	AcquireLeasesActivity(ctx context.Context, taskID int64, holder data.LeaseHolder) (bool, error)
+	// ReleaseLeasesActivity frees the concurrency slots held by a run, backfill, compare or refresh
+	ReleaseLeasesActivity(ctx context.Context, holder data.LeaseHolder) error
+
+	// CheckRunStartActivity reports whether the task is paused, or else whether its skip-next flag skips the run
+	CheckRunStartActivity(ctx context.Context, taskID int64) (*RunStart, error)
+	// CheckTaskPausedActivity reports whether the task is paused, without consuming its skip-next flag
+	CheckTaskPausedActivity(ctx context.Context, taskID int64) (bool, error)
 
 	// ExecuteBenthosPipeline generates config and runs the Benthos pipeline for the task
-	ExecuteBenthosPipelineActivity(ctx context.Context, taskID int64, runID int64) (output string, err error)
+	ExecuteBenthosPipelineActivity(ctx context.Context, taskID int64, runID int64) (*PipelineResult, error)
+
+	// ResolveTaskTablesActivity expands a multi-table task's table rules; nil for single-table tasks
+	ResolveTaskTablesActivity(ctx context.Context, taskID int64) (*TablePlan, error)
+
+	// CreateTableRun records the run of one table under a multi-table run
+	CreateTableRun(ctx context.Context, taskID int64, parentRunID int64, tableName string) (*data.ReplicationRun, error)
+
+	// ExecuteTablePipelineActivity runs the pipeline for one table of a multi-table run
+	ExecuteTablePipelineActivity(ctx context.Context, taskID int64, runID int64, table schema.TableSelection) (*PipelineResult, error)
+
+	// PlanRunChunksActivity splits a partitioned run into chunks; nil when the task is not partitioned
+	PlanRunChunksActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection) (*ChunkPlan, error)
+
+	// ExecuteChunkPipelineActivity runs the pipeline for one chunk of a partitioned run
+	ExecuteChunkPipelineActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection, chunk data.ReplicationRunChunk) (*PipelineResult, error)
+
+	// CheckSchemaDriftActivity snapshots the source schema and applies the task's drift policy
+	CheckSchemaDriftActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection) (*DriftOutcome, error)
+
+	// CreateTargetTableActivity creates the target table before a load when the target asks for it
+	CreateTargetTableActivity(ctx context.Context, taskID int64, runID int64, table *schema.TableSelection) error
+
+	// FinalizeSourceFilesActivity archives or deletes source files after a successful run
+	FinalizeSourceFilesActivity(ctx context.Context, taskID int64, files []string, runID int64) error
+
+	// PlanCompareActivity lists the tables of a compare report
+	PlanCompareActivity(ctx context.Context, reportID int64) (*ComparePlan, error)
+
+	// CompareTableActivity compares one table's source and target by key range
+	CompareTableActivity(ctx context.Context, reportID int64, table compare.Table) (*compare.TableResult, error)
+
+	// CompleteCompareReportActivity stores the outcome of a compare
+	CompleteCompareReportActivity(ctx context.Context, reportID int64, results []compare.TableResult, errorMessage string) error
+
+	// PlanRefreshActivity picks the mismatched ranges a refresh rewrites
+	PlanRefreshActivity(ctx context.Context, refreshID int64) (*RefreshPlan, error)
+
+	// RefreshTableActivity rewrites one table's mismatched ranges in the target until the task is paused
+	RefreshTableActivity(ctx context.Context, refreshID int64, table compare.RefreshTable) (int, error)
+
+	// CompleteRefreshActivity stores the outcome of a refresh
+	CompleteRefreshActivity(ctx context.Context, refreshID int64, errorMessage string) error
+
+	// PlanBackfillActivity loads the slices of a backfill
+	PlanBackfillActivity(ctx context.Context, backfillID int64) (*BackfillPlan, error)
+
+	// ExecuteBackfillSliceActivity runs the pipeline over one slice of a backfill
+	ExecuteBackfillSliceActivity(ctx context.Context, backfillID int64, taskID int64, sliceID int64) (*PipelineResult, error)
+
+	// SetBackfillStatusActivity stores the status of a paused or resumed backfill
+	SetBackfillStatusActivity(ctx context.Context, backfillID int64, status string) error
+
+	// CompleteBackfillActivity stores the outcome of a backfill
+	CompleteBackfillActivity(ctx context.Context, backfillID int64, status string, errorMessage string) error
+
+	// StartPipelineRunActivity records a pipeline run and returns the tasks it runs
+	StartPipelineRunActivity(ctx context.Context, pipelineID int64) (*PipelineRunPlan, error)
+
+	// UpdatePipelineRunTasksActivity stores the task statuses of a pipeline run in progress
+	UpdatePipelineRunTasksActivity(ctx context.Context, runID int64, statuses map[int64]string) error
+
+	// CompletePipelineRunActivity stores the outcome of a pipeline run
+	CompletePipelineRunActivity(ctx context.Context, runID int64, statuses map[int64]string, errorMessage string) error
+
+	// ReconcileRunsActivity ends runs whose workflows stopped without recording their outcome
+	ReconcileRunsActivity(ctx context.Context) (*service.RunReconciliation, error)
+	// RunStreamSegmentActivity streams a streaming task for one segment, rolling up its micro-runs
+	RunStreamSegmentActivity(ctx context.Context, taskID int64) (*StreamSegmentResult, error)
 
 	// UpdateReplicationRunStatus updates the status of a replication run
 	UpdateReplicationRunStatus(ctx context.Context, runID int64, status string, errorMsg string) error
@@ -6,6 +6,7 @@ CREATE TABLE Connections (
     Name VARCHAR(255) NOT NULL UNIQUE,
     Type VARCHAR(50) NOT NULL, -- e.g., 'oracle', 'sqlserver', 's3', 'snowflake'
     ConnectionString TEXT NOT NULL, -- Can store complex connection details
+    MaxConcurrentRuns INT NOT NULL DEFAULT 0, -- Runs using the connection at once; 0 for no limit
     CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(), -- Use TIMESTAMP and NOW()
     UpdatedAt TIMESTAMP NOT NULL DEFAULT NOW()
 );
@@ -19,7 +20,16 @@ CREATE TABLE ReplicationTasks (
     Schedule VARCHAR(100) NULL, -- e.g., cron expression
     DataSelectionCriteria TEXT NULL, -- e.g., SQL query, S3 prefix
     TransformationRules TEXT NULL, -- e.g., Bloblang script
+    TableRules TEXT NULL, -- JSON include/exclude table patterns for multi-table tasks
+    ColumnTypes TEXT NULL, -- JSON target column type overrides used when creating target tables
+    DriftPolicy VARCHAR(50) NULL, -- 'fail' (default), 'ignore', 'add_columns' or 'quarantine'
+    Partitioning TEXT NULL, -- JSON split column, chunk count and method for parallel chunked extraction
+    ExecutionPolicy TEXT NULL, -- JSON retry policy, timeouts and non-retryable error types of the task's runs
+    StreamConfig TEXT NULL, -- JSON streaming settings (micro-run rollup interval, segment duration); set for continuous tasks
+    MaxConcurrentRuns INT NOT NULL DEFAULT 0, -- Runs of the task at once; 0 for no limit
     TemporalWorkflowID VARCHAR(255) NULL,
+    Watermark TEXT NULL, -- Last incremental position (e.g., API cursor), updated after successful runs
+    SkipNextRun BOOLEAN NOT NULL DEFAULT FALSE, -- Set by skip-next; cleared by the run it skips
     Status VARCHAR(50) NOT NULL, -- e.g., 'active', 'inactive', 'paused'
     CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
     UpdatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
@@ -37,11 +47,228 @@ CREATE TABLE ReplicationRuns (
     EndTime TIMESTAMP NULL, -- Nullable until the run completes
     Status VARCHAR(50) NOT NULL, -- e.g., 'loading', 'running', 'completed', 'failed'
     ErrorDetails TEXT NULL, -- Store error messages if the run failed
+    TemporalWorkflowID VARCHAR(255) NULL, -- Workflow carrying out the run; cancelled with TemporalRunID
     TemporalRunID VARCHAR(255) NULL,
+    ParentRunID BIGINT NULL, -- Set on the per-table runs of a multi-table run
+    TableName VARCHAR(255) NULL, -- Source table (schema.table) of a per-table run
+    SchemaDrift TEXT NULL, -- JSON source schema drift detected by the run
+    SourceFiles TEXT NULL, -- JSON list of the source files pinned by the run, reused on retry and resume
+    Parameters TEXT NULL, -- JSON parameters the run was triggered with
+    LogicalTime TIMESTAMP NULL, -- Scheduled or requested time the run covers
+    RenderedCriteria TEXT NULL, -- Data selection criteria as rendered for the run, reused on retry and resume
+    RerunOfRunID BIGINT NULL, -- Run this run reruns
+    Streaming BOOLEAN NOT NULL DEFAULT FALSE, -- Set on the micro-runs of a streaming task
+    RowsRead BIGINT NOT NULL DEFAULT 0, -- Records read by a micro-run
+    RowsWritten BIGINT NOT NULL DEFAULT 0, -- Records written by a micro-run
     CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
 
     -- Foreign Key constraint
-    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE -- Cascade delete if task is deleted
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE, -- Cascade delete if task is deleted
+    FOREIGN KEY (ParentRunID) REFERENCES ReplicationRuns(ID) ON DELETE CASCADE,
+    FOREIGN KEY (RerunOfRunID) REFERENCES ReplicationRuns(ID) ON DELETE SET NULL
+);
+
+-- ReplicationRunChunks Table: Chunks of a partitioned run, checkpointed so failed chunks retry on their own
+CREATE TABLE ReplicationRunChunks (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationRunID BIGINT NOT NULL,
+    ChunkIndex INT NOT NULL,
+    Predicate TEXT NOT NULL, -- SQL condition on the split column
+    Status VARCHAR(50) NOT NULL, -- e.g., 'pending', 'running', 'completed', 'failed'
+    ErrorDetails TEXT NULL,
+    StartTime TIMESTAMP NULL,
+    EndTime TIMESTAMP NULL,
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (ReplicationRunID) REFERENCES ReplicationRuns(ID) ON DELETE CASCADE,
+    UNIQUE (ReplicationRunID, ChunkIndex)
+);
+
+-- SchemaSnapshots Table: Source columns seen by runs, the baseline for schema drift detection
+CREATE TABLE SchemaSnapshots (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationTaskID BIGINT NOT NULL,
+    ReplicationRunID BIGINT NULL,
+    TableName VARCHAR(255) NOT NULL DEFAULT '', -- Source table (schema.table); empty for query-based tasks
+    Columns TEXT NOT NULL, -- JSON source columns
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE,
+    FOREIGN KEY (ReplicationRunID) REFERENCES ReplicationRuns(ID) ON DELETE SET NULL
+);
+
+-- Events Table: Feed of notable task and run events (e.g., schema drift)
+CREATE TABLE Events (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationTaskID BIGINT NULL,
+    ReplicationRunID BIGINT NULL,
+    Type VARCHAR(50) NOT NULL, -- e.g., 'schema_drift'
+    Message TEXT NOT NULL,
+    Details TEXT NULL, -- JSON event payload
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE,
+    FOREIGN KEY (ReplicationRunID) REFERENCES ReplicationRuns(ID) ON DELETE SET NULL
+);
+
+-- ReplicationLagSamples Table: Replication lag of streaming tasks, measured while they stream
+CREATE TABLE ReplicationLagSamples (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationTaskID BIGINT NOT NULL,
+    ReplicationRunID BIGINT NULL, -- Micro-run the sample was measured in
+    LatencyMs BIGINT NULL, -- Source commit to target apply; NULL when unknown
+    Backlog BIGINT NULL, -- Distance of the target behind the source; NULL when unknown
+    BacklogUnit VARCHAR(20) NOT NULL, -- 'bytes' (Postgres WAL) or 'messages' (Kafka offsets)
+    SourcePosition VARCHAR(100) NULL, -- e.g., the source's current WAL LSN
+    TargetPosition VARCHAR(100) NULL, -- e.g., the replication slot's confirmed LSN
+    MeasuredAt TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE,
+    FOREIGN KEY (ReplicationRunID) REFERENCES ReplicationRuns(ID) ON DELETE SET NULL
+);
+
+-- CompareReports Table: History of source-target compares (row counts and checksums per key range)
+CREATE TABLE CompareReports (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationTaskID BIGINT NOT NULL,
+    Status VARCHAR(50) NOT NULL, -- e.g., 'running', 'completed', 'failed'
+    InSync BOOLEAN NULL, -- Set once completed
+    Options TEXT NULL, -- JSON compare options
+    Result TEXT NULL, -- JSON per-table results with mismatched key ranges
+    ErrorDetails TEXT NULL,
+    TemporalWorkflowID VARCHAR(255) NULL,
+    StartTime TIMESTAMP NOT NULL,
+    EndTime TIMESTAMP NULL,
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE
+);
+
+-- Refreshes Table: Resynchronization of the mismatched key ranges of a compare report
+CREATE TABLE Refreshes (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationTaskID BIGINT NOT NULL,
+    CompareReportID BIGINT NOT NULL,
+    Status VARCHAR(50) NOT NULL, -- e.g., 'running', 'completed', 'failed'
+    Options TEXT NULL, -- JSON refresh options
+    TotalRanges INT NOT NULL DEFAULT 0,
+    CompletedRanges INT NOT NULL DEFAULT 0,
+    RowsDeleted BIGINT NOT NULL DEFAULT 0,
+    RowsWritten BIGINT NOT NULL DEFAULT 0,
+    SkippedTables TEXT NULL, -- Comma-separated tables that were not refreshed
+    ErrorDetails TEXT NULL,
+    TemporalWorkflowID VARCHAR(255) NULL,
+    StartTime TIMESTAMP NOT NULL,
+    EndTime TIMESTAMP NULL,
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE,
+    FOREIGN KEY (CompareReportID) REFERENCES CompareReports(ID) ON DELETE CASCADE
+);
+
+-- Backfills Table: Replication of a task over a date or ID range, in slices
+CREATE TABLE Backfills (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationTaskID BIGINT NOT NULL,
+    Status VARCHAR(50) NOT NULL, -- e.g., 'running', 'paused', 'completed', 'failed', 'cancelled'
+    Options TEXT NOT NULL, -- JSON range, step and max_parallel
+    TotalSlices INT NOT NULL DEFAULT 0,
+    ErrorDetails TEXT NULL,
+    TemporalWorkflowID VARCHAR(255) NULL,
+    StartTime TIMESTAMP NOT NULL,
+    EndTime TIMESTAMP NULL,
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) ON DELETE CASCADE
+);
+
+-- BackfillSlices Table: Per-slice status of a backfill
+CREATE TABLE BackfillSlices (
+    ID BIGSERIAL PRIMARY KEY,
+    BackfillID BIGINT NOT NULL,
+    SliceIndex INT NOT NULL,
+    RangeStart VARCHAR(64) NOT NULL, -- Included
+    RangeEnd VARCHAR(64) NOT NULL, -- Excluded
+    Status VARCHAR(50) NOT NULL, -- e.g., 'pending', 'running', 'completed', 'failed', 'cancelled'
+    ReplicationRunID BIGINT NULL, -- Run that replicated the slice
+    ErrorDetails TEXT NULL,
+    StartTime TIMESTAMP NULL,
+    EndTime TIMESTAMP NULL,
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (BackfillID, SliceIndex),
+    FOREIGN KEY (BackfillID) REFERENCES Backfills(ID) ON DELETE CASCADE,
+    FOREIGN KEY (ReplicationRunID) REFERENCES ReplicationRuns(ID) ON DELETE SET NULL
+);
+
+-- RunLeases Table: Slots held on connections and tasks with a concurrency limit by runs, backfills, compares or refreshes
+CREATE TABLE RunLeases (
+    ID BIGSERIAL PRIMARY KEY,
+    ReplicationRunID BIGINT NULL,
+    BackfillID BIGINT NULL,
+    CompareReportID BIGINT NULL,
+    RefreshID BIGINT NULL,
+    ResourceType VARCHAR(50) NOT NULL, -- 'connection' or 'task'
+    ResourceID BIGINT NOT NULL,
+    AcquiredAt TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (ReplicationRunID) REFERENCES ReplicationRuns(ID) ON DELETE CASCADE,
+    FOREIGN KEY (BackfillID) REFERENCES Backfills(ID) ON DELETE CASCADE,
+    FOREIGN KEY (CompareReportID) REFERENCES CompareReports(ID) ON DELETE CASCADE,
+    FOREIGN KEY (RefreshID) REFERENCES Refreshes(ID) ON DELETE CASCADE,
+    CHECK (num_nonnulls(ReplicationRunID, BackfillID, CompareReportID, RefreshID) = 1),
+    UNIQUE (ReplicationRunID, ResourceType, ResourceID),
+    UNIQUE (BackfillID, ResourceType, ResourceID),
+    UNIQUE (CompareReportID, ResourceType, ResourceID),
+    UNIQUE (RefreshID, ResourceType, ResourceID)
+);
+
+-- Pipelines Table: DAGs of replication tasks that run as one unit
+CREATE TABLE Pipelines (
+    ID BIGSERIAL PRIMARY KEY,
+    Name VARCHAR(255) NOT NULL UNIQUE,
+    Description TEXT NULL,
+    Schedule VARCHAR(255) NULL, -- Cron expression; NULL to run on demand only
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+    UpdatedAt TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- PipelineTasks Table: The replication tasks of a pipeline
+CREATE TABLE PipelineTasks (
+    PipelineID BIGINT NOT NULL,
+    ReplicationTaskID BIGINT NOT NULL,
+    TriggerRule VARCHAR(50) NOT NULL DEFAULT 'all_succeeded', -- 'all_succeeded', 'any_failed' or 'always'
+    Position INT NOT NULL, -- Order the tasks were listed in
+
+    PRIMARY KEY (PipelineID, ReplicationTaskID),
+    FOREIGN KEY (PipelineID) REFERENCES Pipelines(ID) ON DELETE CASCADE,
+    FOREIGN KEY (ReplicationTaskID) REFERENCES ReplicationTasks(ID) -- A task can't be deleted while a pipeline uses it
+);
+
+-- PipelineTaskDependencies Table: Edges of a pipeline's DAG; the task runs after DependsOnTaskID
+CREATE TABLE PipelineTaskDependencies (
+    PipelineID BIGINT NOT NULL,
+    ReplicationTaskID BIGINT NOT NULL,
+    DependsOnTaskID BIGINT NOT NULL,
+
+    PRIMARY KEY (PipelineID, ReplicationTaskID, DependsOnTaskID),
+    FOREIGN KEY (PipelineID, ReplicationTaskID) REFERENCES PipelineTasks(PipelineID, ReplicationTaskID) ON DELETE CASCADE,
+    FOREIGN KEY (PipelineID, DependsOnTaskID) REFERENCES PipelineTasks(PipelineID, ReplicationTaskID) ON DELETE CASCADE
+);
+
+-- PipelineRuns Table: Executions of a pipeline
+CREATE TABLE PipelineRuns (
+    ID BIGSERIAL PRIMARY KEY,
+    PipelineID BIGINT NOT NULL,
+    Status VARCHAR(50) NOT NULL, -- e.g., 'running', 'completed', 'failed'
+    TaskStatuses TEXT NULL, -- JSON task ID to status
+    ErrorDetails TEXT NULL,
+    TemporalWorkflowID VARCHAR(255) NULL,
+    StartTime TIMESTAMP NOT NULL,
+    EndTime TIMESTAMP NULL,
+    CreatedAt TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (PipelineID) REFERENCES Pipelines(ID) ON DELETE CASCADE
 );
 
 -- BenthosConfigurations Table: Stores reusable Benthos pipeline snippets or full configs
@@ -58,5 +285,17 @@ CREATE INDEX IX_ReplicationTasks_SourceConnectionID ON ReplicationTasks(SourceCo
 CREATE INDEX IX_ReplicationTasks_TargetConnectionID ON ReplicationTasks(TargetConnectionID);
 CREATE INDEX IX_ReplicationRuns_ReplicationTaskID ON ReplicationRuns(ReplicationTaskID);
 CREATE INDEX IX_ReplicationRuns_Status ON ReplicationRuns(Status);
+CREATE INDEX IX_ReplicationRuns_ParentRunID ON ReplicationRuns(ParentRunID);
+CREATE INDEX IX_ReplicationRuns_TemporalRunID ON ReplicationRuns(TemporalRunID);
+CREATE INDEX IX_ReplicationRuns_Unfinished ON ReplicationRuns(StartTime) WHERE EndTime IS NULL;
+CREATE INDEX IX_RunLeases_Resource ON RunLeases(ResourceType, ResourceID);
+CREATE INDEX IX_SchemaSnapshots_Task_Table ON SchemaSnapshots(ReplicationTaskID, TableName, ID);
+CREATE INDEX IX_Events_ReplicationTaskID ON Events(ReplicationTaskID, ID);
+CREATE INDEX IX_ReplicationLagSamples_Task_MeasuredAt ON ReplicationLagSamples(ReplicationTaskID, MeasuredAt);
+CREATE INDEX IX_CompareReports_ReplicationTaskID ON CompareReports(ReplicationTaskID);
+CREATE INDEX IX_Refreshes_ReplicationTaskID ON Refreshes(ReplicationTaskID);
+CREATE INDEX IX_Backfills_ReplicationTaskID ON Backfills(ReplicationTaskID);
+CREATE INDEX IX_PipelineTasks_ReplicationTaskID ON PipelineTasks(ReplicationTaskID);
+CREATE INDEX IX_PipelineRuns_PipelineID ON PipelineRuns(PipelineID);
 
 -- Note: Syntax for IDENTITY, DEFAULT GETDATE(), TIMESTAMP might vary slightly depending on the specific SQL database (e.g., PostgreSQL, MySQL). Adjust as needed. 
\ No newline at end of file